@@ -1,14 +1,16 @@
 package bilibili
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -22,30 +24,64 @@ const (
 // Bilibili 结构体
 type Bilibili struct {
 	cookie string
+	client *utils.Client
 }
 
 // New 初始化函数
-func New(cookie string) *Bilibili {
+func New(cookie string, opts ...utils.ClientOption) *Bilibili {
 	return &Bilibili{
 		cookie: cookie,
+		client: utils.NewClient(opts...),
 	}
 }
 
-var defaultBilibili = New("buvid3=2E109C72-251F-3827-FA8E-921FA0D7EC5291319infoc; SESSDATA=your_sessdata;")
+// Client 返回实例使用的 HTTP 客户端
+func (b *Bilibili) Client() *utils.Client { return b.client }
 
-func Search(keyword string) ([]model.Song, error) { return defaultBilibili.Search(keyword) }
+var (
+	defaultMu       sync.RWMutex
+	defaultBilibili = New("buvid3=2E109C72-251F-3827-FA8E-921FA0D7EC5291319infoc; SESSDATA=your_sessdata;")
+)
+
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultBilibili = New(defaultBilibili.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Bilibili {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultBilibili
+}
+
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultBilibili.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
+}
+func GetPlaylistSongs(id string) ([]model.Song, error) { return getDefault().GetPlaylistSongs(id) }
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
-func GetPlaylistSongs(id string) ([]model.Song, error) { return defaultBilibili.GetPlaylistSongs(id) }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
-	return defaultBilibili.ParsePlaylist(link)
+	return getDefault().ParsePlaylist(link)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return defaultBilibili.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return defaultBilibili.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)       { return defaultBilibili.Parse(link) }
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
 
 type bilibiliViewResponse struct {
+	Code int `json:"code"`
 	Data struct {
 		BVID  string `json:"bvid"`
 		Title string `json:"title"`
@@ -143,6 +179,94 @@ func countSeasonEpisodes(sections []bilibiliSeasonSection) int {
 	return count
 }
 
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
+
+func (b *Bilibili) Search(keyword string) ([]model.Song, error) {
+	return b.SearchContext(context.Background(), keyword)
+}
+
+func (b *Bilibili) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return b.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (b *Bilibili) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return b.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (b *Bilibili) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return b.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (b *Bilibili) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return b.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (b *Bilibili) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return b.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (b *Bilibili) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return b.ParsePlaylistContext(context.Background(), link)
+}
+
+func (b *Bilibili) Parse(link string) (*model.Song, error) {
+	return b.ParseContext(context.Background(), link)
+}
+
+func (b *Bilibili) GetDownloadURL(s *model.Song) (string, error) {
+	return b.GetDownloadURLContext(context.Background(), s)
+}
+
+func (b *Bilibili) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return b.GetStreamsContext(context.Background(), s)
+}
+
+func (b *Bilibili) GetLyrics(s *model.Song) (string, error) {
+	return b.GetLyricsContext(context.Background(), s)
+}
+
 func (b *Bilibili) buildSongsFromSeasonSections(sections []bilibiliSeasonSection, seasonTitle, seasonCover, artistName string, archiveIndex map[string]bilibiliSeasonArchiveMeta) []model.Song {
 	var songs []model.Song
 	if artistName == "" {
@@ -227,9 +351,9 @@ func needsSeasonArchiveFallback(sections []bilibiliSeasonSection) bool {
 	return false
 }
 
-func (b *Bilibili) fetchSeasonArchiveIndex(mid, seasonID int64) (map[string]bilibiliSeasonArchiveMeta, string, string, error) {
+func (b *Bilibili) fetchSeasonArchiveIndex(ctx context.Context, mid, seasonID int64) (map[string]bilibiliSeasonArchiveMeta, string, string, error) {
 	if seasonID == 0 || mid == 0 {
-		return nil, "", "", errors.New("invalid season info")
+		return nil, "", "", model.Errorf("bilibili", model.ErrInvalidInput, "invalid season info")
 	}
 
 	index := make(map[string]bilibiliSeasonArchiveMeta)
@@ -240,7 +364,7 @@ func (b *Bilibili) fetchSeasonArchiveIndex(mid, seasonID int64) (map[string]bili
 	pageSize := 30
 	for {
 		apiURL := fmt.Sprintf("https://api.bilibili.com/x/space/ugc/season?mid=%d&season_id=%d&page_num=%d&page_size=%d", mid, seasonID, pageNum, pageSize)
-		body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+		body, err := b.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -250,7 +374,7 @@ func (b *Bilibili) fetchSeasonArchiveIndex(mid, seasonID int64) (map[string]bili
 			return nil, "", "", err
 		}
 		if resp.Code != 0 {
-			return nil, "", "", fmt.Errorf("bilibili season api error: %d", resp.Code)
+			return nil, "", "", apiError(resp.Code)
 		}
 		if seasonTitle == "" {
 			seasonTitle = resp.Data.Season.Title
@@ -293,23 +417,26 @@ func normalizeCover(cover string) string {
 	return cover
 }
 
-func (b *Bilibili) fetchView(bvid string) (*bilibiliViewResponse, error) {
+func (b *Bilibili) fetchView(ctx context.Context, bvid string) (*bilibiliViewResponse, error) {
 	viewURL := fmt.Sprintf("https://api.bilibili.com/x/web-interface/view?bvid=%s", bvid)
-	viewBody, err := utils.Get(viewURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", b.cookie))
+	viewBody, err := b.client.GetContext(ctx, viewURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", b.cookie))
 	if err != nil {
 		return nil, err
 	}
 
 	var viewResp bilibiliViewResponse
 	if err := json.Unmarshal(viewBody, &viewResp); err != nil {
-		return nil, err
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "view json parse error: %w", err)
+	}
+	if viewResp.Code != 0 {
+		return nil, apiError(viewResp.Code)
 	}
 	return &viewResp, nil
 }
 
-func (b *Bilibili) fetchPageList(bvid string) ([]bilibiliPage, error) {
+func (b *Bilibili) fetchPageList(ctx context.Context, bvid string) ([]bilibiliPage, error) {
 	pageURL := fmt.Sprintf("https://api.bilibili.com/x/player/pagelist?bvid=%s", bvid)
-	body, err := utils.Get(pageURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+	body, err := b.client.GetContext(ctx, pageURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
 	if err != nil {
 		return nil, err
 	}
@@ -319,7 +446,7 @@ func (b *Bilibili) fetchPageList(bvid string) ([]bilibiliPage, error) {
 		return nil, err
 	}
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("bilibili pagelist api error: %d", resp.Code)
+		return nil, apiError(resp.Code)
 	}
 	return resp.Data, nil
 }
@@ -354,16 +481,26 @@ func (b *Bilibili) buildSongsFromPages(bvid, rootTitle, author, cover string, pa
 	return songs
 }
 
-// Search 搜索歌曲
-func (b *Bilibili) Search(keyword string) ([]model.Song, error) {
+// SearchContext 搜索歌曲
+func (b *Bilibili) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := b.SearchPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索视频。每条结果都要额外请求一次 view 接口，limit 不宜过大
+func (b *Bilibili) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 50)
 	params := url.Values{}
 	params.Set("search_type", "video")
 	params.Set("keyword", keyword)
-	params.Set("page", "1")
-	params.Set("page_size", "20")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("page_size", strconv.Itoa(limit))
 
 	searchURL := "https://api.bilibili.com/x/web-interface/search/type?" + params.Encode()
-	body, err := utils.Get(searchURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+	body, err := b.client.GetContext(ctx, searchURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
 	if err != nil {
 		return nil, err
 	}
@@ -376,24 +513,25 @@ func (b *Bilibili) Search(keyword string) ([]model.Song, error) {
 				Author string `json:"author"`
 				Pic    string `json:"pic"`
 			} `json:"result"`
+			NumResults int `json:"numResults"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return nil, fmt.Errorf("bilibili search json error: %w", err)
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "search json error: %w", err)
 	}
 
 	var songs []model.Song
 	for _, item := range searchResp.Data.Result {
 		rootTitle := cleanTitle(item.Title)
-		viewResp, err := b.fetchView(item.BVID)
+		viewResp, err := b.fetchView(ctx, item.BVID)
 		if err != nil || len(viewResp.Data.Pages) == 0 {
 			continue
 		}
 
 		cover := normalizeCover(item.Pic)
-		page := viewResp.Data.Pages[0]
-		displayTitle := page.Part
+		part := viewResp.Data.Pages[0]
+		displayTitle := part.Part
 		if displayTitle == "" {
 			displayTitle = rootTitle
 		} else if displayTitle != rootTitle {
@@ -406,28 +544,39 @@ func (b *Bilibili) Search(keyword string) ([]model.Song, error) {
 			Name:     displayTitle,
 			Artist:   item.Author,
 			Album:    item.BVID,
-			Duration: page.Duration,
+			Duration: part.Duration,
 			Cover:    cover,
 			Link:     fmt.Sprintf("https://www.bilibili.com/video/%s?p=1", item.BVID),
 			Extra: map[string]string{
 				"bvid": item.BVID,
-				"cid":  strconv.FormatInt(page.CID, 10),
+				"cid":  strconv.FormatInt(part.CID, 10),
 			},
 		})
 	}
-	return songs, nil
+	return model.NewPage(songs, page, limit, searchResp.Data.NumResults, len(searchResp.Data.Result)), nil
 }
 
-// SearchPlaylist 搜索合集/分P
-func (b *Bilibili) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索合集/分P
+func (b *Bilibili) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := b.SearchPlaylistPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索合集/分P。翻页针对的是视频搜索结果，
+// 单页视频中只有合集和多P视频会被收录，因此 Items 可能少于 limit
+func (b *Bilibili) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 50)
 	params := url.Values{}
 	params.Set("search_type", "video")
 	params.Set("keyword", keyword)
-	params.Set("page", "1")
-	params.Set("page_size", "20")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("page_size", strconv.Itoa(limit))
 
 	searchURL := "https://api.bilibili.com/x/web-interface/search/type?" + params.Encode()
-	body, err := utils.Get(searchURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+	body, err := b.client.GetContext(ctx, searchURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
 	if err != nil {
 		return nil, err
 	}
@@ -440,16 +589,17 @@ func (b *Bilibili) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 				Author string `json:"author"`
 				Pic    string `json:"pic"`
 			} `json:"result"`
+			NumResults int `json:"numResults"`
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return nil, fmt.Errorf("bilibili search json error: %w", err)
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "search json error: %w", err)
 	}
 
 	plMap := make(map[string]bool)
 	var playlists []model.Playlist
 	for _, item := range searchResp.Data.Result {
-		viewResp, err := b.fetchView(item.BVID)
+		viewResp, err := b.fetchView(ctx, item.BVID)
 		if err != nil {
 			continue
 		}
@@ -475,7 +625,7 @@ func (b *Bilibili) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 
 			trackCount := countSeasonEpisodes(viewResp.Data.UgcSeason.Sections)
 			if trackCount == 0 && seasonID != 0 && mid != 0 {
-				seasonSongs, err := b.fetchSeasonSongs(mid, seasonID)
+				seasonSongs, err := b.fetchSeasonSongs(ctx, mid, seasonID)
 				if err == nil {
 					trackCount = len(seasonSongs)
 				}
@@ -522,15 +672,15 @@ func (b *Bilibili) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			})
 		}
 	}
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, searchResp.Data.NumResults, len(searchResp.Data.Result)), nil
 }
 
-// GetPlaylistSongs 获取合集/分P所有歌曲
-func (b *Bilibili) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取合集/分P所有歌曲
+func (b *Bilibili) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
 	if strings.HasPrefix(id, "season:") {
 		parts := strings.Split(id, ":")
 		if len(parts) < 3 {
-			return nil, errors.New("invalid season id")
+			return nil, model.Errorf("bilibili", model.ErrInvalidInput, "invalid season id")
 		}
 		seasonID, _ := strconv.ParseInt(parts[1], 10, 64)
 		mid, _ := strconv.ParseInt(parts[2], 10, 64)
@@ -539,13 +689,13 @@ func (b *Bilibili) GetPlaylistSongs(id string) ([]model.Song, error) {
 			bvid = parts[3]
 		}
 		if bvid != "" {
-			viewResp, err := b.fetchView(bvid)
+			viewResp, err := b.fetchView(ctx, bvid)
 			if err == nil && viewResp.Data.UgcSeason != nil {
 				sections := viewResp.Data.UgcSeason.Sections
 				archiveIndex := map[string]bilibiliSeasonArchiveMeta{}
 				seasonTitle := viewResp.Data.UgcSeason.Title
 				seasonCover := viewResp.Data.UgcSeason.Cover
-				idx, sTitle, sCover, idxErr := b.fetchSeasonArchiveIndex(mid, seasonID)
+				idx, sTitle, sCover, idxErr := b.fetchSeasonArchiveIndex(ctx, mid, seasonID)
 				if idxErr == nil {
 					archiveIndex = idx
 					if seasonTitle == "" {
@@ -561,37 +711,47 @@ func (b *Bilibili) GetPlaylistSongs(id string) ([]model.Song, error) {
 				}
 			}
 		}
-		return b.fetchSeasonSongs(mid, seasonID)
+		return b.fetchSeasonSongs(ctx, mid, seasonID)
 	}
 
 	bvid := strings.TrimPrefix(id, "bvid:")
 	if bvid == "" {
-		return nil, errors.New("invalid playlist id")
+		return nil, model.Errorf("bilibili", model.ErrInvalidInput, "invalid playlist id")
 	}
-	viewResp, err := b.fetchView(bvid)
+	viewResp, err := b.fetchView(ctx, bvid)
 	if err != nil {
 		return nil, err
 	}
 	rootTitle := viewResp.Data.Title
 	pages := viewResp.Data.Pages
 	if len(pages) <= 1 {
-		if pageList, err := b.fetchPageList(bvid); err == nil && len(pageList) > 0 {
+		if pageList, err := b.fetchPageList(ctx, bvid); err == nil && len(pageList) > 0 {
 			pages = pageList
 		}
 	}
 	if len(pages) == 0 {
-		return nil, errors.New("no video pages found")
+		return nil, model.Errorf("bilibili", model.ErrNotFound, "no video pages found")
 	}
 	return b.buildSongsFromPages(bvid, rootTitle, viewResp.Data.Owner.Name, viewResp.Data.Pic, pages), nil
 }
 
-// ParsePlaylist 解析合集/分P链接
-func (b *Bilibili) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取合集/分P歌曲
+func (b *Bilibili) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	songs, err := b.GetPlaylistSongsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParsePlaylistContext 解析合集/分P链接
+func (b *Bilibili) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	bvidRe := regexp.MustCompile(`(BV\w+)`)
 	bvidMatches := bvidRe.FindStringSubmatch(link)
 	if len(bvidMatches) >= 2 {
 		bvid := bvidMatches[1]
-		viewResp, err := b.fetchView(bvid)
+		viewResp, err := b.fetchView(ctx, bvid)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -620,7 +780,7 @@ func (b *Bilibili) ParsePlaylist(link string) (*model.Playlist, []model.Song, er
 			archiveIndex := map[string]bilibiliSeasonArchiveMeta{}
 			seasonTitle := viewResp.Data.UgcSeason.Title
 			seasonCover := viewResp.Data.UgcSeason.Cover
-			idx, sTitle, sCover, idxErr := b.fetchSeasonArchiveIndex(mid, seasonID)
+			idx, sTitle, sCover, idxErr := b.fetchSeasonArchiveIndex(ctx, mid, seasonID)
 			if idxErr == nil {
 				archiveIndex = idx
 				if seasonTitle == "" {
@@ -638,7 +798,7 @@ func (b *Bilibili) ParsePlaylist(link string) (*model.Playlist, []model.Song, er
 			if len(songs) > 0 {
 				return playlist, songs, nil
 			}
-			songs, err := b.fetchSeasonSongs(mid, seasonID)
+			songs, err := b.fetchSeasonSongs(ctx, mid, seasonID)
 			return playlist, songs, err
 		}
 
@@ -679,12 +839,12 @@ func (b *Bilibili) ParsePlaylist(link string) (*model.Playlist, []model.Song, er
 		}
 	}
 
-	return nil, nil, errors.New("invalid bilibili playlist link")
+	return nil, nil, model.Errorf("bilibili", model.ErrInvalidInput, "invalid bilibili playlist link")
 }
 
-func (b *Bilibili) fetchSeasonSongs(mid, seasonID int64) ([]model.Song, error) {
+func (b *Bilibili) fetchSeasonSongs(ctx context.Context, mid, seasonID int64) ([]model.Song, error) {
 	if seasonID == 0 || mid == 0 {
-		return nil, errors.New("invalid season info")
+		return nil, model.Errorf("bilibili", model.ErrInvalidInput, "invalid season info")
 	}
 
 	var allSongs []model.Song
@@ -695,7 +855,7 @@ func (b *Bilibili) fetchSeasonSongs(mid, seasonID int64) ([]model.Song, error) {
 	pageSize := 30
 	for {
 		apiURL := fmt.Sprintf("https://api.bilibili.com/x/space/ugc/season?mid=%d&season_id=%d&page_num=%d&page_size=%d", mid, seasonID, pageNum, pageSize)
-		body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+		body, err := b.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
 		if err != nil {
 			return nil, err
 		}
@@ -705,7 +865,7 @@ func (b *Bilibili) fetchSeasonSongs(mid, seasonID int64) ([]model.Song, error) {
 			return nil, err
 		}
 		if resp.Code != 0 {
-			return nil, fmt.Errorf("bilibili season api error: %d", resp.Code)
+			return nil, apiError(resp.Code)
 		}
 		if seasonTitle == "" {
 			seasonTitle = resp.Data.Season.Title
@@ -751,13 +911,13 @@ func (b *Bilibili) fetchSeasonSongs(mid, seasonID int64) ([]model.Song, error) {
 	return allSongs, nil
 }
 
-// Parse 解析链接并获取完整信息（包括下载链接）
-func (b *Bilibili) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接并获取完整信息（包括下载链接）
+func (b *Bilibili) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	// 1. 提取 BVID
 	bvidRe := regexp.MustCompile(`(BV\w+)`)
 	bvidMatches := bvidRe.FindStringSubmatch(link)
 	if len(bvidMatches) < 2 {
-		return nil, errors.New("invalid bilibili link: bvid not found")
+		return nil, model.Errorf("bilibili", model.ErrInvalidInput, "invalid bilibili link: bvid not found")
 	}
 	bvid := bvidMatches[1]
 
@@ -772,20 +932,20 @@ func (b *Bilibili) Parse(link string) (*model.Song, error) {
 	}
 
 	// 3. 调用 View 接口获取元数据
-	viewResp, err := b.fetchView(bvid)
+	viewResp, err := b.fetchView(ctx, bvid)
 	if err != nil {
 		return nil, err
 	}
 	if viewResp.Data.UgcSeason != nil || len(viewResp.Data.Pages) > 1 {
-		return nil, errors.New("playlist link detected")
+		return nil, model.Errorf("bilibili", model.ErrInvalidInput, "playlist link detected")
 	}
 	if len(viewResp.Data.Pages) <= 1 {
-		if pages, err := b.fetchPageList(bvid); err == nil && len(pages) > 1 {
-			return nil, errors.New("playlist link detected")
+		if pages, err := b.fetchPageList(ctx, bvid); err == nil && len(pages) > 1 {
+			return nil, model.Errorf("bilibili", model.ErrInvalidInput, "playlist link detected")
 		}
 	}
 	if len(viewResp.Data.Pages) == 0 {
-		return nil, errors.New("no video pages found")
+		return nil, model.Errorf("bilibili", model.ErrNotFound, "no video pages found")
 	}
 
 	if page > len(viewResp.Data.Pages) {
@@ -808,7 +968,7 @@ func (b *Bilibili) Parse(link string) (*model.Song, error) {
 	cidStr := strconv.FormatInt(targetPage.CID, 10)
 
 	// 4. 立即获取下载链接
-	audioURL, audioErr := b.fetchAudioURL(bvid, cidStr)
+	audioURL, audioErr := b.fetchAudioURL(ctx, bvid, cidStr)
 	if audioErr != nil {
 		slog.Warn("[bilibili] fetch audio url failed, returning metadata only", "bvid", bvid, "error", audioErr)
 	}
@@ -830,10 +990,10 @@ func (b *Bilibili) Parse(link string) (*model.Song, error) {
 	}, nil
 }
 
-// GetDownloadURL 获取下载链接
-func (b *Bilibili) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (b *Bilibili) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "bilibili" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	if s.URL != "" {
@@ -847,57 +1007,152 @@ func (b *Bilibili) GetDownloadURL(s *model.Song) (string, error) {
 	}
 
 	if bvid == "" || cid == "" {
-		return "", fmt.Errorf("[bilibili] missing extra data for song %s", s.ID)
+		return "", model.Errorf("bilibili", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
 	}
 
-	return b.fetchAudioURL(bvid, cid)
+	streams, err := b.fetchStreams(ctx, bvid, cid)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("bilibili", model.ErrNotFound, "no audio found for bvid=%s cid=%s", bvid, cid)
+	}
+	return st.URL, nil
 }
 
-// fetchAudioURL 内部逻辑提取
-func (b *Bilibili) fetchAudioURL(bvid, cid string) (string, error) {
-	apiURL := fmt.Sprintf("https://api.bilibili.com/x/player/playurl?fnval=80&qn=127&bvid=%s&cid=%s", bvid, cid)
-	body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+// GetStreamsContext 列出歌曲可用的音频流。B 站音频需要带 Referer 才能下载
+func (b *Bilibili) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "bilibili" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	var bvid, cid string
+	if s.Extra != nil {
+		bvid = s.Extra["bvid"]
+		cid = s.Extra["cid"]
+	}
+	if bvid == "" || cid == "" {
+		return nil, model.Errorf("bilibili", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
+	}
+	return b.fetchStreams(ctx, bvid, cid)
+}
+
+// fetchAudioURL 内部逻辑提取，按 无损 > dash 音频 > durl 的顺序取第一个
+func (b *Bilibili) fetchAudioURL(ctx context.Context, bvid, cid string) (string, error) {
+	streams, err := b.fetchStreams(ctx, bvid, cid)
 	if err != nil {
 		return "", err
 	}
+	return streams[0].URL, nil
+}
 
+// fetchStreams 获取 playurl 返回的全部音频流，依次为无损、dash 音频（码率从高到低）和 durl
+func (b *Bilibili) fetchStreams(ctx context.Context, bvid, cid string) ([]model.Stream, error) {
+	apiURL := fmt.Sprintf("https://api.bilibili.com/x/player/playurl?fnval=80&qn=127&bvid=%s&cid=%s", bvid, cid)
+	body, err := b.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+	if err != nil {
+		return nil, err
+	}
+
+	type dashAudio struct {
+		ID        int    `json:"id"`
+		BaseURL   string `json:"baseUrl"`
+		Bandwidth int    `json:"bandwidth"` // bps
+	}
 	var resp struct {
+		Code int `json:"code"`
 		Data struct {
 			Durl []struct {
-				URL string `json:"url"`
+				URL  string `json:"url"`
+				Size int64  `json:"size"`
 			} `json:"durl"`
 			Dash struct {
-				Audio []struct {
-					BaseURL string `json:"baseUrl"`
-				} `json:"audio"`
-				Flac struct {
-					Audio []struct {
-						BaseURL string `json:"baseUrl"`
-					} `json:"audio"`
+				Audio []dashAudio `json:"audio"`
+				Flac  struct {
+					Audio []dashAudio `json:"audio"`
 				} `json:"flac"`
 			} `json:"dash"`
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("[bilibili] playurl json parse error for bvid=%s cid=%s: %w", bvid, cid, err)
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "playurl json parse error for bvid=%s cid=%s: %w", bvid, cid, err)
+	}
+	if resp.Code != 0 {
+		return nil, apiError(resp.Code)
 	}
 
-	if len(resp.Data.Dash.Flac.Audio) > 0 {
-		return resp.Data.Dash.Flac.Audio[0].BaseURL, nil
+	headers := map[string]string{"User-Agent": UserAgent, "Referer": Referer}
+	var streams []model.Stream
+	for _, a := range resp.Data.Dash.Flac.Audio {
+		if a.BaseURL != "" {
+			streams = append(streams, model.Stream{URL: a.BaseURL, Ext: "flac", Label: strconv.Itoa(a.ID), Headers: headers})
+		}
 	}
-	if len(resp.Data.Dash.Audio) > 0 {
-		return resp.Data.Dash.Audio[0].BaseURL, nil
+	audios := resp.Data.Dash.Audio
+	sort.SliceStable(audios, func(i, j int) bool { return audios[i].Bandwidth > audios[j].Bandwidth })
+	for _, a := range audios {
+		if a.BaseURL != "" {
+			streams = append(streams, model.Stream{URL: a.BaseURL, Ext: "m4a", Bitrate: a.Bandwidth / 1000, Label: strconv.Itoa(a.ID), Headers: headers})
+		}
 	}
-	if len(resp.Data.Durl) > 0 {
-		return resp.Data.Durl[0].URL, nil
+	for _, d := range resp.Data.Durl {
+		if d.URL != "" {
+			streams = append(streams, model.Stream{URL: d.URL, Ext: "mp4", Size: d.Size, Label: "durl", Headers: headers})
+		}
 	}
 
-	return "", fmt.Errorf("[bilibili] no audio found for bvid=%s cid=%s", bvid, cid)
+	if len(streams) == 0 {
+		return nil, model.Errorf("bilibili", model.ErrNotFound, "no audio found for bvid=%s cid=%s", bvid, cid)
+	}
+	return streams, nil
 }
 
-func (b *Bilibili) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 把视频的 CC 字幕 (没有时用 AI 字幕) 转换为 LRC 歌词。
+// 视频没有字幕时返回空字符串
+func (b *Bilibili) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "bilibili" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
+	}
+
+	var bvid, cid string
+	if s.Extra != nil {
+		bvid = s.Extra["bvid"]
+		cid = s.Extra["cid"]
+	}
+	if bvid == "" || cid == "" {
+		return "", model.Errorf("bilibili", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
+	}
+
+	tracks, err := b.fetchSubtitleTracks(ctx, bvid, cid)
+	if err != nil {
+		return "", err
+	}
+	track, ok := pickSubtitle(tracks)
+	if !ok {
+		return "", nil
+	}
+	cues, err := b.fetchSubtitle(ctx, track.SubtitleURL)
+	if err != nil {
+		return "", err
+	}
+	return subtitleLyrics(s, cues).LRC(), nil
+}
+
+// apiError 把 B 站接口返回的 code 映射为统一的错误分类
+func apiError(code int) error {
+	var kind error
+	switch code {
+	case -101:
+		kind = model.ErrLoginExpired
+	case -404, 62002, 62004:
+		kind = model.ErrNotFound
+	case -403, 62012:
+		kind = model.ErrRestricted
+	case -412, -509, -799:
+		kind = model.ErrRateLimited
+	case -400:
+		kind = model.ErrInvalidInput
 	}
-	return "", nil
+	return model.CodeError("bilibili", kind, code, "api error")
 }
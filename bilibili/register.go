@@ -0,0 +1,12 @@
+package bilibili
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "bilibili",
+		DisplayName: "Bilibili",
+		Canary:      registry.Canary{Keyword: "周杰伦"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
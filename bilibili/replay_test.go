@@ -0,0 +1,29 @@
+package bilibili
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Bilibili]{
+		// Search fetches the view API for every result; removed videos are skipped.
+		{Name: "search", Call: func(_ *testing.T, b *Bilibili) (any, error) { return b.Search("翻唱") }},
+		// Uploaded subtitles are preferred over AI ones and converted to LRC,
+		// with an empty line closing each gap.
+		{Name: "lyrics", Call: func(_ *testing.T, b *Bilibili) (any, error) {
+			return b.GetLyrics(&model.Song{
+				Source: "bilibili",
+				ID:     "BV1xx411c7mD",
+				Name:   "起风了",
+				Artist: "某UP主",
+				Extra:  map[string]string{"bvid": "BV1xx411c7mD", "cid": "279786"},
+			})
+		}},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Bilibili { return New("", opts...) }
@@ -0,0 +1,116 @@
+package bilibili
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// subtitleTrack is one entry of the player API's subtitle list.
+type subtitleTrack struct {
+	Lan         string `json:"lan"`
+	LanDoc      string `json:"lan_doc"`
+	SubtitleURL string `json:"subtitle_url"`
+}
+
+// subtitleCue is one cue of a BCC subtitle file; times are in seconds.
+type subtitleCue struct {
+	From    float64 `json:"from"`
+	To      float64 `json:"to"`
+	Content string  `json:"content"`
+}
+
+// fetchSubtitleTracks lists the CC and AI subtitle tracks of a video page.
+// Most tracks are only listed for a logged-in cookie.
+func (b *Bilibili) fetchSubtitleTracks(ctx context.Context, bvid, cid string) ([]subtitleTrack, error) {
+	apiURL := fmt.Sprintf("https://api.bilibili.com/x/player/v2?bvid=%s&cid=%s", bvid, cid)
+	body, err := b.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer), utils.WithHeader("Cookie", b.cookie))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code int `json:"code"`
+		Data struct {
+			Subtitle struct {
+				Subtitles []subtitleTrack `json:"subtitles"`
+			} `json:"subtitle"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "player json parse error for bvid=%s cid=%s: %w", bvid, cid, err)
+	}
+	if resp.Code != 0 {
+		return nil, apiError(resp.Code)
+	}
+	return resp.Data.Subtitle.Subtitles, nil
+}
+
+// pickSubtitle prefers uploaded subtitles over AI-generated ones ("ai-"
+// language codes) and Chinese over other languages.
+func pickSubtitle(tracks []subtitleTrack) (subtitleTrack, bool) {
+	best, bestScore := subtitleTrack{}, -1
+	for _, t := range tracks {
+		if t.SubtitleURL == "" {
+			continue
+		}
+		score := 0
+		if !strings.HasPrefix(t.Lan, "ai-") {
+			score += 2
+		}
+		if strings.Contains(strings.TrimPrefix(t.Lan, "ai-"), "zh") {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// fetchSubtitle downloads a BCC subtitle file.
+func (b *Bilibili) fetchSubtitle(ctx context.Context, subtitleURL string) ([]subtitleCue, error) {
+	if strings.HasPrefix(subtitleURL, "//") {
+		subtitleURL = "https:" + subtitleURL
+	}
+	body, err := b.client.GetContext(ctx, subtitleURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Referer", Referer))
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Body []subtitleCue `json:"body"`
+	}
+	if err := json.Unmarshal(body, &file); err != nil {
+		return nil, model.Errorf("bilibili", model.ErrSchemaChanged, "subtitle json parse error: %w", err)
+	}
+	return file.Body, nil
+}
+
+// subtitleLyrics turns subtitle cues into lyrics. A cue that ends before the
+// next one starts is followed by an empty line, so players clear the text
+// during instrumental gaps instead of holding the last line.
+func subtitleLyrics(s *model.Song, cues []subtitleCue) *model.Lyrics {
+	l := &model.Lyrics{Tags: map[string]string{}}
+	if s.Name != "" {
+		l.Tags["ti"] = s.Name
+	}
+	if s.Artist != "" {
+		l.Tags["ar"] = s.Artist
+	}
+	for i, c := range cues {
+		text := strings.Join(strings.Fields(c.Content), " ")
+		if text == "" {
+			continue
+		}
+		start, end := int(c.From*1000+0.5), int(c.To*1000+0.5)
+		l.Lines = append(l.Lines, model.LyricLine{Start: start, Duration: end - start, Text: text})
+		if i+1 == len(cues) || int(cues[i+1].From*1000+0.5) > end {
+			l.Lines = append(l.Lines, model.LyricLine{Start: end})
+		}
+	}
+	return l
+}
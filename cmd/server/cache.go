@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/internal/store"
+	"gorm.io/gorm"
+)
+
+// newResponseCache builds the API response cache from the environment:
+//
+//	CACHE_SIZE          entries kept in memory (default 1000); 0 disables the cache
+//	CACHE_PERSIST       "true" also keeps entries in the SQLite database so they
+//	                    survive a restart (default false)
+//	CACHE_TTL_SEARCH    lifetime of search results (default 10m)
+//	CACHE_TTL_PLAYLIST  lifetime of playlist contents (default 30m)
+//	CACHE_TTL_CHARTS    lifetime of chart lists and chart contents (default 1h)
+//	CACHE_TTL_LYRICS    lifetime of lyrics (default 24h)
+//
+// A TTL of 0 disables caching for that kind. It returns nil when the cache is off.
+func newResponseCache(db *gorm.DB) (*cache.Cache, error) {
+	size := cache.DefaultSize
+	if raw := os.Getenv("CACHE_SIZE"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("CACHE_SIZE: invalid value %q", raw)
+		}
+		size = n
+	}
+	if size == 0 {
+		slog.Info("response cache disabled")
+		return nil, nil
+	}
+
+	opts := cache.Options{Size: size, TTL: map[cache.Kind]time.Duration{}}
+	for _, kind := range []cache.Kind{cache.Search, cache.Playlist, cache.Charts, cache.Lyrics} {
+		key := "CACHE_TTL_" + strings.ToUpper(string(kind))
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: invalid duration %q", key, raw)
+		}
+		opts.TTL[kind] = d
+	}
+
+	if envBool("CACHE_PERSIST", false) {
+		if n, err := store.PurgeExpiredCache(db, time.Now()); err != nil {
+			slog.Warn("purge expired cache", "error", err)
+		} else if n > 0 {
+			slog.Info("purged expired cache entries", "count", n)
+		}
+		opts.Backend = store.NewCacheBackend(db)
+	}
+
+	c := cache.New(opts)
+	slog.Info("response cache enabled",
+		"size", size,
+		"persist", opts.Backend != nil,
+		"ttl_search", c.TTL(cache.Search).String(),
+		"ttl_playlist", c.TTL(cache.Playlist).String(),
+		"ttl_charts", c.TTL(cache.Charts).String(),
+		"ttl_lyrics", c.TTL(cache.Lyrics).String(),
+	)
+	return c, nil
+}
@@ -1,17 +1,21 @@
 package main
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/guohuiyuan/music-lib/download"
 	"github.com/guohuiyuan/music-lib/internal/api"
+	"github.com/guohuiyuan/music-lib/internal/health"
 	"github.com/guohuiyuan/music-lib/internal/monitor"
 	"github.com/guohuiyuan/music-lib/internal/store"
 	"github.com/guohuiyuan/music-lib/login"
 	"github.com/guohuiyuan/music-lib/netease"
 	"github.com/guohuiyuan/music-lib/qq"
+	"github.com/guohuiyuan/music-lib/registry"
 )
 
 func main() {
@@ -25,11 +29,30 @@ func main() {
 	scrapeEnabled := envBool("SCRAPE_ENABLED", true)
 	scrapeCover := envBool("SCRAPE_COVER", true)
 	scrapeLyrics := envBool("SCRAPE_LYRICS", true)
+	lyricsTranslation := envBool("LYRICS_TRANSLATION", false)
+	lyricsRomanization := envBool("LYRICS_ROMANIZATION", false)
+	lyricsSidecar := envBool("LYRICS_SIDECAR", false)
+	lyricsWordLevel := envBool("LYRICS_WORD_LEVEL", false)
+	lyricsFallback := envBool("LYRICS_FALLBACK", false)
+	downloadSegments := envInt("DOWNLOAD_SEGMENTS", 1)
 	cfgDir := envOr("CONFIG_DIR", dataDir)
 
 	// 2. Initialize slog (JSON handler, level from LOG_LEVEL).
 	initSlog(os.Getenv("LOG_LEVEL"))
 
+	// 2b. Per-provider proxy, timeout and cookie jar.
+	if err := configureProviders(); err != nil {
+		slog.Error("invalid provider http settings", "error", err)
+		os.Exit(1)
+	}
+
+	// 2c. Download bandwidth limit and time windows.
+	bandwidth, err := bandwidthSchedule()
+	if err != nil {
+		slog.Error("invalid download bandwidth settings", "error", err)
+		os.Exit(1)
+	}
+
 	// 3. Init DB.
 	db, err := store.Init(dataDir)
 	if err != nil {
@@ -37,9 +60,19 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 4. Mark interrupted tasks as failed.
-	if err := store.MarkRunningAsFailed(db); err != nil {
-		slog.Warn("mark running as failed", "error", err)
+	// 3b. Response cache (search, playlists, charts, lyrics).
+	respCache, err := newResponseCache(db)
+	if err != nil {
+		slog.Error("invalid cache settings", "error", err)
+		os.Exit(1)
+	}
+
+	// 4. Mark interrupted tasks as failed when there is no download manager
+	// to resume them (see step 14).
+	if musicDir == "" {
+		if err := store.MarkRunningAsFailed(db); err != nil {
+			slog.Warn("mark running as failed", "error", err)
+		}
 	}
 
 	// 5. Load existing tasks.
@@ -85,28 +118,26 @@ func main() {
 		}
 	})
 
-	// 8. Build provider funcs map.
-	providers := buildProviders()
-
-	// 9. Build download provider funcs.
-	dlProviders := make(map[string]download.ProviderFuncs)
-	for name, p := range providers {
-		dlProviders[name] = download.ProviderFuncs{
-			Search:         p.Search,
-			GetDownloadURL: p.GetDownloadURL,
-			GetLyrics:      p.GetLyrics,
-		}
-	}
+	// 8-9. Providers registered themselves on import (see providers.go).
+	providers := registry.Default()
 
 	// 10. Create download manager.
 	dlCfg := download.Config{
-		MusicDir:      musicDir,
-		Concurrency:   concurrency,
-		MaxRetries:    maxRetries,
-		RetryBackoff:  retryBackoff,
-		ScrapeEnabled: scrapeEnabled,
-		ScrapeCover:   scrapeCover,
-		ScrapeLyrics:  scrapeLyrics,
+		MusicDir:           musicDir,
+		Concurrency:        concurrency,
+		MaxRetries:         maxRetries,
+		RetryBackoff:       retryBackoff,
+		ScrapeEnabled:      scrapeEnabled,
+		ScrapeCover:        scrapeCover,
+		ScrapeLyrics:       scrapeLyrics,
+		LyricsTranslation:  lyricsTranslation,
+		LyricsRomanization: lyricsRomanization,
+		LyricsSidecar:      lyricsSidecar,
+		LyricsWordLevel:    lyricsWordLevel,
+		LyricsFallback:     lyricsFallback,
+		Segments:           downloadSegments,
+		ProviderSegments:   providerSegments(providers),
+		Bandwidth:          bandwidth,
 	}
 	var dlMgr *download.Manager
 	if musicDir != "" {
@@ -114,7 +145,7 @@ func main() {
 			slog.Error("MUSIC_DIR not usable", "dir", musicDir, "error", err)
 			os.Exit(1)
 		}
-		dlMgr = download.NewManager(dlCfg, dlProviders)
+		dlMgr = download.NewManager(dlCfg, providers)
 		slog.Info("NAS download enabled", "dir", musicDir, "concurrency", concurrency)
 	} else {
 		slog.Warn("NAS download disabled (MUSIC_DIR not set)")
@@ -136,32 +167,42 @@ func main() {
 		} else {
 			dlMgr.LoadBatchNames(batchNames)
 		}
+
+		// 14. Resume tasks interrupted by the last shutdown from their .part files.
+		if n := dlMgr.ResumeInterrupted(); n > 0 {
+			slog.Info("resuming interrupted downloads", "count", n)
+		}
 	}
 
-	// 14. Start chart monitor scheduler.
+	// 15. Start chart monitor scheduler.
 	if dlMgr != nil {
-		chartProviders := make(map[string]monitor.ChartProvider)
-		for name, p := range providers {
-			if p.GetChartSongs != nil || p.GetPlaylistSongs != nil {
-				chartProviders[name] = monitor.ChartProvider{
-					GetChartSongs:    p.GetChartSongs,
-					GetPlaylistSongs: p.GetPlaylistSongs,
-					GetDownloadURL:   p.GetDownloadURL,
-					GetLyrics:        p.GetLyrics,
-				}
+		chartPlatforms := 0
+		for _, p := range providers.All() {
+			if p.ChartSource() != nil || p.PlaylistSource() != nil {
+				chartPlatforms++
 			}
 		}
-		scheduler := monitor.NewScheduler(db, dlMgr, chartProviders)
+		scheduler := monitor.NewScheduler(db, dlMgr, providers)
+		scheduler.SetCache(respCache)
 		scheduler.Start()
 		api.SetMonitorScheduler(scheduler)
 		monitorCount := 0
 		if monitors, err := store.ListMonitors(db); err == nil {
 			monitorCount = len(monitors)
 		}
-		slog.Info("chart monitor enabled", "platforms", len(chartProviders), "monitors", monitorCount)
+		slog.Info("chart monitor enabled", "platforms", chartPlatforms, "monitors", monitorCount)
+	}
+
+	// 16. Start provider health prober.
+	probeInterval := envDuration("HEALTH_PROBE_INTERVAL", time.Hour)
+	if probeInterval > 0 {
+		prober := health.NewProber(db, providers, probeInterval, envDuration("HEALTH_PROBE_TIMEOUT", 20*time.Second))
+		prober.Start()
+	} else {
+		slog.Info("provider health prober disabled")
 	}
 
-	// 15. Create router.
+	// 17. Create router.
 	router := api.NewRouter(
 		providers,
 		loginMgr,
@@ -169,11 +210,12 @@ func main() {
 		db,
 		neteaseAuth{},
 		qqAuth{},
+		respCache,
 	)
 
 	slog.Info("server starting", "port", port, "music_dir", musicDir, "data_dir", dataDir)
 
-	// 16. Run.
+	// 18. Run.
 	if err := router.Run(":" + port); err != nil {
 		slog.Error("server failed", "error", err)
 		os.Exit(1)
@@ -182,6 +224,20 @@ func main() {
 
 // --- helpers ---
 
+// bandwidthSchedule reads DOWNLOAD_BANDWIDTH (e.g. "500KB", "pause"; default
+// unlimited) and DOWNLOAD_BANDWIDTH_WINDOWS (e.g. "01:00-07:00=0").
+func bandwidthSchedule() (download.BandwidthSchedule, error) {
+	limit, err := download.ParseRate(os.Getenv("DOWNLOAD_BANDWIDTH"))
+	if err != nil {
+		return download.BandwidthSchedule{}, fmt.Errorf("DOWNLOAD_BANDWIDTH: %w", err)
+	}
+	windows, err := download.ParseBandwidthWindows(os.Getenv("DOWNLOAD_BANDWIDTH_WINDOWS"))
+	if err != nil {
+		return download.BandwidthSchedule{}, fmt.Errorf("DOWNLOAD_BANDWIDTH_WINDOWS: %w", err)
+	}
+	return download.BandwidthSchedule{Limit: limit, Windows: windows}, nil
+}
+
 func envOr(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -206,6 +262,18 @@ func envInt(key string, def int) int {
 	return def
 }
 
+// envDuration parses a Go duration such as "30m"; invalid or negative values
+// fall back to def, "0" is returned as 0.
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		slog.Warn("invalid duration, using default", "key", key, "value", v, "default", def.String())
+	}
+	return def
+}
+
 func initSlog(level string) {
 	var lvl slog.Level
 	switch level {
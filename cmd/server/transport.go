@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http/cookiejar"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// configureProviders applies per-provider HTTP settings read from the
+// environment to every registered provider with a Configure hook. For a
+// source such as "joox" it looks at:
+//
+//	JOOX_PROXY       http://, https:// or socks5:// proxy; "direct" ignores HTTP_PROXY
+//	JOOX_TIMEOUT     request timeout, e.g. "30s" (default: HTTP_TIMEOUT, then 15s)
+//	JOOX_COOKIE_JAR  "true" keeps Set-Cookie between requests (default: HTTP_COOKIE_JAR)
+//	JOOX_RATE_LIMIT  requests per second, 0 disables (default: HTTP_RATE_LIMIT, then 5)
+//	JOOX_RATE_BURST  requests allowed in a burst (default: HTTP_RATE_BURST, then 10)
+//	JOOX_BREAKER_THRESHOLD / JOOX_BREAKER_COOLDOWN
+//	                 consecutive failures that open the circuit breaker and how
+//	                 long it stays open (default: HTTP_BREAKER_*, then 5 and 30s)
+func configureProviders() error {
+	globalTimeout := os.Getenv("HTTP_TIMEOUT")
+	globalJar := os.Getenv("HTTP_COOKIE_JAR")
+	globalRate := envOr("HTTP_RATE_LIMIT", "5")
+	globalBurst := envOr("HTTP_RATE_BURST", "10")
+	globalThreshold := os.Getenv("HTTP_BREAKER_THRESHOLD")
+	globalCooldown := os.Getenv("HTTP_BREAKER_COOLDOWN")
+
+	for _, p := range registry.All() {
+		if p.Configure == nil {
+			continue
+		}
+		prefix := strings.ToUpper(p.Name) + "_"
+		var opts []utils.ClientOption
+		var attrs []any
+
+		if raw, ok := os.LookupEnv(prefix + "PROXY"); ok {
+			proxy, err := utils.ParseProxy(raw)
+			if err != nil {
+				return fmt.Errorf("%sPROXY: %w", prefix, err)
+			}
+			opts = append(opts, utils.WithProxy(proxy))
+			if proxy != nil {
+				attrs = append(attrs, "proxy", proxy.Redacted())
+			} else {
+				attrs = append(attrs, "proxy", "direct")
+			}
+		}
+
+		if raw := envOr(prefix+"TIMEOUT", globalTimeout); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				return fmt.Errorf("%sTIMEOUT: invalid duration %q", prefix, raw)
+			}
+			opts = append(opts, utils.WithTimeout(d))
+			attrs = append(attrs, "timeout", d.String())
+		}
+
+		if raw := envOr(prefix+"COOKIE_JAR", globalJar); raw == "true" || raw == "1" {
+			jar, err := cookiejar.New(nil)
+			if err != nil {
+				return fmt.Errorf("%sCOOKIE_JAR: %w", prefix, err)
+			}
+			opts = append(opts, utils.WithCookieJar(jar))
+			attrs = append(attrs, "cookie_jar", true)
+		}
+
+		rate, err := strconv.ParseFloat(envOr(prefix+"RATE_LIMIT", globalRate), 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("%sRATE_LIMIT: invalid rate %q", prefix, envOr(prefix+"RATE_LIMIT", globalRate))
+		}
+		burst, err := strconv.Atoi(envOr(prefix+"RATE_BURST", globalBurst))
+		if err != nil || burst < 1 {
+			return fmt.Errorf("%sRATE_BURST: invalid burst %q", prefix, envOr(prefix+"RATE_BURST", globalBurst))
+		}
+		opts = append(opts, utils.WithRateLimit(rate, burst))
+		attrs = append(attrs, "rate_limit", rate, "burst", burst)
+
+		threshold, cooldown := utils.DefaultBreakerThreshold, utils.DefaultBreakerCooldown
+		if raw := envOr(prefix+"BREAKER_THRESHOLD", globalThreshold); raw != "" {
+			if threshold, err = strconv.Atoi(raw); err != nil || threshold < 0 {
+				return fmt.Errorf("%sBREAKER_THRESHOLD: invalid value %q", prefix, raw)
+			}
+		}
+		if raw := envOr(prefix+"BREAKER_COOLDOWN", globalCooldown); raw != "" {
+			if cooldown, err = time.ParseDuration(raw); err != nil || cooldown <= 0 {
+				return fmt.Errorf("%sBREAKER_COOLDOWN: invalid duration %q", prefix, raw)
+			}
+		}
+		opts = append(opts, utils.WithBreaker(threshold, cooldown))
+		attrs = append(attrs, "breaker_threshold", threshold, "breaker_cooldown", cooldown.String())
+
+		p.Configure(opts...)
+		slog.Debug("provider http client configured", append([]any{"source", p.Name}, attrs...)...)
+	}
+	return nil
+}
+
+// providerSegments reads per-provider overrides of DOWNLOAD_SEGMENTS, such
+// as KUWO_DOWNLOAD_SEGMENTS=4 for a CDN that throttles each connection.
+func providerSegments(providers *registry.Registry) map[string]int {
+	segments := map[string]int{}
+	for _, p := range providers.All() {
+		if n := envInt(strings.ToUpper(p.Name)+"_DOWNLOAD_SEGMENTS", 0); n > 0 {
+			segments[p.Name] = n
+		}
+	}
+	return segments
+}
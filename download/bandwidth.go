@@ -0,0 +1,346 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+// BandwidthPaused as a limit stops downloads until a window with another
+// limit begins.
+const BandwidthPaused int64 = -1
+
+// pausePoll is how often a paused download checks whether its window has
+// ended; a schedule change wakes it immediately.
+const pausePoll = 30 * time.Second
+
+// BandwidthWindow applies its own limit during a daily time window.
+type BandwidthWindow struct {
+	Start string `json:"start"` // "HH:MM", local time
+	End   string `json:"end"`   // "HH:MM"; earlier than Start spans midnight, equal to it covers the whole day
+	Limit int64  `json:"limit"` // bytes per second; 0 unlimited, BandwidthPaused
+}
+
+// contains reports whether the minute of the day m falls in the window.
+func (w BandwidthWindow) contains(m int) bool {
+	start, err1 := parseClock(w.Start)
+	end, err2 := parseClock(w.End)
+	switch {
+	case err1 != nil || err2 != nil:
+		return false
+	case start == end:
+		return true
+	case start < end:
+		return m >= start && m < end
+	default:
+		return m >= start || m < end
+	}
+}
+
+// BandwidthSchedule is the download speed limit shared by all running
+// tasks: Limit applies outside every window, and the first window that
+// contains the current time overrides it.
+type BandwidthSchedule struct {
+	Limit   int64             `json:"limit"` // bytes per second; 0 unlimited, BandwidthPaused
+	Windows []BandwidthWindow `json:"windows"`
+}
+
+// Validate checks the limits and the window times.
+func (s BandwidthSchedule) Validate() error {
+	if s.Limit < BandwidthPaused {
+		return fmt.Errorf("invalid limit %d", s.Limit)
+	}
+	for i, w := range s.Windows {
+		if _, err := parseClock(w.Start); err != nil {
+			return fmt.Errorf("window %d: start: %w", i, err)
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return fmt.Errorf("window %d: end: %w", i, err)
+		}
+		if w.Limit < BandwidthPaused {
+			return fmt.Errorf("window %d: invalid limit %d", i, w.Limit)
+		}
+	}
+	return nil
+}
+
+// LimitAt returns the limit in force at t.
+func (s BandwidthSchedule) LimitAt(t time.Time) int64 {
+	m := t.Hour()*60 + t.Minute()
+	for _, w := range s.Windows {
+		if w.contains(m) {
+			return w.Limit
+		}
+	}
+	return s.Limit
+}
+
+// parseClock parses "HH:MM" into minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseRate parses a bandwidth limit such as "500KB", "2MB/s" or "800000"
+// (bytes). "0" and "unlimited" mean no limit, "pause" stops downloads.
+// Units are binary: 1KB = 1024 bytes.
+func ParseRate(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "/S")
+	switch v {
+	case "", "0", "UNLIMITED":
+		return 0, nil
+	case "PAUSE", "PAUSED":
+		return BandwidthPaused, nil
+	}
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(v, u.suffix) {
+			v, mult = strings.TrimSuffix(v, u.suffix), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// ParseBandwidthWindows parses a comma-separated list of
+// "HH:MM-HH:MM=rate" windows, e.g. "01:00-07:00=0,18:00-23:00=pause".
+func ParseBandwidthWindows(s string) ([]BandwidthWindow, error) {
+	var windows []BandwidthWindow
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		span, rate, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("window %q: want HH:MM-HH:MM=rate", part)
+		}
+		start, end, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("window %q: want HH:MM-HH:MM=rate", part)
+		}
+		limit, err := ParseRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", part, err)
+		}
+		w := BandwidthWindow{Start: strings.TrimSpace(start), End: strings.TrimSpace(end), Limit: limit}
+		if err := (BandwidthSchedule{Windows: []BandwidthWindow{w}}).Validate(); err != nil {
+			return nil, fmt.Errorf("window %q: %w", part, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// Bandwidth is a token bucket shared by every download of a Manager, whose
+// rate follows a BandwidthSchedule. The schedule can be changed at any time;
+// waiting downloads pick up the new limit immediately.
+type Bandwidth struct {
+	mu       sync.Mutex
+	schedule BandwidthSchedule
+	tokens   float64
+	last     time.Time
+	changed  chan struct{} // closed and replaced by SetSchedule
+	now      func() time.Time
+}
+
+// NewBandwidth returns a limiter following s.
+func NewBandwidth(s BandwidthSchedule) *Bandwidth {
+	return &Bandwidth{schedule: s, changed: make(chan struct{}), now: time.Now}
+}
+
+// Schedule returns the current schedule.
+func (b *Bandwidth) Schedule() BandwidthSchedule {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.schedule
+	s.Windows = append([]BandwidthWindow{}, s.Windows...)
+	return s
+}
+
+// SetSchedule replaces the schedule after validating it.
+func (b *Bandwidth) SetSchedule(s BandwidthSchedule) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.schedule = s
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+	return nil
+}
+
+// Limit returns the limit in force now.
+func (b *Bandwidth) Limit() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.schedule.LimitAt(b.now())
+}
+
+// WaitActive blocks while downloads are paused.
+func (b *Bandwidth) WaitActive(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		limit, changed := b.schedule.LimitAt(b.now()), b.changed
+		b.mu.Unlock()
+		if limit != BandwidthPaused {
+			return nil
+		}
+		if err := sleepOrChange(ctx, pausePoll, changed); err != nil {
+			return err
+		}
+	}
+}
+
+// pausedNow reports whether the schedule pauses downloads now, along with a
+// channel that the next SetSchedule closes.
+func (b *Bandwidth) pausedNow() (bool, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.schedule.LimitAt(b.now()) == BandwidthPaused, b.changed
+}
+
+// waitN accounts for n bytes just read, sleeping as long as the limit
+// requires; while paused it blocks until downloads may continue.
+func (b *Bandwidth) waitN(ctx context.Context, n int) error {
+	for {
+		b.mu.Lock()
+		now := b.now()
+		limit, changed := b.schedule.LimitAt(now), b.changed
+		if limit == BandwidthPaused {
+			b.mu.Unlock()
+			if err := sleepOrChange(ctx, pausePoll, changed); err != nil {
+				return err
+			}
+			continue
+		}
+		if limit == 0 {
+			b.last = now
+			b.mu.Unlock()
+			return nil
+		}
+		// One second of traffic may be sent in a burst; debt is allowed so
+		// that concurrent readers are served in turn.
+		rate := float64(limit)
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*rate, rate)
+		b.last = now
+		b.tokens -= float64(n)
+		deficit := -b.tokens
+		b.mu.Unlock()
+
+		if deficit <= 0 {
+			return nil
+		}
+		return sleepOrChange(ctx, time.Duration(deficit/rate*float64(time.Second)), changed)
+	}
+}
+
+// sleepOrChange waits for d, a schedule change or the end of ctx.
+func sleepOrChange(ctx context.Context, d time.Duration, changed <-chan struct{}) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader returns r limited by b.
+func (b *Bandwidth) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &limitedReader{ctx: ctx, r: r, bw: b}
+}
+
+// Client returns a copy of hc whose response bodies are limited by b.
+func (b *Bandwidth) Client(hc *http.Client) *http.Client {
+	if hc == nil {
+		hc = longClient
+	}
+	c := *hc
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.Transport = &limitedTransport{base: base, bw: b}
+	return &c
+}
+
+// Opener returns o with the streams it opens limited by b.
+func (b *Bandwidth) Opener(o registry.StreamOpener) registry.StreamOpener {
+	return limitedOpener{StreamOpener: o, bw: b}
+}
+
+type limitedReader struct {
+	ctx context.Context
+	r   io.Reader
+	bw  *Bandwidth
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if n > 0 {
+		if waitErr := l.bw.waitN(l.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// limitedBody limits reads from a body while closing the original.
+type limitedBody struct {
+	io.ReadCloser
+	r io.Reader
+}
+
+func (b limitedBody) Read(p []byte) (int, error) { return b.r.Read(p) }
+
+type limitedTransport struct {
+	base http.RoundTripper
+	bw   *Bandwidth
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = limitedBody{ReadCloser: resp.Body, r: t.bw.Reader(req.Context(), resp.Body)}
+	return resp, nil
+}
+
+type limitedOpener struct {
+	registry.StreamOpener
+	bw *Bandwidth
+}
+
+func (o limitedOpener) OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error) {
+	audio, err := o.StreamOpener.OpenStreamContext(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	audio.ReadCloser = limitedBody{ReadCloser: audio.ReadCloser, r: o.bw.Reader(ctx, audio.ReadCloser)}
+	return audio, nil
+}
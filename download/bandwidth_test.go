@@ -0,0 +1,102 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]int64{
+		"0":         0,
+		"unlimited": 0,
+		"pause":     BandwidthPaused,
+		"500KB":     500 << 10,
+		"2MB/s":     2 << 20,
+		"1.5m":      3 << 19,
+		"800000":    800000,
+	}
+	for in, want := range tests {
+		got, err := ParseRate(in)
+		if err != nil || got != want {
+			t.Errorf("%q: got %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseRate("fast"); err == nil {
+		t.Error("expected an error for an invalid rate")
+	}
+}
+
+func TestBandwidthSchedule_LimitAt(t *testing.T) {
+	windows, err := ParseBandwidthWindows("01:00-07:00=0, 22:00-01:00=pause")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := BandwidthSchedule{Limit: 500 << 10, Windows: windows}
+	at := func(clock string) time.Time {
+		c, _ := time.Parse("15:04", clock)
+		return time.Date(2026, 1, 1, c.Hour(), c.Minute(), 0, 0, time.Local)
+	}
+	for clock, want := range map[string]int64{
+		"00:30": BandwidthPaused, // window spanning midnight
+		"01:00": 0,
+		"06:59": 0,
+		"07:00": 500 << 10,
+		"23:00": BandwidthPaused,
+	} {
+		if got := s.LimitAt(at(clock)); got != want {
+			t.Errorf("%s: got %d, want %d", clock, got, want)
+		}
+	}
+	if _, err := ParseBandwidthWindows("25:00-07:00=0"); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
+
+func TestBandwidth_Throttles(t *testing.T) {
+	bw := NewBandwidth(BandwidthSchedule{Limit: 100 << 10})
+	start := time.Now()
+	// One second's worth passes as a burst, the remaining 50KB take ~0.5s.
+	n, err := io.Copy(io.Discard, bw.Reader(context.Background(), bytes.NewReader(make([]byte, 150<<10))))
+	if err != nil || n != 150<<10 {
+		t.Fatalf("copy: %d, %v", n, err)
+	}
+	if d := time.Since(start); d < 400*time.Millisecond || d > 2*time.Second {
+		t.Errorf("150KB at 100KB/s took %v", d)
+	}
+}
+
+func TestBandwidth_PauseAndResume(t *testing.T) {
+	bw := NewBandwidth(BandwidthSchedule{Limit: BandwidthPaused})
+	done := make(chan error, 1)
+	go func() { done <- bw.WaitActive(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("WaitActive returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := bw.SetSchedule(BandwidthSchedule{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("schedule change did not wake the paused download")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = bw.SetSchedule(BandwidthSchedule{Limit: BandwidthPaused})
+	if err := bw.WaitActive(ctx); err == nil {
+		t.Error("expected the context error while paused")
+	}
+	if err := bw.SetSchedule(BandwidthSchedule{Limit: -5}); err == nil {
+		t.Error("expected an error for an invalid limit")
+	}
+}
@@ -1,10 +1,9 @@
 package download
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
-	"strings"
 
 	"github.com/guohuiyuan/music-lib/model"
 )
@@ -14,91 +13,67 @@ var fallbackOrder = []string{
 	"kugou", "kuwo", "migu", "qq", "qianqian", "soda", "fivesing", "joox", "bilibili", "jamendo",
 }
 
-// reParens matches parenthesized or bracketed content (ASCII and CJK).
-var reParens = regexp.MustCompile(`[\(（\[【][^)）\]】]*[\)）\]】]`)
-
-// rePunctuation matches common punctuation and symbols.
-var rePunctuation = regexp.MustCompile(`[.,!?;:'"、。！？；：""''·\-_]+`)
-
-// reSpaces collapses multiple spaces into one.
-var reSpaces = regexp.MustCompile(`\s+`)
-
-// normalizeName normalises a song/artist name for fuzzy comparison:
-//   - lower-case
-//   - strip parenthesized content (e.g. "(Live)", "（翻唱）")
-//   - strip punctuation
-//   - collapse whitespace and trim
-func normalizeName(s string) string {
-	s = strings.ToLower(s)
-	s = reParens.ReplaceAllString(s, "")
-	s = rePunctuation.ReplaceAllString(s, " ")
-	s = reSpaces.ReplaceAllString(s, " ")
-	return strings.TrimSpace(s)
-}
-
-// isSongMatch returns true when the candidate is considered the same song as the target.
-//
-// Rules:
-//  1. Normalised names are equal AND artist contains the other (or vice versa).
-//  2. One normalised name contains the other AND normalised artists are equal.
-func isSongMatch(target, candidate model.Song) bool {
-	tName := normalizeName(target.Name)
-	cName := normalizeName(candidate.Name)
-	tArtist := normalizeName(target.Artist)
-	cArtist := normalizeName(candidate.Artist)
-
-	if tName == "" || cName == "" {
-		return false
-	}
-
-	artistMatch := tArtist == cArtist ||
-		(tArtist != "" && cArtist != "" && (strings.Contains(tArtist, cArtist) || strings.Contains(cArtist, tArtist)))
-
-	// Rule 1: exact name match + artist contains
-	if tName == cName && artistMatch {
-		return true
-	}
-
-	// Rule 2: name containment + exact artist match
-	nameContains := strings.Contains(tName, cName) || strings.Contains(cName, tName)
-	if nameContains && tArtist == cArtist {
-		return true
-	}
-
-	return false
-}
-
-// tryFallback searches other providers for a matching song and returns a working download URL.
-func (m *Manager) tryFallback(song model.Song, originalSource string) (audioURL string, fallbackSource string, err error) {
+// tryFallback searches other providers for a matching song and returns a playable stream,
+// chosen with the same requested quality as the original song.
+// Each provider call gets its own ProviderTimeout deadline derived from ctx.
+func (m *Manager) tryFallback(ctx context.Context, song model.Song, originalSource string) (stream model.Stream, fallbackSource string, err error) {
 	keyword := song.Artist + " " + song.Name
 
 	for _, name := range fallbackOrder {
+		if ctx.Err() != nil {
+			return model.Stream{}, "", ctx.Err()
+		}
 		if name == originalSource {
 			continue
 		}
-		pf, ok := m.providers[name]
-		if !ok || pf.Search == nil || pf.GetDownloadURL == nil {
+		p, ok := m.providers.Get(name)
+		if !ok {
+			continue
+		}
+		if c := p.Client(); c != nil && !c.Available() {
+			slog.Debug("download.fallback.breaker_open", "provider", name)
+			continue
+		}
+		searcher := p.Searcher()
+		if searcher == nil || (p.StreamSource() == nil && p.Downloader() == nil) {
 			continue
 		}
 
-		results, searchErr := pf.Search(keyword)
+		searchCtx, cancel := context.WithTimeout(ctx, m.cfg.ProviderTimeout)
+		results, searchErr := searcher.SearchContext(searchCtx, keyword)
+		cancel()
 		if searchErr != nil {
 			slog.Warn("download.fallback.search_error", "provider", name, "error", searchErr)
 			continue
 		}
 
+	results:
 		for i := range results {
-			if !isSongMatch(song, results[i]) {
+			if !model.IsSameSong(song, results[i]) {
 				continue
 			}
-			url, dlErr := pf.GetDownloadURL(&results[i])
+			if quality := song.Extra["quality"]; quality != "" {
+				if results[i].Extra == nil {
+					results[i].Extra = map[string]string{}
+				}
+				results[i].Extra["quality"] = quality
+			}
+			urlCtx, cancel := context.WithTimeout(ctx, m.cfg.ProviderTimeout)
+			st, dlErr := p.ResolveStream(urlCtx, &results[i])
+			cancel()
 			if dlErr != nil {
 				slog.Warn("download.fallback.url_error", "provider", name, "error", dlErr)
+				// Expired login, rate limiting or a changed API affect every song on
+				// this provider; move on instead of trying its other matches.
+				switch model.KindOf(dlErr) {
+				case model.ErrLoginExpired, model.ErrRateLimited, model.ErrSchemaChanged:
+					break results
+				}
 				continue
 			}
-			return url, name, nil
+			return st, name, nil
 		}
 	}
 
-	return "", "", fmt.Errorf("no fallback provider found a matching download for %s", song.Display())
+	return model.Stream{}, "", fmt.Errorf("no fallback provider found a matching download for %s", song.Display())
 }
@@ -0,0 +1,112 @@
+package download
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// partSuffix marks an unfinished download next to its final path.
+	partSuffix = ".part"
+	// partMetaSuffix marks the metadata saved beside a part file.
+	partMetaSuffix = ".json"
+)
+
+// partMeta records what a part file is a prefix of, so a later attempt can
+// ask the server for the rest of the same file.
+type partMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Size         int64  `json:"size"` // total size; -1 or 0 when unknown
+	// Segments is set by a segmented download, whose part file is
+	// preallocated and filled out of order (see downloadSegmented).
+	Segments []partSegment `json:"segments,omitempty"`
+}
+
+// partSegment is one byte range of a segmented download. Done counts the
+// bytes written from Start. It is saved every segmentCheckpoint, so after a
+// crash it lags behind the file by at most that much transfer time, which is
+// fetched again.
+type partSegment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"` // inclusive
+	Done  int64 `json:"done"`
+}
+
+func (s partSegment) size() int64 { return s.End - s.Start + 1 }
+
+// resumable reports whether a part file can be continued from its end:
+// without a validator or a known total size there is no way to tell whether
+// a ranged response belongs to the same file, and the size of a segmented
+// part file says nothing about how much of it was written.
+func (m partMeta) resumable() bool {
+	return len(m.Segments) == 0 && (m.Size > 0 || m.validator() != "")
+}
+
+// validator returns the If-Range value for the part file, preferring a
+// strong ETag over Last-Modified. Weak ETags are not allowed in If-Range.
+func (m partMeta) validator() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+func readPartMeta(partPath string) (partMeta, error) {
+	var m partMeta
+	data, err := os.ReadFile(partPath + partMetaSuffix)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// writePartMeta replaces the metadata through a temporary file, so a crash
+// mid-write leaves the previous checkpoint rather than a truncated one.
+func writePartMeta(partPath string, m partMeta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := partPath + partMetaSuffix + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, partPath+partMetaSuffix)
+}
+
+// parseContentRange parses a "bytes start-end/total" or "bytes */total"
+// Content-Range header. total is -1 when the server sent "*".
+func parseContentRange(v string) (start, total int64, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(v), "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		total = n
+	}
+	if rng == "*" {
+		return -1, total, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return n, total, true
+}
@@ -0,0 +1,360 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Priority orders queued tasks: higher priorities start first, equal ones in
+// enqueue order.
+type Priority int
+
+const (
+	// PriorityLow is for chart monitor batches, which can be hundreds of
+	// songs nobody is waiting for.
+	PriorityLow Priority = -10
+	// PriorityNormal is for playlist, album and artist batches.
+	PriorityNormal Priority = 0
+	// PriorityHigh is for single songs a user asked for.
+	PriorityHigh Priority = 10
+)
+
+var (
+	// ErrTaskNotFound is returned for an unknown task ID.
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrBatchNotFound is returned for a batch ID with no tasks.
+	ErrBatchNotFound = errors.New("batch not found")
+	// ErrTaskFinished is returned when pausing, resuming or cancelling a
+	// task that is done, failed or already cancelled.
+	ErrTaskFinished = errors.New("task already finished")
+)
+
+// Causes a running task's context is cancelled with (see stopTask).
+var (
+	errTaskPaused    = errors.New("task paused")
+	errQueuePaused   = errors.New("queue paused")
+	errTaskCancelled = errors.New("task cancelled")
+)
+
+// worker runs queued tasks one at a time until the Manager is closed.
+func (m *Manager) worker() {
+	for {
+		// Nothing starts while the bandwidth schedule pauses downloads.
+		if err := m.bandwidth.WaitActive(m.ctx); err != nil {
+			return
+		}
+		task, ctx, ok := m.next()
+		if !ok {
+			return
+		}
+		m.runTask(ctx, task)
+
+		m.mu.Lock()
+		cancel := m.running[task.ID]
+		delete(m.running, task.ID)
+		m.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// pauseOnSchedule interrupts running tasks while the bandwidth schedule
+// pauses downloads, the way Pause does, so their transfers are not left
+// blocked on an open connection for the whole window. They go back to the
+// queue with their .part files, and WaitActive holds the workers until the
+// window ends. The check runs on every schedule change and every pausePoll.
+func (m *Manager) pauseOnSchedule() {
+	for {
+		paused, changed := m.bandwidth.pausedNow()
+		if paused {
+			m.mu.Lock()
+			for _, cancel := range m.running {
+				cancel(errQueuePaused)
+			}
+			m.mu.Unlock()
+		}
+		if err := sleepOrChange(m.ctx, pausePoll, changed); err != nil {
+			return
+		}
+	}
+}
+
+// next blocks until a queued task may start, then marks it running and
+// returns it with a context that PauseTask, CancelTask and Pause cancel.
+// It returns false once the Manager is closed.
+func (m *Manager) next() (*Task, context.Context, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if m.ctx.Err() != nil {
+			return nil, nil, false
+		}
+		if i := m.pickLocked(); i >= 0 {
+			task := m.queue[i]
+			m.queue = slices.Delete(m.queue, i, i+1)
+			ctx, cancel := context.WithCancelCause(m.ctx)
+			m.running[task.ID] = cancel
+			task.Status = StatusRunning
+			m.notifyUpdate(task)
+			return task, ctx, true
+		}
+		m.cond.Wait()
+	}
+}
+
+// pickLocked returns the queue index of the next task to start, or -1.
+func (m *Manager) pickLocked() int {
+	if m.paused {
+		return -1
+	}
+	best := -1
+	for i, t := range m.queue {
+		if t.Status == StatusPending && (best < 0 || t.Priority > m.queue[best].Priority) {
+			best = i
+		}
+	}
+	return best
+}
+
+// stopTask ends a task that could not finish. A task interrupted by
+// PauseTask, Pause or Close goes back to the front of the queue, keeping its
+// .part file, so it is resumed later or by ResumeInterrupted after a
+// restart; one interrupted by CancelTask is marked cancelled and its .part
+// file removed; anything else is a failure.
+func (m *Manager) stopTask(ctx context.Context, task *Task, msg string) {
+	cause := context.Cause(ctx)
+	if m.ctx.Err() != nil && errors.Is(cause, context.Canceled) {
+		cause = errQueuePaused
+	}
+	switch {
+	case errors.Is(cause, errTaskPaused), errors.Is(cause, errQueuePaused):
+		m.mu.Lock()
+		task.Status = StatusPending
+		if errors.Is(cause, errTaskPaused) {
+			task.Status = StatusPaused
+		}
+		task.Error = ""
+		m.queue = slices.Insert(m.queue, 0, task)
+		m.notifyUpdate(task)
+		m.cond.Signal()
+		m.mu.Unlock()
+		slog.Info("download.paused", "task_id", task.ID, "song", task.Song.Display(), "progress", task.Progress)
+	case errors.Is(cause, errTaskCancelled):
+		m.cancelled(task)
+	default:
+		m.failTask(task, msg)
+	}
+}
+
+// cancelled marks a task that is no longer queued or running as cancelled
+// and removes its partial download.
+func (m *Manager) cancelled(task *Task) {
+	now := time.Now()
+	m.mu.Lock()
+	task.Status = StatusCancelled
+	task.Error = ""
+	task.CompletedAt = &now
+	song := task.Song
+	m.notifyUpdate(task)
+	m.mu.Unlock()
+
+	if m.cfg.MusicDir != "" {
+		removePart(m.cfg.MusicDir, &song)
+	}
+	slog.Info("download.cancelled", "task_id", task.ID, "song", song.Display())
+}
+
+// Pause stops starting queued tasks. Running tasks are interrupted and
+// queued again, keeping their partial files, so Resume continues them where
+// they stopped.
+func (m *Manager) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+	for _, cancel := range m.running {
+		cancel(errQueuePaused)
+	}
+}
+
+// Resume undoes Pause.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+	m.cond.Broadcast()
+}
+
+// Paused reports whether the queue is paused.
+func (m *Manager) Paused() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.paused
+}
+
+// PauseTask holds a queued task, or interrupts a running one and holds it,
+// until ResumeTask. Pausing a paused task does nothing.
+func (m *Manager) PauseTask(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	_, err := m.pauseLocked(t)
+	return err
+}
+
+// pauseLocked pauses t and reports whether its state changed.
+func (m *Manager) pauseLocked(t *Task) (bool, error) {
+	switch t.Status {
+	case StatusPending:
+		t.Status = StatusPaused
+		m.notifyUpdate(t)
+		return true, nil
+	case StatusRunning:
+		if cancel, ok := m.running[t.ID]; ok {
+			cancel(errTaskPaused)
+		}
+		return true, nil
+	case StatusPaused:
+		return false, nil
+	default:
+		return false, ErrTaskFinished
+	}
+}
+
+// ResumeTask queues a paused task again. Resuming a pending or running task
+// does nothing.
+func (m *Manager) ResumeTask(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	_, err := m.resumeLocked(t)
+	return err
+}
+
+// resumeLocked resumes t and reports whether its state changed.
+func (m *Manager) resumeLocked(t *Task) (bool, error) {
+	switch t.Status {
+	case StatusPaused:
+		t.Status = StatusPending
+		if !slices.Contains(m.queue, t) {
+			// Loaded by LoadTasks but not queued by ResumeInterrupted.
+			m.queue = append(m.queue, t)
+		}
+		m.notifyUpdate(t)
+		m.cond.Signal()
+		return true, nil
+	case StatusPending, StatusRunning:
+		return false, nil
+	default:
+		return false, ErrTaskFinished
+	}
+}
+
+// CancelTask removes a queued task, or aborts a running one, and deletes
+// its partial download.
+func (m *Manager) CancelTask(id string) error {
+	m.mu.Lock()
+	t, ok := m.tasks[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrTaskNotFound
+	}
+	dequeued, _, err := m.cancelLocked(t)
+	m.mu.Unlock()
+	if dequeued {
+		m.cancelled(t)
+	}
+	return err
+}
+
+// cancelLocked cancels t and reports whether it was taken off the queue, in
+// which case the caller must call cancelled once m.mu is released, and
+// whether its state changed. A running task is marked cancelled by its
+// worker.
+func (m *Manager) cancelLocked(t *Task) (dequeued, changed bool, err error) {
+	switch t.Status {
+	case StatusPending, StatusPaused:
+		if i := slices.Index(m.queue, t); i >= 0 {
+			m.queue = slices.Delete(m.queue, i, i+1)
+		}
+		return true, true, nil
+	case StatusRunning:
+		if cancel, ok := m.running[t.ID]; ok {
+			cancel(errTaskCancelled)
+		}
+		return false, true, nil
+	default:
+		return false, false, ErrTaskFinished
+	}
+}
+
+// SetTaskPriority changes the priority of a task that has not started yet;
+// for other tasks it is only recorded.
+func (m *Manager) SetTaskPriority(id string, priority Priority) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	t.Priority = priority
+	m.notifyUpdate(t)
+	return nil
+}
+
+// PauseBatch pauses every unfinished task of a batch (see PauseTask) and
+// returns how many were not already paused.
+func (m *Manager) PauseBatch(batchID string) (int, error) {
+	return m.batchAction(batchID, m.pauseLocked)
+}
+
+// ResumeBatch resumes every paused task of a batch (see ResumeTask) and
+// returns how many there were.
+func (m *Manager) ResumeBatch(batchID string) (int, error) {
+	return m.batchAction(batchID, m.resumeLocked)
+}
+
+// CancelBatch cancels every unfinished task of a batch (see CancelTask).
+func (m *Manager) CancelBatch(batchID string) (int, error) {
+	var dequeued []*Task
+	n, err := m.batchAction(batchID, func(t *Task) (bool, error) {
+		ok, changed, err := m.cancelLocked(t)
+		if ok {
+			dequeued = append(dequeued, t)
+		}
+		return changed, err
+	})
+	for _, t := range dequeued {
+		m.cancelled(t)
+	}
+	return n, err
+}
+
+// batchAction applies fn to the batch's tasks under m.mu and counts the
+// tasks whose state fn changed.
+func (m *Manager) batchAction(batchID string, fn func(*Task) (bool, error)) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	found, n := false, 0
+	for _, id := range m.order {
+		t := m.tasks[id]
+		if t.BatchID != batchID {
+			continue
+		}
+		found = true
+		if changed, _ := fn(t); changed {
+			n++
+		}
+	}
+	if !found {
+		return 0, ErrBatchNotFound
+	}
+	return n, nil
+}
@@ -0,0 +1,303 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// minSegmentSize keeps segmented downloads from splitting small files into
+// ranges that cost more in requests than they gain in throughput.
+const minSegmentSize = 1 << 20
+
+// segmentCheckpoint is how often a segmented download saves the progress of
+// its segments, so a process that is killed resumes from the last
+// checkpoint rather than from the start of every segment.
+var segmentCheckpoint = 2 * time.Second
+
+// errRangeIgnored is returned by a segment whose request was answered with
+// the whole file, either because the server dropped Range support or
+// because If-Range found the file changed.
+var errRangeIgnored = errors.New("server ignored range request")
+
+// downloadSegmented downloads url into partPath over up to segments parallel
+// connections, each fetching its own byte range, for CDNs that throttle
+// every connection. progressFn receives the combined size of all segments.
+//
+// A probe request checks that the server honours Range and reports the
+// total size; when it does not, or the file is too small to split, the
+// download falls back to a single downloadFile stream. Per-segment progress
+// is saved beside the part file, so an interrupted download resumes each
+// segment where it stopped. A part file without segments, left by a
+// single-stream download, is resumed by downloadFile.
+func downloadSegmented(ctx context.Context, hc *http.Client, segments int, partPath, url string, headers map[string]string, progressFn func(int64)) error {
+	if hc == nil {
+		hc = longClient
+	}
+
+	meta, err := readPartMeta(partPath)
+	info, statErr := os.Stat(partPath)
+	if statErr == nil && info.Size() > 0 && len(meta.Segments) == 0 {
+		// Left by a single-stream download: continue it the same way rather
+		// than preallocating over what it already fetched.
+		return downloadFile(ctx, hc, partPath, url, headers, progressFn)
+	}
+	if err != nil || statErr != nil || len(meta.Segments) == 0 || info.Size() != meta.Size {
+		meta, err = probeRanges(ctx, hc, url, headers)
+		if err != nil {
+			return err
+		}
+		n := min(int64(segments), meta.Size/minSegmentSize)
+		if n < 2 {
+			return downloadFile(ctx, hc, partPath, url, headers, progressFn)
+		}
+		meta.Segments = splitSegments(meta.Size, n)
+		if err := preparePart(partPath, meta); err != nil {
+			return err
+		}
+	}
+
+	err = fetchSegments(ctx, hc, partPath, url, headers, &meta, progressFn)
+	if errors.Is(err, errRangeIgnored) {
+		// The file changed since the part was started: start over.
+		slog.Warn("download.segments_restart", "path", partPath, "error", err)
+		_ = os.Remove(partPath + partMetaSuffix)
+		return downloadFile(ctx, hc, partPath, url, headers, progressFn)
+	}
+	return err
+}
+
+// probeRanges asks for the first byte of url to learn whether the server
+// supports ranges, the file's total size and its validators. Size is 0 when
+// ranges are not supported.
+func probeRanges(ctx context.Context, hc *http.Client, url string, headers map[string]string) (partMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return partMeta{}, fmt.Errorf("new request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return partMeta{}, fmt.Errorf("http get: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != 0 || total <= 0 {
+			return partMeta{}, nil
+		}
+		return partMeta{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Size:         total,
+		}, nil
+	case http.StatusOK:
+		return partMeta{}, nil
+	default:
+		return partMeta{}, &HTTPError{StatusCode: resp.StatusCode}
+	}
+}
+
+// splitSegments divides size bytes into n contiguous ranges of nearly equal
+// length.
+func splitSegments(size, n int64) []partSegment {
+	segs := make([]partSegment, n)
+	var start int64
+	for i := range segs {
+		end := start + (size-start)/(n-int64(i)) - 1
+		segs[i] = partSegment{Start: start, End: end}
+		start = end + 1
+	}
+	return segs
+}
+
+// preparePart creates a part file of the full size for segments to write
+// into, and saves meta before any data so a crash cannot leave a
+// preallocated file that looks complete.
+func preparePart(partPath string, meta partMeta) error {
+	if err := writePartMeta(partPath, meta); err != nil {
+		return fmt.Errorf("save part meta: %w", err)
+	}
+	f, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(meta.Size); err != nil {
+		return fmt.Errorf("allocate file: %w", err)
+	}
+	return nil
+}
+
+// fetchSegments downloads the unfinished part of every segment in parallel,
+// saving their progress every segmentCheckpoint and when done. The first
+// failing segment cancels the others.
+func fetchSegments(ctx context.Context, hc *http.Client, partPath, url string, headers map[string]string, meta *partMeta, progressFn func(int64)) error {
+	f, err := os.OpenFile(partPath, os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		total    int64
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for _, s := range meta.Segments {
+		total += s.Done
+	}
+	report := func(n int64) {
+		mu.Lock()
+		total += n
+		if progressFn != nil {
+			progressFn(total)
+		}
+		mu.Unlock()
+	}
+
+	stop := make(chan struct{})
+	checkpointed := make(chan struct{})
+	go func() {
+		defer close(checkpointed)
+		ticker := time.NewTicker(segmentCheckpoint)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				checkpointSegments(f, partPath, meta, &mu)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for i := range meta.Segments {
+		seg := &meta.Segments[i]
+		if seg.Done >= seg.size() {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fetchSegment(ctx, hc, f, url, headers, meta, seg, &mu, report)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-checkpointed
+
+	checkpointSegments(f, partPath, meta, &mu)
+	return firstErr
+}
+
+// checkpointSegments saves the progress of meta's segments. The file is
+// synced first so the saved progress never covers bytes that are not on
+// disk yet.
+func checkpointSegments(f *os.File, partPath string, meta *partMeta, mu *sync.Mutex) {
+	mu.Lock()
+	snap := *meta
+	snap.Segments = slices.Clone(meta.Segments)
+	mu.Unlock()
+	if err := f.Sync(); err != nil {
+		slog.Warn("download.part_sync", "path", partPath, "error", err)
+		return
+	}
+	if err := writePartMeta(partPath, snap); err != nil {
+		slog.Warn("download.part_meta", "path", partPath, "error", err)
+	}
+}
+
+// fetchSegment downloads the rest of seg into f. seg.Done is updated under
+// mu as data is written.
+func fetchSegment(ctx context.Context, hc *http.Client, f *os.File, url string, headers map[string]string, meta *partMeta, seg *partSegment, mu *sync.Mutex, report func(int64)) error {
+	start := seg.Start + seg.Done
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, seg.End))
+	if v := meta.validator(); v != "" {
+		req.Header.Set("If-Range", v)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("http get: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		got, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || got != start || (total > 0 && total != meta.Size) {
+			return fmt.Errorf("%w: got %q for bytes %d-%d", errRangeIgnored, resp.Header.Get("Content-Range"), start, seg.End)
+		}
+	case http.StatusOK:
+		return errRangeIgnored
+	default:
+		return &HTTPError{StatusCode: resp.StatusCode}
+	}
+
+	w := &segmentWriter{f: f, seg: seg, mu: mu, report: report}
+	if _, err := io.Copy(w, io.LimitReader(resp.Body, seg.End-start+1)); err != nil {
+		return fmt.Errorf("segment %d-%d: %w", seg.Start, seg.End, err)
+	}
+	mu.Lock()
+	done := seg.Done
+	mu.Unlock()
+	if done < seg.size() {
+		return fmt.Errorf("%w: segment %d-%d got %d of %d bytes", io.ErrUnexpectedEOF, seg.Start, seg.End, done, seg.size())
+	}
+	return nil
+}
+
+// segmentWriter writes a segment's bytes at their offset in the part file
+// and records them as done.
+type segmentWriter struct {
+	f      *os.File
+	seg    *partSegment
+	mu     *sync.Mutex
+	report func(int64)
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	off := w.seg.Start + w.seg.Done
+	w.mu.Unlock()
+	n, err := w.f.WriteAt(p, off)
+	w.mu.Lock()
+	w.seg.Done += int64(n)
+	w.mu.Unlock()
+	w.report(int64(n))
+	if err != nil {
+		return n, fmt.Errorf("write: %w", err)
+	}
+	return n, nil
+}
@@ -1,19 +1,25 @@
 package download
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math"
 	"net"
+	"net/http"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
 	"github.com/guohuiyuan/music-lib/scrape"
+	"github.com/guohuiyuan/music-lib/utils"
 )
 
 // TaskStatus represents the current state of a download task.
@@ -24,6 +30,8 @@ const (
 	StatusRunning   TaskStatus = "running"
 	StatusDone      TaskStatus = "done"
 	StatusFailed    TaskStatus = "failed"
+	StatusPaused    TaskStatus = "paused"
+	StatusCancelled TaskStatus = "cancelled"
 	// StatusCompleted is kept as alias so callers that used it still compile.
 	StatusCompleted = StatusDone
 )
@@ -38,6 +46,7 @@ type Task struct {
 	FilePath       string     `json:"file_path,omitempty"`
 	Song           model.Song `json:"song"`
 	Status         TaskStatus `json:"status"`
+	Priority       Priority   `json:"priority"`
 	RetryCount     int        `json:"retry_count"`
 	Skipped        bool       `json:"skipped"`
 	Progress       int64      `json:"progress"`
@@ -71,13 +80,15 @@ type UpgradeError struct {
 
 // BatchInfo summarizes the state of a batch download.
 type BatchInfo struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Total   int    `json:"total"`
-	Done    int    `json:"done"`
-	Failed  int    `json:"failed"`
-	Running int    `json:"running"`
-	Pending int    `json:"pending"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Done      int    `json:"done"`
+	Failed    int    `json:"failed"`
+	Running   int    `json:"running"`
+	Pending   int    `json:"pending"`
+	Paused    int    `json:"paused"`
+	Cancelled int    `json:"cancelled"`
 }
 
 // Config holds Manager configuration.
@@ -89,26 +100,75 @@ type Config struct {
 	ScrapeEnabled bool
 	ScrapeCover   bool
 	ScrapeLyrics  bool
+	// LyricsTranslation and LyricsRomanization also fetch those versions
+	// where the source has them (netease, qq). They are merged under each
+	// original line of the .lrc and embedded lyrics, or with LyricsSidecar
+	// written to separate .trans.lrc/.roma.lrc files, leaving the main
+	// lyrics untouched.
+	LyricsTranslation  bool
+	LyricsRomanization bool
+	LyricsSidecar      bool
+	// LyricsWordLevel saves word-timed lyrics (QRC/KRC/YRC) as Enhanced LRC
+	// where the source has them, for karaoke-style players.
+	LyricsWordLevel bool
+	// LyricsFallback looks the song up on other providers when its source
+	// returns no lyrics (see registry.FallbackLyrics).
+	LyricsFallback bool
+	// Segments is the number of parallel range requests used for one
+	// file (see WriteSegmentedStreamToDiskContext); 0 or 1 downloads over
+	// a single connection. ProviderSegments overrides it per source.
+	Segments         int
+	ProviderSegments map[string]int
+	// Bandwidth caps the combined speed of all downloads, optionally by
+	// time of day; the zero value is unlimited. It can be changed at
+	// runtime through Manager.Bandwidth.
+	Bandwidth BandwidthSchedule
+	// ProviderTimeout bounds each individual provider call (download URL,
+	// lyrics, fallback search). Default 30s.
+	ProviderTimeout time.Duration
 }
 
-// Manager coordinates download tasks with bounded concurrency.
+// segmentsFor returns the segment count to use for downloads from source.
+func (c Config) segmentsFor(source string) int {
+	if n, ok := c.ProviderSegments[source]; ok {
+		return n
+	}
+	return c.Segments
+}
+
+// Manager coordinates download tasks with a bounded pool of workers that
+// take queued tasks by priority (see queue.go).
 type Manager struct {
 	mu           sync.RWMutex
 	tasks        map[string]*Task
 	order        []string
 	batches      map[string]string // batchID -> batchName (separated from tasks)
-	sem          chan struct{}
 	cfg          Config
-	providers    map[string]ProviderFuncs
+	providers    *registry.Registry
 	onTaskUpdate func(task *Task)
 	updateCh     chan Task // serialized write queue for DB persistence
+	bandwidth    *Bandwidth
+
+	// queue holds pending and paused tasks in enqueue order; workers wait
+	// on cond (over mu) for a task to start. running maps the ID of each
+	// running task to the function that interrupts it.
+	queue   []*Task
+	cond    *sync.Cond
+	running map[string]context.CancelCauseFunc
+	paused  bool
+
+	// ctx is the parent of every task's context; cancel aborts all in-flight
+	// provider calls and transfers (see Close).
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewManager creates a Manager using the given Config.
 // If cfg.Concurrency <= 0 it defaults to 3.
 // If cfg.MaxRetries <= 0 it defaults to 3.
 // If cfg.RetryBackoff <= 0 it defaults to 2.
-func NewManager(cfg Config, providers map[string]ProviderFuncs) *Manager {
+// If cfg.ProviderTimeout <= 0 it defaults to 30s.
+func NewManager(cfg Config, providers *registry.Registry) *Manager {
 	if cfg.Concurrency <= 0 {
 		cfg.Concurrency = 3
 	}
@@ -118,18 +178,41 @@ func NewManager(cfg Config, providers map[string]ProviderFuncs) *Manager {
 	if cfg.RetryBackoff <= 0 {
 		cfg.RetryBackoff = 2
 	}
+	if cfg.ProviderTimeout <= 0 {
+		cfg.ProviderTimeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	m := &Manager{
-		tasks:    make(map[string]*Task),
-		batches:  make(map[string]string),
-		sem:      make(chan struct{}, cfg.Concurrency),
-		cfg:      cfg,
+		tasks:     make(map[string]*Task),
+		batches:   make(map[string]string),
+		cfg:       cfg,
 		providers: providers,
-		updateCh: make(chan Task, 256),
+		updateCh:  make(chan Task, 256),
+		bandwidth: NewBandwidth(cfg.Bandwidth),
+		running:   make(map[string]context.CancelCauseFunc),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+	m.cond = sync.NewCond(&m.mu)
 	go m.drainUpdates()
+	go m.pauseOnSchedule()
+	for range cfg.Concurrency {
+		go m.worker()
+	}
 	return m
 }
 
+// Close cancels the Manager's context, aborting in-flight provider calls,
+// pending retries and file transfers, and stops the workers. Tasks
+// interrupted this way go back to pending with their .part files, like
+// queued ones, so ResumeInterrupted continues them after a restart.
+func (m *Manager) Close() {
+	m.cancel()
+	m.mu.Lock()
+	m.cond.Broadcast()
+	m.mu.Unlock()
+}
+
 // SetOnTaskUpdate registers a callback called whenever a task's state changes.
 func (m *Manager) SetOnTaskUpdate(fn func(*Task)) {
 	m.mu.Lock()
@@ -138,7 +221,7 @@ func (m *Manager) SetOnTaskUpdate(fn func(*Task)) {
 }
 
 // LoadTasks populates the in-memory map from a persisted task slice.
-// Called once at startup after MarkRunningAsFailed.
+// Called once at startup, before ResumeInterrupted.
 func (m *Manager) LoadTasks(tasks []*Task) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -150,6 +233,33 @@ func (m *Manager) LoadTasks(tasks []*Task) {
 	}
 }
 
+// ResumeInterrupted queues loaded tasks that were pending or running when
+// the previous process stopped; their transfers continue from the .part
+// files left on disk. Paused tasks are queued but stay paused. Returns the
+// number of tasks restarted.
+func (m *Manager) ResumeInterrupted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	resumed := 0
+	for _, id := range m.order {
+		t := m.tasks[id]
+		switch t.Status {
+		case StatusPending, StatusRunning:
+			t.Status = StatusPending
+			t.Error = ""
+			m.notifyUpdate(t)
+			slog.Info("download.resume", "task_id", t.ID, "song", t.Song.Display(), "progress", t.Progress)
+			resumed++
+		case StatusPaused:
+		default:
+			continue
+		}
+		m.queue = append(m.queue, t)
+	}
+	m.cond.Broadcast()
+	return resumed
+}
+
 // MusicDir returns the configured base directory for NAS downloads.
 func (m *Manager) MusicDir() string {
 	return m.cfg.MusicDir
@@ -157,7 +267,13 @@ func (m *Manager) MusicDir() string {
 
 // Concurrency returns the max concurrent downloads.
 func (m *Manager) Concurrency() int {
-	return cap(m.sem)
+	return m.cfg.Concurrency
+}
+
+// Bandwidth returns the limiter shared by all downloads, whose schedule
+// can be read and changed at runtime.
+func (m *Manager) Bandwidth() *Bandwidth {
+	return m.bandwidth
 }
 
 // notifyUpdate enqueues a deep-copied snapshot of task for serialized DB write.
@@ -196,85 +312,71 @@ func (m *Manager) drainUpdates() {
 	}
 }
 
-// Enqueue creates a single download task and starts it in a goroutine.
-func (m *Manager) Enqueue(
-	song model.Song,
-	source string,
-	getURL func(*model.Song) (string, error),
-	getLyrics func(*model.Song) (string, error),
-) string {
-	id := newID("t")
-	requestedQuality := ""
-	if song.Extra != nil {
-		requestedQuality = song.Extra["quality"]
-	}
-	task := &Task{
-		ID:               id,
-		Source:           source,
-		Song:             song,
-		Status:           StatusPending,
-		CreatedAt:        time.Now(),
-		RequestedQuality: requestedQuality,
-	}
+// Enqueue queues a single download task at PriorityHigh, ahead of batches.
+// The source's Downloader and LyricsFetcher are resolved from the registry
+// when the task runs.
+func (m *Manager) Enqueue(song model.Song, source string) string {
+	task := newTask(song, source, "", PriorityHigh)
 
 	m.mu.Lock()
-	m.tasks[id] = task
-	m.order = append(m.order, id)
-	m.notifyUpdate(task)
+	m.addLocked(task)
 	m.mu.Unlock()
 
 	slog.Info("download.enqueue",
-		"task_id", id,
+		"task_id", task.ID,
 		"title", song.Name,
 		"artist", song.Artist,
 		"source", source,
 	)
+	return task.ID
+}
 
-	go m.runTask(task, getURL, getLyrics)
-	return id
+// EnqueueBatch queues tasks for multiple songs sharing a batch ID at
+// PriorityNormal. The batchName is stored in the synthetic batch task (for
+// ListBatches).
+func (m *Manager) EnqueueBatch(songs []model.Song, batchName, source string) string {
+	return m.EnqueueBatchWithPriority(songs, batchName, source, PriorityNormal)
 }
 
-// EnqueueBatch creates tasks for multiple songs sharing a batch ID.
-// The batchName is stored in the synthetic batch task (for ListBatches).
-func (m *Manager) EnqueueBatch(
-	songs []model.Song,
-	batchName string,
-	source string,
-	getURL func(*model.Song) (string, error),
-	getLyrics func(*model.Song) (string, error),
-) string {
+// EnqueueBatchWithPriority is EnqueueBatch with an explicit priority, e.g.
+// PriorityLow for chart monitor batches.
+func (m *Manager) EnqueueBatchWithPriority(songs []model.Song, batchName, source string, priority Priority) string {
 	batchID := newID("b")
 
 	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.batches[batchID] = batchName
-	m.mu.Unlock()
-
 	for i := range songs {
-		id := newID("t")
-		requestedQuality := ""
-		if songs[i].Extra != nil {
-			requestedQuality = songs[i].Extra["quality"]
-		}
-		task := &Task{
-			ID:               id,
-			Source:           source,
-			BatchID:          batchID,
-			Song:             songs[i],
-			Status:           StatusPending,
-			CreatedAt:        time.Now(),
-			RequestedQuality: requestedQuality,
-		}
-
-		m.mu.Lock()
-		m.tasks[id] = task
-		m.order = append(m.order, id)
-		m.notifyUpdate(task)
-		m.mu.Unlock()
+		m.addLocked(newTask(songs[i], source, batchID, priority))
+	}
+	return batchID
+}
 
-		go m.runTask(task, getURL, getLyrics)
+// newTask returns a pending task for song.
+func newTask(song model.Song, source, batchID string, priority Priority) *Task {
+	requestedQuality := ""
+	if song.Extra != nil {
+		requestedQuality = song.Extra["quality"]
+	}
+	return &Task{
+		ID:               newID("t"),
+		Source:           source,
+		BatchID:          batchID,
+		Song:             song,
+		Status:           StatusPending,
+		Priority:         priority,
+		CreatedAt:        time.Now(),
+		RequestedQuality: requestedQuality,
 	}
+}
 
-	return batchID
+// addLocked registers task and queues it. Must be called with m.mu held.
+func (m *Manager) addLocked(task *Task) {
+	m.tasks[task.ID] = task
+	m.order = append(m.order, task.ID)
+	m.notifyUpdate(task)
+	m.queue = append(m.queue, task)
+	m.cond.Signal()
 }
 
 // GetTask returns a task by ID.
@@ -314,6 +416,7 @@ func (m *Manager) ListBatches() []BatchInfo {
 	type acc struct {
 		name                                   string
 		total, done, failed, running, pending int
+		paused, cancelled                     int
 	}
 	agg := make(map[string]*acc)
 	var batchOrder []string
@@ -341,6 +444,10 @@ func (m *Manager) ListBatches() []BatchInfo {
 			a.failed++
 		case StatusRunning:
 			a.running++
+		case StatusPaused:
+			a.paused++
+		case StatusCancelled:
+			a.cancelled++
 		default:
 			a.pending++
 		}
@@ -350,13 +457,15 @@ func (m *Manager) ListBatches() []BatchInfo {
 	for _, bid := range batchOrder {
 		a := agg[bid]
 		result = append(result, BatchInfo{
-			ID:      bid,
-			Name:    a.name,
-			Total:   a.total,
-			Done:    a.done,
-			Failed:  a.failed,
-			Running: a.running,
-			Pending: a.pending,
+			ID:        bid,
+			Name:      a.name,
+			Total:     a.total,
+			Done:      a.done,
+			Failed:    a.failed,
+			Running:   a.running,
+			Pending:   a.pending,
+			Paused:    a.paused,
+			Cancelled: a.cancelled,
 		})
 	}
 	return result
@@ -374,40 +483,37 @@ func (m *Manager) LoadBatchNames(names map[string]string) {
 	}
 }
 
-// runTask executes a download in a goroutine bounded by the semaphore.
-func (m *Manager) runTask(
-	task *Task,
-	getURL func(*model.Song) (string, error),
-	getLyrics func(*model.Song) (string, error),
-) {
-	// Acquire semaphore slot.
-	m.sem <- struct{}{}
-	defer func() { <-m.sem }()
-
-	m.mu.Lock()
-	task.Status = StatusRunning
-	m.notifyUpdate(task)
-	m.mu.Unlock()
+// runTask executes a download on a worker (see next). ctx is cancelled when
+// the task is paused or cancelled, with the reason as its cause.
+func (m *Manager) runTask(ctx context.Context, task *Task) {
+	p, ok := m.providers.Get(task.Source)
+	if !ok || (p.StreamSource() == nil && p.Downloader() == nil) {
+		m.stopTask(ctx, task, fmt.Sprintf("provider %q does not support download", task.Source))
+		return
+	}
+	lyricsProvider := p
 
-	// 1. Get download URL with retry on transient errors.
-	var audioURL string
+	// 1. Resolve the stream to download, with retry on transient errors.
+	var stream model.Stream
 	var lastGetURLErr error
 
 	getURLFn := func() error {
-		url, err := getURL(&task.Song)
+		ctx, cancel := context.WithTimeout(ctx, m.cfg.ProviderTimeout)
+		defer cancel()
+		st, err := p.ResolveStream(ctx, &task.Song)
+		if errors.Is(err, registry.ErrNoStream) {
+			// Nothing playable = platform refused — not retryable, treat as a hard error.
+			return errEmptyURL
+		}
 		if err != nil {
 			return err
 		}
-		if url == "" {
-			// Empty URL = platform refused — not retryable, treat as a hard error.
-			return errEmptyURL
-		}
-		audioURL = url
+		stream = st
 		return nil
 	}
 
 	var totalAttempts int
-	if err := withRetry(m.cfg.MaxRetries, m.cfg.RetryBackoff, getURLFn, func(attempt int, waitMs int64, err error) {
+	if err := withRetry(ctx, m.cfg.MaxRetries, m.cfg.RetryBackoff, getURLFn, func(attempt int, waitMs int64, err error) {
 		totalAttempts = attempt
 		m.mu.Lock()
 		task.RetryCount = attempt
@@ -429,16 +535,23 @@ func (m *Manager) runTask(
 		m.mu.Lock()
 		task.RetryCount = finalAttempts
 		m.mu.Unlock()
+		if ctx.Err() != nil {
+			m.stopTask(ctx, task, fmt.Sprintf("cancelled: %v", lastGetURLErr))
+			return
+		}
 		// Primary source failed after all retries — try fallback.
-		fallbackURL, fbSource, fbErr := m.tryFallback(task.Song, task.Source)
+		fallbackStream, fbSource, fbErr := m.tryFallback(ctx, task.Song, task.Source)
 		if fbErr != nil {
-			m.failTask(task, fmt.Sprintf(
+			m.stopTask(ctx, task, fmt.Sprintf(
 				"primary source %s failed after %d attempts (last: %v); all fallback providers exhausted",
 				task.Source, finalAttempts, lastGetURLErr,
 			))
 			return
 		}
-		audioURL = fallbackURL
+		stream = fallbackStream
+		if fp, ok := m.providers.Get(fbSource); ok {
+			p = fp
+		}
 		slog.Warn("download.fallback",
 			"task_id", task.ID,
 			"from", task.Source,
@@ -447,36 +560,130 @@ func (m *Manager) runTask(
 		)
 		m.mu.Lock()
 		task.FallbackSource = fbSource
+		stream.ApplyTo(&task.Song)
 		m.notifyUpdate(task)
 		m.mu.Unlock()
 	}
 
 	// 2. Get lyrics (best-effort).
 	var lyrics string
-	if getLyrics != nil {
+	var lyricsSet *model.LyricsSet
+	lyricsOpts := model.LyricsOptions{Translation: m.cfg.LyricsTranslation, Romanization: m.cfg.LyricsRomanization, WordLevel: m.cfg.LyricsWordLevel}
+	if lyricsProvider.LyricsFetcher() != nil || lyricsProvider.LyricsSetFetcher() != nil {
+		ctx, cancel := context.WithTimeout(ctx, m.cfg.ProviderTimeout)
 		var err error
-		lyrics, err = getLyrics(&task.Song)
+		lyricsSet, err = lyricsProvider.FetchLyrics(ctx, &task.Song, lyricsOpts)
+		cancel()
 		if err != nil {
+			lyricsSet = nil
 			slog.Warn("download lyrics skipped", "task_id", task.ID, "song", task.Song.Display(), "error", err)
 		}
 	}
+	if m.cfg.LyricsFallback && (lyricsSet == nil || strings.TrimSpace(lyricsSet.Original) == "") {
+		// The whole lookup is bounded by registry.LyricsLookupTimeout.
+		set, from, err := m.providers.FallbackLyrics(ctx, &task.Song, lyricsOpts)
+		if err != nil {
+			slog.Debug("download.lyrics_fallback", "task_id", task.ID, "song", task.Song.Display(), "error", err)
+		} else {
+			lyricsSet = set
+			slog.Info("download.lyrics_fallback", "task_id", task.ID, "from", task.Source, "to", from)
+		}
+	}
+	if lyricsSet != nil {
+		if m.cfg.LyricsSidecar {
+			lyrics = lyricsSet.Original
+		} else {
+			lyrics = lyricsSet.Merge(lyricsOpts)
+		}
+	}
 
-	// 3. Write song to disk with progress tracking.
+	// 3. Write song to disk with progress tracking. Transfers are retried
+	// like the URL lookup; each attempt resumes from the .part file, and a
+	// stream URL that expired or was rejected is resolved again first.
 	progressFn := func(n int64) {
 		m.mu.Lock()
 		task.Progress = n
 		m.mu.Unlock()
 	}
-	writeResult, err := WriteSongToDisk(m.cfg.MusicDir, &task.Song, audioURL, lyrics, progressFn)
-	if err != nil {
-		m.failTask(task, fmt.Sprintf("write to disk: %v", err))
+	refreshStream := func() error {
+		if task.FallbackSource != "" {
+			st, fbSource, err := m.tryFallback(ctx, task.Song, task.Source)
+			if err != nil {
+				return err
+			}
+			if fp, ok := m.providers.Get(fbSource); ok {
+				p = fp
+			}
+			m.mu.Lock()
+			task.FallbackSource = fbSource
+			m.mu.Unlock()
+			stream = st
+			return nil
+		}
+		return getURLFn()
+	}
+	var writeResult WriteResult
+	var hc *http.Client
+	writeFn := func() error {
+		if stream.ExpiresAt > 0 && time.Until(time.Unix(stream.ExpiresAt, 0)) < streamExpiryMargin {
+			if err := refreshStream(); err != nil {
+				return err
+			}
+		}
+		if stream.Size > 0 {
+			m.mu.Lock()
+			task.TotalSize = stream.Size
+			m.mu.Unlock()
+		}
+		// Fetch the audio through the serving provider's client so its proxy
+		// applies, or through its StreamOpener when the audio is encrypted,
+		// both under the shared bandwidth limit.
+		hc = m.bandwidth.Client(p.Client().HTTPClientWithTimeout(downloadTimeout))
+		var err error
+		if opener := p.StreamOpener(); opener != nil {
+			// The opened stream may carry a different format than the
+			// resolved one; copy it back under the lock.
+			song := task.Song
+			writeResult, err = WriteOpenedStreamToDiskContext(ctx, m.bandwidth.Opener(opener), m.cfg.MusicDir, &song, stream, lyrics, progressFn)
+			if song.Ext != task.Song.Ext {
+				m.mu.Lock()
+				task.Song.Ext = song.Ext
+				m.notifyUpdate(task)
+				m.mu.Unlock()
+			}
+		} else {
+			writeResult, err = WriteSegmentedStreamToDiskContext(ctx, hc, m.cfg.segmentsFor(p.Name), m.cfg.MusicDir, &task.Song, stream, lyrics, progressFn)
+		}
+		if isStreamRejected(err) {
+			if refreshErr := refreshStream(); refreshErr != nil {
+				return refreshErr
+			}
+			return fmt.Errorf("%w: %v", errStreamExpired, err)
+		}
+		return err
+	}
+	if err := withRetry(ctx, m.cfg.MaxRetries, m.cfg.RetryBackoff, writeFn, func(attempt int, waitMs int64, err error) {
+		slog.Warn("download.write_retry",
+			"task_id", task.ID,
+			"attempt", attempt,
+			"max", m.cfg.MaxRetries,
+			"error", err,
+			"wait_ms", waitMs,
+		)
+	}); err != nil {
+		m.stopTask(ctx, task, fmt.Sprintf("write to disk: %v", err))
 		return
 	}
+	if lyricsSet != nil && m.cfg.LyricsSidecar && lyricsOpts.Any() {
+		if lrcErr := saveLyricsSidecars(filepath.Dir(writeResult.FilePath), &task.Song, lyricsSet, lyricsOpts); lrcErr != nil {
+			slog.Warn("download.lyrics_sidecar", "task_id", task.ID, "error", lrcErr)
+		}
+	}
 
 	// 4. Download cover (best-effort) — external cover.jpg for Plex/Navidrome.
 	if task.Song.Cover != "" {
 		coverDir := buildSongDir(m.cfg.MusicDir, &task.Song)
-		if coverErr := saveCover(coverDir, task.Song.Cover); coverErr != nil {
+		if coverErr := saveCover(ctx, hc, coverDir, task.Song.Cover); coverErr != nil {
 			slog.Warn("download cover skipped", "task_id", task.ID, "song", task.Song.Display(), "error", coverErr)
 		}
 	}
@@ -488,6 +695,7 @@ func (m *Manager) runTask(
 			Enabled: m.cfg.ScrapeEnabled,
 			Cover:   m.cfg.ScrapeCover,
 			Lyrics:  m.cfg.ScrapeLyrics,
+			Client:  p.Client().HTTPClient(),
 		}, &task.Song, writeResult.FilePath, lyrics)
 
 		scrapeNow := time.Now()
@@ -611,8 +819,8 @@ func (m *Manager) EnqueueUpgrade(taskIDs []string, quality string) UpgradeResult
 			continue
 		}
 
-		pf, ok := m.providers[t.Source]
-		if !ok || pf.GetDownloadURL == nil {
+		p, ok := m.providers.Get(t.Source)
+		if !ok || (p.StreamSource() == nil && p.Downloader() == nil) {
 			result.Skipped++
 			result.Errors = append(result.Errors, UpgradeError{
 				TaskID: t.ID,
@@ -634,14 +842,11 @@ func (m *Manager) EnqueueUpgrade(taskIDs []string, quality string) UpgradeResult
 		}
 		songCopy.Extra["quality"] = quality
 
-		newID := m.Enqueue(songCopy, t.Source, pf.GetDownloadURL, pf.GetLyrics)
-
 		// Tag the new task with requested quality and the upgrade batch ID.
+		task := newTask(songCopy, t.Source, batchID, PriorityNormal)
+		task.RequestedQuality = quality
 		m.mu.Lock()
-		if newTask, exists := m.tasks[newID]; exists {
-			newTask.BatchID = batchID
-			newTask.RequestedQuality = quality
-		}
+		m.addLocked(task)
 		m.mu.Unlock()
 
 		result.Queued++
@@ -674,9 +879,37 @@ func (m *Manager) failTask(task *Task, msg string) {
 
 // --- package-level helpers ---
 
-// errEmptyURL is returned when a provider returns an empty URL (non-retryable).
+// errEmptyURL is returned when a provider returns an empty URL or no playable
+// stream (non-retryable).
 var errEmptyURL = errors.New("empty download URL (platform refused or no copyright)")
 
+// errStreamExpired marks a transfer that failed because the stream URL
+// expired; the URL has been resolved again, so the next attempt may succeed.
+var errStreamExpired = errors.New("stream URL expired")
+
+// streamExpiryMargin is how long before Stream.ExpiresAt the URL is
+// resolved again instead of being used for a transfer.
+const streamExpiryMargin = 30 * time.Second
+
+// isStreamRejected reports whether a transfer failed in the way CDNs reject
+// an expired signed URL.
+func isStreamRejected(err error) bool {
+	status := 0
+	var httpErr *HTTPError
+	var statusErr *utils.StatusError
+	switch {
+	case errors.As(err, &httpErr):
+		status = httpErr.StatusCode
+	case errors.As(err, &statusErr):
+		status = statusErr.StatusCode
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
 // newID generates a random ID with the given prefix, e.g. "t-{16hex}" or "b-{16hex}".
 func newID(prefix string) string {
 	b := make([]byte, 8)
@@ -688,8 +921,10 @@ func newID(prefix string) string {
 }
 
 // isRetryable returns true when the error should trigger an automatic retry.
-// Retryable: network timeouts, HTTP 5xx, HTTP 429, generic net.OpError.
-// Not retryable: HTTP 404, HTTP 403, empty URL, other HTTP 4xx.
+// Retryable: rate limiting, network timeouts, HTTP 5xx, HTTP 429, generic net.OpError.
+// Not retryable: any other classified provider error (not found, VIP, restricted,
+// login expired, schema changed, invalid input), HTTP 404/403/other 4xx, empty URL.
+// Retrying a login-expired or VIP error would only hammer the platform.
 func isRetryable(err error) bool {
 	if err == nil {
 		return false
@@ -697,6 +932,30 @@ func isRetryable(err error) bool {
 	if errors.Is(err, errEmptyURL) {
 		return false
 	}
+	// A refreshed stream URL or a cut-off transfer is worth another attempt;
+	// the latter resumes from its .part file.
+	if errors.Is(err, errStreamExpired) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// Cancellation is the caller's decision; an expired per-call deadline
+	// is treated like any other timeout below.
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// An open breaker fails fast on purpose; retrying would only wait it out.
+	if errors.Is(err, utils.ErrCircuitOpen) {
+		return false
+	}
+
+	if kind := model.KindOf(err); kind != nil {
+		return kind == model.ErrRateLimited
+	}
+
+	// Non-200 answer from a provider API call (utils.GetContext/PostContext).
+	var statusErr *utils.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
 
 	// Structured HTTP error from downloadFile / writer.go.
 	var httpErr *HTTPError
@@ -726,11 +985,16 @@ func isRetryable(err error) bool {
 	return false
 }
 
+// maxRetryWait caps a single backoff sleep, including one requested via Retry-After.
+const maxRetryWait = 60 * time.Second
+
 // withRetry calls fn up to maxRetries times with exponential backoff.
-// Backoff schedule: backoffBase^(attempt-1) seconds (1s, 2s, 4s for base=2).
+// Backoff schedule: backoffBase^(attempt-1) seconds (1s, 2s, 4s for base=2), or the
+// error's Retry-After when that is longer.
 // onRetry is called before each sleep with the current attempt number and wait duration.
-// Returns nil on first success, or the last error after all attempts.
-func withRetry(maxRetries, backoffBase int, fn func() error, onRetry func(attempt int, waitMs int64, err error)) error {
+// Returns nil on first success, or the last error after all attempts. Cancelling
+// ctx interrupts the backoff sleep and stops further attempts.
+func withRetry(ctx context.Context, maxRetries, backoffBase int, fn func() error, onRetry func(attempt int, waitMs int64, err error)) error {
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		lastErr = fn()
@@ -743,16 +1007,26 @@ func withRetry(maxRetries, backoffBase int, fn func() error, onRetry func(attemp
 		if attempt == maxRetries {
 			break
 		}
-		// Exponential backoff: backoffBase^(attempt-1) seconds, capped at 60s.
+		// Exponential backoff: backoffBase^(attempt-1) seconds, capped at maxRetryWait.
 		waitSec := math.Pow(float64(backoffBase), float64(attempt-1))
-		if waitSec > 60 {
-			waitSec = 60
+		if waitSec > maxRetryWait.Seconds() {
+			waitSec = maxRetryWait.Seconds()
 		}
 		waitDur := time.Duration(waitSec * float64(time.Second))
+		// Honour the platform's Retry-After when it asks for a longer pause.
+		if ra := model.RetryAfter(lastErr); ra > waitDur {
+			waitDur = min(ra, maxRetryWait)
+		}
 		if onRetry != nil {
 			onRetry(attempt, waitDur.Milliseconds(), lastErr)
 		}
-		time.Sleep(waitDur)
+		timer := time.NewTimer(waitDur)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return lastErr
+		case <-timer.C:
+		}
 	}
 	return lastErr
 }
@@ -1,12 +1,23 @@
 package download
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
 )
 
 // --- newID ---
@@ -112,6 +123,17 @@ func TestIsRetryable_WrappedHTTPError(t *testing.T) {
 	}
 }
 
+func TestIsRetryable_InterruptedTransfer(t *testing.T) {
+	for _, err := range []error{
+		fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+		fmt.Errorf("%w: %v", errStreamExpired, &HTTPError{StatusCode: 403}),
+	} {
+		if !isRetryable(err) {
+			t.Errorf("%v should be retryable", err)
+		}
+	}
+}
+
 func TestIsRetryable_GenericError(t *testing.T) {
 	err := errors.New("some random error")
 	if isRetryable(err) {
@@ -119,11 +141,32 @@ func TestIsRetryable_GenericError(t *testing.T) {
 	}
 }
 
+func TestIsRetryable_ProviderKinds(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{model.Errorf("netease", model.ErrRateLimited, "too many requests"), true},
+		{model.Errorf("netease", model.ErrLoginExpired, "need login"), false},
+		{model.Errorf("qq", model.ErrVIPRequired, "vkey"), false},
+		{model.Errorf("kugou", model.ErrNotFound, "song"), false},
+		{&utils.StatusError{StatusCode: 503}, true},
+		{&utils.StatusError{StatusCode: 429}, true},
+		{&utils.StatusError{StatusCode: 403}, false},
+		{fmt.Errorf("search: %w", utils.ErrCircuitOpen), false},
+	}
+	for _, c := range cases {
+		if got := isRetryable(c.err); got != c.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
 // --- withRetry ---
 
 func TestWithRetry_SuccessFirstAttempt(t *testing.T) {
 	calls := 0
-	err := withRetry(3, 2, func() error {
+	err := withRetry(context.Background(), 3, 2, func() error {
 		calls++
 		return nil
 	}, nil)
@@ -137,7 +180,7 @@ func TestWithRetry_SuccessFirstAttempt(t *testing.T) {
 
 func TestWithRetry_SuccessAfterRetries(t *testing.T) {
 	calls := 0
-	err := withRetry(3, 1, func() error {
+	err := withRetry(context.Background(), 3, 1, func() error {
 		calls++
 		if calls < 3 {
 			return &HTTPError{StatusCode: 503}
@@ -154,7 +197,7 @@ func TestWithRetry_SuccessAfterRetries(t *testing.T) {
 
 func TestWithRetry_AllFail(t *testing.T) {
 	calls := 0
-	err := withRetry(3, 1, func() error {
+	err := withRetry(context.Background(), 3, 1, func() error {
 		calls++
 		return &HTTPError{StatusCode: 500}
 	}, nil)
@@ -168,7 +211,7 @@ func TestWithRetry_AllFail(t *testing.T) {
 
 func TestWithRetry_NonRetryableExitsImmediately(t *testing.T) {
 	calls := 0
-	err := withRetry(3, 1, func() error {
+	err := withRetry(context.Background(), 3, 1, func() error {
 		calls++
 		return &HTTPError{StatusCode: 404}
 	}, nil)
@@ -182,7 +225,7 @@ func TestWithRetry_NonRetryableExitsImmediately(t *testing.T) {
 
 func TestWithRetry_OnRetryCallback(t *testing.T) {
 	var attempts []int
-	_ = withRetry(3, 1, func() error {
+	_ = withRetry(context.Background(), 3, 1, func() error {
 		return &HTTPError{StatusCode: 502}
 	}, func(attempt int, waitMs int64, err error) {
 		attempts = append(attempts, attempt)
@@ -197,7 +240,7 @@ func TestWithRetry_BackoffCap(t *testing.T) {
 	// With backoffBase=100 and attempt=2, raw = 100^1 = 100s > 60s cap.
 	// Verify it doesn't take > 61s.
 	start := time.Now()
-	_ = withRetry(2, 100, func() error {
+	_ = withRetry(context.Background(), 2, 100, func() error {
 		return &HTTPError{StatusCode: 500}
 	}, nil)
 	elapsed := time.Since(start)
@@ -210,7 +253,7 @@ func TestWithRetry_BackoffCap(t *testing.T) {
 
 func TestWithRetry_EmptyURLNoRetry(t *testing.T) {
 	calls := 0
-	err := withRetry(3, 1, func() error {
+	err := withRetry(context.Background(), 3, 1, func() error {
 		calls++
 		return errEmptyURL
 	}, nil)
@@ -222,6 +265,35 @@ func TestWithRetry_EmptyURLNoRetry(t *testing.T) {
 	}
 }
 
+func TestWithRetry_ContextCancelStopsBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := withRetry(ctx, 3, 10, func() error {
+		calls++
+		cancel()
+		return &HTTPError{StatusCode: 503}
+	}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call after cancel, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancel should interrupt backoff sleep, took %v", elapsed)
+	}
+}
+
+func TestIsRetryable_ContextErrors(t *testing.T) {
+	if isRetryable(context.Canceled) {
+		t.Fatal("context.Canceled should not be retryable")
+	}
+	if !isRetryable(fmt.Errorf("get url: %w", context.DeadlineExceeded)) {
+		t.Fatal("per-call deadline should be retryable")
+	}
+}
+
 // --- Manager basic ---
 
 func TestManager_EnqueueAndGetTask(t *testing.T) {
@@ -300,3 +372,349 @@ func TestManager_LoadBatchNames(t *testing.T) {
 		t.Fatalf("expected 'Another', got %q", batches[1].Name)
 	}
 }
+
+func TestWithRetry_LoginExpiredNoRetry(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), 3, 1, func() error {
+		calls++
+		return model.Errorf("qq", model.ErrLoginExpired, "cookie expired")
+	}, nil)
+	if !errors.Is(err, model.ErrLoginExpired) {
+		t.Fatalf("expected ErrLoginExpired, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call (no retry for login expired), got %d", calls)
+	}
+}
+
+func TestWithRetry_HonoursRetryAfter(t *testing.T) {
+	calls := 0
+	var waits []int64
+	_ = withRetry(context.Background(), 2, 1, func() error {
+		calls++
+		return &model.ProviderError{Source: "bilibili", Kind: model.ErrRateLimited, RetryAfter: 2 * time.Second}
+	}, func(attempt int, waitMs int64, err error) {
+		waits = append(waits, waitMs)
+	})
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	// The 2s hint is longer than the 1s backoff, so it wins.
+	if len(waits) != 1 || waits[0] != 2000 {
+		t.Fatalf("unexpected waits %v", waits)
+	}
+}
+
+// expiringDownloader hands out a fresh signed URL per call; the server only
+// accepts the newest one, like a CDN whose links have expired.
+type expiringDownloader struct {
+	base  string
+	calls *atomic.Int32
+}
+
+func (d expiringDownloader) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return fmt.Sprintf("%s/audio?sig=%d", d.base, d.calls.Add(1)), nil
+}
+
+func TestManager_ResumeInterrupted_RefreshesExpiredURL(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig") != strconv.Itoa(int(calls.Load())) || calls.Load() < 2 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("fake flac data"))
+	}))
+	defer srv.Close()
+
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return expiringDownloader{base: srv.URL, calls: &calls} })
+	m := NewManager(Config{MusicDir: t.TempDir(), Concurrency: 1, MaxRetries: 3, RetryBackoff: 1}, reg)
+	defer m.Close()
+
+	m.LoadTasks([]*Task{
+		{ID: "t-001", Source: "test", Status: StatusRunning, Error: "stale", Song: model.Song{ID: "1", Name: "Song", Artist: "Artist", Ext: "flac"}},
+		{ID: "t-002", Source: "test", Status: StatusDone},
+	})
+	if n := m.ResumeInterrupted(); n != 1 {
+		t.Fatalf("expected 1 resumed task, got %d", n)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		m.mu.RLock()
+		status, errMsg := m.tasks["t-001"].Status, m.tasks["t-001"].Error
+		m.mu.RUnlock()
+		if status == StatusDone {
+			break
+		}
+		if status == StatusFailed || time.Now().After(deadline) {
+			t.Fatalf("task not done: status %s, error %q", status, errMsg)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the URL to be resolved twice, got %d", calls.Load())
+	}
+}
+
+// streamOnly offers streams but no download URL, like soda.
+type streamOnly struct{}
+
+func (streamOnly) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return nil, nil
+}
+
+func TestManager_EnqueueUpgrade_StreamOnlyProvider(t *testing.T) {
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return streamOnly{} })
+	m := NewManager(Config{MusicDir: t.TempDir(), Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+	defer m.Close()
+	m.Pause()
+
+	m.LoadTasks([]*Task{{ID: "t-001", Source: "test", Status: StatusDone, Song: model.Song{ID: "1", Name: "Song", Ext: "mp3"}}})
+	if result := m.EnqueueUpgrade([]string{"t-001"}, "lossless"); result.Queued != 1 {
+		t.Fatalf("expected the task to be queued, got %+v", result)
+	}
+}
+
+// --- queue ---
+
+func TestManager_QueuePriority(t *testing.T) {
+	m := NewManager(Config{MusicDir: t.TempDir(), Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, nil)
+	defer m.Close()
+	m.Pause()
+
+	m.EnqueueBatchWithPriority([]model.Song{{ID: "1"}, {ID: "2"}}, "chart", "test", PriorityLow)
+	m.EnqueueBatch([]model.Song{{ID: "3"}}, "playlist", "test")
+	single := m.Enqueue(model.Song{ID: "4"}, "test")
+
+	m.mu.Lock()
+	if i := m.pickLocked(); i >= 0 {
+		t.Errorf("paused queue handed out %s", m.queue[i].ID)
+	}
+	var order []string
+	m.paused = false
+	for i := m.pickLocked(); i >= 0; i = m.pickLocked() {
+		order = append(order, m.queue[i].Song.ID)
+		m.queue = append(m.queue[:i], m.queue[i+1:]...)
+	}
+	m.paused = true
+	m.mu.Unlock()
+
+	if want := []string{"4", "3", "1", "2"}; strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Fatalf("start order %v, want %v", order, want)
+	}
+
+	if err := m.SetTaskPriority(single, PriorityLow); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := m.GetTask(single); task.Priority != PriorityLow {
+		t.Errorf("priority not updated: %d", task.Priority)
+	}
+	if err := m.SetTaskPriority("t-missing", PriorityHigh); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestManager_PauseResumeCancelBatch(t *testing.T) {
+	m := NewManager(Config{MusicDir: t.TempDir(), Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, nil)
+	defer m.Close()
+	m.Pause()
+
+	batchID := m.EnqueueBatch([]model.Song{{ID: "1"}, {ID: "2"}}, "playlist", "test")
+	other := m.Enqueue(model.Song{ID: "3"}, "test")
+
+	if n, err := m.PauseBatch(batchID); err != nil || n != 2 {
+		t.Fatalf("PauseBatch = %d, %v", n, err)
+	}
+	if n, err := m.PauseBatch(batchID); err != nil || n != 0 {
+		t.Fatalf("pausing a paused batch again = %d, %v; want 0 changed", n, err)
+	}
+	batches := m.ListBatches()
+	if len(batches) != 1 || batches[0].Paused != 2 {
+		t.Fatalf("expected 2 paused tasks, got %+v", batches)
+	}
+	m.mu.Lock()
+	picked := ""
+	m.paused = false
+	if i := m.pickLocked(); i >= 0 {
+		picked = m.queue[i].ID
+	}
+	m.paused = true
+	m.mu.Unlock()
+	if picked != other {
+		t.Fatalf("paused batch tasks should be skipped, picked %q", picked)
+	}
+
+	if n, err := m.ResumeBatch(batchID); err != nil || n != 2 {
+		t.Fatalf("ResumeBatch = %d, %v", n, err)
+	}
+	if n, err := m.ResumeBatch(batchID); err != nil || n != 0 {
+		t.Fatalf("resuming a pending batch again = %d, %v; want 0 changed", n, err)
+	}
+	if batches := m.ListBatches(); batches[0].Pending != 2 {
+		t.Fatalf("expected 2 pending tasks, got %+v", batches[0])
+	}
+
+	if n, err := m.CancelBatch(batchID); err != nil || n != 2 {
+		t.Fatalf("CancelBatch = %d, %v", n, err)
+	}
+	if batches := m.ListBatches(); batches[0].Cancelled != 2 {
+		t.Fatalf("expected 2 cancelled tasks, got %+v", batches[0])
+	}
+	m.mu.RLock()
+	queued := len(m.queue)
+	m.mu.RUnlock()
+	if queued != 1 {
+		t.Errorf("cancelled tasks should leave the queue, %d queued", queued)
+	}
+	if n, err := m.CancelBatch(batchID); err != nil || n != 0 {
+		t.Errorf("cancelling a finished batch again = %d, %v; want 0 changed", n, err)
+	}
+	if _, err := m.PauseBatch("b-missing"); !errors.Is(err, ErrBatchNotFound) {
+		t.Errorf("expected ErrBatchNotFound, got %v", err)
+	}
+}
+
+// stallingServer sends the first chunk of a file and then holds the
+// connection open until the client goes away.
+func stallingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1048576")
+		_, _ = w.Write([]byte(strings.Repeat("x", 4096)))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type fixedDownloader string
+
+func (d fixedDownloader) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return string(d), nil
+}
+
+// waitTask polls until cond holds for the task or fails the test.
+func waitTask(t *testing.T, m *Manager, id string, what string, cond func(*Task) bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		m.mu.RLock()
+		ok, status := cond(m.tasks[id]), m.tasks[id].Status
+		m.mu.RUnlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s never %s (status %s)", id, what, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManager_CancelRunningTask_RemovesPart(t *testing.T) {
+	srv := stallingServer(t)
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return fixedDownloader(srv.URL + "/audio") })
+	dir := t.TempDir()
+	m := NewManager(Config{MusicDir: dir, Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+	defer m.Close()
+
+	id := m.Enqueue(model.Song{ID: "1", Name: "Song", Artist: "Artist", Ext: "flac"}, "test")
+	waitTask(t, m, id, "started", func(t *Task) bool { return t.Progress > 0 })
+	parts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix+"*"))
+	if len(parts) == 0 {
+		t.Fatal("expected a part file while downloading")
+	}
+
+	if err := m.CancelTask(id); err != nil {
+		t.Fatal(err)
+	}
+	waitTask(t, m, id, "cancelled", func(t *Task) bool { return t.Status == StatusCancelled })
+	if parts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix+"*")); len(parts) != 0 {
+		t.Errorf("part files left behind: %v", parts)
+	}
+	if err := m.CancelTask(id); !errors.Is(err, ErrTaskFinished) {
+		t.Errorf("expected ErrTaskFinished, got %v", err)
+	}
+}
+
+func TestManager_PauseRunningTask_KeepsPart(t *testing.T) {
+	srv := stallingServer(t)
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return fixedDownloader(srv.URL + "/audio") })
+	dir := t.TempDir()
+	m := NewManager(Config{MusicDir: dir, Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+	defer m.Close()
+
+	id := m.Enqueue(model.Song{ID: "1", Name: "Song", Artist: "Artist", Ext: "flac"}, "test")
+	waitTask(t, m, id, "started", func(t *Task) bool { return t.Progress > 0 })
+
+	if err := m.PauseTask(id); err != nil {
+		t.Fatal(err)
+	}
+	waitTask(t, m, id, "paused", func(t *Task) bool { return t.Status == StatusPaused })
+	if parts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix)); len(parts) != 1 {
+		t.Errorf("expected the part file to be kept, got %v", parts)
+	}
+
+	if err := m.ResumeTask(id); err != nil {
+		t.Fatal(err)
+	}
+	waitTask(t, m, id, "restarted", func(t *Task) bool { return t.Status == StatusRunning })
+}
+
+func TestManager_BandwidthPauseRequeuesRunningTask(t *testing.T) {
+	srv := stallingServer(t)
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return fixedDownloader(srv.URL + "/audio") })
+	dir := t.TempDir()
+	m := NewManager(Config{MusicDir: dir, Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+	defer m.Close()
+
+	id := m.Enqueue(model.Song{ID: "1", Name: "Song", Artist: "Artist", Ext: "flac"}, "test")
+	waitTask(t, m, id, "started", func(t *Task) bool { return t.Progress > 0 })
+
+	if err := m.Bandwidth().SetSchedule(BandwidthSchedule{Limit: BandwidthPaused}); err != nil {
+		t.Fatal(err)
+	}
+	waitTask(t, m, id, "requeued", func(t *Task) bool { return t.Status == StatusPending })
+	if parts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix)); len(parts) != 1 {
+		t.Errorf("expected the part file to be kept, got %v", parts)
+	}
+
+	if err := m.Bandwidth().SetSchedule(BandwidthSchedule{}); err != nil {
+		t.Fatal(err)
+	}
+	waitTask(t, m, id, "restarted", func(t *Task) bool { return t.Status == StatusRunning })
+}
+
+func TestManager_CloseRequeuesRunningTask(t *testing.T) {
+	srv := stallingServer(t)
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "test"}, func() any { return fixedDownloader(srv.URL + "/audio") })
+	dir := t.TempDir()
+	m := NewManager(Config{MusicDir: dir, Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+
+	id := m.Enqueue(model.Song{ID: "1", Name: "Song", Artist: "Artist", Ext: "flac"}, "test")
+	waitTask(t, m, id, "started", func(t *Task) bool { return t.Progress > 0 })
+
+	m.Close()
+	waitTask(t, m, id, "requeued", func(t *Task) bool { return t.Status == StatusPending })
+	if parts, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+partSuffix)); len(parts) != 1 {
+		t.Errorf("expected the part file to be kept, got %v", parts)
+	}
+
+	// After a restart the task is picked up again.
+	task, _ := m.GetTask(id)
+	m2 := NewManager(Config{MusicDir: dir, Concurrency: 1, MaxRetries: 1, RetryBackoff: 1}, reg)
+	defer m2.Close()
+	m2.Pause()
+	m2.LoadTasks([]*Task{{ID: id, Source: "test", Status: task.Status, Song: task.Song}})
+	if n := m2.ResumeInterrupted(); n != 1 {
+		t.Errorf("ResumeInterrupted = %d, want 1", n)
+	}
+}
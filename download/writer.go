@@ -1,6 +1,7 @@
 package download
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
 	"github.com/guohuiyuan/music-lib/utils"
 )
 
@@ -24,8 +26,11 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("http status %d", e.StatusCode)
 }
 
-// longClient has a generous timeout for large audio file downloads.
-var longClient = &http.Client{Timeout: 10 * time.Minute}
+// downloadTimeout is the generous timeout used for large audio file downloads.
+const downloadTimeout = 10 * time.Minute
+
+// longClient is used when the caller does not supply a provider's client.
+var longClient = &http.Client{Timeout: downloadTimeout}
 
 // WriteAction describes what happened when WriteSongToDisk ran.
 type WriteAction string
@@ -99,6 +104,93 @@ func qualityScore(ext string, bitrate int, fileSize int64) int {
 //
 // Lyrics and cover are saved regardless of the Action.
 func WriteSongToDisk(baseDir string, song *model.Song, audioURL, lyrics string, progressFn func(int64)) (WriteResult, error) {
+	return WriteSongToDiskContext(context.Background(), baseDir, song, audioURL, lyrics, progressFn)
+}
+
+// WriteSongToDiskContext is WriteSongToDisk with a context; cancelling ctx
+// aborts the audio transfer, leaving a .part file the next call resumes.
+//
+// When the song's provider in the default registry implements
+// registry.StreamOpener, the audio is read through it, so a soda
+// "url#auth=" link is saved decrypted.
+func WriteSongToDiskContext(ctx context.Context, baseDir string, song *model.Song, audioURL, lyrics string, progressFn func(int64)) (WriteResult, error) {
+	stream := model.Stream{URL: audioURL}
+	if p, ok := registry.Get(song.Source); ok {
+		if opener := p.StreamOpener(); opener != nil {
+			return WriteOpenedStreamToDiskContext(ctx, opener, baseDir, song, stream, lyrics, progressFn)
+		}
+	}
+	return WriteStreamToDiskContext(ctx, nil, baseDir, song, stream, lyrics, progressFn)
+}
+
+// WriteStreamToDiskContext is WriteSongToDiskContext for a resolved stream:
+// the stream's Headers are sent with the audio request. The file name and
+// quality comparison use song.Ext/Bitrate, so callers should apply the stream
+// to song first (ResolveStream does).
+//
+// hc carries the provider's proxy and transport; nil uses a plain client.
+func WriteStreamToDiskContext(ctx context.Context, hc *http.Client, baseDir string, song *model.Song, stream model.Stream, lyrics string, progressFn func(int64)) (WriteResult, error) {
+	return WriteSegmentedStreamToDiskContext(ctx, hc, 1, baseDir, song, stream, lyrics, progressFn)
+}
+
+// WriteSegmentedStreamToDiskContext is WriteStreamToDiskContext over up to
+// segments parallel connections, each fetching its own byte range, for CDNs
+// that throttle every connection. It falls back to a single stream when the
+// server does not support ranges or the file is too small to split;
+// segments <= 1 always uses a single stream. progressFn receives the
+// combined progress of all segments.
+func WriteSegmentedStreamToDiskContext(ctx context.Context, hc *http.Client, segments int, baseDir string, song *model.Song, stream model.Stream, lyrics string, progressFn func(int64)) (WriteResult, error) {
+	return writeToDisk(baseDir, song, lyrics, func(partPath string) error {
+		if segments > 1 {
+			return downloadSegmented(ctx, hc, segments, partPath, stream.URL, stream.Headers, progressFn)
+		}
+		return downloadFile(ctx, hc, partPath, stream.URL, stream.Headers, progressFn)
+	})
+}
+
+// WriteOpenedStreamToDiskContext is WriteStreamToDiskContext for providers
+// whose audio needs more than a plain GET: the file is written from
+// opener.OpenStreamContext, e.g. decrypted on the fly for soda. Unless an
+// existing file is already at least as good, the stream is opened before the
+// file is named, so the name follows the format it actually carries:
+// song.Ext is set to the opened stream's Ext when there is one. Such streams
+// cannot be resumed, so an interrupted transfer starts over.
+func WriteOpenedStreamToDiskContext(ctx context.Context, opener registry.StreamOpener, baseDir string, song *model.Song, stream model.Stream, lyrics string, progressFn func(int64)) (WriteResult, error) {
+	// Opening costs a request and the decryptor setup, so check first.
+	dir := buildSongDir(baseDir, song)
+	if existing, ext, size, found := existingAudio(dir, song); found && keepExisting(dir, song, lyrics, existing, ext, size) {
+		return WriteResult{FilePath: existing, Action: ActionSkipped}, nil
+	}
+
+	audio, err := opener.OpenStreamContext(ctx, stream)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	defer audio.Close()
+	if audio.Ext != "" {
+		song.Ext = audio.Ext
+	}
+	return writeToDisk(baseDir, song, lyrics, func(partPath string) error {
+		f, err := os.Create(partPath)
+		if err != nil {
+			return fmt.Errorf("create file: %w", err)
+		}
+		defer f.Close()
+		n, err := copyWithProgress(f, audio, 0, progressFn)
+		if err != nil {
+			return err
+		}
+		if audio.Size > 0 && n != audio.Size {
+			return fmt.Errorf("%w: got %d of %d bytes", io.ErrUnexpectedEOF, n, audio.Size)
+		}
+		return nil
+	})
+}
+
+// writeToDisk implements WriteStreamToDiskContext. fetch writes the audio to
+// the .part path it is given, which is renamed into place once complete; a
+// failed fetch leaves the part file for the next attempt to resume.
+func writeToDisk(baseDir string, song *model.Song, lyrics string, fetch func(partPath string) error) (WriteResult, error) {
 	dir := buildSongDir(baseDir, song)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return WriteResult{}, fmt.Errorf("create dir: %w", err)
@@ -107,6 +199,67 @@ func WriteSongToDisk(baseDir string, song *model.Song, audioURL, lyrics string,
 	newFilename := song.Filename()
 	destPath := filepath.Join(dir, newFilename)
 
+	bestExisting, existingExt, existingSize, found := existingAudio(dir, song)
+	if !found {
+		// No existing file — normal download.
+		if err := fetchPart(destPath, fetch); err != nil {
+			return WriteResult{}, err
+		}
+		if lyrics != "" {
+			if lrcErr := saveLyrics(dir, song, lyrics); lrcErr != nil {
+				slog.Warn("download.lyrics_save", "error", lrcErr)
+			}
+		}
+		return WriteResult{FilePath: destPath, Action: ActionNew}, nil
+	}
+
+	if keepExisting(dir, song, lyrics, bestExisting, existingExt, existingSize) {
+		return WriteResult{FilePath: bestExisting, Action: ActionSkipped}, nil
+	}
+
+	// New file has higher quality — safe replace via the part file; the old
+	// file is untouched until the new one is complete.
+	if err := fetchPart(destPath, fetch); err != nil {
+		return WriteResult{}, fmt.Errorf("download upgrade: %w", err)
+	}
+
+	// Remove old file if it has a different path (different extension).
+	if bestExisting != destPath {
+		if removeErr := os.Remove(bestExisting); removeErr != nil {
+			slog.Warn("download.remove_old_file_failed",
+				"old_path", bestExisting,
+				"error", removeErr,
+			)
+		}
+	}
+
+	if lyrics != "" {
+		if lrcErr := saveLyrics(dir, song, lyrics); lrcErr != nil {
+			slog.Warn("download.lyrics_save", "error", lrcErr)
+		}
+	}
+
+	slog.Info("download.upgrade",
+		"old_path", bestExisting,
+		"new_path", destPath,
+		"previous_ext", existingExt,
+		"previous_size", existingSize,
+		"new_ext", song.Ext,
+		"action", "upgraded",
+	)
+
+	return WriteResult{
+		FilePath:     destPath,
+		Action:       ActionUpgraded,
+		PreviousExt:  existingExt,
+		PreviousSize: existingSize,
+	}, nil
+}
+
+// existingAudio returns the highest-quality audio file already saved for
+// song in dir, with its extension and size; found is false when there is
+// none.
+func existingAudio(dir string, song *model.Song) (path, ext string, size int64, found bool) {
 	// Find existing files with the same base name using ReadDir + prefix match.
 	// Avoids filepath.Glob which treats [ ] as character class syntax — breaks
 	// on song names containing brackets (e.g. "[Bonus Track]").
@@ -115,7 +268,7 @@ func WriteSongToDisk(baseDir string, song *model.Song, audioURL, lyrics string,
 
 	var audioMatches []string
 	entries, readErr := os.ReadDir(dir)
-	if readErr != nil {
+	if readErr != nil && !os.IsNotExist(readErr) {
 		slog.Warn("download.readdir_error", "dir", dir, "error", readErr)
 	}
 	for _, e := range entries {
@@ -127,23 +280,13 @@ func WriteSongToDisk(baseDir string, song *model.Song, audioURL, lyrics string,
 			continue
 		}
 		ext := strings.ToLower(filepath.Ext(name))
-		if ext == ".lrc" || ext == ".tmp" {
+		if ext == ".lrc" || ext == ".tmp" || strings.HasSuffix(name, partSuffix) || strings.HasSuffix(name, partSuffix+partMetaSuffix) {
 			continue
 		}
 		audioMatches = append(audioMatches, filepath.Join(dir, name))
 	}
-
 	if len(audioMatches) == 0 {
-		// No existing file — normal download.
-		if err := downloadFile(destPath, audioURL, progressFn); err != nil {
-			return WriteResult{}, err
-		}
-		if lyrics != "" {
-			if lrcErr := saveLyrics(dir, song, lyrics); lrcErr != nil {
-				slog.Warn("download.lyrics_save", "error", lrcErr)
-			}
-		}
-		return WriteResult{FilePath: destPath, Action: ActionNew}, nil
+		return "", "", 0, false
 	}
 
 	// Find the highest-quality existing file.
@@ -163,76 +306,33 @@ func WriteSongToDisk(baseDir string, song *model.Song, audioURL, lyrics string,
 		}
 	}
 
-	existingInfo, statErr := os.Stat(bestExisting)
-	var existingSize int64
-	if statErr == nil {
-		existingSize = existingInfo.Size()
+	if info, statErr := os.Stat(bestExisting); statErr == nil {
+		size = info.Size()
 	}
-	existingExt := strings.TrimPrefix(strings.ToLower(filepath.Ext(bestExisting)), ".")
+	ext = strings.TrimPrefix(strings.ToLower(filepath.Ext(bestExisting)), ".")
+	return bestExisting, ext, size, true
+}
 
+// keepExisting reports whether the existing file is at least as good as
+// song, in which case the download is skipped and only the lyrics are saved.
+func keepExisting(dir string, song *model.Song, lyrics, existing, existingExt string, existingSize int64) bool {
 	newScore := qualityScore(song.Ext, song.Bitrate, 0)
 	existingScore := qualityScore(existingExt, 0, existingSize)
-
-	if newScore <= existingScore {
-		// Existing file is at least as good — skip download, still save lyrics.
-		if lyrics != "" {
-			if lrcErr := saveLyrics(dir, song, lyrics); lrcErr != nil {
-				slog.Warn("download.lyrics_save", "error", lrcErr)
-			}
-		}
-		slog.Info("download.skipped",
-			"existing", bestExisting,
-			"existing_score", existingScore,
-			"new_score", newScore,
-		)
-		return WriteResult{FilePath: bestExisting, Action: ActionSkipped}, nil
-	}
-
-	// New file has higher quality — safe replace via tmp file.
-	tmpPath := destPath + ".tmp"
-
-	if err := downloadFile(tmpPath, audioURL, progressFn); err != nil {
-		// Clean up tmp on failure; old file is untouched.
-		_ = os.Remove(tmpPath)
-		return WriteResult{}, fmt.Errorf("download upgrade: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, destPath); err != nil {
-		_ = os.Remove(tmpPath)
-		return WriteResult{}, fmt.Errorf("rename tmp file: %w", err)
+	if newScore > existingScore {
+		return false
 	}
-
-	// Remove old file if it has a different path (different extension).
-	if bestExisting != destPath {
-		if removeErr := os.Remove(bestExisting); removeErr != nil {
-			slog.Warn("download.remove_old_file_failed",
-				"old_path", bestExisting,
-				"error", removeErr,
-			)
-		}
-	}
-
+	// Existing file is at least as good — skip download, still save lyrics.
 	if lyrics != "" {
 		if lrcErr := saveLyrics(dir, song, lyrics); lrcErr != nil {
 			slog.Warn("download.lyrics_save", "error", lrcErr)
 		}
 	}
-
-	slog.Info("download.upgrade",
-		"old_path", bestExisting,
-		"new_path", destPath,
-		"previous_ext", existingExt,
-		"previous_size", existingSize,
-		"new_ext", song.Ext,
-		"action", "upgraded",
+	slog.Info("download.skipped",
+		"existing", existing,
+		"existing_score", existingScore,
+		"new_score", newScore,
 	)
-
-	return WriteResult{
-		FilePath:     destPath,
-		Action:       ActionUpgraded,
-		PreviousExt:  existingExt,
-		PreviousSize: existingSize,
-	}, nil
+	return true
 }
 
 // buildSongDir returns {base}/{Artist}/{Album}.
@@ -248,32 +348,149 @@ func buildSongDir(baseDir string, song *model.Song) string {
 	return filepath.Join(baseDir, utils.SanitizeFilename(artist), utils.SanitizeFilename(album))
 }
 
-// downloadFile streams a URL to destPath, calling progressFn with cumulative bytes.
-func downloadFile(destPath, url string, progressFn func(int64)) error {
-	resp, err := longClient.Get(url)
-	if err != nil {
-		return fmt.Errorf("http get: %w", err)
+// fetchPart runs fetch on destPath's part file and moves the result into
+// place. The part file and its metadata survive a failed fetch.
+func fetchPart(destPath string, fetch func(partPath string) error) error {
+	partPath := destPath + partSuffix
+	if err := fetch(partPath); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
+	if err := os.Rename(partPath, destPath); err != nil {
+		return fmt.Errorf("rename part file: %w", err)
+	}
+	_ = os.Remove(partPath + partMetaSuffix)
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return &HTTPError{StatusCode: resp.StatusCode}
+// removePart deletes the part file and metadata of an unfinished download
+// of song, e.g. when its task is cancelled.
+func removePart(baseDir string, song *model.Song) {
+	partPath := filepath.Join(buildSongDir(baseDir, song), song.Filename()) + partSuffix
+	for _, p := range []string{partPath, partPath + partMetaSuffix, partPath + partMetaSuffix + ".tmp"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			slog.Warn("download.remove_part", "path", p, "error", err)
+		}
 	}
+}
+
+// downloadFile downloads url into partPath, calling progressFn with the
+// cumulative size of the file. headers are added to the request (e.g. a
+// Referer some CDNs insist on).
+//
+// If partPath already holds the start of the file, only the rest is
+// requested, with a Range header and an If-Range validator taken from the
+// response that started it. A server that answers with the full body, a
+// different range or a different total size restarts the file from zero.
+// A body shorter than its Content-Length fails with io.ErrUnexpectedEOF,
+// keeping what was received for the next attempt.
+func downloadFile(ctx context.Context, hc *http.Client, partPath, url string, headers map[string]string, progressFn func(int64)) error {
+	if hc == nil {
+		hc = longClient
+	}
+
+	var offset int64
+	meta, metaErr := readPartMeta(partPath)
+	if info, err := os.Stat(partPath); err == nil && metaErr == nil && meta.resumable() {
+		offset = info.Size()
+	}
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("new request: %w", err)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			if v := meta.validator(); v != "" {
+				req.Header.Set("If-Range", v)
+			}
+		}
+		resp, err := hc.Do(req)
+		if err != nil {
+			return fmt.Errorf("http get: %w", err)
+		}
+
+		restart := false
+		switch resp.StatusCode {
+		case http.StatusOK:
+			offset = 0
+			meta = partMeta{
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				Size:         resp.ContentLength,
+			}
+			if err := writePartMeta(partPath, meta); err != nil {
+				slog.Warn("download.part_meta", "path", partPath, "error", err)
+			}
+		case http.StatusPartialContent:
+			start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			restart = offset == 0 || !ok || start != offset || (meta.Size > 0 && total > 0 && total != meta.Size)
+			if meta.Size <= 0 {
+				meta.Size = total
+			}
+		case http.StatusRequestedRangeNotSatisfiable:
+			if _, total, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+				resp.Body.Close()
+				return nil // the part file is already complete
+			}
+			restart = true
+		default:
+			resp.Body.Close()
+			return &HTTPError{StatusCode: resp.StatusCode}
+		}
+		if restart {
+			// Not the continuation that was asked for: start over.
+			resp.Body.Close()
+			if offset == 0 {
+				return fmt.Errorf("unexpected partial response %q", resp.Header.Get("Content-Range"))
+			}
+			offset = 0
+			continue
+		}
 
-	f, err := os.Create(destPath)
+		err = appendPart(partPath, resp.Body, offset, meta.Size, progressFn)
+		resp.Body.Close()
+		return err
+	}
+}
+
+// appendPart writes r to partPath starting at offset (truncating the file
+// when offset is 0) and checks the result against the expected total size.
+func appendPart(partPath string, r io.Reader, offset, total int64, progressFn func(int64)) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
 		return fmt.Errorf("create file: %w", err)
 	}
 	defer f.Close()
 
-	var written int64
+	n, err := copyWithProgress(f, r, offset, progressFn)
+	if err != nil {
+		return err
+	}
+	if total > 0 && n != total {
+		return fmt.Errorf("%w: got %d of %d bytes", io.ErrUnexpectedEOF, n, total)
+	}
+	return nil
+}
+
+// copyWithProgress copies r to w, calling progressFn with offset plus the
+// bytes copied so far, and returns that cumulative count.
+func copyWithProgress(w io.Writer, r io.Reader, offset int64, progressFn func(int64)) (int64, error) {
+	written := offset
 	buf := make([]byte, 32*1024)
 	for {
-		nr, readErr := resp.Body.Read(buf)
+		nr, readErr := r.Read(buf)
 		if nr > 0 {
-			nw, writeErr := f.Write(buf[:nr])
+			nw, writeErr := w.Write(buf[:nr])
 			if writeErr != nil {
-				return fmt.Errorf("write: %w", writeErr)
+				return written, fmt.Errorf("write: %w", writeErr)
 			}
 			written += int64(nw)
 			if progressFn != nil {
@@ -282,30 +499,55 @@ func downloadFile(destPath, url string, progressFn func(int64)) error {
 		}
 		if readErr != nil {
 			if readErr == io.EOF {
-				break
+				return written, nil
 			}
-			return fmt.Errorf("read: %w", readErr)
+			return written, fmt.Errorf("read: %w", readErr)
 		}
 	}
-
-	return nil
 }
 
-// saveLyrics writes an LRC file next to the audio file.
+// saveLyrics writes an LRC file next to the audio file. The lyrics are
+// normalized with model.NormalizeLRC first so that CRLF line endings, GBK
+// text or out-of-order timestamps from a platform do not end up on disk.
 func saveLyrics(dir string, song *model.Song, lyrics string) error {
 	lrcPath := filepath.Join(dir, song.LrcFilename())
-	return os.WriteFile(lrcPath, []byte(lyrics), 0644)
+	return os.WriteFile(lrcPath, []byte(model.NormalizeLRC(lyrics)), 0644)
+}
+
+// saveLyricsSidecars writes the translation and romanization selected by
+// opts as "Artist - Song.trans.lrc" and "Artist - Song.roma.lrc" next to the
+// audio file. Versions the source does not have are skipped.
+func saveLyricsSidecars(dir string, song *model.Song, set *model.LyricsSet, opts model.LyricsOptions) error {
+	base := strings.TrimSuffix(song.LrcFilename(), ".lrc")
+	if opts.Translation && set.Translation != "" {
+		if err := os.WriteFile(filepath.Join(dir, base+".trans.lrc"), []byte(model.NormalizeLRC(set.Translation)), 0644); err != nil {
+			return err
+		}
+	}
+	if opts.Romanization && set.Romanization != "" {
+		if err := os.WriteFile(filepath.Join(dir, base+".roma.lrc"), []byte(model.NormalizeLRC(set.Romanization)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // saveCover downloads and saves cover.jpg into the given directory.
-// It skips if cover.jpg already exists.
-func saveCover(dir, coverURL string) error {
+// It skips if cover.jpg already exists. A nil hc uses a plain client.
+func saveCover(ctx context.Context, hc *http.Client, dir, coverURL string) error {
+	if hc == nil {
+		hc = longClient
+	}
 	coverPath := filepath.Join(dir, "cover.jpg")
 	if _, err := os.Stat(coverPath); err == nil {
 		return nil // already exists
 	}
 
-	resp, err := longClient.Get(coverURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coverURL, nil)
+	if err != nil {
+		return fmt.Errorf("new cover request: %w", err)
+	}
+	resp, err := hc.Do(req)
 	if err != nil {
 		return fmt.Errorf("http get cover: %w", err)
 	}
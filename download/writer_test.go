@@ -1,12 +1,23 @@
 package download
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
 )
@@ -282,3 +293,499 @@ func TestWriteSongToDisk_LyricsAlwaysSaved(t *testing.T) {
 		t.Error("lyrics content mismatch")
 	}
 }
+
+// TestWriteStreamToDisk_SendsHeaders: stream headers reach the audio request
+func TestWriteStreamToDisk_SendsHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Referer") != "https://example.com/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("fake m4a data"))
+	}))
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("m4a", "TestArtist", "TestSong", 192)
+	stream := model.Stream{URL: srv.URL, Headers: map[string]string{"Referer": "https://example.com/"}}
+
+	result, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Action != ActionNew {
+		t.Errorf("expected ActionNew, got %s", result.Action)
+	}
+}
+
+// upperOpener stands in for a decrypting provider: it "decrypts" the
+// stream key by upper-casing it.
+type upperOpener struct{}
+
+func (upperOpener) OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error) {
+	return &model.AudioStream{ReadCloser: io.NopCloser(strings.NewReader(strings.ToUpper(st.Key))), Ext: "m4a", Size: -1}, nil
+}
+
+// TestWriteOpenedStreamToDisk: the file holds what the opener returned, not the URL body
+func TestWriteOpenedStreamToDisk(t *testing.T) {
+	baseDir := t.TempDir()
+	song := testSong("m4a", "TestArtist", "TestSong", 192)
+	stream := model.Stream{URL: "http://invalid.example/audio", Encryption: "playauth", Key: "decrypted audio"}
+
+	var progress int64
+	result, err := WriteOpenedStreamToDiskContext(context.Background(), upperOpener{}, baseDir, &song, stream, "", func(n int64) { progress = n })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "DECRYPTED AUDIO" || progress != int64(len(data)) {
+		t.Errorf("got %q, progress %d", data, progress)
+	}
+}
+
+// TestWriteOpenedStreamToDisk_UsesOpenedExt: the file is named after the
+// format the opener reports, not the one the stream was resolved with.
+func TestWriteOpenedStreamToDisk_UsesOpenedExt(t *testing.T) {
+	baseDir := t.TempDir()
+	song := testSong("mp4", "TestArtist", "TestSong", 192)
+	stream := model.Stream{URL: "http://invalid.example/audio", Encryption: "playauth", Key: "decrypted audio"}
+
+	result, err := WriteOpenedStreamToDiskContext(context.Background(), upperOpener{}, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Ext(result.FilePath) != ".m4a" || song.Ext != "m4a" {
+		t.Errorf("expected an .m4a file, got %s (song.Ext %q)", result.FilePath, song.Ext)
+	}
+}
+
+// failingOpener fails the test if a stream is opened.
+type failingOpener struct{ t *testing.T }
+
+func (o failingOpener) OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error) {
+	o.t.Error("stream opened although the existing file is kept")
+	return nil, errors.New("unexpected open")
+}
+
+// TestWriteOpenedStreamToDisk_SkipsWithoutOpening: an existing file that is
+// at least as good is kept before the stream is opened.
+func TestWriteOpenedStreamToDisk_SkipsWithoutOpening(t *testing.T) {
+	baseDir := t.TempDir()
+	song := testSong("m4a", "TestArtist", "TestSong", 192)
+	dir := buildSongDir(baseDir, &song)
+	existing := filepath.Join(dir, "TestArtist - TestSong.flac")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, make([]byte, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := WriteOpenedStreamToDiskContext(context.Background(), failingOpener{t}, baseDir, &song, model.Stream{Key: "audio"}, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Action != ActionSkipped || result.FilePath != existing {
+		t.Errorf("expected %s to be kept, got %s %s", existing, result.Action, result.FilePath)
+	}
+}
+
+// flakyServer serves body with the given ETag through http.ServeContent, so
+// Range and If-Range are honoured, but cuts the first response off halfway.
+func flakyServer(t *testing.T, etag func() string, body func() []byte) (*httptest.Server, *[]string) {
+	t.Helper()
+	var ranges []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		ranges = append(ranges, r.Header.Get("Range"))
+		data := body()
+		w.Header().Set("ETag", etag())
+		if calls == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data[:len(data)/2])
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &ranges
+}
+
+// TestWriteStreamToDisk_Resume: a cut-off download keeps its .part file and
+// the next attempt only fetches the rest
+func TestWriteStreamToDisk_Resume(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 1000)
+	srv, ranges := flakyServer(t, func() string { return `"v1"` }, func() []byte { return body })
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	stream := model.Stream{URL: srv.URL}
+
+	_, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+	parts, _ := filepath.Glob(filepath.Join(baseDir, "*", "*", "*"+partSuffix))
+	if len(parts) != 1 {
+		t.Fatalf("expected one part file, got %v", parts)
+	}
+
+	var progress int64
+	result, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", func(n int64) { progress = n })
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if want := "bytes=" + strconv.Itoa(len(body)/2) + "-"; (*ranges)[1] != want {
+		t.Errorf("range: got %q, want %q", (*ranges)[1], want)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) || progress != int64(len(body)) {
+		t.Errorf("got %d bytes, progress %d, want %d", len(data), progress, len(body))
+	}
+	if left, _ := filepath.Glob(filepath.Join(baseDir, "*", "*", "*.part*")); len(left) != 0 {
+		t.Errorf("part files left behind: %v", left)
+	}
+}
+
+// TestWriteStreamToDisk_ResumeChangedFile: If-Range makes the server send the
+// whole file when it changed since the part file was started
+func TestWriteStreamToDisk_ResumeChangedFile(t *testing.T) {
+	body, etag := bytes.Repeat([]byte("a"), 4000), `"v1"`
+	srv, _ := flakyServer(t, func() string { return etag }, func() []byte { return body })
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	stream := model.Stream{URL: srv.URL}
+
+	if _, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", nil); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	body, etag = bytes.Repeat([]byte("b"), 3000), `"v2"`
+	result, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("stale bytes kept: got %d bytes starting %q", len(data), data[:1])
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		in           string
+		start, total int64
+		ok           bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"bytes */1000", -1, 1000, true},
+		{"items 0-9/10", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, total, ok := parseContentRange(tt.in)
+		if start != tt.start || total != tt.total || ok != tt.ok {
+			t.Errorf("%q: got (%d, %d, %v)", tt.in, start, total, ok)
+		}
+	}
+}
+
+// TestWriteSegmentedStreamToDisk: a large file is fetched as parallel ranges
+// and reassembled in order
+func TestWriteSegmentedStreamToDisk(t *testing.T) {
+	body := make([]byte, 3*minSegmentSize+123)
+	for i := range body {
+		body[i] = byte(i * 7)
+	}
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	var progress atomic.Int64
+	result, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 4, baseDir, &song, model.Stream{URL: srv.URL}, "", func(n int64) { progress.Store(n) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Fatal("reassembled file differs from the original")
+	}
+	if progress.Load() != int64(len(body)) {
+		t.Errorf("progress: got %d, want %d", progress.Load(), len(body))
+	}
+	// One probe plus one request per segment (3 MiB allows 3).
+	if len(ranges) != 4 || ranges[0] != "bytes=0-0" {
+		t.Errorf("unexpected requests: %v", ranges)
+	}
+	if left, _ := filepath.Glob(filepath.Join(baseDir, "*", "*", "*.part*")); len(left) != 0 {
+		t.Errorf("part files left behind: %v", left)
+	}
+}
+
+// TestWriteSegmentedStreamToDisk_NoRangeSupport: servers that ignore Range
+// get a single plain download
+func TestWriteSegmentedStreamToDisk_NoRangeSupport(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 3*minSegmentSize)
+	srv := makeAudioServer(t, body)
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	result, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 4, baseDir, &song, model.Stream{URL: srv.URL}, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(result.FilePath); err != nil || info.Size() != int64(len(body)) {
+		t.Fatalf("unexpected file: %v, %v", info, err)
+	}
+}
+
+func TestSplitSegments(t *testing.T) {
+	segs := splitSegments(10, 3)
+	want := []partSegment{{Start: 0, End: 2}, {Start: 3, End: 5}, {Start: 6, End: 9}}
+	if len(segs) != len(want) {
+		t.Fatalf("got %+v", segs)
+	}
+	for i := range want {
+		if segs[i] != want[i] {
+			t.Errorf("segment %d: got %+v, want %+v", i, segs[i], want[i])
+		}
+	}
+}
+
+// TestWriteSegmentedStreamToDisk_Resume: after a segment is cut off, the next
+// attempt only asks for what each segment is still missing
+func TestWriteSegmentedStreamToDisk_Resume(t *testing.T) {
+	body := make([]byte, 2*minSegmentSize)
+	for i := range body {
+		body[i] = byte(i % 251)
+	}
+	var mu sync.Mutex
+	var ranges []string
+	cut := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		cutNow := !cut && r.Header.Get("Range") == "bytes="+strconv.Itoa(minSegmentSize)+"-"+strconv.Itoa(len(body)-1)
+		if cutNow {
+			cut = true
+		}
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		if cutNow {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", minSegmentSize, len(body)-1, len(body)))
+			w.Header().Set("Content-Length", strconv.Itoa(minSegmentSize))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(body[minSegmentSize : minSegmentSize+1000])
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	stream := model.Stream{URL: srv.URL}
+	if _, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 2, baseDir, &song, stream, "", nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+
+	mu.Lock()
+	ranges = nil
+	mu.Unlock()
+	result, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 2, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	// The first segment may or may not have finished before the cut-off
+	// cancelled it, but there is no new probe and the second segment
+	// continues from where it stopped.
+	if want := fmt.Sprintf("bytes=%d-%d", minSegmentSize+1000, len(body)-1); !slices.Contains(ranges, want) || slices.Contains(ranges, "bytes=0-0") {
+		t.Errorf("resume requests: got %v, want %s", ranges, want)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Fatal("resumed file differs from the original")
+	}
+}
+
+// TestWriteSegmentedStreamToDisk_ResumeSingleStream: a part file left by a
+// single-stream download is continued with a Range request, not overwritten
+// by a fresh segmented download
+func TestWriteSegmentedStreamToDisk_ResumeSingleStream(t *testing.T) {
+	body := make([]byte, 2*minSegmentSize)
+	for i := range body {
+		body[i] = byte(i % 251)
+	}
+	var mu sync.Mutex
+	var ranges []string
+	cut := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		cutNow := !cut
+		cut = true
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		if cutNow {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			_, _ = w.Write(body[:minSegmentSize])
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	stream := model.Stream{URL: srv.URL}
+	if _, err := WriteStreamToDiskContext(context.Background(), nil, baseDir, &song, stream, "", nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+
+	mu.Lock()
+	ranges = nil
+	mu.Unlock()
+	result, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 2, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if want := []string{fmt.Sprintf("bytes=%d-", minSegmentSize)}; !slices.Equal(ranges, want) {
+		t.Errorf("resume requests: got %v, want %v", ranges, want)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Fatal("resumed file differs from the original")
+	}
+}
+
+// TestWriteSegmentedStreamToDisk_ResumeAfterCrash: segment progress is
+// checkpointed while the transfer runs, so the state a killed process leaves
+// on disk resumes every segment from its checkpoint
+func TestWriteSegmentedStreamToDisk_ResumeAfterCrash(t *testing.T) {
+	defer func(d time.Duration) { segmentCheckpoint = d }(segmentCheckpoint)
+	segmentCheckpoint = 10 * time.Millisecond
+
+	body := make([]byte, 2*minSegmentSize)
+	for i := range body {
+		body[i] = byte(i % 251)
+	}
+	const sent = 64 << 10
+	var mu sync.Mutex
+	var ranges []string
+	stall := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		stallNow := stall && r.Header.Get("Range") != "bytes=0-0"
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		if !stallNow {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+			return
+		}
+		// Send the start of the range, then hang like a dying connection.
+		var start, end int
+		_, _ = fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(body)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(body[start : start+sent])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	baseDir := t.TempDir()
+	song := testSong("flac", "TestArtist", "TestSong", 0)
+	stream := model.Stream{URL: srv.URL}
+	partPath := filepath.Join(buildSongDir(baseDir, &song), song.Filename()) + partSuffix
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := WriteSegmentedStreamToDiskContext(ctx, nil, 2, baseDir, &song, stream, "", nil)
+		done <- err
+	}()
+
+	// Wait for a checkpoint covering both partial segments, then capture the
+	// files as a kill at this moment would leave them.
+	var metaData, partData []byte
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		metaData, _ = os.ReadFile(partPath + partMetaSuffix)
+		var m partMeta
+		if json.Unmarshal(metaData, &m) == nil && len(m.Segments) == 2 && m.Segments[0].Done == sent && m.Segments[1].Done == sent {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no mid-transfer checkpoint, last meta %s", metaData)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	partData, err := os.ReadFile(partPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-done
+	if err := os.WriteFile(partPath, partData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(partPath+partMetaSuffix, metaData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	stall, ranges = false, nil
+	mu.Unlock()
+	result, err := WriteSegmentedStreamToDiskContext(context.Background(), nil, 2, baseDir, &song, stream, "", nil)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	slices.Sort(ranges)
+	want := []string{
+		fmt.Sprintf("bytes=%d-%d", sent, minSegmentSize-1),
+		fmt.Sprintf("bytes=%d-%d", minSegmentSize+sent, len(body)-1),
+	}
+	slices.Sort(want)
+	if !slices.Equal(ranges, want) {
+		t.Errorf("resume requests: got %v, want %v", ranges, want)
+	}
+	data, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Fatal("resumed file differs from the original")
+	}
+}
@@ -1,12 +1,13 @@
 package fivesing
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"html"
 	"log/slog"
 	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,39 +23,170 @@ const (
 
 type Fivesing struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Fivesing {
-	return &Fivesing{cookie: cookie}
+func New(cookie string, opts ...utils.ClientOption) *Fivesing {
+	return &Fivesing{cookie: cookie, client: utils.NewClient(opts...)}
 }
 
-var defaultFivesing = New("")
+// Client 返回实例使用的 HTTP 客户端
+func (f *Fivesing) Client() *utils.Client { return f.client }
 
-func Search(keyword string) ([]model.Song, error) { return defaultFivesing.Search(keyword) }
+var (
+	defaultMu       sync.RWMutex
+	defaultFivesing = New("")
+)
+
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultFivesing = New(defaultFivesing.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Fivesing {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultFivesing
+}
+
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultFivesing.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }
 func GetPlaylistSongs(id string) ([]model.Song, error) {
-	return defaultFivesing.GetPlaylistSongs(id)
+	return getDefault().GetPlaylistSongs(id)
+}
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
-	return defaultFivesing.ParsePlaylist(link)
+	return getDefault().ParsePlaylist(link)
+}
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return defaultFivesing.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return defaultFivesing.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)       { return defaultFivesing.Parse(link) }
 
-// Search 搜索歌曲
 func (f *Fivesing) Search(keyword string) ([]model.Song, error) {
+	return f.SearchContext(context.Background(), keyword)
+}
+
+func (f *Fivesing) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return f.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (f *Fivesing) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return f.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (f *Fivesing) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return f.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (f *Fivesing) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return f.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (f *Fivesing) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return f.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (f *Fivesing) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return f.ParsePlaylistContext(context.Background(), link)
+}
+
+func (f *Fivesing) Parse(link string) (*model.Song, error) {
+	return f.ParseContext(context.Background(), link)
+}
+
+func (f *Fivesing) GetDownloadURL(s *model.Song) (string, error) {
+	return f.GetDownloadURLContext(context.Background(), s)
+}
+
+func (f *Fivesing) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return f.GetStreamsContext(context.Background(), s)
+}
+
+func (f *Fivesing) GetLyrics(s *model.Song) (string, error) {
+	return f.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (f *Fivesing) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := f.SearchPageContext(ctx, keyword, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲。5sing 每页条数由服务端固定，limit 参数不生效，
+// 返回结果中的 Limit 为本页实际条数
+func (f *Fivesing) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, _ = model.NormalizePage(page, limit, 0, 0)
 	params := url.Values{}
 	params.Set("keyword", keyword)
 	params.Set("sort", "1")
-	params.Set("page", "1")
+	params.Set("page", strconv.Itoa(page))
 	params.Set("filter", "0")
 	params.Set("type", "0")
 
 	apiURL := "http://search.5sing.kugou.com/home/json?" + params.Encode()
-	body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
+	body, err := f.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
 	if err != nil {
 		return nil, err
 	}
@@ -67,10 +199,11 @@ func (f *Fivesing) Search(keyword string) ([]model.Song, error) {
 			SongSize  int64  `json:"songSize"`
 			TypeEname string `json:"typeEname"`
 		} `json:"list"`
+		PageInfo searchPageInfo `json:"pageInfo"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("fivesing json parse error: %w", err)
+		return nil, model.Errorf("fivesing", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -97,20 +230,48 @@ func (f *Fivesing) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	return newSearchPage(songs, page, len(resp.List), resp.PageInfo), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (f *Fivesing) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// searchPageInfo 是 5sing 搜索接口返回的分页信息
+type searchPageInfo struct {
+	TotalPages int `json:"totalPages"`
+	TotalCount int `json:"totalCount"`
+}
+
+// newSearchPage 根据服务端分页信息构造结果；缺少 pageInfo 时，本页非空即认为可能还有下一页
+func newSearchPage[T any](items []T, page, raw int, info searchPageInfo) *model.Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	hasMore := raw > 0
+	if info.TotalPages > 0 {
+		hasMore = page < info.TotalPages
+	}
+	return &model.Page[T]{Items: items, Page: page, Limit: raw, Total: info.TotalCount, HasMore: hasMore}
+}
+
+// SearchPlaylistContext 搜索歌单
+func (f *Fivesing) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := f.SearchPlaylistPageContext(ctx, keyword, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单，limit 同样由服务端决定
+func (f *Fivesing) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, _ = model.NormalizePage(page, limit, 0, 0)
 	params := url.Values{}
 	params.Set("keyword", keyword)
 	params.Set("sort", "1")
-	params.Set("page", "1")
+	params.Set("page", strconv.Itoa(page))
 	params.Set("filter", "0")
 	params.Set("type", "1")
 
 	apiURL := "http://search.5sing.kugou.com/home/json?" + params.Encode()
-	body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
+	body, err := f.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
 	if err != nil {
 		return nil, err
 	}
@@ -126,10 +287,11 @@ func (f *Fivesing) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Content    string `json:"content"`
 			UserId     string `json:"userId"`
 		} `json:"list"`
+		PageInfo searchPageInfo `json:"pageInfo"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("fivesing playlist json parse error: %w", err)
+		return nil, model.Errorf("fivesing", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	playlists := make([]model.Playlist, len(resp.List))
@@ -172,7 +334,7 @@ func (f *Fivesing) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				if name, err := f.fetchCreatorName(plID); err == nil && name != "" {
+				if name, err := f.fetchCreatorName(ctx, plID); err == nil && name != "" {
 					playlists[idx].Creator = name
 				}
 			}(i, item.SongListId)
@@ -180,13 +342,13 @@ func (f *Fivesing) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	}
 	wg.Wait()
 
-	return playlists, nil
+	return newSearchPage(playlists, page, len(resp.List), resp.PageInfo), nil
 }
 
 // fetchCreatorName 辅助函数：仅获取创建者名称
-func (f *Fivesing) fetchCreatorName(id string) (string, error) {
+func (f *Fivesing) fetchCreatorName(ctx context.Context, id string) (string, error) {
 	infoURL := fmt.Sprintf("http://mobileapi.5sing.kugou.com/song/getsonglist?id=%s&songfields=user", id)
-	infoBody, err := utils.Get(infoURL, utils.WithHeader("User-Agent", UserAgent))
+	infoBody, err := f.client.GetContext(ctx, infoURL, utils.WithHeader("User-Agent", UserAgent))
 	if err != nil {
 		return "", err
 	}
@@ -217,30 +379,40 @@ func (f *Fivesing) fetchCreatorName(id string) (string, error) {
 	return data.User.UserName, nil
 }
 
-// GetPlaylistSongs 获取歌单详情 (简化版：直接复用 fetchPlaylistDetail)
-func (f *Fivesing) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取歌单详情 (简化版：直接复用 fetchPlaylistDetail)
+func (f *Fivesing) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
 	// 复用核心逻辑，只返回歌曲切片
-	_, songs, err := f.fetchPlaylistDetail(id)
+	_, songs, err := f.fetchPlaylistDetail(ctx, id)
 	return songs, err
 }
 
-// ParsePlaylist 解析歌单链接并返回详情
-func (f *Fivesing) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲（拉取完整歌单后截取）
+func (f *Fivesing) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	_, songs, err := f.fetchPlaylistDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParsePlaylistContext 解析歌单链接并返回详情
+func (f *Fivesing) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	re := regexp.MustCompile(`5sing\.kugou\.com/(?:(\d+)/)?dj/([a-zA-Z0-9]+)\.html`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 3 {
-		return nil, nil, errors.New("invalid 5sing playlist link")
+		return nil, nil, model.Errorf("fivesing", model.ErrInvalidInput, "invalid 5sing playlist link")
 	}
 	playlistId := matches[2]
 	// userId (matches[1]) 可选，因为 API 验证更准确，这里只用 ID
-	return f.fetchPlaylistDetail(playlistId)
+	return f.fetchPlaylistDetail(ctx, playlistId)
 }
 
 // fetchPlaylistDetail [核心] 获取歌单详情 (API 获取元数据 + HTML 解析歌曲)
-func (f *Fivesing) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, error) {
+func (f *Fivesing) fetchPlaylistDetail(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
 	// 1. 调用 API 获取歌单元数据 (标题、封面、关键的 UserId)
 	infoURL := fmt.Sprintf("http://mobileapi.5sing.kugou.com/song/getsonglist?id=%s&songfields=ID,user", id)
-	infoBody, err := utils.Get(infoURL, utils.WithHeader("User-Agent", UserAgent))
+	infoBody, err := f.client.GetContext(ctx, infoURL, utils.WithHeader("User-Agent", UserAgent))
 	if err != nil {
 		return nil, nil, fmt.Errorf("fetch info failed: %w", err)
 	}
@@ -250,13 +422,13 @@ func (f *Fivesing) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song
 		Data json.RawMessage `json:"data"`
 	}
 	if err := json.Unmarshal(infoBody, &rawResp); err != nil {
-		return nil, nil, fmt.Errorf("fetch playlist info json error: %w", err)
+		return nil, nil, model.Errorf("fivesing", model.ErrSchemaChanged, "fetch playlist info json error: %w", err)
 	}
 
 	// 检查 Data 是否是有效对象 (以 '{' 开头)。如果是 '[]' (空数组)，说明歌单不存在或无权限
 	dataStr := strings.TrimSpace(string(rawResp.Data))
 	if len(dataStr) == 0 || dataStr[0] != '{' {
-		return nil, nil, errors.New("playlist info not found or invalid (api returned empty list)")
+		return nil, nil, model.Errorf("fivesing", model.ErrNotFound, "playlist info not found or invalid (api returned empty list)")
 	}
 
 	// 定义真实的数据结构
@@ -273,7 +445,7 @@ func (f *Fivesing) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song
 	}
 
 	if err := json.Unmarshal(rawResp.Data, &data); err != nil {
-		return nil, nil, fmt.Errorf("parse playlist data error: %w", err)
+		return nil, nil, model.Errorf("fivesing", model.ErrSchemaChanged, "parse playlist data error: %w", err)
 	}
 
 	// 验证 UserId
@@ -282,7 +454,7 @@ func (f *Fivesing) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song
 		userId = strconv.FormatInt(data.User.ID, 10)
 	}
 	if userId == "" {
-		return nil, nil, errors.New("playlist user not found or invalid id")
+		return nil, nil, model.Errorf("fivesing", model.ErrNotFound, "playlist user not found or invalid id")
 	}
 
 	// 构造 Playlist 对象
@@ -301,7 +473,7 @@ func (f *Fivesing) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song
 
 	// 2. 构造歌单页面 URL 并获取 HTML
 	pageURL := playlist.Link
-	htmlBodyBytes, err := utils.Get(pageURL,
+	htmlBodyBytes, err := f.client.GetContext(ctx, pageURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", f.cookie),
 	)
@@ -327,7 +499,7 @@ func (f *Fivesing) parseSongsFromHTML(htmlContent string) ([]model.Song, error)
 	blocks := blockRe.FindAllStringSubmatch(htmlContent, -1)
 
 	if len(blocks) == 0 {
-		return nil, errors.New("no songs found in playlist html (structure mismatch)")
+		return nil, model.Errorf("fivesing", model.ErrSchemaChanged, "no songs found in playlist html (structure mismatch)")
 	}
 
 	// B. 预编译块内提取正则
@@ -373,7 +545,7 @@ func (f *Fivesing) parseSongsFromHTML(htmlContent string) ([]model.Song, error)
 			Source: "fivesing",
 			ID:     songID,
 			Name:   name,
-			Artist:   artist,
+			Artist: artist,
 			Link:   fmt.Sprintf("http://5sing.kugou.com/%s/%s.html", kind, songID),
 			Extra: map[string]string{
 				"songid":   songID,
@@ -384,23 +556,23 @@ func (f *Fivesing) parseSongsFromHTML(htmlContent string) ([]model.Song, error)
 	return songs, nil
 }
 
-// Parse 解析链接并获取完整信息
-func (f *Fivesing) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接并获取完整信息
+func (f *Fivesing) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`5sing\.kugou\.com/(\w+)/(\d+)\.html`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 3 {
-		return nil, errors.New("invalid 5sing link")
+		return nil, model.Errorf("fivesing", model.ErrInvalidInput, "invalid 5sing link")
 	}
 	songType := matches[1]
 	songID := matches[2]
 
-	return f.fetchSongInfo(songID, songType)
+	return f.fetchSongInfo(ctx, songID, songType)
 }
 
-// GetDownloadURL 获取下载链接
-func (f *Fivesing) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (f *Fivesing) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "fivesing" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
@@ -413,15 +585,40 @@ func (f *Fivesing) GetDownloadURL(s *model.Song) (string, error) {
 	}
 
 	if songID == "" || songType == "" {
-		return "", fmt.Errorf("[fivesing] missing extra data for song %s", s.ID)
+		return "", model.Errorf("fivesing", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
 	}
 
-	return f.fetchAudioLink(songID, songType)
+	streams, err := f.fetchStreams(ctx, songID, songType)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("fivesing", model.ErrRestricted, "no valid download url found for song %s", songID)
+	}
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流
+func (f *Fivesing) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "fivesing" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	var songID, songType string
+	if s.Extra != nil {
+		songID = s.Extra["songid"]
+		songType = s.Extra["songtype"]
+	}
+	if songID == "" || songType == "" {
+		return nil, model.Errorf("fivesing", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
+	}
+	return f.fetchStreams(ctx, songID, songType)
 }
 
 // fetchSongInfo 获取完整的歌曲信息（Metadata + URL）
-func (f *Fivesing) fetchSongInfo(songID, songType string) (*model.Song, error) {
-	audioURL, err := f.fetchAudioLink(songID, songType)
+func (f *Fivesing) fetchSongInfo(ctx context.Context, songID, songType string) (*model.Song, error) {
+	streams, err := f.fetchStreams(ctx, songID, songType)
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +628,7 @@ func (f *Fivesing) fetchSongInfo(songID, songType string) (*model.Song, error) {
 	params.Set("songtype", songType)
 	metaURL := "http://mobileapi.5sing.kugou.com/song/newget?" + params.Encode()
 
-	metaBody, metaErr := utils.Get(metaURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
+	metaBody, metaErr := f.client.GetContext(ctx, metaURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
 	if metaErr != nil {
 		slog.Warn("[fivesing] metadata fetch failed, using fallback name", "songid", songID, "error", metaErr)
 	}
@@ -457,31 +654,34 @@ func (f *Fivesing) fetchSongInfo(songID, songType string) (*model.Song, error) {
 		name = fmt.Sprintf("5sing_%s_%s", songType, songID)
 	}
 
-	return &model.Song{
+	song := &model.Song{
 		Source: "fivesing",
 		ID:     songID,
 		Name:   name,
 		Artist: artist,
 		Cover:  cover,
-		URL:    audioURL,
 		Link:   fmt.Sprintf("http://5sing.kugou.com/%s/%s.html", songType, songID),
 		Extra: map[string]string{
 			"songid":   songID,
 			"songtype": songType,
 		},
-	}, nil
+	}
+	if st, ok := song.ChooseStream(streams); ok {
+		song.URL = st.URL
+	}
+	return song, nil
 }
 
-// fetchAudioLink 仅获取音频链接
-func (f *Fivesing) fetchAudioLink(songID, songType string) (string, error) {
+// fetchStreams 获取 SQ/HQ/LQ 三档音频流，每档主链接为空时使用备用链接
+func (f *Fivesing) fetchStreams(ctx context.Context, songID, songType string) ([]model.Stream, error) {
 	params := url.Values{}
 	params.Set("songid", songID)
 	params.Set("songtype", songType)
 
 	apiURL := "http://mobileapi.5sing.kugou.com/song/getSongUrl?" + params.Encode()
-	body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
+	body, err := f.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var resp struct {
@@ -489,37 +689,60 @@ func (f *Fivesing) fetchAudioLink(songID, songType string) (string, error) {
 		Data struct {
 			SQUrl       string `json:"squrl"`
 			SQUrlBackup string `json:"squrl_backup"`
+			SQExt       string `json:"sqext"`
+			SQSize      int64  `json:"sqsize"`
 			HQUrl       string `json:"hqurl"`
 			HQUrlBackup string `json:"hqurl_backup"`
+			HQExt       string `json:"hqext"`
+			HQSize      int64  `json:"hqsize"`
 			LQUrl       string `json:"lqurl"`
 			LQUrlBackup string `json:"lqurl_backup"`
+			LQExt       string `json:"lqext"`
+			LQSize      int64  `json:"lqsize"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("json parse error: %w", err)
+		return nil, model.Errorf("fivesing", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	if resp.Code != 1000 {
-		return "", fmt.Errorf("[fivesing] api returned error code %d for song %s", resp.Code, songID)
+		return nil, model.CodeError("fivesing", nil, resp.Code, "api error for song "+songID)
 	}
 
-	if url := getFirstValid(resp.Data.SQUrl, resp.Data.SQUrlBackup); url != "" {
-		return url, nil
-	}
-	if url := getFirstValid(resp.Data.HQUrl, resp.Data.HQUrlBackup); url != "" {
-		return url, nil
-	}
-	if url := getFirstValid(resp.Data.LQUrl, resp.Data.LQUrlBackup); url != "" {
-		return url, nil
+	d := resp.Data
+	var streams []model.Stream
+	add := func(label, ext string, size int64, bitrate int, urls ...string) {
+		u := getFirstValid(urls...)
+		if u == "" {
+			return
+		}
+		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+		if ext == "" {
+			ext = strings.ToLower(strings.TrimPrefix(path.Ext(strings.SplitN(u, "?", 2)[0]), "."))
+		}
+		if ext == "" {
+			ext = "mp3"
+		}
+		st := model.Stream{URL: u, Ext: ext, Size: size, Label: label, Bitrate: bitrate}
+		if st.Tier() == model.QualityLossless {
+			st.Bitrate = 0
+		}
+		streams = append(streams, st)
 	}
+	add("SQ", d.SQExt, d.SQSize, 320, d.SQUrl, d.SQUrlBackup)
+	add("HQ", d.HQExt, d.HQSize, 320, d.HQUrl, d.HQUrlBackup)
+	add("LQ", d.LQExt, d.LQSize, 128, d.LQUrl, d.LQUrlBackup)
 
-	return "", fmt.Errorf("[fivesing] no valid download url found for song %s", songID)
+	if len(streams) == 0 {
+		return nil, model.Errorf("fivesing", model.ErrRestricted, "no valid download url found for song %s", songID)
+	}
+	return streams, nil
 }
 
-func (f *Fivesing) GetLyrics(s *model.Song) (string, error) {
+func (f *Fivesing) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "fivesing" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	var songID, songType string
@@ -529,7 +752,7 @@ func (f *Fivesing) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if songID == "" {
-		return "", fmt.Errorf("[fivesing] missing extra data for song %s", s.ID)
+		return "", model.Errorf("fivesing", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
 	}
 
 	params := url.Values{}
@@ -537,7 +760,7 @@ func (f *Fivesing) GetLyrics(s *model.Song) (string, error) {
 	params.Set("songtype", songType)
 	apiURL := "http://mobileapi.5sing.kugou.com/song/newget?" + params.Encode()
 
-	body, err := utils.Get(apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
+	body, err := f.client.GetContext(ctx, apiURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", f.cookie))
 	if err != nil {
 		return "", err
 	}
@@ -548,10 +771,10 @@ func (f *Fivesing) GetLyrics(s *model.Song) (string, error) {
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("[fivesing] lyrics json parse error for song %s: %w", s.ID, err)
+		return "", model.Errorf("fivesing", model.ErrSchemaChanged, "lyrics json parse error for song %s: %w", s.ID, err)
 	}
 	if resp.Data.DynamicWords == "" {
-		return "", fmt.Errorf("[fivesing] lyrics not found for song %s", s.ID)
+		return "", model.Errorf("fivesing", model.ErrNotFound, "lyrics not found for song %s", s.ID)
 	}
 	return resp.Data.DynamicWords, nil
 }
@@ -569,4 +792,4 @@ func removeEmTags(s string) string {
 	s = strings.ReplaceAll(s, "<em class=\"keyword\">", "")
 	s = strings.ReplaceAll(s, "</em>", "")
 	return strings.TrimSpace(s)
-}
\ No newline at end of file
+}
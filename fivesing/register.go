@@ -0,0 +1,12 @@
+package fivesing
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "fivesing",
+		DisplayName: "5sing",
+		Canary:      registry.Canary{Keyword: "古风"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
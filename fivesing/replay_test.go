@@ -0,0 +1,16 @@
+package fivesing
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Fivesing]{
+		{Name: "search", Call: func(_ *testing.T, f *Fivesing) (any, error) { return f.SearchPage("古风", 1, 0) }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Fivesing { return New("", opts...) }
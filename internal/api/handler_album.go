@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/album/search?source=X&keyword=Y
+func (s *Server) handleAlbumSearch(c *gin.Context) {
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	searcher := p.AlbumSearcher()
+	if searcher == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("album search not supported for %s", source))
+		return
+	}
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		writeError(c, http.StatusBadRequest, "missing keyword parameter")
+		return
+	}
+	albums, err := searcher.SearchAlbumContext(c.Request.Context(), keyword)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeOK(c, albums)
+}
+
+// GET /api/album/songs?source=X&id=Y
+// Returns the album metadata with its tracks (track/disc numbers included).
+func (s *Server) handleAlbumSongs(c *gin.Context) {
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	albums := p.AlbumSource()
+	if albums == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("album not supported for %s", source))
+		return
+	}
+	id := c.Query("id")
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "missing id parameter")
+		return
+	}
+	album, err := albums.GetAlbumContext(c.Request.Context(), id)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeOK(c, album)
+}
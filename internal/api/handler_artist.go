@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+// GET /api/artist/search?source=X&keyword=Y
+func (s *Server) handleArtistSearch(c *gin.Context) {
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	searcher := p.ArtistSearcher()
+	if searcher == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("artist search not supported for %s", source))
+		return
+	}
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		writeError(c, http.StatusBadRequest, "missing keyword parameter")
+		return
+	}
+	artists, err := searcher.SearchArtistContext(c.Request.Context(), keyword)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeOK(c, artists)
+}
+
+// GET /api/artist/songs?source=X&id=Y
+func (s *Server) handleArtistSongs(c *gin.Context) {
+	artist, id, ok := s.artistSource(c)
+	if !ok {
+		return
+	}
+	songs, err := artist.GetArtistTopSongsContext(c.Request.Context(), id)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeOK(c, songs)
+}
+
+// GET /api/artist/albums?source=X&id=Y
+func (s *Server) handleArtistAlbums(c *gin.Context) {
+	artist, id, ok := s.artistSource(c)
+	if !ok {
+		return
+	}
+	albums, err := artist.GetArtistAlbumsContext(c.Request.Context(), id)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeOK(c, albums)
+}
+
+// artistSource resolves the provider's ArtistSource and the id parameter,
+// writing the error response itself when either is missing.
+func (s *Server) artistSource(c *gin.Context) (registry.ArtistSource, string, bool) {
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return nil, "", false
+	}
+	artist := p.ArtistSource()
+	if artist == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("artist not supported for %s", source))
+		return nil, "", false
+	}
+	id := c.Query("id")
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "missing id parameter")
+		return nil, "", false
+	}
+	return artist, id, true
+}
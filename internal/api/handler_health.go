@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/health"
+	"github.com/guohuiyuan/music-lib/internal/store"
+)
+
+// GET /api/providers/health[?source=X]
+// Returns the latest canary results per provider: an overall status (the
+// worst of its checks) and each check's status, latency, last success and
+// failure reason. Providers that have not been probed yet report "unknown".
+func (s *Server) handleProviderHealth(c *gin.Context) {
+	if s.db == nil {
+		writeError(c, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+	source := c.Query("source")
+	if source != "" {
+		if _, ok := s.providers.Get(source); !ok {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown source: %q", source))
+			return
+		}
+	}
+	records, err := store.ListHealthRecords(s.db)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	bySource := make(map[string][]store.HealthRecord)
+	for _, r := range records {
+		bySource[r.Source] = append(bySource[r.Source], r)
+	}
+
+	type providerHealth struct {
+		Source    string               `json:"source"`
+		Status    string               `json:"status"`
+		CheckedAt *time.Time           `json:"checked_at,omitempty"`
+		Checks    []store.HealthRecord `json:"checks"`
+	}
+	list := []providerHealth{}
+	for _, p := range s.providers.All() {
+		if source != "" && p.Name != source {
+			continue
+		}
+		checks := bySource[p.Name]
+		if checks == nil {
+			checks = []store.HealthRecord{}
+		}
+		ph := providerHealth{Source: p.Name, Checks: checks}
+		statuses := make([]string, len(checks))
+		for i, ch := range checks {
+			statuses[i] = ch.Status
+			if ph.CheckedAt == nil || ch.CheckedAt.After(*ph.CheckedAt) {
+				checked := ch.CheckedAt
+				ph.CheckedAt = &checked
+			}
+		}
+		ph.Status = health.Worst(statuses...)
+		list = append(list, ph)
+	}
+	writeOK(c, list)
+}
@@ -6,8 +6,10 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/cache"
 	"github.com/guohuiyuan/music-lib/internal/monitor"
 	"github.com/guohuiyuan/music-lib/internal/store"
+	"github.com/guohuiyuan/music-lib/registry"
 )
 
 // monScheduler is set by the router init to allow handlers to trigger runs.
@@ -57,21 +59,21 @@ func (s *Server) handleResolvePlaylist(c *gin.Context) {
 	// Try providers that have ParsePlaylist. If we guessed a platform, try that first.
 	type tryResult struct {
 		platform string
-		pf       ProviderFuncs
+		parser   registry.PlaylistParser
 	}
 	var candidates []tryResult
 	if guessedPlatform != "" {
-		if pf, ok := s.providers[guessedPlatform]; ok && pf.ParsePlaylist != nil {
-			candidates = append(candidates, tryResult{guessedPlatform, pf})
+		if p, ok := s.providers.Get(guessedPlatform); ok && p.PlaylistParser() != nil {
+			candidates = append(candidates, tryResult{guessedPlatform, p.PlaylistParser()})
 		}
 	}
 	// Also add remaining providers as fallback (in case the guess was wrong).
-	for name, pf := range s.providers {
-		if name == guessedPlatform {
+	for _, p := range s.providers.All() {
+		if p.Name == guessedPlatform {
 			continue
 		}
-		if pf.ParsePlaylist != nil {
-			candidates = append(candidates, tryResult{name, pf})
+		if parser := p.PlaylistParser(); parser != nil {
+			candidates = append(candidates, tryResult{p.Name, parser})
 		}
 	}
 
@@ -81,7 +83,7 @@ func (s *Server) handleResolvePlaylist(c *gin.Context) {
 	}
 
 	for _, cand := range candidates {
-		playlist, songs, err := cand.pf.ParsePlaylist(body.URL)
+		playlist, songs, err := cand.parser.ParsePlaylistContext(c.Request.Context(), body.URL)
 		if err != nil {
 			continue
 		}
@@ -101,20 +103,23 @@ func (s *Server) handleResolvePlaylist(c *gin.Context) {
 
 // GET /api/charts?platform=X
 func (s *Server) handleGetCharts(c *gin.Context) {
-	pf, _, ok := s.getProvider(c)
+	p, _, ok := s.getProvider(c)
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing or invalid source"})
 		return
 	}
-	if pf.GetCharts == nil {
+	chartSource := p.ChartSource()
+	if chartSource == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "platform does not support charts"})
 		return
 	}
-	charts, err := pf.GetCharts()
+	key := cache.Key(cache.Charts, p.Name)
+	charts, res, err := cache.Fetch(cacheContext(c), s.cache, cache.Charts, key, chartSource.GetChartsContext)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	writeCacheHeaders(c, res)
 	c.JSON(http.StatusOK, gin.H{"code": 0, "data": charts})
 }
 
@@ -164,7 +169,7 @@ func (s *Server) handleCreateMonitor(c *gin.Context) {
 	}
 
 	// Validate platform exists.
-	if _, ok := s.providers[body.Platform]; !ok {
+	if _, ok := s.providers.Get(body.Platform); !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown platform"})
 		return
 	}
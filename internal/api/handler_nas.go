@@ -1,22 +1,25 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/guohuiyuan/music-lib/download"
 	"github.com/guohuiyuan/music-lib/internal/store"
 	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
 )
 
-// proxyClient has a generous timeout for streaming audio files to the browser.
-var proxyClient = &http.Client{Timeout: 10 * time.Minute}
+// proxyTimeout is the generous timeout for streaming audio files to the browser.
+const proxyTimeout = 10 * time.Minute
 
 // GET /api/nas/status
 func (s *Server) handleNASStatus(c *gin.Context) {
@@ -27,19 +30,74 @@ func (s *Server) handleNASStatus(c *gin.Context) {
 	if enabled {
 		data["music_dir"] = s.dlMgr.MusicDir()
 		data["concurrency"] = s.dlMgr.Concurrency()
+		data["queue_paused"] = s.dlMgr.Paused()
 	}
 	writeOK(c, data)
 }
 
+// nasSettings describes the download settings that can be read and changed
+// at runtime. current_limit is the limit in force now (bytes per second,
+// 0 unlimited, -1 paused), after applying the bandwidth windows;
+// queue_paused is set by POST /api/nas/queue.
+func (s *Server) nasSettings() map[string]any {
+	bw := s.dlMgr.Bandwidth()
+	limit := bw.Limit()
+	return map[string]any{
+		"concurrency":   s.dlMgr.Concurrency(),
+		"bandwidth":     bw.Schedule(),
+		"current_limit": limit,
+		"paused":        limit == download.BandwidthPaused,
+		"queue_paused":  s.dlMgr.Paused(),
+	}
+}
+
+// GET /api/nas/settings
+func (s *Server) handleGetNASSettings(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusServiceUnavailable, "NAS download not configured (MUSIC_DIR not set)")
+		return
+	}
+	writeOK(c, s.nasSettings())
+}
+
+// PUT /api/nas/settings
+// Changes take effect immediately for running downloads and last until the
+// server restarts, which goes back to DOWNLOAD_BANDWIDTH(_WINDOWS).
+//
+// Body:
+//
+//	{ "bandwidth": { "limit": 512000, "windows": [{"start": "01:00", "end": "07:00", "limit": 0}] } }
+func (s *Server) handleUpdateNASSettings(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusServiceUnavailable, "NAS download not configured (MUSIC_DIR not set)")
+		return
+	}
+	var body struct {
+		Bandwidth *download.BandwidthSchedule `json:"bandwidth"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if body.Bandwidth != nil {
+		if err := s.dlMgr.Bandwidth().SetSchedule(*body.Bandwidth); err != nil {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid bandwidth: %v", err))
+			return
+		}
+		slog.Info("nas.settings", "bandwidth_limit", body.Bandwidth.Limit, "windows", len(body.Bandwidth.Windows))
+	}
+	writeOK(c, s.nasSettings())
+}
+
 // POST /api/download/file?source=X  body: Song JSON
 // Streams audio from source through server to browser.
 func (s *Server) handleProxyDownload(c *gin.Context) {
-	pf, source, ok := s.getProvider(c)
+	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if pf.GetDownloadURL == nil {
+	if p.Downloader() == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("download not supported for %s", source))
 		return
 	}
@@ -57,39 +115,72 @@ func (s *Server) handleProxyDownload(c *gin.Context) {
 		song.Extra["quality"] = quality
 	}
 
-	audioURL, err := pf.GetDownloadURL(&song)
+	stream, err := p.ResolveStream(c.Request.Context(), &song)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, "get download url: "+err.Error())
+		writeProviderError(c, fmt.Errorf("get download url: %w", err))
 		return
 	}
 
-	resp, err := proxyClient.Get(audioURL)
+	audio, err := openAudio(c.Request.Context(), p, stream)
 	if err != nil {
-		writeError(c, http.StatusBadGateway, "fetch audio: "+err.Error())
+		writeError(c, http.StatusBadGateway, err.Error())
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		writeError(c, http.StatusBadGateway, fmt.Sprintf("remote returned status %d", resp.StatusCode))
-		return
+	defer audio.Close()
+	if audio.Ext != "" {
+		song.Ext = audio.Ext
 	}
 
 	filename := song.Filename()
 	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
-	if ct := resp.Header.Get("Content-Type"); ct != "" {
-		c.Header("Content-Type", ct)
+	if audio.ContentType != "" {
+		c.Header("Content-Type", audio.ContentType)
 	} else {
 		c.Header("Content-Type", "application/octet-stream")
 	}
-	if cl := resp.Header.Get("Content-Length"); cl != "" {
-		c.Header("Content-Length", cl)
+	if audio.Size >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(audio.Size, 10))
 	}
-	if _, err := io.Copy(c.Writer, resp.Body); err != nil {
+	if _, err := io.Copy(c.Writer, audio); err != nil {
 		slog.Warn("proxy download stream interrupted", "song", song.Display(), "error", err)
 	}
 }
 
+// openAudio opens stream through the provider's StreamOpener when it has
+// one, so encrypted audio reaches the browser decrypted, and with a plain
+// GET otherwise.
+func openAudio(ctx context.Context, p *registry.Provider, stream model.Stream) (*model.AudioStream, error) {
+	if opener := p.StreamOpener(); opener != nil {
+		audio, err := opener.OpenStreamContext(ctx, stream)
+		if err != nil {
+			return nil, fmt.Errorf("open audio: %w", err)
+		}
+		return audio, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, stream.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	for k, v := range stream.Headers {
+		req.Header.Set(k, v)
+	}
+	// The provider's client carries its proxy, so region-locked CDNs stay reachable.
+	resp, err := p.Client().HTTPClientWithTimeout(proxyTimeout).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch audio: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote returned status %d", resp.StatusCode)
+	}
+	return &model.AudioStream{
+		ReadCloser:  resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}, nil
+}
+
 // POST /api/nas/download?source=X  body: Song JSON
 func (s *Server) handleNASDownload(c *gin.Context) {
 	if s.dlMgr == nil || s.dlMgr.MusicDir() == "" {
@@ -97,12 +188,12 @@ func (s *Server) handleNASDownload(c *gin.Context) {
 		return
 	}
 
-	pf, source, ok := s.getProvider(c)
+	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if pf.GetDownloadURL == nil {
+	if p.Downloader() == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("download not supported for %s", source))
 		return
 	}
@@ -120,7 +211,7 @@ func (s *Server) handleNASDownload(c *gin.Context) {
 		song.Extra["quality"] = quality
 	}
 
-	taskID := s.dlMgr.Enqueue(song, source, pf.GetDownloadURL, pf.GetLyrics)
+	taskID := s.dlMgr.Enqueue(song, source)
 	writeOK(c, map[string]string{"task_id": taskID})
 }
 
@@ -133,12 +224,12 @@ func (s *Server) handleNASBatchDownload(c *gin.Context) {
 		return
 	}
 
-	pf, source, ok := s.getProvider(c)
+	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if pf.GetDownloadURL == nil {
+	if p.Downloader() == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("download not supported for %s", source))
 		return
 	}
@@ -172,18 +263,213 @@ func (s *Server) handleNASBatchDownload(c *gin.Context) {
 		}
 	}
 
-	batchID := s.dlMgr.EnqueueBatch(body.Songs, batchName, source, pf.GetDownloadURL, pf.GetLyrics)
+	// With preflight=true every song is probed before queuing. Songs that
+	// are dead, VIP-only or previews are left out of the batch; the probe
+	// results and a count per status come back with the batch.
+	songs := body.Songs
+	var results []registry.ProbeResult
+	if c.Query("preflight") == "true" {
+		if len(songs) > maxProbeSongs {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("too many songs to preflight: %d (max %d)", len(songs), maxProbeSongs))
+			return
+		}
+		for i := range songs {
+			songs[i].Source = source
+		}
+		results = s.providers.ProbeSongs(c.Request.Context(), songs, probeConcurrency)
+		usable := make([]model.Song, 0, len(songs))
+		for i, r := range results {
+			if !r.Unusable() {
+				usable = append(usable, songs[i])
+			}
+		}
+		songs = usable
+		if len(songs) == 0 {
+			writeOK(c, map[string]any{
+				"batch_id":   "",
+				"task_count": 0,
+				"results":    results,
+				"summary":    probeSummary(results),
+			})
+			return
+		}
+	}
+
+	batchID := s.dlMgr.EnqueueBatch(songs, batchName, source)
 
 	// Persist the batch record to DB.
 	if s.db != nil {
-		if err := store.CreateBatch(s.db, batchID, source, batchName, len(body.Songs)); err != nil {
+		if err := store.CreateBatch(s.db, batchID, source, batchName, len(songs)); err != nil {
+			slog.Warn("create batch record", "batch_id", batchID, "error", err)
+		}
+	}
+
+	data := map[string]any{
+		"batch_id":   batchID,
+		"task_count": len(songs),
+	}
+	if results != nil {
+		data["results"] = results
+		data["summary"] = probeSummary(results)
+	}
+	writeOK(c, data)
+}
+
+// POST /api/nas/download/album?source=X&id=Y[&quality=Z]
+// Fetches the album server-side and enqueues every track as one batch
+// named "Artist - Album".
+func (s *Server) handleNASAlbumDownload(c *gin.Context) {
+	if s.dlMgr == nil || s.dlMgr.MusicDir() == "" {
+		writeError(c, http.StatusServiceUnavailable, "NAS download not configured (MUSIC_DIR not set)")
+		return
+	}
+
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	albums := p.AlbumSource()
+	if p.Downloader() == nil || albums == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("album download not supported for %s", source))
+		return
+	}
+	id := c.Query("id")
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "missing id parameter")
+		return
+	}
+
+	album, err := albums.GetAlbumContext(c.Request.Context(), id)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	songs := album.Songs()
+	if len(songs) == 0 {
+		writeError(c, http.StatusNotFound, "album has no downloadable tracks")
+		return
+	}
+
+	if quality := c.Query("quality"); quality != "" {
+		for i := range songs {
+			if songs[i].Extra == nil {
+				songs[i].Extra = map[string]string{}
+			}
+			songs[i].Extra["quality"] = quality
+		}
+	}
+
+	batchName := album.Name
+	if artist := album.ArtistString(); artist != "" {
+		batchName = artist + " - " + album.Name
+	}
+	batchID := s.dlMgr.EnqueueBatch(songs, batchName, source)
+
+	if s.db != nil {
+		if err := store.CreateBatch(s.db, batchID, source, batchName, len(songs)); err != nil {
 			slog.Warn("create batch record", "batch_id", batchID, "error", err)
 		}
 	}
 
 	writeOK(c, map[string]any{
 		"batch_id":   batchID,
-		"task_count": len(body.Songs),
+		"task_count": len(songs),
+		"album":      album.Name,
+	})
+}
+
+// POST /api/nas/download/artist?source=X&id=Y[&name=Z&quality=Q]
+// Downloads an artist's discography: every album from the artist's album
+// list is fetched and its tracks are enqueued as a single batch. Songs that
+// appear on several albums are only downloaded once. Albums that fail to
+// load are skipped and reported in "failed_albums".
+func (s *Server) handleNASArtistDownload(c *gin.Context) {
+	if s.dlMgr == nil || s.dlMgr.MusicDir() == "" {
+		writeError(c, http.StatusServiceUnavailable, "NAS download not configured (MUSIC_DIR not set)")
+		return
+	}
+
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	artists, albumSrc := p.ArtistSource(), p.AlbumSource()
+	if p.Downloader() == nil || artists == nil || albumSrc == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("discography download not supported for %s", source))
+		return
+	}
+	id := c.Query("id")
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "missing id parameter")
+		return
+	}
+
+	ctx := c.Request.Context()
+	albums, err := artists.GetArtistAlbumsContext(ctx, id)
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+
+	artistName := c.Query("name")
+	seen := make(map[string]bool)
+	var songs []model.Song
+	var failed []string
+	for _, a := range albums {
+		album, err := albumSrc.GetAlbumContext(ctx, a.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				writeError(c, http.StatusRequestTimeout, ctx.Err().Error())
+				return
+			}
+			slog.Warn("discography: fetch album", "source", source, "album_id", a.ID, "error", err)
+			failed = append(failed, a.Name)
+			continue
+		}
+		if artistName == "" && len(album.Artists) > 0 {
+			artistName = album.Artists[0]
+		}
+		for _, song := range album.Songs() {
+			if seen[song.ID] {
+				continue
+			}
+			seen[song.ID] = true
+			songs = append(songs, song)
+		}
+	}
+	if len(songs) == 0 {
+		writeError(c, http.StatusNotFound, "artist has no downloadable tracks")
+		return
+	}
+
+	if quality := c.Query("quality"); quality != "" {
+		for i := range songs {
+			if songs[i].Extra == nil {
+				songs[i].Extra = map[string]string{}
+			}
+			songs[i].Extra["quality"] = quality
+		}
+	}
+
+	if artistName == "" {
+		artistName = id
+	}
+	batchName := artistName + " - 全部专辑"
+	batchID := s.dlMgr.EnqueueBatch(songs, batchName, source)
+
+	if s.db != nil {
+		if err := store.CreateBatch(s.db, batchID, source, batchName, len(songs)); err != nil {
+			slog.Warn("create batch record", "batch_id", batchID, "error", err)
+		}
+	}
+
+	writeOK(c, map[string]any{
+		"batch_id":      batchID,
+		"task_count":    len(songs),
+		"album_count":   len(albums) - len(failed),
+		"failed_albums": failed,
 	})
 }
 
@@ -236,6 +522,168 @@ func (s *Server) handleListBatches(c *gin.Context) {
 	writeOK(c, s.dlMgr.ListBatches())
 }
 
+// POST /api/nas/queue
+// Pauses or resumes the whole download queue. Pausing interrupts running
+// tasks and queues them again; resuming continues them from their partial
+// files.
+//
+// Body:
+//
+//	{ "action": "pause" | "resume" }
+func (s *Server) handleNASQueue(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusServiceUnavailable, "NAS download not configured (MUSIC_DIR not set)")
+		return
+	}
+	var body struct {
+		Action string `json:"action"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	switch body.Action {
+	case "pause":
+		s.dlMgr.Pause()
+	case "resume":
+		s.dlMgr.Resume()
+	default:
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown action %q", body.Action))
+		return
+	}
+	slog.Info("nas.queue", "action", body.Action)
+	writeOK(c, map[string]any{"paused": s.dlMgr.Paused()})
+}
+
+// POST /api/nas/task/:id
+// Pauses, resumes or reprioritizes a task. Higher priorities start first;
+// single downloads are queued at 10, batches at 0 and chart monitors at -10.
+//
+// Body:
+//
+//	{ "action": "pause" | "resume" | "priority", "priority": 20 }
+func (s *Server) handleTaskAction(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusNotFound, "task not found")
+		return
+	}
+	id := c.Param("id")
+	var body struct {
+		Action   string             `json:"action"`
+		Priority *download.Priority `json:"priority"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	var err error
+	switch body.Action {
+	case "pause":
+		err = s.dlMgr.PauseTask(id)
+	case "resume":
+		err = s.dlMgr.ResumeTask(id)
+	case "priority":
+		if body.Priority == nil {
+			writeError(c, http.StatusBadRequest, "missing priority")
+			return
+		}
+		err = s.dlMgr.SetTaskPriority(id, *body.Priority)
+	default:
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown action %q", body.Action))
+		return
+	}
+	if err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	task, _ := s.dlMgr.GetTask(id)
+	writeOK(c, task)
+}
+
+// DELETE /api/nas/task/:id
+// Cancels a task: a queued task is dropped, a running one has its transfer
+// aborted. Either way its partial file is deleted.
+func (s *Server) handleCancelTask(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusNotFound, "task not found")
+		return
+	}
+	id := c.Param("id")
+	if err := s.dlMgr.CancelTask(id); err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	task, _ := s.dlMgr.GetTask(id)
+	writeOK(c, task)
+}
+
+// POST /api/nas/batch/:id
+// Pauses or resumes every unfinished task of a batch.
+//
+// Body:
+//
+//	{ "action": "pause" | "resume" }
+func (s *Server) handleBatchAction(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusNotFound, "batch not found")
+		return
+	}
+	id := c.Param("id")
+	var body struct {
+		Action string `json:"action"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	var (
+		n   int
+		err error
+	)
+	switch body.Action {
+	case "pause":
+		n, err = s.dlMgr.PauseBatch(id)
+	case "resume":
+		n, err = s.dlMgr.ResumeBatch(id)
+	default:
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown action %q", body.Action))
+		return
+	}
+	if err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	writeOK(c, map[string]any{"batch_id": id, "affected": n})
+}
+
+// DELETE /api/nas/batch/:id
+// Cancels every unfinished task of a batch (see DELETE /api/nas/task/:id).
+func (s *Server) handleCancelBatch(c *gin.Context) {
+	if s.dlMgr == nil {
+		writeError(c, http.StatusNotFound, "batch not found")
+		return
+	}
+	id := c.Param("id")
+	n, err := s.dlMgr.CancelBatch(id)
+	if err != nil {
+		writeQueueError(c, err)
+		return
+	}
+	writeOK(c, map[string]any{"batch_id": id, "affected": n})
+}
+
+// writeQueueError maps a download queue error to its HTTP status.
+func writeQueueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, download.ErrTaskNotFound), errors.Is(err, download.ErrBatchNotFound):
+		writeError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, download.ErrTaskFinished):
+		writeError(c, http.StatusConflict, err.Error())
+	default:
+		writeError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
 // POST /api/nas/download/upgrade
 // Re-queues completed tasks to attempt a higher-quality download.
 //
@@ -1,20 +1,25 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/model"
 )
 
-// GET /api/playlist/search?source=X&keyword=Y
+// GET /api/playlist/search?source=X&keyword=Y[&page=N&limit=M]
 func (s *Server) handlePlaylistSearch(c *gin.Context) {
 	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.SearchPlaylist == nil {
+	searcher := p.PlaylistSearcher()
+	if searcher == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("playlist search not supported for %s", source))
 		return
 	}
@@ -23,22 +28,47 @@ func (s *Server) handlePlaylistSearch(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, "missing keyword parameter")
 		return
 	}
-	playlists, err := p.SearchPlaylist(keyword)
+	page, limit, err := parsePaging(c)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeError(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	ctx := cacheContext(c)
+	if paged := p.PagedPlaylistSearcher(); paged != nil {
+		key := cache.Key(cache.Search, "playlist", source, keyword, strconv.Itoa(page), strconv.Itoa(limit))
+		result, res, err := cache.Fetch(ctx, s.cache, cache.Search, key, func(ctx context.Context) (*model.Page[model.Playlist], error) {
+			return paged.SearchPlaylistPageContext(ctx, keyword, page, limit)
+		})
+		if err != nil {
+			writeProviderError(c, err)
+			return
+		}
+		writeCacheHeaders(c, res)
+		writePage(c, result)
+		return
+	}
+	key := cache.Key(cache.Search, "playlist", source, keyword)
+	playlists, res, err := cache.Fetch(ctx, s.cache, cache.Search, key, func(ctx context.Context) ([]model.Playlist, error) {
+		return searcher.SearchPlaylistContext(ctx, keyword)
+	})
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	writeCacheHeaders(c, res)
 	writeOK(c, playlists)
 }
 
-// GET /api/playlist/songs?source=X&id=Y
+// GET /api/playlist/songs?source=X&id=Y[&page=N&limit=M]
+// Without page/limit the whole playlist is returned as before.
 func (s *Server) handlePlaylistSongs(c *gin.Context) {
 	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.GetPlaylistSongs == nil {
+	lister := p.PlaylistSource()
+	if lister == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("playlist songs not supported for %s", source))
 		return
 	}
@@ -47,11 +77,34 @@ func (s *Server) handlePlaylistSongs(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, "missing id parameter")
 		return
 	}
-	songs, err := p.GetPlaylistSongs(id)
+	page, limit, err := parsePaging(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := cacheContext(c)
+	if paged := p.PagedPlaylistSource(); paged != nil && (page > 0 || limit > 0) {
+		key := cache.Key(cache.Playlist, source, id, strconv.Itoa(page), strconv.Itoa(limit))
+		result, res, err := cache.Fetch(ctx, s.cache, cache.Playlist, key, func(ctx context.Context) (*model.Page[model.Song], error) {
+			return paged.GetPlaylistSongsPageContext(ctx, id, page, limit)
+		})
+		if err != nil {
+			writeProviderError(c, err)
+			return
+		}
+		writeCacheHeaders(c, res)
+		writePage(c, result)
+		return
+	}
+	key := cache.Key(cache.Playlist, source, id)
+	songs, res, err := cache.Fetch(ctx, s.cache, cache.Playlist, key, func(ctx context.Context) ([]model.Song, error) {
+		return lister.GetPlaylistSongsContext(ctx, id)
+	})
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
+	writeCacheHeaders(c, res)
 	writeOK(c, songs)
 }
 
@@ -62,7 +115,8 @@ func (s *Server) handlePlaylistParse(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.ParsePlaylist == nil {
+	parser := p.PlaylistParser()
+	if parser == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("playlist parse not supported for %s", source))
 		return
 	}
@@ -71,9 +125,9 @@ func (s *Server) handlePlaylistParse(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, "missing link parameter")
 		return
 	}
-	playlist, songs, err := p.ParsePlaylist(link)
+	playlist, songs, err := parser.ParsePlaylistContext(c.Request.Context(), link)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
 	writeOK(c, map[string]any{
@@ -89,13 +143,14 @@ func (s *Server) handlePlaylistRecommended(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.GetRecommended == nil {
+	recommender := p.PlaylistRecommender()
+	if recommender == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("playlist recommended not supported for %s", source))
 		return
 	}
-	playlists, err := p.GetRecommended()
+	playlists, err := recommender.GetRecommendedPlaylistsContext(c.Request.Context())
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
 	writeOK(c, playlists)
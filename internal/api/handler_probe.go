@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+const (
+	// maxProbeSongs caps one /api/probe request.
+	maxProbeSongs = 500
+	// probeConcurrency is how many songs are probed at once.
+	probeConcurrency = 4
+)
+
+// POST /api/probe[?source=X][&quality=Y]  body: {"songs":[Song JSON...]}
+// Resolves each song's stream and checks it with a HEAD/Range request,
+// without downloading. Songs come back with is_invalid, size and bitrate
+// filled in, next to one result per song (ok / dead / vip_only / preview /
+// unavailable) and a count per status. source applies to songs that do not
+// carry their own.
+func (s *Server) handleProbe(c *gin.Context) {
+	source := c.Query("source")
+	if source != "" {
+		if _, ok := s.providers.Get(source); !ok {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown source: %q", source))
+			return
+		}
+	}
+	var body struct {
+		Songs []model.Song `json:"songs"`
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(body.Songs) == 0 {
+		writeError(c, http.StatusBadRequest, "no songs provided")
+		return
+	}
+	if len(body.Songs) > maxProbeSongs {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("too many songs: %d (max %d)", len(body.Songs), maxProbeSongs))
+		return
+	}
+	quality := c.Query("quality")
+	for i := range body.Songs {
+		if body.Songs[i].Source == "" {
+			body.Songs[i].Source = source
+		}
+		if quality != "" {
+			if body.Songs[i].Extra == nil {
+				body.Songs[i].Extra = map[string]string{}
+			}
+			body.Songs[i].Extra["quality"] = quality
+		}
+	}
+
+	results := s.providers.ProbeSongs(c.Request.Context(), body.Songs, probeConcurrency)
+	writeOK(c, map[string]any{
+		"songs":   body.Songs,
+		"results": results,
+		"summary": probeSummary(results),
+	})
+}
+
+// probeSummary counts results per status.
+func probeSummary(results []registry.ProbeResult) map[string]int {
+	summary := map[string]int{}
+	for _, r := range results {
+		summary[r.Status]++
+	}
+	return summary
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/resolve"
+)
+
+const (
+	// maxResolveLinks caps the links resolved in one request.
+	maxResolveLinks = 50
+	// resolveConcurrency is how many links are resolved at once.
+	resolveConcurrency = 4
+)
+
+// GET  /api/resolve?text=...  (or repeated ?link=...)
+// POST /api/resolve  body: {"text": "分享...", "links": ["https://..."]}
+// Finds every URL in the share text, follows short links, works out which
+// platform and what kind of content (song / playlist / album / chart) each
+// points at and parses it with that platform. No source parameter is needed.
+// Each link gets its own result; a link that fails does not fail the request.
+func (s *Server) handleResolve(c *gin.Context) {
+	text := c.Query("text")
+	links := c.QueryArray("link")
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			Text  string   `json:"text"`
+			Links []string `json:"links"`
+		}
+		if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+			writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		text, links = body.Text, body.Links
+	}
+
+	var all []string
+	seen := map[string]bool{}
+	for _, l := range append(links, resolve.ExtractURLs(text)...) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			all = append(all, l)
+		}
+	}
+	if len(all) == 0 {
+		writeError(c, http.StatusBadRequest, "no links found")
+		return
+	}
+	if len(all) > maxResolveLinks {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("too many links: %d (max %d)", len(all), maxResolveLinks))
+		return
+	}
+
+	items := s.resolver.ResolveLinks(c.Request.Context(), all, resolveConcurrency)
+	results := make([]resolveResult, len(items))
+	for i, it := range items {
+		results[i] = resolveResult{Item: it, Status: http.StatusOK}
+		if it.Err != nil {
+			results[i].Status = providerErrorStatus(it.Err)
+		}
+	}
+	writeOK(c, results)
+}
+
+// resolveResult is one link's outcome with the HTTP status it would have
+// had as a single request.
+type resolveResult struct {
+	resolve.Item
+	Status int `json:"status"`
+}
@@ -1,61 +1,68 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/internal/search"
 	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
 )
 
+// maxSearchAllTimeout caps the per-provider timeout a client may request.
+const maxSearchAllTimeout = 30 * time.Second
+
 // GET /health
 func (s *Server) handleHealth(c *gin.Context) {
 	writeOK(c, map[string]string{"status": "ok"})
 }
 
 // GET /providers
+// Returns each registered provider's metadata (display name, quality tiers,
+// login support) together with its capability flags.
 func (s *Server) handleProviders(c *gin.Context) {
 	type providerInfo struct {
-		Name                string `json:"name"`
-		Search              bool   `json:"search"`
-		Download            bool   `json:"download"`
-		Lyrics              bool   `json:"lyrics"`
-		Parse               bool   `json:"parse"`
-		PlaylistSearch      bool   `json:"playlist_search"`
-		PlaylistSongs       bool   `json:"playlist_songs"`
-		PlaylistParse       bool   `json:"playlist_parse"`
-		PlaylistRecommended bool   `json:"playlist_recommended"`
-		Charts              bool   `json:"charts"`
-		ChartSongs          bool   `json:"chart_songs"`
-	}
-	var list []providerInfo
-	for name, p := range s.providers {
-		list = append(list, providerInfo{
-			Name:                name,
-			Search:              p.Search != nil,
-			Download:            p.GetDownloadURL != nil,
-			Lyrics:              p.GetLyrics != nil,
-			Parse:               p.Parse != nil,
-			PlaylistSearch:      p.SearchPlaylist != nil,
-			PlaylistSongs:       p.GetPlaylistSongs != nil,
-			PlaylistParse:       p.ParsePlaylist != nil,
-			PlaylistRecommended: p.GetRecommended != nil,
-			Charts:              p.GetCharts != nil,
-			ChartSongs:          p.GetChartSongs != nil,
-		})
+		registry.Info
+		registry.Capabilities
+		ChartSongs bool                `json:"chart_songs"` // kept for older clients; same as charts
+		Breaker    *utils.BreakerState `json:"breaker,omitempty"`
+	}
+	all := s.providers.All()
+	list := make([]providerInfo, 0, len(all))
+	for _, p := range all {
+		caps := p.Capabilities()
+		info := providerInfo{
+			Info:         p.Info,
+			Capabilities: caps,
+			ChartSongs:   caps.Charts,
+		}
+		if client := p.Client(); client != nil {
+			st := client.Breaker()
+			info.Breaker = &st
+		}
+		list = append(list, info)
 	}
 	writeOK(c, list)
 }
 
-// GET /api/search?source=X&keyword=Y
+// GET /api/search?source=X&keyword=Y[&page=N&limit=M]
 func (s *Server) handleSearch(c *gin.Context) {
 	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.Search == nil {
+	searcher := p.Searcher()
+	if searcher == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("search not supported for %s", source))
 		return
 	}
@@ -64,36 +71,249 @@ func (s *Server) handleSearch(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, "missing keyword parameter")
 		return
 	}
-	songs, err := p.Search(keyword)
+	page, limit, err := parsePaging(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := cacheContext(c)
+	if paged := p.PagedSearcher(); paged != nil {
+		key := cache.Key(cache.Search, "song", source, keyword, strconv.Itoa(page), strconv.Itoa(limit))
+		result, res, err := cache.Fetch(ctx, s.cache, cache.Search, key, func(ctx context.Context) (*model.Page[model.Song], error) {
+			return paged.SearchPageContext(ctx, keyword, page, limit)
+		})
+		if err != nil {
+			writeProviderError(c, err)
+			return
+		}
+		writeCacheHeaders(c, res)
+		writePage(c, result)
+		return
+	}
+	key := cache.Key(cache.Search, "song", source, keyword)
+	songs, res, err := cache.Fetch(ctx, s.cache, cache.Search, key, func(ctx context.Context) ([]model.Song, error) {
+		return searcher.SearchContext(ctx, keyword)
+	})
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
+	writeCacheHeaders(c, res)
 	writeOK(c, songs)
 }
 
-// POST /api/lyrics?source=X  body: Song JSON
+// GET /api/search/all?keyword=Y[&sources=a,b][&timeout=S][&limit=N]
+// Searches every provider concurrently and returns one merged, ranked list.
+// timeout is the per-provider deadline in seconds; providers that fail or time
+// out are reported under "errors" without failing the request.
+func (s *Server) handleSearchAll(c *gin.Context) {
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		writeError(c, http.StatusBadRequest, "missing keyword parameter")
+		return
+	}
+	opts := search.Options{}
+	if v := c.Query("sources"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Sources = append(opts.Sources, name)
+			}
+		}
+	}
+	if v := c.Query("timeout"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 1 {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid timeout parameter: %q", v))
+			return
+		}
+		opts.Timeout = min(time.Duration(secs)*time.Second, maxSearchAllTimeout)
+	}
+	_, limit, err := parsePaging(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Limit = limit
+
+	writeOK(c, search.Aggregate(c.Request.Context(), s.providers, keyword, opts))
+}
+
+// POST /api/lyrics?source=X[&translation=true][&romanization=true][&word=true][&export=F][&fallback=true]  body: Song JSON
+// With translation or romanization, "lyrics" is the bilingual LRC with the
+// extra lines merged under each original line, and the separate versions are
+// returned alongside it. With word, sources that have word-timed lyrics for
+// the song return Enhanced LRC plus the per-word timings in "lines"; other
+// songs fall back to line-level LRC. With export (lrc, elrc, srt, vtt or
+// ttml), "lyrics" is converted to that format. With fallback, songs whose
+// source has no lyrics get them from a matching song on another provider.
 func (s *Server) handleLyrics(c *gin.Context) {
 	p, source, ok := s.getProvider(c)
 	if !ok {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.GetLyrics == nil {
+	fallback := c.Query("fallback") == "true"
+	if !fallback && p.LyricsFetcher() == nil && p.LyricsSetFetcher() == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("lyrics not supported for %s", source))
 		return
 	}
+	opts := model.LyricsOptions{
+		Translation:  c.Query("translation") == "true",
+		Romanization: c.Query("romanization") == "true",
+	}
+	export := c.Query("export")
+	if export != "" && !slices.Contains(model.LyricsExportFormats, export) {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unsupported export format: %q", export))
+		return
+	}
+	var song model.Song
+	if err := json.NewDecoder(c.Request.Body).Decode(&song); err != nil {
+		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	// Lyrics are cached by song ID; a song without one is looked up directly.
+	respCache := s.cache
+	if song.ID == "" {
+		respCache = nil
+	}
+
+	var words *model.WordLyrics
+	if wf := p.WordLyricsFetcher(); wf != nil && c.Query("word") == "true" {
+		key := cache.Key(cache.Lyrics, source, song.ID, "word")
+		w, res, err := cache.Fetch(cacheContext(c), respCache, cache.Lyrics, key, func(ctx context.Context) (*model.WordLyrics, error) {
+			return wf.GetWordLyricsContext(ctx, &song)
+		})
+		switch {
+		case err == nil:
+			words = w
+			writeCacheHeaders(c, res)
+		case model.KindOf(err) != model.ErrNotFound:
+			writeProviderError(c, err)
+			return
+		}
+	}
+
+	if !opts.Any() {
+		if words != nil {
+			writeLyrics(c, export, map[string]any{"lyrics": words.EnhancedLRC(), "format": words.Format, "lines": words.Lines})
+			return
+		}
+		key := cache.Key(cache.Lyrics, source, song.ID)
+		if fallback {
+			key = cache.Key(cache.Lyrics, source, song.ID, "fallback")
+		}
+		lyrics, res, err := cache.Fetch(cacheContext(c), respCache, cache.Lyrics, key, func(ctx context.Context) (string, error) {
+			set, err := s.fetchLyrics(ctx, p, &song, opts, fallback)
+			if err != nil {
+				return "", err
+			}
+			return set.Original, nil
+		})
+		if err != nil {
+			writeProviderError(c, err)
+			return
+		}
+		writeCacheHeaders(c, res)
+		writeLyrics(c, export, map[string]any{"lyrics": lyrics})
+		return
+	}
+
+	// Every version comes back from one upstream call, so the whole set is
+	// cached once regardless of which versions were asked for.
+	key := cache.Key(cache.Lyrics, source, song.ID, "set")
+	if fallback {
+		key = cache.Key(cache.Lyrics, source, song.ID, "set", "fallback")
+	}
+	set, res, err := cache.Fetch(cacheContext(c), respCache, cache.Lyrics, key, func(ctx context.Context) (*model.LyricsSet, error) {
+		return s.fetchLyrics(ctx, p, &song, model.LyricsOptions{Translation: true, Romanization: true}, fallback)
+	})
+	if err != nil {
+		writeProviderError(c, err)
+		return
+	}
+	merged := *set
+	out := map[string]any{}
+	if words != nil {
+		merged.Original = words.EnhancedLRC()
+		out["format"], out["lines"] = words.Format, words.Lines
+	}
+	out["lyrics"] = merged.Merge(opts)
+	if opts.Translation {
+		out["translation"] = set.Translation
+	}
+	if opts.Romanization {
+		out["romanization"] = set.Romanization
+	}
+	writeCacheHeaders(c, res)
+	writeLyrics(c, export, out)
+}
+
+// fetchLyrics fetches song's lyrics from p and, with fallback, from another
+// provider when p returns none.
+func (s *Server) fetchLyrics(ctx context.Context, p *registry.Provider, song *model.Song, opts model.LyricsOptions, fallback bool) (*model.LyricsSet, error) {
+	var set *model.LyricsSet
+	var err error
+	if p.LyricsFetcher() != nil || p.LyricsSetFetcher() != nil {
+		set, err = p.FetchLyrics(ctx, song, opts)
+	}
+	if !fallback || (err == nil && set != nil && strings.TrimSpace(set.Original) != "") {
+		return set, err
+	}
+	fb, _, fbErr := s.providers.FallbackLyrics(ctx, song, opts)
+	if fbErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		// An empty set here would be cached as the answer and hide the
+		// fallback until the entry expires.
+		return nil, model.Errorf(p.Name, model.ErrNotFound, "no lyrics from %s or a fallback source: %w", p.Name, fbErr)
+	}
+	return fb, nil
+}
+
+// writeLyrics writes a handleLyrics response, first converting out["lyrics"]
+// to the requested export format if there is one.
+func writeLyrics(c *gin.Context, export string, out map[string]any) {
+	if lrc, ok := out["lyrics"].(string); ok && export != "" {
+		converted, err := model.ParseLyrics(lrc).Export(export)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		out["lyrics"] = converted
+	}
+	writeOK(c, out)
+}
+
+// POST /api/streams?source=X[&quality=Y]  body: Song JSON
+// Lists every stream the source offers for the song, plus the one that
+// would be downloaded for the requested quality.
+func (s *Server) handleStreams(c *gin.Context) {
+	p, source, ok := s.getProvider(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
+		return
+	}
+	src := p.StreamSource()
+	if src == nil {
+		writeError(c, http.StatusNotImplemented, fmt.Sprintf("streams not supported for %s", source))
+		return
+	}
 	var song model.Song
 	if err := json.NewDecoder(c.Request.Body).Decode(&song); err != nil {
 		writeError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
 	}
-	lyrics, err := p.GetLyrics(&song)
+	streams, err := src.GetStreamsContext(c.Request.Context(), &song)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
-	writeOK(c, map[string]string{"lyrics": lyrics})
+	data := map[string]any{"streams": streams}
+	if selected, ok := model.SelectStream(streams, c.Query("quality")); ok {
+		data["selected"] = selected
+	}
+	writeOK(c, data)
 }
 
 // GET /api/parse?source=X&link=Y
@@ -103,7 +323,8 @@ func (s *Server) handleParse(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, fmt.Sprintf("unknown or missing source: %q", source))
 		return
 	}
-	if p.Parse == nil {
+	parser := p.SongParser()
+	if parser == nil {
 		writeError(c, http.StatusNotImplemented, fmt.Sprintf("parse not supported for %s", source))
 		return
 	}
@@ -112,9 +333,9 @@ func (s *Server) handleParse(c *gin.Context) {
 		writeError(c, http.StatusBadRequest, "missing link parameter")
 		return
 	}
-	song, err := p.Parse(link)
+	song, err := parser.ParseContext(c.Request.Context(), link)
 	if err != nil {
-		writeError(c, http.StatusInternalServerError, err.Error())
+		writeProviderError(c, err)
 		return
 	}
 	writeOK(c, song)
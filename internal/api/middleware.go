@@ -1,14 +1,20 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
 )
 
 // Recovery returns a Gin middleware that catches panics, logs them with slog,
@@ -95,13 +101,90 @@ func writeOK(c *gin.Context, data any) {
 	c.JSON(http.StatusOK, gin.H{"code": 0, "data": data})
 }
 
+// writePage writes one page of results. data stays a plain array so existing
+// clients keep working; the paging fields sit next to it:
+// {"code":0,"data":[...],"page":1,"limit":10,"total":123,"has_more":true}
+func writePage[T any](c *gin.Context, p *model.Page[T]) {
+	items := p.Items
+	if items == nil {
+		items = []T{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":     0,
+		"data":     items,
+		"page":     p.Page,
+		"limit":    p.Limit,
+		"total":    p.Total,
+		"has_more": p.HasMore,
+	})
+}
+
+// cacheContext returns the request context for a cached lookup. A request
+// with "Cache-Control: no-cache" skips the cached copy and refreshes it.
+func cacheContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") {
+		ctx = cache.WithRefresh(ctx)
+	}
+	return ctx
+}
+
+// writeCacheHeaders reports how a response was produced: X-Cache is HIT,
+// MISS or SHARED (joined an identical in-flight request), and hits also carry
+// Age in seconds. Nothing is written when the cache was not used.
+func writeCacheHeaders(c *gin.Context, r cache.Result) {
+	if r.Status == "" {
+		return
+	}
+	c.Header("X-Cache", string(r.Status))
+	if r.Status == cache.Hit {
+		c.Header("Age", strconv.Itoa(int(r.Age/time.Second)))
+	}
+}
+
 // writeError writes a failure JSON response: {"code":-1,"message":...}
 func writeError(c *gin.Context, httpCode int, msg string) {
 	c.JSON(httpCode, gin.H{"code": -1, "message": msg})
 }
 
+// writeProviderError writes a failure response for an error returned by a
+// provider, choosing the HTTP status from the error's kind (see model.KindOf).
+// Rate-limited responses carry a Retry-After header when the platform gave one.
+func writeProviderError(c *gin.Context, err error) {
+	if wait := model.RetryAfter(err); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	}
+	writeError(c, providerErrorStatus(err), err.Error())
+}
+
+// providerErrorStatus maps a provider error to an HTTP status code.
+func providerErrorStatus(err error) int {
+	switch model.KindOf(err) {
+	case model.ErrNotFound:
+		return http.StatusNotFound
+	case model.ErrVIPRequired:
+		return http.StatusPaymentRequired
+	case model.ErrRestricted:
+		return http.StatusUnavailableForLegalReasons
+	case model.ErrRateLimited:
+		return http.StatusTooManyRequests
+	case model.ErrLoginExpired:
+		return http.StatusUnauthorized
+	case model.ErrSchemaChanged:
+		return http.StatusBadGateway
+	case model.ErrInvalidInput, model.ErrSourceMismatch:
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, utils.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
 // isValidPlatform returns true for the two supported login platforms.
 func isValidPlatform(p string) bool {
 	return p == "netease" || p == "qq"
 }
-
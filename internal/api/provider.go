@@ -1,27 +1,18 @@
 package api
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/guohuiyuan/music-lib/download"
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/internal/resolve"
 	"github.com/guohuiyuan/music-lib/login"
-	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
 	"gorm.io/gorm"
 )
 
-// ProviderFuncs holds all capabilities for a single music provider.
-type ProviderFuncs struct {
-	Search           func(string) ([]model.Song, error)
-	GetDownloadURL   func(*model.Song) (string, error)
-	GetLyrics        func(*model.Song) (string, error)
-	Parse            func(string) (*model.Song, error)
-	SearchPlaylist   func(string) ([]model.Playlist, error)
-	GetPlaylistSongs func(string) ([]model.Song, error)
-	ParsePlaylist    func(string) (*model.Playlist, []model.Song, error)
-	GetRecommended   func() ([]model.Playlist, error)
-	GetCharts        func() ([]model.Chart, error)
-	GetChartSongs    func(chartID string, limit int) ([]model.Song, error)
-}
-
 // PlatformAuth abstracts platform-specific login state queries.
 type PlatformAuth interface {
 	GetLoginStatus() (bool, string)
@@ -30,21 +21,39 @@ type PlatformAuth interface {
 
 // Server holds all shared dependencies for the API handlers.
 type Server struct {
-	providers map[string]ProviderFuncs
+	providers *registry.Registry
 	loginMgr  *login.Manager
 	dlMgr     *download.Manager
 	db        *gorm.DB
 	netease   PlatformAuth
 	qq        PlatformAuth
+	cache     *cache.Cache // nil disables response caching
+	resolver  *resolve.Resolver
 }
 
-// getProvider resolves the "source" query parameter to a ProviderFuncs.
+// getProvider resolves the "source" query parameter to a registered provider.
 // Returns (provider, source, found).
-func (s *Server) getProvider(c *gin.Context) (ProviderFuncs, string, bool) {
+func (s *Server) getProvider(c *gin.Context) (*registry.Provider, string, bool) {
 	source := c.Query("source")
 	if source == "" {
-		return ProviderFuncs{}, "", false
+		return nil, "", false
 	}
-	p, ok := s.providers[source]
+	p, ok := s.providers.Get(source)
 	return p, source, ok
 }
+
+// parsePaging reads the optional "page" and "limit" query parameters.
+// Missing values come back as 0 so the provider picks its own defaults.
+func parsePaging(c *gin.Context) (page, limit int, err error) {
+	if v := c.Query("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid page parameter: %q", v)
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("invalid limit parameter: %q", v)
+		}
+	}
+	return page, limit, nil
+}
@@ -3,20 +3,25 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/guohuiyuan/music-lib/download"
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"github.com/guohuiyuan/music-lib/internal/resolve"
 	"github.com/guohuiyuan/music-lib/login"
+	"github.com/guohuiyuan/music-lib/registry"
 	"gorm.io/gorm"
 )
 
 // NewRouter creates and configures the Gin engine with all routes registered.
-// providers maps source name to its ProviderFuncs.
+// providers is the registry every source-based handler resolves against.
 // netease and qq expose login status / logout for their respective platforms.
+// respCache caches search, playlist, chart and lyrics responses; it may be nil.
 func NewRouter(
-	providers map[string]ProviderFuncs,
+	providers *registry.Registry,
 	loginMgr *login.Manager,
 	dlMgr *download.Manager,
 	db *gorm.DB,
 	netease PlatformAuth,
 	qq PlatformAuth,
+	respCache *cache.Cache,
 ) *gin.Engine {
 	engine := gin.New()
 
@@ -33,16 +38,24 @@ func NewRouter(
 		db:        db,
 		netease:   netease,
 		qq:        qq,
+		cache:     respCache,
+		resolver:  resolve.New(providers, nil),
 	}
 
 	// Health + provider info
 	engine.GET("/health", srv.handleHealth)
 	engine.GET("/providers", srv.handleProviders)
+	engine.GET("/api/providers/health", srv.handleProviderHealth)
 
 	// Song APIs
 	engine.GET("/api/search", srv.handleSearch)
+	engine.GET("/api/search/all", srv.handleSearchAll)
 	engine.POST("/api/lyrics", srv.handleLyrics)
+	engine.POST("/api/streams", srv.handleStreams)
+	engine.POST("/api/probe", srv.handleProbe)
 	engine.GET("/api/parse", srv.handleParse)
+	engine.GET("/api/resolve", srv.handleResolve)
+	engine.POST("/api/resolve", srv.handleResolve)
 
 	// Playlist APIs
 	engine.GET("/api/playlist/search", srv.handlePlaylistSearch)
@@ -50,6 +63,15 @@ func NewRouter(
 	engine.GET("/api/playlist/parse", srv.handlePlaylistParse)
 	engine.GET("/api/playlist/recommended", srv.handlePlaylistRecommended)
 
+	// Album APIs
+	engine.GET("/api/album/search", srv.handleAlbumSearch)
+	engine.GET("/api/album/songs", srv.handleAlbumSongs)
+
+	// Artist APIs
+	engine.GET("/api/artist/search", srv.handleArtistSearch)
+	engine.GET("/api/artist/songs", srv.handleArtistSongs)
+	engine.GET("/api/artist/albums", srv.handleArtistAlbums)
+
 	// Login APIs
 	engine.POST("/api/login/qr/start", srv.handleLoginQRStart)
 	engine.GET("/api/login/qr/poll", srv.handleLoginQRPoll)
@@ -60,12 +82,21 @@ func NewRouter(
 	// Download / NAS APIs
 	engine.POST("/api/download/file", srv.handleProxyDownload)
 	engine.GET("/api/nas/status", srv.handleNASStatus)
+	engine.GET("/api/nas/settings", srv.handleGetNASSettings)
+	engine.PUT("/api/nas/settings", srv.handleUpdateNASSettings)
 	engine.POST("/api/nas/download", srv.handleNASDownload)
 	engine.POST("/api/nas/download/batch", srv.handleNASBatchDownload)
+	engine.POST("/api/nas/download/album", srv.handleNASAlbumDownload)
+	engine.POST("/api/nas/download/artist", srv.handleNASArtistDownload)
 	engine.POST("/api/nas/download/upgrade", srv.handleNASUpgrade)
 	engine.GET("/api/nas/tasks", srv.handleListTasks)
 	engine.GET("/api/nas/task", srv.handleGetTask)
+	engine.POST("/api/nas/task/:id", srv.handleTaskAction)
+	engine.DELETE("/api/nas/task/:id", srv.handleCancelTask)
 	engine.GET("/api/nas/batches", srv.handleListBatches)
+	engine.POST("/api/nas/batch/:id", srv.handleBatchAction)
+	engine.DELETE("/api/nas/batch/:id", srv.handleCancelBatch)
+	engine.POST("/api/nas/queue", srv.handleNASQueue)
 
 	// Chart / Monitor APIs
 	engine.GET("/api/charts", srv.handleGetCharts)
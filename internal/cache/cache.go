@@ -0,0 +1,306 @@
+// Package cache keeps recent provider responses so repeated lookups (UI
+// navigation, monitor runs) do not hit upstream every time.
+//
+// Entries live in an in-memory LRU, optionally backed by a persistent Backend
+// such as the SQLite table in internal/store. Identical requests that arrive
+// while one is already in flight share its result instead of fetching again.
+//
+// Only data that stays valid for a while is cached: search results, playlist
+// and chart listings, lyrics. Stream and download URLs are signed and expire,
+// so they are always fetched fresh.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind groups cache entries that share a TTL.
+type Kind string
+
+const (
+	Search   Kind = "search"   // song and playlist search results
+	Playlist Kind = "playlist" // playlist contents
+	Charts   Kind = "charts"   // chart lists and chart contents
+	Lyrics   Kind = "lyrics"
+)
+
+// Default TTLs per kind.
+const (
+	DefaultSearchTTL   = 10 * time.Minute
+	DefaultPlaylistTTL = 30 * time.Minute
+	DefaultChartsTTL   = time.Hour
+	DefaultLyricsTTL   = 24 * time.Hour
+)
+
+// DefaultSize is the default number of entries kept in memory.
+const DefaultSize = 1000
+
+// Status says where a response came from. It is sent as the X-Cache header.
+type Status string
+
+const (
+	Miss   Status = "MISS"   // fetched from upstream
+	Hit    Status = "HIT"    // served from the cache
+	Shared Status = "SHARED" // joined an identical request already in flight
+)
+
+// Result describes how Fetch produced its value. The zero Result means the
+// cache was not used (nil cache, or caching disabled for the kind).
+type Result struct {
+	Status Status
+	Age    time.Duration // time since the entry was stored; 0 for a miss
+}
+
+// Entry is a cached, JSON-encoded response.
+type Entry struct {
+	Data      []byte
+	StoredAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Backend is an optional persistent second tier. Load reports ok=false for
+// unknown keys; expired entries may be returned and are ignored by the cache.
+type Backend interface {
+	Load(key string) (e Entry, ok bool, err error)
+	Save(key string, e Entry) error
+}
+
+// Options configures New.
+type Options struct {
+	Size    int                    // in-memory entries, DefaultSize when 0
+	TTL     map[Kind]time.Duration // overrides the default TTLs; 0 disables caching for a kind
+	Backend Backend                // optional
+}
+
+// Cache is an LRU response cache with request coalescing.
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	size    int
+	ttl     map[Kind]time.Duration
+	backend Backend
+	now     func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List // front is most recently used
+	items map[string]*list.Element
+
+	flightMu sync.Mutex
+	flights  map[string]*call
+}
+
+type item struct {
+	key   string
+	entry Entry
+}
+
+// call is one in-flight fetch that later callers can wait on.
+type call struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// New creates a cache.
+func New(opts Options) *Cache {
+	size := opts.Size
+	if size <= 0 {
+		size = DefaultSize
+	}
+	ttl := map[Kind]time.Duration{
+		Search:   DefaultSearchTTL,
+		Playlist: DefaultPlaylistTTL,
+		Charts:   DefaultChartsTTL,
+		Lyrics:   DefaultLyricsTTL,
+	}
+	for k, d := range opts.TTL {
+		ttl[k] = d
+	}
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		backend: opts.Backend,
+		now:     time.Now,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+		flights: make(map[string]*call),
+	}
+}
+
+// TTL returns the lifetime of entries of the given kind.
+func (c *Cache) TTL(kind Kind) time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.ttl[kind]
+}
+
+// Len returns the number of entries held in memory.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Key joins parts into a cache key. Parts are escaped so that, for example,
+// a keyword containing ":" cannot collide with another key.
+func Key(kind Kind, parts ...string) string {
+	escaped := make([]string, 0, len(parts)+1)
+	escaped = append(escaped, string(kind))
+	for _, p := range parts {
+		escaped = append(escaped, url.QueryEscape(p))
+	}
+	return strings.Join(escaped, ":")
+}
+
+type refreshKey struct{}
+
+// WithRefresh returns a context under which Fetch skips cached entries and
+// always asks upstream; the fresh result still replaces the cached one.
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+func refreshing(ctx context.Context) bool {
+	v, _ := ctx.Value(refreshKey{}).(bool)
+	return v
+}
+
+// Fetch returns the cached value for key, or calls fetch and caches its
+// result for the kind's TTL. Errors are never cached. Concurrent calls with
+// the same key share one fetch; each caller still stops waiting when its own
+// ctx is done, while the shared fetch keeps running for the others.
+func Fetch[T any](ctx context.Context, c *Cache, kind Kind, key string, fetch func(context.Context) (T, error)) (T, Result, error) {
+	ttl := c.TTL(kind)
+	if ttl <= 0 {
+		v, err := fetch(ctx)
+		return v, Result{}, err
+	}
+
+	var zero T
+	if !refreshing(ctx) {
+		if e, ok := c.get(key); ok {
+			var v T
+			if err := json.Unmarshal(e.Data, &v); err == nil {
+				return v, Result{Status: Hit, Age: c.now().Sub(e.StoredAt)}, nil
+			}
+		}
+	}
+
+	c.flightMu.Lock()
+	if fl, ok := c.flights[key]; ok {
+		c.flightMu.Unlock()
+		select {
+		case <-fl.done:
+		case <-ctx.Done():
+			return zero, Result{}, ctx.Err()
+		}
+		if fl.err != nil {
+			return zero, Result{}, fl.err
+		}
+		var v T
+		if err := json.Unmarshal(fl.data, &v); err != nil {
+			return zero, Result{}, err
+		}
+		return v, Result{Status: Shared}, nil
+	}
+	fl := &call{done: make(chan struct{})}
+	c.flights[key] = fl
+	c.flightMu.Unlock()
+
+	var v T
+	go func() {
+		defer func() {
+			c.flightMu.Lock()
+			delete(c.flights, key)
+			c.flightMu.Unlock()
+			close(fl.done)
+		}()
+		// Detach from the caller's cancellation: other callers may be
+		// waiting on this fetch. Provider clients bound it with their own timeout.
+		res, err := fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			fl.err = err
+			return
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			fl.err = err
+			return
+		}
+		v, fl.data = res, data
+		c.set(key, data, ttl)
+	}()
+
+	select {
+	case <-fl.done:
+	case <-ctx.Done():
+		return zero, Result{}, ctx.Err()
+	}
+	if fl.err != nil {
+		return zero, Result{}, fl.err
+	}
+	return v, Result{Status: Miss}, nil
+}
+
+// get looks key up in memory, then in the backend.
+func (c *Cache) get(key string) (Entry, bool) {
+	now := c.now()
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		it := el.Value.(*item)
+		if now.Before(it.entry.ExpiresAt) {
+			c.ll.MoveToFront(el)
+			c.mu.Unlock()
+			return it.entry, true
+		}
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+
+	if c.backend == nil {
+		return Entry{}, false
+	}
+	e, ok, err := c.backend.Load(key)
+	if err != nil || !ok || !now.Before(e.ExpiresAt) {
+		return Entry{}, false
+	}
+	c.add(key, e)
+	return e, true
+}
+
+// set stores data in memory and in the backend.
+func (c *Cache) set(key string, data []byte, ttl time.Duration) {
+	now := c.now()
+	e := Entry{Data: data, StoredAt: now, ExpiresAt: now.Add(ttl)}
+	c.add(key, e)
+	if c.backend != nil {
+		// A failed write only costs a future miss.
+		_ = c.backend.Save(key, e)
+	}
+}
+
+func (c *Cache) add(key string, e Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).entry = e
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&item{key: key, entry: e})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetch_HitAfterMiss(t *testing.T) {
+	c := New(Options{})
+	calls := 0
+	fetch := func(context.Context) ([]string, error) {
+		calls++
+		return []string{"a", "b"}, nil
+	}
+	key := Key(Search, "song", "qq", "jay")
+
+	v, res, err := Fetch(context.Background(), c, Search, key, fetch)
+	if err != nil || res.Status != Miss || len(v) != 2 {
+		t.Fatalf("first fetch: %v %+v %v", v, res, err)
+	}
+	v, res, err = Fetch(context.Background(), c, Search, key, fetch)
+	if err != nil || res.Status != Hit || len(v) != 2 {
+		t.Fatalf("second fetch: %v %+v %v", v, res, err)
+	}
+	if calls != 1 {
+		t.Errorf("upstream called %d times, want 1", calls)
+	}
+
+	// A hit is a decoded copy, so callers cannot corrupt the cached entry.
+	v[0] = "changed"
+	v, _, _ = Fetch(context.Background(), c, Search, key, fetch)
+	if v[0] != "a" {
+		t.Errorf("cached value was modified: %v", v)
+	}
+}
+
+func TestFetch_ExpiryAndRefresh(t *testing.T) {
+	c := New(Options{TTL: map[Kind]time.Duration{Charts: time.Minute}})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	calls := 0
+	fetch := func(context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	Fetch(context.Background(), c, Charts, "k", fetch)
+	now = now.Add(30 * time.Second)
+	if v, res, _ := Fetch(context.Background(), c, Charts, "k", fetch); v != 1 || res.Status != Hit || res.Age != 30*time.Second {
+		t.Errorf("within TTL: got %d %+v", v, res)
+	}
+	if v, res, _ := Fetch(WithRefresh(context.Background()), c, Charts, "k", fetch); v != 2 || res.Status != Miss {
+		t.Errorf("refresh: got %d %+v", v, res)
+	}
+	now = now.Add(2 * time.Minute)
+	if v, res, _ := Fetch(context.Background(), c, Charts, "k", fetch); v != 3 || res.Status != Miss {
+		t.Errorf("after expiry: got %d %+v", v, res)
+	}
+}
+
+func TestFetch_ErrorsNotCached(t *testing.T) {
+	c := New(Options{})
+	boom := errors.New("boom")
+	if _, _, err := Fetch(context.Background(), c, Lyrics, "k", func(context.Context) (string, error) { return "", boom }); !errors.Is(err, boom) {
+		t.Fatalf("got %v", err)
+	}
+	v, res, err := Fetch(context.Background(), c, Lyrics, "k", func(context.Context) (string, error) { return "lrc", nil })
+	if err != nil || v != "lrc" || res.Status != Miss {
+		t.Errorf("got %q %+v %v", v, res, err)
+	}
+}
+
+func TestFetch_DisabledKind(t *testing.T) {
+	for _, c := range []*Cache{nil, New(Options{TTL: map[Kind]time.Duration{Search: 0}})} {
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, res, _ := Fetch(context.Background(), c, Search, "k", func(context.Context) (int, error) {
+				calls++
+				return 0, nil
+			})
+			if res.Status != "" {
+				t.Errorf("status = %q, want empty", res.Status)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("upstream called %d times, want 2", calls)
+		}
+	}
+}
+
+func TestFetch_Coalesces(t *testing.T) {
+	c := New(Options{})
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "v", nil
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	statuses := make(chan Status, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, res, err := Fetch(context.Background(), c, Playlist, "k", fetch)
+			if err != nil || v != "v" {
+				t.Errorf("got %q %v", v, err)
+			}
+			statuses <- res.Status
+		}()
+	}
+	// Let every caller reach the in-flight call before releasing it.
+	for deadline := time.Now().Add(time.Second); calls.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(statuses)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("upstream called %d times, want 1", got)
+	}
+	misses := 0
+	for st := range statuses {
+		if st == Miss {
+			misses++
+		}
+	}
+	if misses != 1 {
+		t.Errorf("%d callers saw MISS, want 1", misses)
+	}
+}
+
+func TestFetch_CallerCancelDoesNotAbortSharedFetch(t *testing.T) {
+	c := New(Options{})
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := Fetch(ctx, c, Search, "k", func(ctx context.Context) (string, error) {
+			<-release
+			return "v", ctx.Err()
+		})
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller got %v", err)
+	}
+	close(release)
+
+	// The fetch finished in the background and its result was cached.
+	for deadline := time.Now().Add(time.Second); c.Len() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	v, res, err := Fetch(context.Background(), c, Search, "k", func(context.Context) (string, error) { return "other", nil })
+	if err != nil || v != "v" || res.Status != Hit {
+		t.Errorf("got %q %+v %v", v, res, err)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(Options{Size: 2})
+	get := func(key string) Status {
+		_, res, _ := Fetch(context.Background(), c, Search, key, func(context.Context) (string, error) { return key, nil })
+		return res.Status
+	}
+	get("a")
+	get("b")
+	get("a") // a is now more recent than b
+	get("c") // evicts b
+	if c.Len() != 2 {
+		t.Errorf("len = %d, want 2", c.Len())
+	}
+	if st := get("a"); st != Hit {
+		t.Errorf("a: %s, want HIT", st)
+	}
+	if st := get("b"); st != Miss {
+		t.Errorf("b: %s, want MISS", st)
+	}
+}
+
+type memBackend struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func (b *memBackend) Load(key string) (Entry, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	return e, ok, nil
+}
+
+func (b *memBackend) Save(key string, e Entry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[key] = e
+	return nil
+}
+
+func TestCache_Backend(t *testing.T) {
+	backend := &memBackend{entries: map[string]Entry{}}
+	first := New(Options{Backend: backend})
+	Fetch(context.Background(), first, Lyrics, "k", func(context.Context) (string, error) { return "lrc", nil })
+	if _, ok := backend.entries["k"]; !ok {
+		t.Fatal("entry not written to backend")
+	}
+
+	// A fresh cache (e.g. after a restart) finds the entry in the backend.
+	second := New(Options{Backend: backend})
+	v, res, err := Fetch(context.Background(), second, Lyrics, "k", func(context.Context) (string, error) { return "other", nil })
+	if err != nil || v != "lrc" || res.Status != Hit {
+		t.Errorf("got %q %+v %v", v, res, err)
+	}
+
+	// Expired backend entries are ignored.
+	backend.entries["old"] = Entry{Data: []byte(`"stale"`), StoredAt: time.Now().Add(-2 * time.Hour), ExpiresAt: time.Now().Add(-time.Hour)}
+	v, res, _ = Fetch(context.Background(), second, Lyrics, "old", func(context.Context) (string, error) { return "fresh", nil })
+	if v != "fresh" || res.Status != Miss {
+		t.Errorf("expired entry: got %q %+v", v, res)
+	}
+}
+
+func TestKey_Escapes(t *testing.T) {
+	if Key(Search, "a:b", "c") == Key(Search, "a", "b:c") {
+		t.Error("keys with ':' in parts collide")
+	}
+}
@@ -0,0 +1,338 @@
+// Package health periodically runs canary queries against every provider and
+// records whether each platform still answers with well-formed data, so an
+// upstream API change shows up before users report empty results.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/internal/store"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
+	"gorm.io/gorm"
+)
+
+// Canary checks.
+const (
+	CheckSearch      = "search"
+	CheckDownloadURL = "download_url"
+	CheckLyrics      = "lyrics"
+	CheckCharts      = "charts"
+)
+
+// Check outcomes, from best to worst.
+const (
+	StatusOK       = "ok"
+	StatusSkipped  = "skipped"  // nothing to check, e.g. no canary song because search failed
+	StatusDegraded = "degraded" // the API answered but refused: VIP, region, rate limit, login, circuit open
+	StatusFailed   = "failed"   // network or upstream error
+	StatusDrift    = "drift"    // the response no longer parses into the expected fields
+	StatusUnknown  = "unknown"  // never probed
+)
+
+var severity = map[string]int{
+	StatusUnknown:  -1,
+	StatusOK:       0,
+	StatusSkipped:  0,
+	StatusDegraded: 1,
+	StatusFailed:   2,
+	StatusDrift:    3,
+}
+
+// Worst returns the most severe of statuses, or StatusUnknown when empty.
+func Worst(statuses ...string) string {
+	worst := StatusUnknown
+	for _, s := range statuses {
+		if severity[s] > severity[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// Result is the outcome of one canary check.
+type Result struct {
+	Source  string
+	Check   string
+	Status  string
+	Latency time.Duration
+	Err     error
+}
+
+// errDrift marks a response that parsed but is missing expected fields.
+var errDrift = errors.New("unexpected response")
+
+func driftf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errDrift, fmt.Sprintf(format, args...))
+}
+
+// classify maps a check error to a status.
+func classify(err error) string {
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, errDrift):
+		return StatusDrift
+	}
+	switch model.KindOf(err) {
+	case model.ErrSchemaChanged:
+		return StatusDrift
+	case model.ErrVIPRequired, model.ErrRestricted, model.ErrRateLimited, model.ErrLoginExpired:
+		return StatusDegraded
+	}
+	if errors.Is(err, utils.ErrCircuitOpen) {
+		return StatusDegraded
+	}
+	return StatusFailed
+}
+
+// Probe runs every canary check the provider supports. Each check gets its
+// own timeout. Checks the provider has no capability for are left out.
+func Probe(ctx context.Context, p *registry.Provider, timeout time.Duration) []Result {
+	var results []Result
+	run := func(check string, fn func(context.Context) error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		start := time.Now()
+		err := fn(ctx)
+		results = append(results, Result{
+			Source:  p.Name,
+			Check:   check,
+			Status:  classify(err),
+			Latency: time.Since(start),
+			Err:     err,
+		})
+	}
+	skip := func(check, reason string) {
+		results = append(results, Result{Source: p.Name, Check: check, Status: StatusSkipped, Err: errors.New(reason)})
+	}
+
+	canary := p.Canary
+	var song *model.Song
+	if canary.SongID != "" {
+		song = &model.Song{Source: p.Name, ID: canary.SongID}
+	}
+
+	if s := p.Searcher(); s != nil && canary.Keyword != "" {
+		run(CheckSearch, func(ctx context.Context) error {
+			songs, err := s.SearchContext(ctx, canary.Keyword)
+			if err != nil {
+				return err
+			}
+			if err := validateSongs(p.Name, songs); err != nil {
+				return err
+			}
+			if song == nil {
+				first := songs[0]
+				song = &first
+			}
+			return nil
+		})
+	}
+
+	if d := p.Downloader(); d != nil {
+		if song == nil {
+			skip(CheckDownloadURL, "no canary song")
+		} else {
+			run(CheckDownloadURL, func(ctx context.Context) error {
+				s := *song
+				u, err := d.GetDownloadURLContext(ctx, &s)
+				if err != nil {
+					return err
+				}
+				return validateURL(u)
+			})
+		}
+	}
+
+	if l := p.LyricsFetcher(); l != nil {
+		if song == nil {
+			skip(CheckLyrics, "no canary song")
+		} else {
+			run(CheckLyrics, func(ctx context.Context) error {
+				s := *song
+				lyrics, err := l.GetLyricsContext(ctx, &s)
+				if err != nil {
+					return err
+				}
+				if strings.TrimSpace(lyrics) == "" {
+					// A fixed canary song is known to have lyrics; a search
+					// result may simply be instrumental.
+					if canary.SongID != "" {
+						return driftf("empty lyrics")
+					}
+					return model.Errorf(p.Name, model.ErrNotFound, "empty lyrics for %s", s.ID)
+				}
+				return nil
+			})
+		}
+	}
+
+	if c := p.ChartSource(); c != nil {
+		run(CheckCharts, func(ctx context.Context) error {
+			charts, err := c.GetChartsContext(ctx)
+			if err != nil {
+				return err
+			}
+			if len(charts) == 0 {
+				return driftf("no charts")
+			}
+			for i, ch := range charts {
+				if ch.ID == "" || ch.Name == "" {
+					return driftf("chart %d: empty id or name", i)
+				}
+			}
+			songs, err := c.GetChartSongsContext(ctx, charts[0].ID, 5)
+			if err != nil {
+				return err
+			}
+			return validateSongs(p.Name, songs)
+		})
+	}
+	return results
+}
+
+// validateSongs checks that songs is non-empty and every song carries the
+// fields a parser change would typically lose.
+func validateSongs(source string, songs []model.Song) error {
+	if len(songs) == 0 {
+		return driftf("no songs")
+	}
+	for i, s := range songs {
+		switch {
+		case s.ID == "":
+			return driftf("song %d: empty id", i)
+		case s.Name == "":
+			return driftf("song %d: empty name", i)
+		case s.Source != source:
+			return driftf("song %d: source %q", i, s.Source)
+		}
+	}
+	return nil
+}
+
+func validateURL(raw string) error {
+	if raw == "" {
+		return driftf("empty download url")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return driftf("invalid download url %q", raw)
+	}
+	return nil
+}
+
+// Prober runs Probe for every provider on a fixed interval and stores the
+// results with store.SaveHealthResult.
+type Prober struct {
+	db        *gorm.DB
+	providers *registry.Registry
+	interval  time.Duration
+	timeout   time.Duration
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+
+	// ctx is cancelled by Stop so that in-flight probes return promptly.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewProber creates a prober that checks every provider each interval,
+// allowing timeout for each check.
+func NewProber(db *gorm.DB, providers *registry.Registry, interval, timeout time.Duration) *Prober {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Prober{
+		db:        db,
+		providers: providers,
+		interval:  interval,
+		timeout:   timeout,
+		stopCh:    make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start begins the probing loop. The first round runs immediately.
+func (p *Prober) Start() {
+	p.wg.Add(1)
+	go p.loop()
+	slog.Info("health.prober.started", "interval", p.interval.String())
+}
+
+// Stop signals the prober to stop and waits for it to finish.
+func (p *Prober) Stop() {
+	close(p.stopCh)
+	p.cancel()
+	p.wg.Wait()
+	slog.Info("health.prober.stopped")
+}
+
+func (p *Prober) loop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.RunOnce(p.ctx)
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(p.ctx)
+		}
+	}
+}
+
+// RunOnce probes every provider concurrently, stores the results and returns them.
+func (p *Prober) RunOnce(ctx context.Context) []Result {
+	var (
+		mu  sync.Mutex
+		all []Result
+		wg  sync.WaitGroup
+	)
+	for _, prov := range p.providers.All() {
+		wg.Add(1)
+		go func(prov *registry.Provider) {
+			defer wg.Done()
+			results := Probe(ctx, prov, p.timeout)
+			mu.Lock()
+			all = append(all, results...)
+			mu.Unlock()
+		}(prov)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return all // stopping; partial results would read as failures
+	}
+	now := time.Now()
+	for _, r := range all {
+		rec := &store.HealthRecord{
+			Source:    r.Source,
+			Check:     r.Check,
+			Status:    r.Status,
+			LatencyMs: r.Latency.Milliseconds(),
+			CheckedAt: now,
+		}
+		if r.Err != nil {
+			rec.Error = r.Err.Error()
+		}
+		if err := store.SaveHealthResult(p.db, rec); err != nil {
+			slog.Warn("health.save", "source", r.Source, "check", r.Check, "error", err)
+		}
+		if r.Status != StatusOK && r.Status != StatusSkipped {
+			slog.Warn("health.check", "source", r.Source, "check", r.Check, "status", r.Status, "error", rec.Error)
+		}
+	}
+	slog.Info("health.probe.done", "providers", len(p.providers.All()), "checks", len(all))
+	return all
+}
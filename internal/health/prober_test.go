@@ -0,0 +1,186 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/internal/store"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+// fakeProvider implements search, download URL, lyrics and charts.
+type fakeProvider struct {
+	name     string
+	songs    []model.Song
+	url      string
+	urlErr   error
+	lyrics   string
+	charts   []model.Chart
+	searchID string // the song ID the last download/lyrics call received
+}
+
+func (f *fakeProvider) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return f.songs, nil
+}
+
+func (f *fakeProvider) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	f.searchID = s.ID
+	return f.url, f.urlErr
+}
+
+func (f *fakeProvider) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return f.lyrics, nil
+}
+
+func (f *fakeProvider) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return f.charts, nil
+}
+
+func (f *fakeProvider) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return f.songs, nil
+}
+
+func healthy(name string) *fakeProvider {
+	return &fakeProvider{
+		name:   name,
+		songs:  []model.Song{{Source: name, ID: "1", Name: "晴天", Artist: "周杰伦"}},
+		url:    "https://cdn.example.com/1.mp3",
+		lyrics: "[00:01.00]故事的小黄花",
+		charts: []model.Chart{{ID: "top", Name: "热歌榜"}},
+	}
+}
+
+func statuses(results []Result) map[string]string {
+	m := map[string]string{}
+	for _, r := range results {
+		m[r.Check] = r.Status
+	}
+	return m
+}
+
+func provider(t *testing.T, info registry.Info, impl any) *registry.Provider {
+	t.Helper()
+	r := registry.New()
+	r.Register(info, func() any { return impl })
+	p, _ := r.Get(info.Name)
+	return p
+}
+
+func TestProbe_Healthy(t *testing.T) {
+	f := healthy("fake")
+	p := provider(t, registry.Info{Name: "fake", Canary: registry.Canary{Keyword: "晴天"}}, f)
+	got := statuses(Probe(context.Background(), p, time.Second))
+	want := map[string]string{CheckSearch: StatusOK, CheckDownloadURL: StatusOK, CheckLyrics: StatusOK, CheckCharts: StatusOK}
+	for check, st := range want {
+		if got[check] != st {
+			t.Errorf("%s: got %q, want %q", check, got[check], st)
+		}
+	}
+	if f.searchID != "1" {
+		t.Errorf("without a canary song the first search result should be used, got %q", f.searchID)
+	}
+}
+
+func TestProbe_CanarySong(t *testing.T) {
+	f := healthy("fake")
+	p := provider(t, registry.Info{Name: "fake", Canary: registry.Canary{Keyword: "晴天", SongID: "186016"}}, f)
+	Probe(context.Background(), p, time.Second)
+	if f.searchID != "186016" {
+		t.Errorf("download check used %q, want the canary song", f.searchID)
+	}
+}
+
+func TestProbe_DetectsDrift(t *testing.T) {
+	f := healthy("fake")
+	f.songs = []model.Song{{Source: "fake", ID: "1"}} // parser lost the name
+	f.charts = nil
+	f.url = "not a url"
+	p := provider(t, registry.Info{Name: "fake", Canary: registry.Canary{Keyword: "晴天", SongID: "1"}}, f)
+	got := statuses(Probe(context.Background(), p, time.Second))
+	for _, check := range []string{CheckSearch, CheckDownloadURL, CheckCharts} {
+		if got[check] != StatusDrift {
+			t.Errorf("%s: got %q, want drift", check, got[check])
+		}
+	}
+}
+
+func TestProbe_ClassifiesErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{model.Errorf("fake", model.ErrVIPRequired, "vip"), StatusDegraded},
+		{model.Errorf("fake", model.ErrSchemaChanged, "json"), StatusDrift},
+		{errors.New("connection reset"), StatusFailed},
+	}
+	for _, tc := range cases {
+		f := healthy("fake")
+		f.urlErr = tc.err
+		p := provider(t, registry.Info{Name: "fake", Canary: registry.Canary{Keyword: "晴天"}}, f)
+		if got := statuses(Probe(context.Background(), p, time.Second))[CheckDownloadURL]; got != tc.want {
+			t.Errorf("%v: got %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestProbe_SkipsWithoutCanarySong(t *testing.T) {
+	f := healthy("fake")
+	f.songs = nil
+	p := provider(t, registry.Info{Name: "fake", Canary: registry.Canary{Keyword: "晴天"}}, f)
+	got := statuses(Probe(context.Background(), p, time.Second))
+	if got[CheckSearch] != StatusDrift || got[CheckDownloadURL] != StatusSkipped || got[CheckLyrics] != StatusSkipped {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestWorst(t *testing.T) {
+	if got := Worst(); got != StatusUnknown {
+		t.Errorf("empty: %q", got)
+	}
+	if got := Worst(StatusOK, StatusDrift, StatusFailed); got != StatusDrift {
+		t.Errorf("got %q", got)
+	}
+	if got := Worst(StatusOK, StatusSkipped); got != StatusOK {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestProber_RunOnceStoresResults(t *testing.T) {
+	db, err := store.Init(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := registry.New()
+	good := healthy("good")
+	bad := healthy("bad")
+	bad.urlErr = errors.New("connection reset")
+	r.Register(registry.Info{Name: "good", Canary: registry.Canary{Keyword: "x"}}, func() any { return good })
+	r.Register(registry.Info{Name: "bad", Canary: registry.Canary{Keyword: "x"}}, func() any { return bad })
+
+	p := NewProber(db, r, time.Hour, time.Second)
+	p.RunOnce(context.Background())
+	p.RunOnce(context.Background())
+
+	records, err := store.ListHealthRecords(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 8 {
+		t.Fatalf("expected 8 records, got %d", len(records))
+	}
+	for _, rec := range records {
+		switch {
+		case rec.Source == "bad" && rec.Check == CheckDownloadURL:
+			if rec.Status != StatusFailed || rec.ConsecutiveFailures != 2 || rec.LastSuccessAt != nil || rec.Error == "" {
+				t.Errorf("bad download: %+v", rec)
+			}
+		default:
+			if rec.Status != StatusOK || rec.ConsecutiveFailures != 0 || rec.LastSuccessAt == nil {
+				t.Errorf("%s/%s: %+v", rec.Source, rec.Check, rec)
+			}
+		}
+	}
+}
@@ -1,43 +1,57 @@
 package monitor
 
 import (
+	"context"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/guohuiyuan/music-lib/download"
+	"github.com/guohuiyuan/music-lib/internal/cache"
 	"github.com/guohuiyuan/music-lib/internal/store"
 	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
 	"gorm.io/gorm"
 )
 
-// ChartProvider supplies chart/playlist songs for a given platform.
-type ChartProvider struct {
-	GetChartSongs    func(chartID string, limit int) ([]model.Song, error)
-	GetPlaylistSongs func(playlistID string) ([]model.Song, error)
-	GetDownloadURL   func(*model.Song) (string, error)
-	GetLyrics        func(*model.Song) (string, error)
-}
-
 // Scheduler polls the database for due monitors and executes them.
 type Scheduler struct {
 	db        *gorm.DB
 	dlMgr     *download.Manager
-	providers map[string]ChartProvider
+	providers *registry.Registry
+	cache     *cache.Cache
 	stopCh    chan struct{}
 	wg        sync.WaitGroup
+
+	// ctx is cancelled by Stop so that in-flight chart fetches return promptly.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
+// fetchTimeout bounds a single chart/playlist fetch.
+const fetchTimeout = 60 * time.Second
+
 // NewScheduler creates a new chart monitor scheduler.
-func NewScheduler(db *gorm.DB, dlMgr *download.Manager, providers map[string]ChartProvider) *Scheduler {
+// Monitors resolve their platform's ChartSource / PlaylistSource from providers.
+func NewScheduler(db *gorm.DB, dlMgr *download.Manager, providers *registry.Registry) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
 		db:        db,
 		dlMgr:     dlMgr,
 		providers: providers,
 		stopCh:    make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
+// SetCache makes scheduled runs share fetched charts and playlists with the
+// API through c. Manual triggers always fetch fresh data.
+func (s *Scheduler) SetCache(c *cache.Cache) {
+	s.cache = c
+}
+
 // Start begins the polling loop. It checks for due monitors every minute.
 func (s *Scheduler) Start() {
 	s.wg.Add(1)
@@ -48,6 +62,7 @@ func (s *Scheduler) Start() {
 // Stop signals the scheduler to stop and waits for it to finish.
 func (s *Scheduler) Stop() {
 	close(s.stopCh)
+	s.cancel()
 	s.wg.Wait()
 	slog.Info("monitor.scheduler.stopped")
 }
@@ -84,22 +99,23 @@ func (s *Scheduler) tick() {
 		if m.NextRunAt.After(now) {
 			continue // not due yet
 		}
-		s.execute(m)
+		s.execute(m, false)
 	}
 }
 
-// Execute runs a single monitor. Called by the scheduler loop or manual trigger.
+// Execute runs a single monitor on a manual trigger, bypassing the response cache.
 func (s *Scheduler) Execute(monitorID uint) {
 	m, err := store.GetMonitor(s.db, monitorID)
 	if err != nil {
 		slog.Warn("monitor.execute.not_found", "id", monitorID, "error", err)
 		return
 	}
-	s.execute(m)
+	s.execute(m, true)
 }
 
-func (s *Scheduler) execute(m *store.Monitor) {
-	provider, ok := s.providers[m.Platform]
+// execute runs m. refresh bypasses cached chart and playlist contents.
+func (s *Scheduler) execute(m *store.Monitor, refresh bool) {
+	provider, ok := s.providers.Get(m.Platform)
 	if !ok {
 		slog.Warn("monitor.execute.no_provider", "platform", m.Platform, "monitor_id", m.ID)
 		return
@@ -121,16 +137,25 @@ func (s *Scheduler) execute(m *store.Monitor) {
 	// Fetch songs based on monitor type.
 	var songs []model.Song
 	var fetchErr error
+	ctx, cancel := context.WithTimeout(s.ctx, fetchTimeout)
+	defer cancel()
+	if refresh {
+		ctx = cache.WithRefresh(ctx)
+	}
 
 	if m.Type == "playlist" {
-		if provider.GetPlaylistSongs == nil {
+		source := provider.PlaylistSource()
+		if source == nil {
 			errMsg := "provider does not support GetPlaylistSongs"
 			slog.Error("monitor.execute.failed", "monitor_id", m.ID, "error", errMsg)
 			s.finishRun(run, 0, 0, 0, "failed", errMsg)
 			store.UpdateMonitorSchedule(s.db, m)
 			return
 		}
-		songs, fetchErr = provider.GetPlaylistSongs(m.ChartID)
+		key := cache.Key(cache.Playlist, m.Platform, m.ChartID)
+		songs, _, fetchErr = cache.Fetch(ctx, s.cache, cache.Playlist, key, func(ctx context.Context) ([]model.Song, error) {
+			return source.GetPlaylistSongsContext(ctx, m.ChartID)
+		})
 		if fetchErr == nil {
 			slog.Info("monitor.playlist.fetched", "monitor_id", m.ID, "total", len(songs))
 			// Apply TopN limit after fetch (GetPlaylistSongs has no limit param).
@@ -140,7 +165,18 @@ func (s *Scheduler) execute(m *store.Monitor) {
 		}
 	} else {
 		// type == "chart" or empty (legacy compatibility)
-		songs, fetchErr = provider.GetChartSongs(m.ChartID, m.TopN)
+		charts := provider.ChartSource()
+		if charts == nil {
+			errMsg := "provider does not support charts"
+			slog.Error("monitor.execute.failed", "monitor_id", m.ID, "error", errMsg)
+			s.finishRun(run, 0, 0, 0, "failed", errMsg)
+			store.UpdateMonitorSchedule(s.db, m)
+			return
+		}
+		key := cache.Key(cache.Charts, m.Platform, m.ChartID, strconv.Itoa(m.TopN))
+		songs, _, fetchErr = cache.Fetch(ctx, s.cache, cache.Charts, key, func(ctx context.Context) ([]model.Song, error) {
+			return charts.GetChartSongsContext(ctx, m.ChartID, m.TopN)
+		})
 	}
 
 	if fetchErr != nil {
@@ -174,13 +210,8 @@ func (s *Scheduler) execute(m *store.Monitor) {
 
 	if len(newSongs) > 0 && s.dlMgr != nil {
 		batchName := m.Name + " - " + time.Now().Format("2006-01-02")
-		s.dlMgr.EnqueueBatch(
-			newSongs,
-			batchName,
-			m.Platform,
-			provider.GetDownloadURL,
-			provider.GetLyrics,
-		)
+		// Chart batches run behind anything a user queued by hand.
+		s.dlMgr.EnqueueBatchWithPriority(newSongs, batchName, m.Platform, download.PriorityLow)
 	}
 
 	s.finishRun(run, run.TotalFetched, run.NewQueued, run.Skipped, "done", "")
@@ -0,0 +1,26 @@
+package replay
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestScrubURL(t *testing.T) {
+	u, _ := url.Parse("https://u.y.qq.com/cgi-bin/musicu.fcg?format=json&uin=12345&Sign=abc")
+	want := "https://u.y.qq.com/cgi-bin/musicu.fcg?Sign=SCRUBBED&format=json&uin=SCRUBBED"
+	if got := scrubURL(u); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	u, _ = url.Parse("https://music.163.com/api/search?s=a%20b&offset=0")
+	if got := scrubURL(u); got != u.String() {
+		t.Errorf("URL without secrets changed: %s", got)
+	}
+}
+
+func TestScrubCookies(t *testing.T) {
+	got := scrubCookies([]string{"MUSIC_U=secret; Path=/; HttpOnly", "__csrf=abc"})
+	if want := "MUSIC_U=SCRUBBED, __csrf=SCRUBBED"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,360 @@
+// Package resolve turns pasted share text into provider content without the
+// caller knowing which platform a link belongs to.
+//
+// It pulls URLs out of free text ("分享xxx的单曲《...》https://163cn.tv/abc
+// (@网易云音乐)"), follows short links (163cn.tv, c6.y.qq.com, b23.tv, ...),
+// classifies each link as a song, playlist, album or chart of one provider,
+// and hands it to that provider's parser.
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Kind is what a link points at.
+type Kind string
+
+const (
+	KindSong     Kind = "song"
+	KindPlaylist Kind = "playlist"
+	KindAlbum    Kind = "album"
+	KindChart    Kind = "chart"
+)
+
+// Target is a classified link.
+type Target struct {
+	Source string `json:"source"`
+	Kind   Kind   `json:"kind"`
+	ID     string `json:"id"`
+	// URL is the link in the form the provider's parser expects.
+	URL string `json:"url"`
+}
+
+// rule maps links on hosts (matched by suffix) whose URL matches re to a
+// target. The first group of re is the ID; link formats it into Target.URL,
+// or the link is passed through unchanged when link is empty.
+type rule struct {
+	source string
+	hosts  []string
+	kind   Kind
+	re     *regexp.Regexp
+	link   string
+}
+
+// rules are tried in order; more specific hosts and paths come first.
+var rules = []rule{
+	{"fivesing", []string{"5sing.kugou.com"}, KindPlaylist, regexp.MustCompile(`5sing\.kugou\.com/((?:\d+/)?dj/\w+)\.html`), "http://5sing.kugou.com/%s.html"},
+	{"fivesing", []string{"5sing.kugou.com"}, KindSong, regexp.MustCompile(`5sing\.kugou\.com/(?:m/detail/)?((?:yc|fc|bz)/\d+)`), "http://5sing.kugou.com/%s.html"},
+
+	{"netease", []string{"music.163.com"}, KindChart, regexp.MustCompile(`toplist\?(?:.*&)?id=(\d+)`), ""},
+	{"netease", []string{"music.163.com"}, KindPlaylist, regexp.MustCompile(`playlist(?:\?(?:.*&)?id=|/)(\d+)`), "https://music.163.com/#/playlist?id=%s"},
+	{"netease", []string{"music.163.com"}, KindAlbum, regexp.MustCompile(`album(?:\?(?:.*&)?id=|/)(\d+)`), "https://music.163.com/#/album?id=%s"},
+	{"netease", []string{"music.163.com"}, KindSong, regexp.MustCompile(`song(?:\?(?:.*&)?id=|/)(\d+)`), "https://music.163.com/#/song?id=%s"},
+
+	{"qq", []string{"y.qq.com"}, KindChart, regexp.MustCompile(`toplist(?:/|\.html\?(?:.*&)?id=)(\d+)|(?i:topid)=(\d+)`), ""},
+	{"qq", []string{"y.qq.com"}, KindPlaylist, regexp.MustCompile(`playlist/(\d+)|taoge\.html\?(?:.*&)?id=(\d+)`), "https://y.qq.com/n/ryqq/playlist/%s"},
+	{"qq", []string{"y.qq.com"}, KindAlbum, regexp.MustCompile(`albumDetail/(\w+)|(?i:albummid)=(\w+)`), "https://y.qq.com/n/ryqq/albumDetail/%s"},
+	{"qq", []string{"y.qq.com"}, KindSong, regexp.MustCompile(`songDetail/(\w+)|(?i:songmid)=(\w+)`), "https://y.qq.com/n/ryqq/songDetail/%s"},
+
+	{"kugou", []string{"kugou.com"}, KindChart, regexp.MustCompile(`rank/home/\d+-(\d+)\.html|(?i:rankid)=(\d+)`), ""},
+	{"kugou", []string{"kugou.com"}, KindPlaylist, regexp.MustCompile(`special/single/(\d+)\.html`), "https://www.kugou.com/yy/special/single/%s.html"},
+	{"kugou", []string{"kugou.com"}, KindAlbum, regexp.MustCompile(`album/(?:single/)?(\d+)\.html`), "https://www.kugou.com/album/%s.html"},
+	{"kugou", []string{"kugou.com"}, KindSong, regexp.MustCompile(`(?i:hash)=([0-9a-fA-F]{32})`), "https://www.kugou.com/song/#hash=%s"},
+
+	{"kuwo", []string{"kuwo.cn"}, KindChart, regexp.MustCompile(`rankList/(\d+)`), ""},
+	{"kuwo", []string{"kuwo.cn"}, KindPlaylist, regexp.MustCompile(`playlist_detail/(\d+)`), "http://www.kuwo.cn/playlist_detail/%s"},
+	{"kuwo", []string{"kuwo.cn"}, KindAlbum, regexp.MustCompile(`album_detail/(\d+)`), "http://www.kuwo.cn/album_detail/%s"},
+	{"kuwo", []string{"kuwo.cn"}, KindSong, regexp.MustCompile(`(?:play_detail|yinyue)/(\d+)`), "http://www.kuwo.cn/play_detail/%s"},
+
+	{"migu", []string{"migu.cn"}, KindPlaylist, regexp.MustCompile(`music/playlist/(\d+)`), "https://music.migu.cn/v3/music/playlist/%s"},
+	{"migu", []string{"migu.cn"}, KindAlbum, regexp.MustCompile(`music/album/(\d+)`), "https://music.migu.cn/v3/music/album/%s"},
+	{"migu", []string{"migu.cn"}, KindSong, regexp.MustCompile(`music/song/(\d+)`), "https://music.migu.cn/v3/music/song/%s"},
+
+	{"qianqian", []string{"91q.com"}, KindPlaylist, regexp.MustCompile(`songlist/(\d+)`), ""},
+	{"qianqian", []string{"91q.com"}, KindAlbum, regexp.MustCompile(`album/(\w+)`), ""},
+	{"qianqian", []string{"91q.com"}, KindSong, regexp.MustCompile(`song/(\w+)`), "https://music.91q.com/song/%s"},
+
+	// Multi-part videos are still songs; the parser keeps the ?p= page.
+	{"bilibili", []string{"bilibili.com"}, KindSong, regexp.MustCompile(`(BV[0-9A-Za-z]{10})`), ""},
+
+	{"soda", []string{"qishui.com", "douyin.com"}, KindPlaylist, regexp.MustCompile(`playlist(?:/|_id=)(\d+)`), "https://www.qishui.com/playlist/%s"},
+	{"soda", []string{"qishui.com", "douyin.com"}, KindSong, regexp.MustCompile(`track(?:/|_id=)(\d+)`), "https://www.qishui.com/track/%s"},
+
+	{"jamendo", []string{"jamendo.com"}, KindPlaylist, regexp.MustCompile(`playlist/(\d+)`), ""},
+	{"jamendo", []string{"jamendo.com"}, KindAlbum, regexp.MustCompile(`album/(\d+)`), ""},
+	{"jamendo", []string{"jamendo.com"}, KindSong, regexp.MustCompile(`track/(\d+)`), "https://www.jamendo.com/track/%s"},
+
+	{"joox", []string{"joox.com"}, KindPlaylist, regexp.MustCompile(`playlist/([\w-]+)`), ""},
+	{"joox", []string{"joox.com"}, KindAlbum, regexp.MustCompile(`album/([\w-]+)`), ""},
+	{"joox", []string{"joox.com"}, KindSong, regexp.MustCompile(`single/([\w-]+)`), "https://www.joox.com/hk/single/%s"},
+}
+
+// shortHosts only redirect to the real page.
+var shortHosts = []string{
+	"163cn.tv", "163cn.link", "url.163.com",
+	"c6.y.qq.com", "c.y.qq.com",
+	"b23.tv",
+	"t.kugou.com",
+	"m.kuwo.cn",
+	"c.migu.cn",
+	"qishui.douyin.com", "v.douyin.com",
+}
+
+var reURL = regexp.MustCompile(`https?://[^\s"'<>，。；！？、（）【】《》「」]+`)
+
+// ExtractURLs returns the distinct URLs in text, in order of appearance.
+// Trailing punctuation that share text puts right after a link is dropped.
+func ExtractURLs(text string) []string {
+	var urls []string
+	seen := map[string]bool{}
+	for _, u := range reURL.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?)]}")
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+func hostMatches(host string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsShortLink reports whether link is on a known short-link host.
+func IsShortLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && hostMatches(strings.ToLower(u.Hostname()), shortHosts)
+}
+
+// Classify identifies which provider and kind of content link points at,
+// without any network access. Short links have to be expanded first.
+func Classify(link string) (Target, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return Target{}, false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, r := range rules {
+		if !hostMatches(host, r.hosts) {
+			continue
+		}
+		m := r.re.FindStringSubmatch(link)
+		if m == nil {
+			continue
+		}
+		id := ""
+		for _, g := range m[1:] {
+			if g != "" {
+				id = g
+				break
+			}
+		}
+		t := Target{Source: r.source, Kind: r.kind, ID: id, URL: link}
+		if r.link != "" {
+			t.URL = fmt.Sprintf(r.link, id)
+		}
+		return t, true
+	}
+	return Target{}, false
+}
+
+// Item is the outcome of resolving one link. Exactly one of Song, Playlist,
+// Album or Chart is set on success; Songs holds the tracks of the latter three.
+type Item struct {
+	Link     string          `json:"link"`          // as found in the input
+	URL      string          `json:"url,omitempty"` // after following short links
+	Source   string          `json:"source,omitempty"`
+	Kind     Kind            `json:"kind,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Song     *model.Song     `json:"song,omitempty"`
+	Playlist *model.Playlist `json:"playlist,omitempty"`
+	Album    *model.Album    `json:"album,omitempty"`
+	Chart    *model.Chart    `json:"chart,omitempty"`
+	Songs    []model.Song    `json:"songs,omitempty"`
+	Error    string          `json:"error,omitempty"`
+
+	// Err is the error behind Error, for mapping to a status code.
+	Err error `json:"-"`
+}
+
+// ExpandTimeout bounds following one short link.
+const ExpandTimeout = 10 * time.Second
+
+// maxPageBytes is how much of a short link's landing page is scanned for
+// the real URL when the redirect ends on an unrecognised page.
+const maxPageBytes = 256 << 10
+
+// Resolver classifies links and dispatches them to registered providers.
+type Resolver struct {
+	providers *registry.Registry
+	client    *utils.Client
+}
+
+// New creates a resolver that follows short links through client
+// (nil means utils.DefaultClient).
+func New(providers *registry.Registry, client *utils.Client) *Resolver {
+	return &Resolver{providers: providers, client: client}
+}
+
+// Identify classifies link, following it first when it is a short link.
+// It returns the expanded URL alongside the target.
+func (r *Resolver) Identify(ctx context.Context, link string) (Target, string, error) {
+	if t, ok := Classify(link); ok {
+		return t, link, nil
+	}
+	if !IsShortLink(link) {
+		return Target{}, link, model.Errorf("", model.ErrInvalidInput, "unrecognised link %q", link)
+	}
+	final, err := r.expand(ctx, link)
+	if err != nil {
+		return Target{}, link, err
+	}
+	if t, ok := Classify(final); ok {
+		return t, final, nil
+	}
+	return Target{}, final, model.Errorf("", model.ErrInvalidInput, "short link %q leads to unrecognised page %q", link, final)
+}
+
+// expand follows the redirects of a short link. Some short links land on a
+// page that redirects with JavaScript; the first recognisable URL on that
+// page is used then.
+func (r *Resolver) expand(ctx context.Context, link string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ExpandTimeout)
+	defer cancel()
+	resp, err := r.client.GetRawContext(ctx, link)
+	if err != nil {
+		return "", fmt.Errorf("follow short link: %w", err)
+	}
+	defer resp.Body.Close()
+
+	final := resp.Request.URL.String()
+	if _, ok := Classify(final); ok {
+		return final, nil
+	}
+	page, _ := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	text := strings.ReplaceAll(string(page), `\/`, `/`)
+	for _, u := range ExtractURLs(text) {
+		if _, ok := Classify(u); ok {
+			return u, nil
+		}
+	}
+	return final, nil
+}
+
+// Resolve identifies link and fetches its content from the provider.
+func (r *Resolver) Resolve(ctx context.Context, link string) Item {
+	item := Item{Link: link}
+	t, final, err := r.Identify(ctx, link)
+	item.URL = final
+	if err != nil {
+		return item.fail(err)
+	}
+	item.Source, item.Kind, item.ID = t.Source, t.Kind, t.ID
+
+	p, ok := r.providers.Get(t.Source)
+	if !ok {
+		return item.fail(model.Errorf(t.Source, model.ErrInvalidInput, "provider %s is not available", t.Source))
+	}
+	unsupported := func() Item {
+		return item.fail(model.Errorf(t.Source, model.ErrInvalidInput, "%s links are not supported for %s", t.Kind, t.Source))
+	}
+
+	switch t.Kind {
+	case KindSong:
+		parser := p.SongParser()
+		if parser == nil {
+			return unsupported()
+		}
+		item.Song, err = parser.ParseContext(ctx, t.URL)
+	case KindPlaylist:
+		if parser := p.PlaylistParser(); parser != nil {
+			item.Playlist, item.Songs, err = parser.ParsePlaylistContext(ctx, t.URL)
+			break
+		}
+		// Without a link parser the songs can still be listed by ID.
+		src := p.PlaylistSource()
+		if src == nil {
+			return unsupported()
+		}
+		item.Playlist = &model.Playlist{ID: t.ID, Source: t.Source, Link: t.URL}
+		item.Songs, err = src.GetPlaylistSongsContext(ctx, t.ID)
+	case KindAlbum:
+		src := p.AlbumSource()
+		if src == nil {
+			return unsupported()
+		}
+		item.Album, err = src.GetAlbumContext(ctx, t.ID)
+	case KindChart:
+		src := p.ChartSource()
+		if src == nil {
+			return unsupported()
+		}
+		item.Chart = &model.Chart{ID: t.ID}
+		if charts, err := src.GetChartsContext(ctx); err == nil {
+			for _, ch := range charts {
+				if ch.ID == t.ID {
+					item.Chart.Name = ch.Name
+				}
+			}
+		}
+		item.Songs, err = src.GetChartSongsContext(ctx, t.ID, 0)
+	}
+	if err != nil {
+		return item.fail(err)
+	}
+	return item
+}
+
+func (it Item) fail(err error) Item {
+	it.Err, it.Error = err, err.Error()
+	return it
+}
+
+// ResolveText resolves every URL found in text, at most concurrency at a
+// time. Items are in the order the links appear.
+func (r *Resolver) ResolveText(ctx context.Context, text string, concurrency int) []Item {
+	return r.ResolveLinks(ctx, ExtractURLs(text), concurrency)
+}
+
+// ResolveLinks resolves links, at most concurrency at a time, keeping their order.
+func (r *Resolver) ResolveLinks(ctx context.Context, links []string, concurrency int) []Item {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	items := make([]Item, len(links))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Add(1)
+		go func(i int, link string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				items[i] = Item{Link: link}.fail(ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+			items[i] = r.Resolve(ctx, link)
+		}(i, link)
+	}
+	wg.Wait()
+	return items
+}
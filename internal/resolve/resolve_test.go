@@ -0,0 +1,147 @@
+package resolve
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestExtractURLs(t *testing.T) {
+	text := "分享周杰伦的单曲《晴天》https://163cn.tv/abc (@网易云音乐)，还有这个：https://y.qq.com/n/ryqq/playlist/8825279434。https://163cn.tv/abc"
+	got := ExtractURLs(text)
+	want := []string{"https://163cn.tv/abc", "https://y.qq.com/n/ryqq/playlist/8825279434"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		link   string
+		source string
+		kind   Kind
+		id     string
+		url    string
+	}{
+		{"https://music.163.com/#/song?id=186016", "netease", KindSong, "186016", "https://music.163.com/#/song?id=186016"},
+		{"https://y.music.163.com/m/song?app_version=8.9&id=186016&userid=1", "netease", KindSong, "186016", "https://music.163.com/#/song?id=186016"},
+		{"https://music.163.com/playlist?id=3778678&userid=1", "netease", KindPlaylist, "3778678", "https://music.163.com/#/playlist?id=3778678"},
+		{"https://music.163.com/#/album?id=18918", "netease", KindAlbum, "18918", "https://music.163.com/#/album?id=18918"},
+		{"https://music.163.com/#/discover/toplist?id=19723756", "netease", KindChart, "19723756", "https://music.163.com/#/discover/toplist?id=19723756"},
+		{"https://i.y.qq.com/v8/playsong.html?songid=97773&songmid=0039MnYb0qxYhV&type=0", "qq", KindSong, "0039MnYb0qxYhV", "https://y.qq.com/n/ryqq/songDetail/0039MnYb0qxYhV"},
+		{"https://i.y.qq.com/n2/m/share/details/taoge.html?platform=11&id=8825279434", "qq", KindPlaylist, "8825279434", "https://y.qq.com/n/ryqq/playlist/8825279434"},
+		{"https://y.qq.com/n/ryqq/albumDetail/000MkMni19ClKG", "qq", KindAlbum, "000MkMni19ClKG", "https://y.qq.com/n/ryqq/albumDetail/000MkMni19ClKG"},
+		{"https://y.qq.com/n/ryqq/toplist/26", "qq", KindChart, "26", "https://y.qq.com/n/ryqq/toplist/26"},
+		{"https://www.kugou.com/song/#hash=7F5A0B2C3D4E5F60718293A4B5C6D7E8&album_id=1", "kugou", KindSong, "7F5A0B2C3D4E5F60718293A4B5C6D7E8", "https://www.kugou.com/song/#hash=7F5A0B2C3D4E5F60718293A4B5C6D7E8"},
+		{"https://www.kugou.com/yy/special/single/546903.html", "kugou", KindPlaylist, "546903", "https://www.kugou.com/yy/special/single/546903.html"},
+		{"https://www.kugou.com/yy/rank/home/1-8888.html", "kugou", KindChart, "8888", "https://www.kugou.com/yy/rank/home/1-8888.html"},
+		{"http://5sing.kugou.com/yc/3431542.html", "fivesing", KindSong, "yc/3431542", "http://5sing.kugou.com/yc/3431542.html"},
+		{"http://5sing.kugou.com/12345/dj/abcdef.html", "fivesing", KindPlaylist, "12345/dj/abcdef", "http://5sing.kugou.com/12345/dj/abcdef.html"},
+		{"https://m.kuwo.cn/yinyue/228908", "kuwo", KindSong, "228908", "http://www.kuwo.cn/play_detail/228908"},
+		{"http://www.kuwo.cn/playlist_detail/1082685103", "kuwo", KindPlaylist, "1082685103", "http://www.kuwo.cn/playlist_detail/1082685103"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD?p=2", "bilibili", KindSong, "BV1xx411c7mD", "https://www.bilibili.com/video/BV1xx411c7mD?p=2"},
+		{"https://music.migu.cn/v3/music/song/60054701923?from=share", "migu", KindSong, "60054701923", "https://music.migu.cn/v3/music/song/60054701923"},
+		{"https://music.91q.com/songlist/295822", "qianqian", KindPlaylist, "295822", "https://music.91q.com/songlist/295822"},
+		{"https://music.91q.com/song/T10038958536", "qianqian", KindSong, "T10038958536", "https://music.91q.com/song/T10038958536"},
+		{"https://music.douyin.com/qishui/share/track?track_id=7123456789", "soda", KindSong, "7123456789", "https://www.qishui.com/track/7123456789"},
+		{"https://www.jamendo.com/track/1886257/x", "jamendo", KindSong, "1886257", "https://www.jamendo.com/track/1886257"},
+		{"https://www.joox.com/hk/single/abc-DEF_1", "joox", KindSong, "abc-DEF_1", "https://www.joox.com/hk/single/abc-DEF_1"},
+	}
+	for _, tc := range cases {
+		got, ok := Classify(tc.link)
+		want := Target{Source: tc.source, Kind: tc.kind, ID: tc.id, URL: tc.url}
+		if !ok || got != want {
+			t.Errorf("%s:\n got %+v (%v)\nwant %+v", tc.link, got, ok, want)
+		}
+	}
+
+	for _, link := range []string{"https://163cn.tv/abc", "https://example.com/song?id=1", "not a url"} {
+		if got, ok := Classify(link); ok {
+			t.Errorf("%s: unexpectedly classified as %+v", link, got)
+		}
+	}
+}
+
+// fakeTransport serves short-link redirects and landing pages by host.
+type fakeTransport map[string]func(*http.Request) *http.Response
+
+func (f fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h, ok := f[req.URL.Host]; ok {
+		return h(req), nil
+	}
+	return respond(req, http.StatusOK, "<html></html>", nil), nil
+}
+
+func respond(req *http.Request, status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func redirect(to string) func(*http.Request) *http.Response {
+	return func(req *http.Request) *http.Response {
+		return respond(req, http.StatusFound, "", http.Header{"Location": {to}})
+	}
+}
+
+type fakeParser struct{ links chan string }
+
+func (f fakeParser) ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	f.links <- link
+	return &model.Song{Source: "netease", ID: "186016", Name: "晴天"}, nil
+}
+
+func TestResolver_FollowsShortLinks(t *testing.T) {
+	client := utils.NewClient(utils.WithTransport(fakeTransport{
+		"163cn.tv": redirect("https://y.music.163.com/m/song?id=186016&uct=x"),
+		// Lands on a page that redirects with JavaScript.
+		"c6.y.qq.com": func(req *http.Request) *http.Response {
+			return respond(req, http.StatusOK, `<script>location.href="https:\/\/i.y.qq.com\/v8\/playsong.html?songmid=0039MnYb0qxYhV"</script>`, nil)
+		},
+		"b23.tv": redirect("https://www.bilibili.com/read/cv1"),
+	}))
+
+	parsed := make(chan string, 1)
+	reg := registry.New()
+	reg.Register(registry.Info{Name: "netease"}, func() any { return fakeParser{links: parsed} })
+	r := New(reg, client)
+
+	items := r.ResolveText(context.Background(),
+		"分享晴天 https://163cn.tv/abc (@网易云音乐) https://c6.y.qq.com/base/fcgi-bin/u?__=x https://b23.tv/y https://example.com/z", 2)
+	if len(items) != 4 {
+		t.Fatalf("got %d items", len(items))
+	}
+
+	ne := items[0]
+	if ne.Err != nil || ne.Source != "netease" || ne.Kind != KindSong || ne.Song == nil || ne.Song.Name != "晴天" {
+		t.Errorf("netease short link: %+v", ne)
+	}
+	if got := <-parsed; got != "https://music.163.com/#/song?id=186016" {
+		t.Errorf("parser got %q", got)
+	}
+
+	qq := items[1]
+	if qq.Source != "qq" || qq.ID != "0039MnYb0qxYhV" || model.KindOf(qq.Err) != model.ErrInvalidInput {
+		t.Errorf("qq short link should be identified but unavailable in this registry: %+v", qq)
+	}
+	for _, it := range items[2:] {
+		if model.KindOf(it.Err) != model.ErrInvalidInput || it.Error == "" {
+			t.Errorf("%s: expected an invalid input error, got %+v", it.Link, it)
+		}
+	}
+	if items[2].URL != "https://www.bilibili.com/read/cv1" {
+		t.Errorf("expanded URL not reported: %q", items[2].URL)
+	}
+}
@@ -0,0 +1,201 @@
+// Package search fans a keyword out to every registered provider and merges
+// the results into one ranked list.
+package search
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+// DefaultTimeout bounds each provider's search when the caller gives none.
+const DefaultTimeout = 8 * time.Second
+
+// Options controls an aggregated search.
+type Options struct {
+	// Sources restricts the fan-out to these providers; empty means all.
+	Sources []string
+	// Timeout is the per-provider deadline. Zero uses DefaultTimeout.
+	Timeout time.Duration
+	// Limit is the page size requested from providers that support paging.
+	// Zero keeps each provider's default.
+	Limit int
+}
+
+// SourceHit is one provider's copy of a merged song.
+type SourceHit struct {
+	Source  string     `json:"source"`
+	Quality string     `json:"quality"` // Song.QualityString(); empty when the search result carries no format
+	Bitrate int        `json:"bitrate"`
+	Song    model.Song `json:"song"`
+}
+
+// Result is a song merged across providers.
+type Result struct {
+	Name     string      `json:"name"`
+	Artist   string      `json:"artist"`
+	Album    string      `json:"album"`
+	Duration int         `json:"duration"`
+	Cover    string      `json:"cover"`
+	Sources  []SourceHit `json:"sources"` // best quality first
+
+	rank int // best position of this song in any provider's own result list
+}
+
+// Response is the outcome of an aggregated search. Errors maps provider name
+// to the error message for providers that failed or timed out; they do not
+// fail the whole search.
+type Response struct {
+	Results []Result          `json:"results"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// Aggregate searches all selected providers concurrently and merges the
+// results using model.IsSameSong, the same matching the download fallback uses.
+func Aggregate(ctx context.Context, providers *registry.Registry, keyword string, opts Options) Response {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	targets := selectProviders(providers, opts.Sources)
+	lists := make([][]model.Song, len(targets))
+	errs := make([]error, len(targets))
+
+	var wg sync.WaitGroup
+	for i, p := range targets {
+		wg.Add(1)
+		go func(i int, p *registry.Provider) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			lists[i], errs[i] = searchOne(pctx, p, keyword, opts.Limit)
+		}(i, p)
+	}
+	wg.Wait()
+
+	resp := Response{}
+	for i, p := range targets {
+		if errs[i] != nil {
+			if resp.Errors == nil {
+				resp.Errors = make(map[string]string)
+			}
+			resp.Errors[p.Name] = errs[i].Error()
+		}
+	}
+	resp.Results = Merge(lists)
+	return resp
+}
+
+func selectProviders(providers *registry.Registry, sources []string) []*registry.Provider {
+	if len(sources) == 0 {
+		var out []*registry.Provider
+		for _, p := range providers.All() {
+			if p.Searcher() != nil {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	var out []*registry.Provider
+	for _, name := range sources {
+		if p, ok := providers.Get(name); ok && p.Searcher() != nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func searchOne(ctx context.Context, p *registry.Provider, keyword string, limit int) ([]model.Song, error) {
+	if limit > 0 {
+		if paged := p.PagedSearcher(); paged != nil {
+			page, err := paged.SearchPageContext(ctx, keyword, 1, limit)
+			if err != nil {
+				return nil, err
+			}
+			return page.Items, nil
+		}
+	}
+	return p.Searcher().SearchContext(ctx, keyword)
+}
+
+// Merge folds per-provider result lists into one ranked list. Songs that
+// model.IsSameSong considers equal share an entry; a provider contributes at
+// most one hit per entry (its most relevant one).
+//
+// Ranking: songs found on more providers come first, then songs that ranked
+// higher in any provider's own list, then higher best bitrate.
+func Merge(lists [][]model.Song) []Result {
+	results := []Result{}
+	for _, list := range lists {
+		for pos, song := range list {
+			idx := -1
+			for i := range results {
+				if model.IsSameSong(results[i].Sources[0].Song, song) {
+					idx = i
+					break
+				}
+			}
+			hit := SourceHit{Source: song.Source, Quality: song.QualityString(), Bitrate: song.Bitrate, Song: song}
+			if idx < 0 {
+				results = append(results, Result{
+					Name:     song.Name,
+					Artist:   song.Artist,
+					Album:    song.Album,
+					Duration: song.Duration,
+					Cover:    song.Cover,
+					Sources:  []SourceHit{hit},
+					rank:     pos,
+				})
+				continue
+			}
+			r := &results[idx]
+			if r.hasSource(song.Source) {
+				continue
+			}
+			r.Sources = append(r.Sources, hit)
+			if pos < r.rank {
+				r.rank = pos
+			}
+			if r.Cover == "" {
+				r.Cover = song.Cover
+			}
+			if r.Album == "" {
+				r.Album = song.Album
+			}
+			if r.Duration == 0 {
+				r.Duration = song.Duration
+			}
+		}
+	}
+
+	for i := range results {
+		sort.SliceStable(results[i].Sources, func(a, b int) bool {
+			return results[i].Sources[a].Bitrate > results[i].Sources[b].Bitrate
+		})
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		ra, rb := results[a], results[b]
+		if len(ra.Sources) != len(rb.Sources) {
+			return len(ra.Sources) > len(rb.Sources)
+		}
+		if ra.rank != rb.rank {
+			return ra.rank < rb.rank
+		}
+		return ra.Sources[0].Bitrate > rb.Sources[0].Bitrate
+	})
+	return results
+}
+
+func (r *Result) hasSource(source string) bool {
+	for _, h := range r.Sources {
+		if h.Source == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/registry"
+)
+
+type fakeSearcher struct {
+	songs []model.Song
+	err   error
+	delay time.Duration
+}
+
+func (f fakeSearcher) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return f.songs, f.err
+}
+
+func song(source, name, artist string, bitrate int, ext string) model.Song {
+	return model.Song{Source: source, Name: name, Artist: artist, Bitrate: bitrate, Ext: ext}
+}
+
+func TestMerge_GroupsDuplicatesAndRanks(t *testing.T) {
+	lists := [][]model.Song{
+		{song("kugou", "晴天", "周杰伦", 128, "mp3"), song("kugou", "七里香", "周杰伦", 128, "mp3")},
+		{song("qq", "七里香", "周杰伦", 320, "mp3"), song("qq", "晴天 (Live)", "周杰伦", 900, "flac")},
+		{song("kuwo", "七里香", "周杰伦", 128, "mp3")},
+	}
+	results := Merge(lists)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 merged songs, got %d: %+v", len(results), results)
+	}
+	if results[0].Name != "七里香" || len(results[0].Sources) != 3 {
+		t.Fatalf("song on most sources should rank first, got %+v", results[0])
+	}
+	if results[0].Sources[0].Source != "qq" || results[0].Sources[0].Quality != "320kbps MP3" {
+		t.Errorf("sources should be ordered by quality, got %+v", results[0].Sources)
+	}
+	if results[1].Sources[0].Quality != "FLAC" {
+		t.Errorf("expected FLAC hit first for 晴天, got %+v", results[1].Sources)
+	}
+}
+
+func TestMerge_OneHitPerSource(t *testing.T) {
+	lists := [][]model.Song{
+		{song("kugou", "晴天", "周杰伦", 128, "mp3"), song("kugou", "晴天（Live）", "周杰伦", 320, "mp3")},
+	}
+	results := Merge(lists)
+	if len(results) != 1 || len(results[0].Sources) != 1 {
+		t.Fatalf("expected a single hit, got %+v", results)
+	}
+	if results[0].Sources[0].Bitrate != 128 {
+		t.Errorf("the provider's first (most relevant) hit should be kept")
+	}
+}
+
+func TestAggregate_TimeoutAndErrorsDoNotFailSearch(t *testing.T) {
+	r := registry.New()
+	r.Register(registry.Info{Name: "fast"}, func() any {
+		return fakeSearcher{songs: []model.Song{song("fast", "晴天", "周杰伦", 128, "mp3")}}
+	})
+	r.Register(registry.Info{Name: "slow"}, func() any {
+		return fakeSearcher{delay: time.Second}
+	})
+	r.Register(registry.Info{Name: "broken"}, func() any {
+		return fakeSearcher{err: errors.New("boom")}
+	})
+
+	start := time.Now()
+	resp := Aggregate(context.Background(), r, "晴天", Options{Timeout: 50 * time.Millisecond})
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("slow provider should be cut off by the per-provider timeout")
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected one result, got %+v", resp.Results)
+	}
+	if resp.Errors["slow"] == "" || resp.Errors["broken"] != "boom" {
+		t.Errorf("unexpected errors: %+v", resp.Errors)
+	}
+	if _, ok := resp.Errors["fast"]; ok {
+		t.Error("fast provider should not report an error")
+	}
+}
+
+func TestAggregate_SourcesFilter(t *testing.T) {
+	r := registry.New()
+	r.Register(registry.Info{Name: "a"}, func() any {
+		return fakeSearcher{songs: []model.Song{song("a", "x", "y", 0, "")}}
+	})
+	r.Register(registry.Info{Name: "b"}, func() any {
+		return fakeSearcher{err: errors.New("should not be called")}
+	})
+	resp := Aggregate(context.Background(), r, "x", Options{Sources: []string{"a"}})
+	if len(resp.Errors) != 0 || len(resp.Results) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
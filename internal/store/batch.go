@@ -57,26 +57,30 @@ func ListBatchNames(db *gorm.DB) (map[string]string, error) {
 
 // BatchWithStats represents a batch with aggregated task counts from the DB.
 type BatchWithStats struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Total   int    `json:"total"`
-	Done    int    `json:"done"`
-	Failed  int    `json:"failed"`
-	Running int    `json:"running"`
-	Pending int    `json:"pending"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Done      int    `json:"done"`
+	Failed    int    `json:"failed"`
+	Running   int    `json:"running"`
+	Pending   int    `json:"pending"`
+	Paused    int    `json:"paused"`
+	Cancelled int    `json:"cancelled"`
 }
 
 // ListBatchesWithStats aggregates task counts per batch from the DB.
 // This is the persistent source of truth, surviving restarts.
 func ListBatchesWithStats(db *gorm.DB) ([]BatchWithStats, error) {
 	type row struct {
-		BatchID string
-		Name    string
-		Total   int
-		Done    int
-		Failed  int
-		Running int
-		Pending int
+		BatchID   string
+		Name      string
+		Total     int
+		Done      int
+		Failed    int
+		Running   int
+		Pending   int
+		Paused    int
+		Cancelled int
 	}
 	var rows []row
 	err := db.Raw(`
@@ -87,7 +91,9 @@ func ListBatchesWithStats(db *gorm.DB) ([]BatchWithStats, error) {
 			SUM(CASE WHEN t.status = 'done' THEN 1 ELSE 0 END) AS done,
 			SUM(CASE WHEN t.status = 'failed' THEN 1 ELSE 0 END) AS failed,
 			SUM(CASE WHEN t.status = 'running' THEN 1 ELSE 0 END) AS running,
-			SUM(CASE WHEN t.status = 'pending' THEN 1 ELSE 0 END) AS pending
+			SUM(CASE WHEN t.status = 'pending' THEN 1 ELSE 0 END) AS pending,
+			SUM(CASE WHEN t.status = 'paused' THEN 1 ELSE 0 END) AS paused,
+			SUM(CASE WHEN t.status = 'cancelled' THEN 1 ELSE 0 END) AS cancelled
 		FROM download_batches b
 		LEFT JOIN download_tasks t ON t.batch_id = b.id
 		GROUP BY b.id
@@ -99,13 +105,15 @@ func ListBatchesWithStats(db *gorm.DB) ([]BatchWithStats, error) {
 	result := make([]BatchWithStats, len(rows))
 	for i, r := range rows {
 		result[i] = BatchWithStats{
-			ID:      r.BatchID,
-			Name:    r.Name,
-			Total:   r.Total,
-			Done:    r.Done,
-			Failed:  r.Failed,
-			Running: r.Running,
-			Pending: r.Pending,
+			ID:        r.BatchID,
+			Name:      r.Name,
+			Total:     r.Total,
+			Done:      r.Done,
+			Failed:    r.Failed,
+			Running:   r.Running,
+			Pending:   r.Pending,
+			Paused:    r.Paused,
+			Cancelled: r.Cancelled,
 		}
 	}
 	return result, nil
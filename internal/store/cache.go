@@ -0,0 +1,62 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/internal/cache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CacheRecord is the GORM model for the response_cache table.
+type CacheRecord struct {
+	Key       string    `gorm:"primaryKey"`
+	Data      []byte    `gorm:"not null"`
+	StoredAt  time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName overrides the default table name.
+func (CacheRecord) TableName() string { return "response_cache" }
+
+// CacheBackend persists response cache entries in SQLite so they survive a
+// restart. It implements cache.Backend.
+type CacheBackend struct {
+	db *gorm.DB
+}
+
+// NewCacheBackend returns a cache.Backend stored in db.
+func NewCacheBackend(db *gorm.DB) *CacheBackend {
+	return &CacheBackend{db: db}
+}
+
+// Load reads the entry for key.
+func (b *CacheBackend) Load(key string) (cache.Entry, bool, error) {
+	var r CacheRecord
+	err := b.db.First(&r, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return cache.Entry{}, false, nil
+	}
+	if err != nil {
+		return cache.Entry{}, false, err
+	}
+	return cache.Entry{Data: r.Data, StoredAt: r.StoredAt, ExpiresAt: r.ExpiresAt}, true, nil
+}
+
+// Save upserts the entry for key.
+func (b *CacheBackend) Save(key string, e cache.Entry) error {
+	return b.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&CacheRecord{
+		Key:       key,
+		Data:      e.Data,
+		StoredAt:  e.StoredAt,
+		ExpiresAt: e.ExpiresAt,
+	}).Error
+}
+
+// PurgeExpiredCache deletes cache entries that expired before now and
+// returns how many were removed. Called at startup.
+func PurgeExpiredCache(db *gorm.DB, now time.Time) (int64, error) {
+	res := db.Where("expires_at < ?", now).Delete(&CacheRecord{})
+	return res.RowsAffected, res.Error
+}
@@ -26,7 +26,7 @@ func Init(dataDir string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
 
-	if err := db.AutoMigrate(&BatchRecord{}, &TaskRecord{}, &Monitor{}, &MonitorRun{}); err != nil {
+	if err := db.AutoMigrate(&BatchRecord{}, &TaskRecord{}, &Monitor{}, &MonitorRun{}, &CacheRecord{}, &HealthRecord{}); err != nil {
 		return nil, fmt.Errorf("auto migrate: %w", err)
 	}
 
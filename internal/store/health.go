@@ -0,0 +1,60 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HealthRecord is the GORM model for the provider_health table. It holds the
+// latest result of each canary check per provider.
+type HealthRecord struct {
+	Source              string     `gorm:"primaryKey" json:"source"`
+	Check               string     `gorm:"primaryKey" json:"check"`
+	Status              string     `gorm:"not null" json:"status"`
+	LatencyMs           int64      `json:"latency_ms"`
+	Error               string     `json:"error,omitempty"`
+	CheckedAt           time.Time  `gorm:"not null" json:"checked_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// TableName overrides the default table name.
+func (HealthRecord) TableName() string { return "provider_health" }
+
+// SaveHealthResult records the outcome of one check. LastSuccessAt and
+// ConsecutiveFailures are carried over from the previous result: an "ok"
+// status resets the failure count, "skipped" leaves it alone, anything else
+// increments it.
+func SaveHealthResult(db *gorm.DB, r *HealthRecord) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var prev HealthRecord
+		err := tx.First(&prev, "source = ? AND `check` = ?", r.Source, r.Check).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		switch r.Status {
+		case "ok":
+			checked := r.CheckedAt
+			r.LastSuccessAt = &checked
+			r.ConsecutiveFailures = 0
+		case "skipped":
+			r.LastSuccessAt = prev.LastSuccessAt
+			r.ConsecutiveFailures = prev.ConsecutiveFailures
+		default:
+			r.LastSuccessAt = prev.LastSuccessAt
+			r.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+		}
+		return tx.Save(r).Error
+	})
+}
+
+// ListHealthRecords returns every stored check result ordered by source and check.
+func ListHealthRecords(db *gorm.DB) ([]HealthRecord, error) {
+	var records []HealthRecord
+	if err := db.Order("source ASC").Order("`check` ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/guohuiyuan/music-lib/download"
+	"github.com/guohuiyuan/music-lib/internal/cache"
 	"github.com/guohuiyuan/music-lib/model"
 	"gorm.io/gorm"
 )
@@ -331,6 +332,27 @@ func TestListBatchesWithStats(t *testing.T) {
 	}
 }
 
+// TestSaveTask_FullSong: fields beyond the summary columns survive a reload,
+// so an interrupted task can resolve its stream again.
+func TestSaveTask_FullSong(t *testing.T) {
+	db := testDB(t)
+	song := model.Song{ID: "003OUlho2HcRHC", Source: "qq", Name: "Test Song", Duration: 240, Extra: map[string]string{"songmid": "003OUlho2HcRHC", "media_mid": "000MkMni19ClKG"}}
+	if err := SaveTask(db, &download.Task{ID: "t-full", Source: "qq", Song: song, Status: download.StatusPaused, Priority: download.PriorityHigh, Progress: 1024}); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	tasks, _ := ListAllTasks(db)
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	got := tasks[0]
+	if got.Song.Source != "qq" || got.Song.Duration != 240 || got.Song.Extra["media_mid"] != "000MkMni19ClKG" || got.Progress != 1024 {
+		t.Errorf("song not restored: %+v, progress %d", got.Song, got.Progress)
+	}
+	if got.Status != download.StatusPaused || got.Priority != download.PriorityHigh {
+		t.Errorf("queue state not restored: status %s, priority %d", got.Status, got.Priority)
+	}
+}
+
 // --- Restart simulation ---
 
 func TestRestartRecovery_FullFlow(t *testing.T) {
@@ -390,3 +412,47 @@ func TestRestartRecovery_FullFlow(t *testing.T) {
 		t.Errorf("batch name: %q", names["b-restart"])
 	}
 }
+
+// --- Response cache backend ---
+
+func TestCacheBackend_SaveLoadPurge(t *testing.T) {
+	db := testDB(t)
+	b := NewCacheBackend(db)
+
+	if _, ok, err := b.Load("missing"); ok || err != nil {
+		t.Fatalf("missing key: ok=%v err=%v", ok, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	fresh := cache.Entry{Data: []byte(`["a"]`), StoredAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := b.Save("search:song:qq:jay", fresh); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Saving again overwrites the entry.
+	fresh.Data = []byte(`["b"]`)
+	if err := b.Save("search:song:qq:jay", fresh); err != nil {
+		t.Fatalf("Save overwrite: %v", err)
+	}
+	got, ok, err := b.Load("search:song:qq:jay")
+	if err != nil || !ok {
+		t.Fatalf("Load: ok=%v err=%v", ok, err)
+	}
+	if string(got.Data) != `["b"]` || !got.ExpiresAt.Equal(fresh.ExpiresAt) {
+		t.Errorf("Load: got %s expires %v", got.Data, got.ExpiresAt)
+	}
+
+	stale := cache.Entry{Data: []byte(`"old"`), StoredAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	if err := b.Save("lyrics:qq:1", stale); err != nil {
+		t.Fatal(err)
+	}
+	n, err := PurgeExpiredCache(db, now)
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeExpiredCache: n=%d err=%v", n, err)
+	}
+	if _, ok, _ := b.Load("lyrics:qq:1"); ok {
+		t.Error("expired entry still present")
+	}
+	if _, ok, _ := b.Load("search:song:qq:jay"); !ok {
+		t.Error("fresh entry was purged")
+	}
+}
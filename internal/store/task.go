@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/guohuiyuan/music-lib/download"
@@ -20,8 +21,10 @@ type TaskRecord struct {
 	Album          string
 	Ext            string
 	Quality        string
+	SongJSON       string // full model.Song, so an interrupted task can be resumed
 	FilePath       string
 	Status         string     `gorm:"not null;index"`
+	Priority       int        `gorm:"default:0"`
 	Error          string
 	RetryCount     int        `gorm:"default:0"`
 	Skipped        bool
@@ -49,6 +52,10 @@ func SaveTask(db *gorm.DB, t *download.Task) error {
 	if createdAt.IsZero() {
 		createdAt = now
 	}
+	songJSON, err := json.Marshal(t.Song)
+	if err != nil {
+		return err
+	}
 	r := TaskRecord{
 		ID:             t.ID,
 		Source:         t.Source,
@@ -60,8 +67,10 @@ func SaveTask(db *gorm.DB, t *download.Task) error {
 		Album:          t.Song.Album,
 		Ext:            t.Song.Ext,
 		Quality:        quality,
+		SongJSON:       string(songJSON),
 		FilePath:       t.FilePath,
 		Status:         string(t.Status),
+		Priority:       int(t.Priority),
 		Error:          t.Error,
 		RetryCount:     t.RetryCount,
 		Skipped:        t.Skipped,
@@ -100,6 +109,13 @@ func ListAllTasks(db *gorm.DB) ([]*download.Task, error) {
 			Ext:    r.Ext,
 			Extra:  extra,
 		}
+		if r.SongJSON != "" {
+			// Rows written before the column existed keep the summary above.
+			var full model.Song
+			if err := json.Unmarshal([]byte(r.SongJSON), &full); err == nil {
+				song = full
+			}
+		}
 		t := &download.Task{
 			ID:             r.ID,
 			Source:         r.Source,
@@ -108,6 +124,7 @@ func ListAllTasks(db *gorm.DB) ([]*download.Task, error) {
 			FilePath:       r.FilePath,
 			Song:           song,
 			Status:         download.TaskStatus(r.Status),
+			Priority:       download.Priority(r.Priority),
 			Error:          r.Error,
 			RetryCount:     r.RetryCount,
 			Skipped:        r.Skipped,
@@ -125,7 +142,8 @@ func ListAllTasks(db *gorm.DB) ([]*download.Task, error) {
 }
 
 // MarkRunningAsFailed marks all running and pending tasks as failed.
-// Called at startup to handle tasks that were interrupted by a previous restart.
+// Called at startup when no download manager will resume them (see
+// download.Manager.ResumeInterrupted).
 func MarkRunningAsFailed(db *gorm.DB) error {
 	return db.Model(&TaskRecord{}).
 		Where("status IN ?", []string{"running", "pending"}).
@@ -1,15 +1,16 @@
 package jamendo
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
 	"regexp"
 	"strconv"
+	"sync"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -32,34 +33,157 @@ const (
 
 type Jamendo struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Jamendo {
-	return &Jamendo{cookie: cookie}
+func New(cookie string, opts ...utils.ClientOption) *Jamendo {
+	return &Jamendo{cookie: cookie, client: utils.NewClient(opts...)}
 }
 
-var defaultJamendo = New("")
+// Client 返回实例使用的 HTTP 客户端
+func (j *Jamendo) Client() *utils.Client { return j.client }
 
-func Search(keyword string) ([]model.Song, error) { return defaultJamendo.Search(keyword) }
+var (
+	defaultMu      sync.RWMutex
+	defaultJamendo = New("")
+)
+
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultJamendo = New(defaultJamendo.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Jamendo {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultJamendo
+}
+
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultJamendo.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }                                                      // [新增]
-func GetPlaylistSongs(id string) ([]model.Song, error) { return defaultJamendo.GetPlaylistSongs(id) } // [新增]
-func GetDownloadURL(s *model.Song) (string, error)     { return defaultJamendo.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)          { return defaultJamendo.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)           { return defaultJamendo.Parse(link) }
+func GetPlaylistSongs(id string) ([]model.Song, error) { return getDefault().GetPlaylistSongs(id) } // [新增]
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
+}
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
 
-// Search 搜索歌曲
 func (j *Jamendo) Search(keyword string) ([]model.Song, error) {
+	return j.SearchContext(context.Background(), keyword)
+}
+
+func (j *Jamendo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return j.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (j *Jamendo) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return j.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (j *Jamendo) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return j.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (j *Jamendo) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return j.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (j *Jamendo) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return j.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (j *Jamendo) Parse(link string) (*model.Song, error) {
+	return j.ParseContext(context.Background(), link)
+}
+
+func (j *Jamendo) GetDownloadURL(s *model.Song) (string, error) {
+	return j.GetDownloadURLContext(context.Background(), s)
+}
+
+func (j *Jamendo) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return j.GetStreamsContext(context.Background(), s)
+}
+
+func (j *Jamendo) GetLyrics(s *model.Song) (string, error) {
+	return j.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (j *Jamendo) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := j.SearchPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲（offset/limit）
+func (j *Jamendo) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 100)
 	params := url.Values{}
 	params.Set("query", keyword)
 	params.Set("type", "track")
-	params.Set("limit", "20")
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("offset", strconv.Itoa((page-1)*limit))
 	params.Set("identities", "www")
 	apiURL := SearchAPI + "?" + params.Encode()
 	xJamCall := makeXJamCall(SearchApiPath)
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("x-jam-call", xJamCall),
@@ -90,7 +214,7 @@ func (j *Jamendo) Search(keyword string) ([]model.Song, error) {
 		Stream   map[string]string `json:"stream"`
 	}
 	if err := json.Unmarshal(body, &results); err != nil {
-		return nil, fmt.Errorf("jamendo json parse error: %w", err)
+		return nil, model.Errorf("jamendo", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -124,21 +248,33 @@ func (j *Jamendo) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	// 接口不返回总数，按本页是否取满推断是否还有更多
+	return model.NewPage(songs, page, limit, 0, len(results)), nil
 }
 
-// SearchPlaylist 搜索歌单 (Updated to use internal API)
-func (j *Jamendo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单 (Updated to use internal API)
+func (j *Jamendo) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := j.SearchPlaylistPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (j *Jamendo) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 100)
 	params := url.Values{}
 	params.Set("query", keyword)   // Same parameter as Search
 	params.Set("type", "playlist") // Change type to playlist
-	params.Set("limit", "20")
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("offset", strconv.Itoa((page-1)*limit))
 	params.Set("identities", "www")
 
 	apiURL := SearchAPI + "?" + params.Encode()
 	xJamCall := makeXJamCall(SearchApiPath)
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("x-jam-call", xJamCall),
@@ -159,7 +295,7 @@ func (j *Jamendo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &results); err != nil {
-		return nil, fmt.Errorf("jamendo playlist json parse error: %w", err)
+		return nil, model.Errorf("jamendo", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -173,18 +309,18 @@ func (j *Jamendo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Link:    fmt.Sprintf("https://www.jamendo.com/playlist/%d", item.ID),
 		})
 	}
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, 0, len(results)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情 (Updated to use internal API)
-func (j *Jamendo) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取歌单详情 (Updated to use internal API)
+func (j *Jamendo) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
 	params := url.Values{}
 	params.Set("id", id)
 
 	apiURL := PlaylistTracksAPI + "?" + params.Encode()
 	xJamCall := makeXJamCall(PlaylistTracksPath)
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("x-jam-call", xJamCall),
@@ -217,11 +353,11 @@ func (j *Jamendo) GetPlaylistSongs(id string) ([]model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &results); err != nil {
-		return nil, fmt.Errorf("jamendo playlist tracks json error: %w", err)
+		return nil, model.Errorf("jamendo", model.ErrSchemaChanged, "playlist tracks json error: %w", err)
 	}
 
 	if len(results) == 0 {
-		return nil, errors.New("playlist is empty or invalid")
+		return nil, model.Errorf("jamendo", model.ErrNotFound, "playlist is empty or invalid")
 	}
 
 	var songs []model.Song
@@ -258,23 +394,34 @@ func (j *Jamendo) GetPlaylistSongs(id string) ([]model.Song, error) {
 	return songs, nil
 }
 
-// Parse 解析链接并获取完整 Song 详情
-func (j *Jamendo) Parse(link string) (*model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲。playlist/tracks 接口没有分页参数，取回后再切页
+func (j *Jamendo) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	songs, err := j.GetPlaylistSongsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParseContext 解析链接并获取完整 Song 详情
+func (j *Jamendo) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`jamendo\.com/track/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid jamendo link")
+		return nil, model.Errorf("jamendo", model.ErrInvalidInput, "invalid jamendo link")
 	}
 	trackID := matches[1]
 
 	// 直接调用底层获取详情
-	return j.getTrackByID(trackID)
+	song, _, err := j.getTrackByID(ctx, trackID)
+	return song, err
 }
 
-// GetDownloadURL 获取下载链接
-func (j *Jamendo) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (j *Jamendo) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "jamendo" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
@@ -285,26 +432,48 @@ func (j *Jamendo) GetDownloadURL(s *model.Song) (string, error) {
 		trackID = s.Extra["track_id"]
 	}
 	if trackID == "" {
-		return "", errors.New("id missing")
+		return "", model.Errorf("jamendo", model.ErrInvalidInput, "id missing")
 	}
 
 	// 复用底层逻辑
-	info, err := j.getTrackByID(trackID)
+	_, streams, err := j.getTrackByID(ctx, trackID)
 	if err != nil {
 		return "", err
 	}
-	return info.URL, nil
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("jamendo", model.ErrNotFound, "no valid stream found")
+	}
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流（下载版与在线播放版）
+func (j *Jamendo) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "jamendo" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	trackID := s.ID
+	if s.Extra != nil && s.Extra["track_id"] != "" {
+		trackID = s.Extra["track_id"]
+	}
+	if trackID == "" {
+		return nil, model.Errorf("jamendo", model.ErrInvalidInput, "id missing")
+	}
+
+	_, streams, err := j.getTrackByID(ctx, trackID)
+	return streams, err
 }
 
-// getTrackByID 底层核心逻辑：通过 ID 获取完整 Song 对象
-func (j *Jamendo) getTrackByID(id string) (*model.Song, error) {
+// getTrackByID 底层核心逻辑：通过 ID 获取完整 Song 对象及其全部音频流
+func (j *Jamendo) getTrackByID(ctx context.Context, id string) (*model.Song, []model.Stream, error) {
 	params := url.Values{}
 	params.Set("id", id)
 
 	apiURL := TrackAPI + "?" + params.Encode()
 	xJamCall := makeXJamCall(TrackApiPath)
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("x-jam-call", xJamCall),
@@ -313,7 +482,7 @@ func (j *Jamendo) getTrackByID(id string) (*model.Song, error) {
 		utils.WithHeader("Cookie", j.cookie),
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var results []struct {
@@ -336,38 +505,56 @@ func (j *Jamendo) getTrackByID(id string) (*model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &results); err != nil {
-		return nil, fmt.Errorf("jamendo track json error: %w", err)
+		return nil, nil, model.Errorf("jamendo", model.ErrSchemaChanged, "track json error: %w", err)
 	}
 	if len(results) == 0 {
-		return nil, errors.New("track not found")
+		return nil, nil, model.Errorf("jamendo", model.ErrNotFound, "track not found")
 	}
 
 	item := results[0]
-	streams := item.Download
+	streams := append(trackStreams(item.Download, "download"), trackStreams(item.Stream, "stream")...)
 	if len(streams) == 0 {
-		streams = item.Stream
-	}
-
-	downloadURL, ext := pickBestQuality(streams)
-	if downloadURL == "" {
-		return nil, errors.New("no valid stream found")
+		return nil, nil, model.Errorf("jamendo", model.ErrNotFound, "no valid stream found")
 	}
 
-	return &model.Song{
+	song := &model.Song{
 		Source:   "jamendo",
 		ID:       strconv.Itoa(item.ID),
 		Name:     item.Name,
 		Artist:   item.Artist.Name,
 		Album:    item.Album.Name,
 		Duration: item.Duration,
-		Ext:      ext,
 		Cover:    item.Cover.Big.Size300,
-		URL:      downloadURL, // 已填充
 		Link:     fmt.Sprintf("https://www.jamendo.com/track/%d", item.ID),
 		Extra: map[string]string{
 			"track_id": strconv.Itoa(item.ID),
 		},
-	}, nil
+	}
+	st, _ := song.ChooseStream(streams)
+	song.URL = st.URL // 已填充
+	return song, streams, nil
+}
+
+// jamendoBitrates 是各格式的大致码率，下载版 mp3 为 VBR 高码率，在线播放版较低
+var jamendoBitrates = map[string]map[string]int{
+	"download": {"mp3": 320, "ogg": 192},
+	"stream":   {"mp3": 96, "ogg": 96},
+}
+
+// trackStreams 把接口返回的 格式 -> 链接 映射转成音频流，label 区分下载版和在线播放版
+func trackStreams(m map[string]string, label string) []model.Stream {
+	var streams []model.Stream
+	for _, ext := range []string{"flac", "mp3", "ogg"} {
+		if u := m[ext]; u != "" {
+			streams = append(streams, model.Stream{
+				URL:     u,
+				Ext:     ext,
+				Bitrate: jamendoBitrates[label][ext],
+				Label:   label,
+			})
+		}
+	}
+	return streams
 }
 
 func pickBestQuality(streams map[string]string) (string, string) {
@@ -392,9 +579,9 @@ func makeXJamCall(path string) string {
 	return fmt.Sprintf("$%s*%s~", digest, randStr)
 }
 
-func (j *Jamendo) GetLyrics(s *model.Song) (string, error) {
+func (j *Jamendo) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "jamendo" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	return "", nil
 }
@@ -0,0 +1,12 @@
+package jamendo
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "jamendo",
+		DisplayName: "Jamendo",
+		Canary:      registry.Canary{Keyword: "piano"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
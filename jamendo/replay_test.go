@@ -0,0 +1,16 @@
+package jamendo
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Jamendo]{
+		{Name: "search", Call: func(_ *testing.T, j *Jamendo) (any, error) { return j.SearchPage("piano", 1, 20) }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Jamendo { return New("", opts...) }
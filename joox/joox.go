@@ -1,13 +1,15 @@
 package joox
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -21,35 +23,147 @@ const (
 
 type Joox struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Joox {
+func New(cookie string, opts ...utils.ClientOption) *Joox {
 	if cookie == "" {
 		cookie = Cookie
 	}
-	return &Joox{cookie: cookie}
+	return &Joox{cookie: cookie, client: utils.NewClient(opts...)}
 }
 
-var defaultJoox = New(Cookie)
+// Client 返回实例使用的 HTTP 客户端
+func (j *Joox) Client() *utils.Client { return j.client }
 
-func Search(keyword string) ([]model.Song, error) { return defaultJoox.Search(keyword) }
+var (
+	defaultMu   sync.RWMutex
+	defaultJoox = New(Cookie)
+)
+
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultJoox = New(defaultJoox.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Joox {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultJoox
+}
+
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultJoox.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }                                                      // [新增]
-func GetPlaylistSongs(id string) ([]model.Song, error) { return defaultJoox.GetPlaylistSongs(id) } // [新增]
-func GetDownloadURL(s *model.Song) (string, error)     { return defaultJoox.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)          { return defaultJoox.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)           { return defaultJoox.Parse(link) }
+func GetPlaylistSongs(id string) ([]model.Song, error) { return getDefault().GetPlaylistSongs(id) } // [新增]
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
+}
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
 
-// Search 搜索歌曲
 func (j *Joox) Search(keyword string) ([]model.Song, error) {
+	return j.SearchContext(context.Background(), keyword)
+}
+
+func (j *Joox) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return j.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (j *Joox) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return j.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (j *Joox) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return j.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (j *Joox) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return j.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (j *Joox) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return j.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (j *Joox) Parse(link string) (*model.Song, error) {
+	return j.ParseContext(context.Background(), link)
+}
+
+func (j *Joox) GetDownloadURL(s *model.Song) (string, error) {
+	return j.GetDownloadURLContext(context.Background(), s)
+}
+
+func (j *Joox) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return j.GetStreamsContext(context.Background(), s)
+}
+
+func (j *Joox) GetLyrics(s *model.Song) (string, error) {
+	return j.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (j *Joox) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
 	params := url.Values{}
 	params.Set("country", "sg")
 	params.Set("lang", "zh_cn")
 	params.Set("keyword", keyword)
 	apiURL := "https://cache.api.joox.com/openjoox/v3/search?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", j.cookie),
 		utils.WithHeader("X-Forwarded-For", XForwardedFor),
@@ -81,7 +195,7 @@ func (j *Joox) Search(keyword string) ([]model.Song, error) {
 		} `json:"section_list"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("joox search json error: %w", err)
+		return nil, model.Errorf("joox", model.ErrSchemaChanged, "search json error: %w", err)
 	}
 
 	var songs []model.Song
@@ -128,15 +242,15 @@ func (j *Joox) Search(keyword string) ([]model.Song, error) {
 	return songs, nil
 }
 
-// SearchPlaylist 搜索歌单
-func (j *Joox) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (j *Joox) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
 	params := url.Values{}
 	params.Set("country", "sg")
 	params.Set("lang", "zh_cn")
 	params.Set("keyword", keyword)
 	apiURL := "https://cache.api.joox.com/openjoox/v3/search?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", j.cookie),
 		utils.WithHeader("X-Forwarded-For", XForwardedFor),
@@ -165,7 +279,7 @@ func (j *Joox) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("joox search playlist json error: %w", err)
+		return nil, model.Errorf("joox", model.ErrSchemaChanged, "search playlist json error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -221,8 +335,8 @@ func (j *Joox) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	return playlists, nil
 }
 
-// GetPlaylistSongs 获取歌单详情 (Updated to use OpenJoox v3 API)
-func (j *Joox) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取歌单详情 (Updated to use OpenJoox v3 API)
+func (j *Joox) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
 	params := url.Values{}
 	// The new v3 API uses "id" instead of "playlistid"
 	params.Set("id", id)
@@ -233,7 +347,7 @@ func (j *Joox) GetPlaylistSongs(id string) ([]model.Song, error) {
 	// Guessing the endpoint is /playlist based on /search pattern
 	apiURL := "https://cache.api.joox.com/openjoox/v3/playlist?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", j.cookie),
 		utils.WithHeader("X-Forwarded-For", XForwardedFor),
@@ -270,7 +384,7 @@ func (j *Joox) GetPlaylistSongs(id string) ([]model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("joox playlist json error: %w", err)
+		return nil, model.Errorf("joox", model.ErrSchemaChanged, "playlist json error: %w", err)
 	}
 
 	var songs []model.Song
@@ -333,14 +447,45 @@ func (j *Joox) GetPlaylistSongs(id string) ([]model.Song, error) {
 
 	if !foundSongs {
 		// If no songs found, the ID might be invalid or the playlist is empty
-		return nil, errors.New("no songs found in playlist or invalid playlist ID")
+		return nil, model.Errorf("joox", model.ErrNotFound, "no songs found in playlist or invalid playlist ID")
 	}
 
 	return songs, nil
 }
 
-// Parse 解析链接并获取完整信息
-func (j *Joox) Parse(link string) (*model.Song, error) {
+// SearchPageContext 分页搜索歌曲。openjoox v3 的搜索接口没有翻页参数，一次返回全部命中结果，
+// 因此在本地按 page/limit 切分
+func (j *Joox) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 100)
+	songs, err := j.SearchContext(ctx, keyword)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单，做法同 SearchPageContext
+func (j *Joox) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 100)
+	playlists, err := j.SearchPlaylistContext(ctx, keyword)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(playlists, page, limit), nil
+}
+
+// GetPlaylistSongsPageContext 分页获取歌单歌曲
+func (j *Joox) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	songs, err := j.GetPlaylistSongsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParseContext 解析链接并获取完整信息
+func (j *Joox) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	// 1. 提取 ID
 	// 支持格式: https://www.joox.com/hk/single/C+Q0... 或纯 ID
 	re := regexp.MustCompile(`joox\.com/.*/single/([a-zA-Z0-9]+)`)
@@ -353,18 +498,19 @@ func (j *Joox) Parse(link string) (*model.Song, error) {
 		if len(link) > 10 && !strings.Contains(link, "/") {
 			songID = link
 		} else {
-			return nil, errors.New("invalid joox link")
+			return nil, model.Errorf("joox", model.ErrInvalidInput, "invalid joox link")
 		}
 	}
 
 	// 2. 调用核心逻辑获取详情
-	return j.fetchSongInfo(songID)
+	song, _, err := j.fetchSongInfo(ctx, songID)
+	return song, err
 }
 
-// GetDownloadURL 获取下载链接
-func (j *Joox) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (j *Joox) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "joox" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
@@ -376,15 +522,34 @@ func (j *Joox) GetDownloadURL(s *model.Song) (string, error) {
 	}
 
 	// 复用核心逻辑
-	info, err := j.fetchSongInfo(songID)
+	_, streams, err := j.fetchSongInfo(ctx, songID)
 	if err != nil {
 		return "", err
 	}
-	return info.URL, nil
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("joox", model.ErrRestricted, "no valid download url found for song %s", songID)
+	}
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流
+func (j *Joox) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "joox" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	songID := s.ID
+	if s.Extra != nil && s.Extra["songid"] != "" {
+		songID = s.Extra["songid"]
+	}
+
+	_, streams, err := j.fetchSongInfo(ctx, songID)
+	return streams, err
 }
 
-// fetchSongInfo 内部函数：获取歌曲详情和下载链接
-func (j *Joox) fetchSongInfo(songID string) (*model.Song, error) {
+// fetchSongInfo 内部函数：获取歌曲详情和全部可用音频流
+func (j *Joox) fetchSongInfo(ctx context.Context, songID string) (*model.Song, []model.Stream, error) {
 	params := url.Values{}
 	params.Set("songid", songID)
 	params.Set("lang", "zh_cn")
@@ -392,13 +557,13 @@ func (j *Joox) fetchSongInfo(songID string) (*model.Song, error) {
 
 	apiURL := "https://api.joox.com/web-fcgi-bin/web_get_songinfo?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", j.cookie),
 		utils.WithHeader("X-Forwarded-For", XForwardedFor),
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	bodyStr := string(body)
@@ -421,7 +586,7 @@ func (j *Joox) fetchSongInfo(songID string) (*model.Song, error) {
 	}
 
 	if err := json.Unmarshal([]byte(bodyStr), &resp); err != nil {
-		return nil, fmt.Errorf("joox detail json error: %w", err)
+		return nil, nil, model.Errorf("joox", model.ErrSchemaChanged, "detail json error: %w", err)
 	}
 
 	// 解析下载链接
@@ -432,29 +597,41 @@ func (j *Joox) fetchSongInfo(songID string) (*model.Song, error) {
 		availableQualities = kbpsMapObj
 	}
 
-	type Candidate struct {
-		MapKey string
-		URL    string
-	}
-	candidates := []Candidate{
-		{"320", resp.R320Url}, {"192", resp.R192Url}, {"128", resp.Mp3Url}, {"96", resp.M4aUrl},
+	// kbps_map 的值是对应档位的文件大小，为 0 表示该档位不可用
+	candidates := []struct {
+		MapKey  string
+		URL     string
+		Ext     string
+		Bitrate int
+	}{
+		{"320", resp.R320Url, "mp3", 320}, {"192", resp.R192Url, "ogg", 192},
+		{"128", resp.Mp3Url, "mp3", 128}, {"96", resp.M4aUrl, "m4a", 96},
 	}
 
-	var downloadURL string
+	var streams []model.Stream
 	for _, c := range candidates {
 		if val, ok := availableQualities[c.MapKey]; ok {
-			if utils.ParseAnyInt64(val) > 0 && c.URL != "" {
-				downloadURL = c.URL
-				break
+			if size := utils.ParseAnyInt64(val); size > 0 && c.URL != "" {
+				ext := strings.TrimPrefix(path.Ext(strings.SplitN(c.URL, "?", 2)[0]), ".")
+				if ext == "" {
+					ext = c.Ext
+				}
+				streams = append(streams, model.Stream{
+					URL:     c.URL,
+					Ext:     ext,
+					Bitrate: c.Bitrate,
+					Size:    size,
+					Label:   c.MapKey,
+				})
 			}
 		}
 	}
 
-	if downloadURL == "" {
-		return nil, fmt.Errorf("[joox] no valid download url found for song %s", songID)
+	if len(streams) == 0 {
+		return nil, nil, model.Errorf("joox", model.ErrRestricted, "no valid download url found for song %s", songID)
 	}
 
-	return &model.Song{
+	song := &model.Song{
 		Source:   "joox",
 		ID:       songID,
 		Name:     resp.Msong,
@@ -462,18 +639,20 @@ func (j *Joox) fetchSongInfo(songID string) (*model.Song, error) {
 		Album:    resp.Malbum,
 		Duration: resp.MInterval,
 		Cover:    resp.Img,
-		URL:      downloadURL,
 		Link:     fmt.Sprintf("https://www.joox.com/hk/single/%s", songID),
 		Extra: map[string]string{
 			"songid": songID,
 		},
-	}, nil
+	}
+	st, _ := song.ChooseStream(streams)
+	song.URL = st.URL
+	return song, streams, nil
 }
 
-// GetLyrics 获取歌词
-func (j *Joox) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (j *Joox) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "joox" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	songID := s.ID
@@ -487,7 +666,7 @@ func (j *Joox) GetLyrics(s *model.Song) (string, error) {
 	params.Set("lang", "zh_cn")
 	apiURL := "https://api.joox.com/web-fcgi-bin/web_lyric?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := j.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", j.cookie),
 		utils.WithHeader("X-Forwarded-For", XForwardedFor),
@@ -506,10 +685,10 @@ func (j *Joox) GetLyrics(s *model.Song) (string, error) {
 		Lyric string `json:"lyric"`
 	}
 	if err := json.Unmarshal([]byte(bodyStr), &resp); err != nil {
-		return "", fmt.Errorf("joox lyric json parse error: %w", err)
+		return "", model.Errorf("joox", model.ErrSchemaChanged, "lyric json parse error: %w", err)
 	}
 	if resp.Lyric == "" {
-		return "", errors.New("lyric not found or empty")
+		return "", model.Errorf("joox", model.ErrNotFound, "lyric not found or empty")
 	}
 
 	decodedBytes, err := base64.StdEncoding.DecodeString(resp.Lyric)
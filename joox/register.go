@@ -0,0 +1,12 @@
+package joox
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "joox",
+		DisplayName: "JOOX",
+		Canary:      registry.Canary{Keyword: "Jay Chou"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
@@ -0,0 +1,16 @@
+package joox
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Joox]{
+		{Name: "search", Call: func(_ *testing.T, j *Joox) (any, error) { return j.Search("Eason") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Joox { return New("", opts...) }
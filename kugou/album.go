@@ -0,0 +1,207 @@
+package kugou
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// SearchAlbum 按关键词搜索专辑
+func SearchAlbum(keyword string) ([]model.Album, error) { return getDefault().SearchAlbum(keyword) }
+
+// GetAlbum 获取专辑及其曲目
+func GetAlbum(id string) (*model.Album, error) { return getDefault().GetAlbum(id) }
+
+// SearchAlbumContext 按关键词搜索专辑
+func SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	return getDefault().SearchAlbumContext(ctx, keyword)
+}
+
+// GetAlbumContext 获取专辑及其曲目
+func GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	return getDefault().GetAlbumContext(ctx, id)
+}
+
+// SearchAlbum 按关键词搜索专辑
+func (k *Kugou) SearchAlbum(keyword string) ([]model.Album, error) {
+	return k.SearchAlbumContext(context.Background(), keyword)
+}
+
+// GetAlbum 获取专辑及其曲目
+func (k *Kugou) GetAlbum(id string) (*model.Album, error) {
+	return k.GetAlbumContext(context.Background(), id)
+}
+
+// kugouAlbum 是专辑搜索和专辑信息共用的专辑结构
+type kugouAlbum struct {
+	AlbumID     interface{} `json:"albumid"`
+	AlbumName   string      `json:"albumname"`
+	SingerName  string      `json:"singername"`
+	PublishTime string      `json:"publishtime"` // "2003-07-31 00:00:00"
+	SongCount   int         `json:"songcount"`
+	ImgURL      string      `json:"imgurl"`
+	Intro       string      `json:"intro"`
+}
+
+func (a kugouAlbum) toModel() model.Album {
+	id := utils.ParseAnyString(a.AlbumID)
+	release := a.PublishTime
+	if i := strings.IndexByte(release, ' '); i > 0 {
+		release = release[:i]
+	}
+	var artists []string
+	if a.SingerName != "" {
+		artists = strings.Split(a.SingerName, "、")
+	}
+	return model.Album{
+		ID:          id,
+		Name:        a.AlbumName,
+		Artists:     artists,
+		ReleaseDate: release,
+		Cover:       strings.Replace(a.ImgURL, "{size}", "400", 1),
+		TrackCount:  a.SongCount,
+		Description: a.Intro,
+		Source:      "kugou",
+		Link:        fmt.Sprintf("https://www.kugou.com/album/%s.html", id),
+	}
+}
+
+// SearchAlbumContext 按关键词搜索专辑
+func (k *Kugou) SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	params := url.Values{}
+	params.Set("keyword", keyword)
+	params.Set("page", "1")
+	params.Set("pagesize", "10")
+	params.Set("format", "json")
+	apiURL := "http://mobilecdn.kugou.com/api/v3/search/album?" + params.Encode()
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", MobileUserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Info []kugouAlbum `json:"info"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "album search json error: %w", err)
+	}
+
+	albums := make([]model.Album, 0, len(resp.Data.Info))
+	for _, item := range resp.Data.Info {
+		albums = append(albums, item.toModel())
+	}
+	return albums, nil
+}
+
+// GetAlbumContext 先取专辑信息再取歌曲。酷狗不返回曲目号，按专辑接口返回的顺序编号
+func (k *Kugou) GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("kugou", model.ErrInvalidInput, "invalid kugou album id: %q", id)
+	}
+
+	infoBody, err := k.client.GetContext(ctx, "http://mobilecdn.kugou.com/api/v3/album/info?albumid="+id,
+		utils.WithHeader("User-Agent", MobileUserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var infoResp struct {
+		Status int        `json:"status"`
+		Data   kugouAlbum `json:"data"`
+	}
+	if err := json.Unmarshal(infoBody, &infoResp); err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "album info json error: %w", err)
+	}
+	if infoResp.Status != 1 {
+		return nil, model.Errorf("kugou", model.ErrNotFound, "album not found")
+	}
+	album := infoResp.Data.toModel()
+	album.ID = id
+
+	songsURL := fmt.Sprintf("http://mobilecdn.kugou.com/api/v3/album/song?albumid=%s&page=1&pagesize=500&format=json", id)
+	body, err := k.client.GetContext(ctx, songsURL,
+		utils.WithHeader("User-Agent", MobileUserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data struct {
+			Info  []kugouV3Song `json:"info"`
+			Total int           `json:"total"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "album songs json error: %w", err)
+	}
+
+	for i, item := range resp.Data.Info {
+		if item.Hash == "" {
+			continue
+		}
+		song := item.toSong(album.ArtistString())
+		song.Album = album.Name
+		song.AlbumID = id
+		song.Cover = album.Cover
+		album.Tracks = append(album.Tracks, model.AlbumTrack{
+			Song:        song,
+			TrackNumber: i + 1,
+			DiscNumber:  1,
+		})
+	}
+	if resp.Data.Total > 0 {
+		album.TrackCount = resp.Data.Total
+	} else if album.TrackCount == 0 {
+		album.TrackCount = len(album.Tracks)
+	}
+	return &album, nil
+}
+
+// kugouV3Song 是 mobilecdn v3 专辑和歌手列表里的一首歌
+type kugouV3Song struct {
+	Hash      string      `json:"hash"`
+	FileName  string      `json:"filename"` // "歌手 - 歌名"
+	Duration  int         `json:"duration"`
+	FileSize  int64       `json:"filesize"`
+	BitRate   int         `json:"bitrate"`
+	AlbumName string      `json:"album_name"`
+	AlbumID   interface{} `json:"album_id"`
+}
+
+// toSong 把 filename 拆成歌手和歌名，没有分隔符时歌手用 defaultArtist
+func (item kugouV3Song) toSong(defaultArtist string) model.Song {
+	name, artist := item.FileName, defaultArtist
+	if parts := strings.SplitN(item.FileName, " - ", 2); len(parts) == 2 {
+		artist = strings.TrimSpace(parts[0])
+		name = strings.TrimSpace(parts[1])
+	}
+	return model.Song{
+		Source:   "kugou",
+		ID:       item.Hash,
+		Name:     name,
+		Artist:   artist,
+		Album:    item.AlbumName,
+		AlbumID:  utils.ParseAnyString(item.AlbumID),
+		Duration: item.Duration,
+		Size:     item.FileSize,
+		Bitrate:  item.BitRate,
+		Link:     fmt.Sprintf("https://www.kugou.com/song/#hash=%s", item.Hash),
+		Extra: map[string]string{
+			"hash": item.Hash,
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package kugou
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+const artistAlbumPageSize = 100
+
+// SearchArtist 按关键词搜索歌手
+func SearchArtist(keyword string) ([]model.Artist, error) { return getDefault().SearchArtist(keyword) }
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func GetArtistTopSongs(id string) ([]model.Song, error) { return getDefault().GetArtistTopSongs(id) }
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func GetArtistAlbums(id string) ([]model.Album, error) { return getDefault().GetArtistAlbums(id) }
+
+// SearchArtistContext 按关键词搜索歌手
+func SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	return getDefault().SearchArtistContext(ctx, keyword)
+}
+
+// GetArtistTopSongsContext 获取歌手最热门的歌曲
+func GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetArtistTopSongsContext(ctx, id)
+}
+
+// GetArtistAlbumsContext 获取歌手的全部专辑，不含曲目
+func GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	return getDefault().GetArtistAlbumsContext(ctx, id)
+}
+
+// SearchArtist 按关键词搜索歌手
+func (k *Kugou) SearchArtist(keyword string) ([]model.Artist, error) {
+	return k.SearchArtistContext(context.Background(), keyword)
+}
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func (k *Kugou) GetArtistTopSongs(id string) ([]model.Song, error) {
+	return k.GetArtistTopSongsContext(context.Background(), id)
+}
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func (k *Kugou) GetArtistAlbums(id string) ([]model.Album, error) {
+	return k.GetArtistAlbumsContext(context.Background(), id)
+}
+
+// SearchArtistContext 按关键词搜索歌手
+func (k *Kugou) SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	params := url.Values{}
+	params.Set("keyword", keyword)
+	params.Set("page", "1")
+	params.Set("pagesize", "10")
+	params.Set("format", "json")
+	apiURL := "http://mobilecdn.kugou.com/api/v3/search/singer?" + params.Encode()
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", MobileUserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			SingerID   interface{} `json:"singerid"`
+			SingerName string      `json:"singername"`
+			ImgURL     string      `json:"imgurl"`
+			SongCount  int         `json:"songcount"`
+			AlbumCount int         `json:"albumcount"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "artist search json error: %w", err)
+	}
+
+	artists := make([]model.Artist, 0, len(resp.Data))
+	for _, item := range resp.Data {
+		id := utils.ParseAnyString(item.SingerID)
+		artists = append(artists, model.Artist{
+			ID:         id,
+			Name:       item.SingerName,
+			Avatar:     strings.Replace(item.ImgURL, "{size}", "400", 1),
+			SongCount:  item.SongCount,
+			AlbumCount: item.AlbumCount,
+			Source:     "kugou",
+			Link:       fmt.Sprintf("https://www.kugou.com/singer/%s.html", id),
+		})
+	}
+	return artists, nil
+}
+
+// GetArtistTopSongsContext 返回歌手歌曲列表的前 50 首，酷狗按热度排序
+func (k *Kugou) GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("kugou", model.ErrInvalidInput, "invalid kugou singer id: %q", id)
+	}
+	apiURL := fmt.Sprintf("http://mobilecdn.kugou.com/api/v3/singer/song?singerid=%s&page=1&pagesize=50&format=json", id)
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", MobileUserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Info []kugouV3Song `json:"info"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "artist songs json error: %w", err)
+	}
+
+	songs := make([]model.Song, 0, len(resp.Data.Info))
+	for _, item := range resp.Data.Info {
+		if item.Hash == "" {
+			continue
+		}
+		songs = append(songs, item.toSong(""))
+	}
+	return songs, nil
+}
+
+// GetArtistAlbumsContext 翻页取回全部专辑，直到达到接口报告的总数
+func (k *Kugou) GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("kugou", model.ErrInvalidInput, "invalid kugou singer id: %q", id)
+	}
+
+	return utils.FetchPages(func(page, offset int) ([]model.Album, bool, error) {
+		apiURL := fmt.Sprintf("http://mobilecdn.kugou.com/api/v3/singer/album?singerid=%s&page=%d&pagesize=%d&format=json", id, page+1, artistAlbumPageSize)
+
+		body, err := k.client.GetContext(ctx, apiURL,
+			utils.WithHeader("User-Agent", MobileUserAgent),
+			utils.WithHeader("Cookie", k.cookie),
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var resp struct {
+			Data struct {
+				Total int          `json:"total"`
+				Info  []kugouAlbum `json:"info"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false, model.Errorf("kugou", model.ErrSchemaChanged, "artist albums json error: %w", err)
+		}
+
+		albums := make([]model.Album, 0, len(resp.Data.Info))
+		for _, item := range resp.Data.Info {
+			albums = append(albums, item.toModel())
+		}
+		return albums, offset+len(albums) < resp.Data.Total, nil
+	})
+}
@@ -1,6 +1,7 @@
 package kugou
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -16,18 +17,34 @@ var kugouCharts = []model.Chart{
 }
 
 // GetCharts returns the list of supported Kugou charts.
-func GetCharts() ([]model.Chart, error) { return defaultKugou.GetCharts() }
+func GetCharts() ([]model.Chart, error) { return getDefault().GetCharts() }
 
 // GetChartSongs returns the top N songs from a Kugou chart.
 func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
-	return defaultKugou.GetChartSongs(chartID, limit)
+	return getDefault().GetChartSongs(chartID, limit)
+}
+
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
 }
 
 func (k *Kugou) GetCharts() ([]model.Chart, error) {
-	return kugouCharts, nil
+	return k.GetChartsContext(context.Background())
 }
 
 func (k *Kugou) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return k.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (k *Kugou) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return kugouCharts, nil
+}
+
+func (k *Kugou) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -37,7 +54,7 @@ func (k *Kugou) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 		chartID, limit,
 	)
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -64,7 +81,7 @@ func (k *Kugou) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("kugou chart json: %w", err)
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "chart json: %w", err)
 	}
 
 	var songs []model.Song
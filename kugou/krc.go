@@ -0,0 +1,71 @@
+package kugou
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+// krcKey is the XOR key Kugou applies to the zlib-compressed KRC body.
+var krcKey = [16]byte{0x40, 0x47, 0x61, 0x77, 0x5e, 0x32, 0x74, 0x47, 0x51, 0x36, 0x31, 0x2d, 0xce, 0xd2, 0x6e, 0x69}
+
+// GetWordLyrics returns the word-timed KRC lyrics of a Kugou song.
+func GetWordLyrics(s *model.Song) (*model.WordLyrics, error) { return getDefault().GetWordLyrics(s) }
+
+// GetWordLyricsContext is GetWordLyrics with a context.
+func GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	return getDefault().GetWordLyricsContext(ctx, s)
+}
+
+func (k *Kugou) GetWordLyrics(s *model.Song) (*model.WordLyrics, error) {
+	return k.GetWordLyricsContext(context.Background(), s)
+}
+
+// GetWordLyricsContext downloads the song's lyrics in KRC format, decrypts
+// them and parses the per-word timings. Songs whose lyrics carry no word
+// timings return model.ErrNotFound so callers can fall back to GetLyrics.
+func (k *Kugou) GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	content, err := k.downloadLyrics(ctx, s, "krc")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("base64 decode error: %w", err)
+	}
+	krc, err := decryptKRC(raw)
+	if err != nil {
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "krc decrypt: %w", err)
+	}
+	w := model.ParseKRC(krc)
+	if !w.HasWords() {
+		return nil, model.Errorf("kugou", model.ErrNotFound, "no word-timed lyrics for %s", s.ID)
+	}
+	return w, nil
+}
+
+// decryptKRC strips the "krc1" magic, undoes the XOR and inflates the body.
+func decryptKRC(data []byte) (string, error) {
+	if len(data) < 4 || string(data[:4]) != "krc1" {
+		return "", fmt.Errorf("missing krc1 header")
+	}
+	body := make([]byte, len(data)-4)
+	for i, b := range data[4:] {
+		body[i] = b ^ krcKey[i%len(krcKey)]
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,63 @@
+package kugou
+
+import (
+	"bytes"
+	"compress/zlib"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecryptKRC(t *testing.T) {
+	krc := "[ti:晴天]\n[29350,3110]<0,400,0>故<400,300,0>事\n"
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(krc))
+	zw.Close()
+	data := append([]byte("krc1"), buf.Bytes()...)
+	for i := range data[4:] {
+		data[4+i] ^= krcKey[i%len(krcKey)]
+	}
+
+	got, err := decryptKRC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != krc {
+		t.Fatalf("got %q", got)
+	}
+	if _, err := decryptKRC([]byte("[00:01.00]lrc")); err == nil {
+		t.Error("content without the krc1 header should be rejected")
+	}
+}
+
+// TestDecryptKRC_Captured decrypts .krc files captured from the Kugou API:
+// testdata/krc/<name>.krc is the payload as served and <name>.txt the
+// plaintext exported by the official client. Unlike TestDecryptKRC they do
+// not depend on this package's key to build the input. No samples are
+// checked in yet.
+func TestDecryptKRC_Captured(t *testing.T) {
+	samples, _ := filepath.Glob(filepath.Join("testdata", "krc", "*.krc"))
+	if len(samples) == 0 {
+		t.Skip("no captured KRC samples in testdata/krc")
+	}
+	for _, path := range samples {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(strings.TrimSuffix(path, ".krc") + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decryptKRC(data)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if got != string(want) {
+			t.Errorf("%s: plaintext differs from the .txt export", path)
+		}
+	}
+}
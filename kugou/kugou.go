@@ -1,14 +1,15 @@
 package kugou
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -21,45 +22,184 @@ const (
 
 type Kugou struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Kugou { return &Kugou{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *Kugou {
+	return &Kugou{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (k *Kugou) Client() *utils.Client { return k.client }
+
+var (
+	defaultMu    sync.RWMutex
+	defaultKugou = New("")
+)
 
-var defaultKugou = New("")
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKugou = New(defaultKugou.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Kugou {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKugou
+}
 
-func Search(keyword string) ([]model.Song, error) { return defaultKugou.Search(keyword) }
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultKugou.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }
 func GetPlaylistSongs(id string) ([]model.Song, error) {
 	// 保持原接口兼容性，仅返回 Songs
-	_, songs, err := defaultKugou.fetchPlaylistDetail(id)
-	return songs, err
+	return getDefault().GetPlaylistSongs(id)
+}
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
-	return defaultKugou.ParsePlaylist(link)
+	return getDefault().ParsePlaylist(link)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return defaultKugou.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return defaultKugou.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)       { return defaultKugou.Parse(link) }
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
 
 // GetRecommendedPlaylists 获取推荐歌单
 func GetRecommendedPlaylists() ([]model.Playlist, error) {
-	return defaultKugou.GetRecommendedPlaylists()
+	return getDefault().GetRecommendedPlaylists()
+}
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
+	return getDefault().GetRecommendedPlaylistsContext(ctx)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
 }
 
-// Search 搜索歌曲
 func (k *Kugou) Search(keyword string) ([]model.Song, error) {
+	return k.SearchContext(context.Background(), keyword)
+}
+
+func (k *Kugou) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return k.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (k *Kugou) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return k.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (k *Kugou) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return k.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (k *Kugou) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return k.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (k *Kugou) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return k.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (k *Kugou) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return k.ParsePlaylistContext(context.Background(), link)
+}
+
+func (k *Kugou) GetRecommendedPlaylists() ([]model.Playlist, error) {
+	return k.GetRecommendedPlaylistsContext(context.Background())
+}
+
+func (k *Kugou) Parse(link string) (*model.Song, error) {
+	return k.ParseContext(context.Background(), link)
+}
+
+func (k *Kugou) GetDownloadURL(s *model.Song) (string, error) {
+	return k.GetDownloadURLContext(context.Background(), s)
+}
+
+func (k *Kugou) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return k.GetStreamsContext(context.Background(), s)
+}
+
+func (k *Kugou) GetLyrics(s *model.Song) (string, error) {
+	return k.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (k *Kugou) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := k.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，page 从 1 开始
+func (k *Kugou) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	params := url.Values{}
 	params.Set("keyword", keyword)
 	params.Set("platform", "WebFilter")
 	params.Set("format", "json")
-	params.Set("page", "1")
-	params.Set("pagesize", "10")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("pagesize", strconv.Itoa(limit))
 
 	apiURL := "http://songsearch.kugou.com/song_search_v2?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -83,11 +223,12 @@ func (k *Kugou) Search(keyword string) ([]model.Song, error) {
 				PayType    int         `json:"PayType"`
 				Privilege  int         `json:"Privilege"`
 			} `json:"lists"`
+			Total int `json:"total"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("json parse error: %w", err)
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -131,21 +272,31 @@ func (k *Kugou) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	return model.NewPage(songs, page, limit, resp.Data.Total, len(resp.Data.Lists)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (k *Kugou) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (k *Kugou) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := k.SearchPlaylistPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (k *Kugou) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	params := url.Values{}
 	params.Set("keyword", keyword)
 	params.Set("platform", "WebFilter")
 	params.Set("format", "json")
-	params.Set("page", "1")
-	params.Set("pagesize", "10")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("pagesize", strconv.Itoa(limit))
 	params.Set("filter", "0")
 	apiURL := "http://mobilecdn.kugou.com/api/v3/search/special?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -165,11 +316,12 @@ func (k *Kugou) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 				NickName    string `json:"nickname"`
 				PubTime     string `json:"publishtime"`
 			} `json:"info"`
+			Total int `json:"total"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("kugou playlist search json error: %w", err)
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "playlist search json error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -187,35 +339,42 @@ func (k *Kugou) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Link:        fmt.Sprintf("https://www.kugou.com/yy/special/single/%d.html", item.SpecialID),
 		})
 	}
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, resp.Data.Total, len(resp.Data.Info)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情 (仅返回 Songs, 兼容旧接口)
-func (k *Kugou) GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := k.fetchPlaylistDetail(id)
+// GetPlaylistSongsContext 获取歌单详情 (仅返回 Songs, 兼容旧接口)
+func (k *Kugou) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	_, songs, err := k.fetchPlaylistDetail(ctx, id)
 	return songs, err
 }
 
-// ParsePlaylist 解析歌单链接
-func (k *Kugou) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲
+func (k *Kugou) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 300)
+	_, result, err := k.fetchPlaylistPage(ctx, id, page, limit)
+	return result, err
+}
+
+// ParsePlaylistContext 解析歌单链接
+func (k *Kugou) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	// 链接格式: https://www.kugou.com/yy/special/single/546903.html
 	re := regexp.MustCompile(`special/single/(\d+)\.html`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, nil, errors.New("invalid kugou playlist link")
+		return nil, nil, model.Errorf("kugou", model.ErrInvalidInput, "invalid kugou playlist link")
 	}
 	specialID := matches[1]
 
-	return k.fetchPlaylistDetail(specialID)
+	return k.fetchPlaylistDetail(ctx, specialID)
 }
 
-// GetRecommendedPlaylists 获取推荐歌单
-func (k *Kugou) GetRecommendedPlaylists() ([]model.Playlist, error) {
+// GetRecommendedPlaylistsContext 获取推荐歌单
+func (k *Kugou) GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
 	// [修改] 使用 m.kugou.com 的 plist 接口，这个接口对 MobileUserAgent 更友好
 	// json=true 返回 JSON 数据
 	apiURL := "http://m.kugou.com/plist/index&json=true"
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Referer", MobileReferer),
 		utils.WithHeader("Cookie", k.cookie),
@@ -227,7 +386,7 @@ func (k *Kugou) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	// 检查 Body 是否是 JSON 格式 (简单的开头检查)
 	// 如果酷狗返回 HTML 错误页，这里可以拦截到
 	if len(body) == 0 || body[0] != '{' {
-		return nil, fmt.Errorf("kugou api returned invalid json: %s", string(body))
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "api returned invalid json: %s", string(body))
 	}
 
 	var resp struct {
@@ -247,7 +406,7 @@ func (k *Kugou) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("kugou recommended playlist json parse error: %w", err)
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "recommended playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -268,17 +427,27 @@ func (k *Kugou) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	if len(playlists) == 0 {
-		return nil, errors.New("no recommended playlists found")
+		return nil, model.Errorf("kugou", model.ErrNotFound, "no recommended playlists found")
 	}
 
 	return playlists, nil
 }
 
 // fetchPlaylistDetail [内部复用] 获取歌单详情 (Metadata + Songs)
-func (k *Kugou) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, error) {
-	apiURL := fmt.Sprintf("http://mobilecdn.kugou.com/api/v3/special/song?specialid=%s&page=1&pagesize=300&version=9108&area_code=1", id)
+func (k *Kugou) fetchPlaylistDetail(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
+	playlist, result, err := k.fetchPlaylistPage(ctx, id, 1, 300)
+	if err != nil {
+		return nil, nil, err
+	}
+	playlist.TrackCount = len(result.Items)
+	return playlist, result.Items, nil
+}
+
+// fetchPlaylistPage 按页获取歌单歌曲，page 从 1 开始
+func (k *Kugou) fetchPlaylistPage(ctx context.Context, id string, page, pageSize int) (*model.Playlist, *model.Page[model.Song], error) {
+	apiURL := fmt.Sprintf("http://mobilecdn.kugou.com/api/v3/special/song?specialid=%s&page=%d&pagesize=%d&version=9108&area_code=1", id, page, pageSize)
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -302,11 +471,12 @@ func (k *Kugou) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, e
 					UnionCover string `json:"union_cover"`
 				} `json:"trans_param"`
 			} `json:"info"`
+			Total int `json:"total"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("kugou playlist detail json error: %w", err)
+		return nil, nil, model.Errorf("kugou", model.ErrSchemaChanged, "playlist detail json error: %w", err)
 	}
 
 	playlist := &model.Playlist{
@@ -356,59 +526,71 @@ func (k *Kugou) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, e
 		})
 	}
 
-	playlist.TrackCount = len(songs)
+	playlist.TrackCount = resp.Data.Total
 
-	return playlist, songs, nil
+	return playlist, model.NewPage(songs, page, pageSize, resp.Data.Total, len(resp.Data.Info)), nil
 }
 
-// Parse 解析链接
-func (k *Kugou) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接
+func (k *Kugou) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`(?i)hash=([a-f0-9]{32})`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid kugou link or hash not found")
+		return nil, model.Errorf("kugou", model.ErrInvalidInput, "invalid kugou link or hash not found")
 	}
 	hash := matches[1]
-	return k.fetchSongInfo(hash)
+	return k.fetchSongInfo(ctx, hash)
 }
 
-// GetDownloadURL 获取下载链接
-func (k *Kugou) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (k *Kugou) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "kugou" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
 	}
 
+	streams, err := k.GetStreamsContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	st, _ := s.ChooseStream(streams)
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流。
+// 接口只按 hash 返回一路，音质由搜索时选中的 hash（SQ/HQ/普通）决定。
+func (k *Kugou) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "kugou" {
+		return nil, model.ErrSourceMismatch
+	}
+
 	hash := s.ID
 	if s.Extra != nil && s.Extra["hash"] != "" {
 		hash = s.Extra["hash"]
 	}
 
-	info, err := k.fetchSongInfo(hash)
+	info, err := k.fetchSongInfo(ctx, hash)
 	if err != nil {
-		return "", err
-	}
-	// 回写实际音质到 Song
-	if info.Ext != "" {
-		s.Ext = info.Ext
+		return nil, err
 	}
-	if info.Bitrate > 0 {
-		s.Bitrate = info.Bitrate
+	st := model.Stream{URL: info.URL, Ext: info.Ext, Bitrate: info.Bitrate, Size: info.Size}
+	if st.Ext == "flac" {
+		st.Bitrate = 0
 	}
-	return info.URL, nil
+	return []model.Stream{st}, nil
 }
 
 // fetchSongInfo 内部核心逻辑：获取详情和 URL
-func (k *Kugou) fetchSongInfo(hash string) (*model.Song, error) {
+func (k *Kugou) fetchSongInfo(ctx context.Context, hash string) (*model.Song, error) {
 	params := url.Values{}
 	params.Set("cmd", "playInfo")
 	params.Set("hash", hash)
 
 	apiURL := "http://m.kugou.com/app/i/getSongInfo.php?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Referer", MobileReferer),
 		utils.WithHeader("Cookie", k.cookie),
@@ -430,11 +612,11 @@ func (k *Kugou) fetchSongInfo(hash string) (*model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("json parse error: %w", err)
+		return nil, model.Errorf("kugou", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	if resp.URL == "" {
-		return nil, fmt.Errorf("[kugou] download url not found for song %s (might be paid song)", hash)
+		return nil, model.Errorf("kugou", model.ErrVIPRequired, "download url not found for song %s (might be paid song)", hash)
 	}
 
 	cover := strings.Replace(resp.AlbumImg, "{size}", "240", 1)
@@ -457,10 +639,26 @@ func (k *Kugou) fetchSongInfo(hash string) (*model.Song, error) {
 	}, nil
 }
 
-// GetLyrics 获取歌词
-func (k *Kugou) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (k *Kugou) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	content, err := k.downloadLyrics(ctx, s, "lrc")
+	if err != nil {
+		return "", err
+	}
+
+	decodedBytes, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", fmt.Errorf("base64 decode error: %w", err)
+	}
+
+	return string(decodedBytes), nil
+}
+
+// downloadLyrics 先按 hash 搜索歌词候选，再下载第一个候选的 format (lrc/krc) 格式歌词，
+// 返回接口里 base64 编码的 content
+func (k *Kugou) downloadLyrics(ctx context.Context, s *model.Song, format string) (string, error) {
 	if s.Source != "kugou" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	hash := s.ID
@@ -470,7 +668,7 @@ func (k *Kugou) GetLyrics(s *model.Song) (string, error) {
 
 	searchURL := fmt.Sprintf("http://krcs.kugou.com/search?ver=1&client=mobi&duration=&hash=%s&album_audio_id=", hash)
 
-	body, err := utils.Get(searchURL,
+	body, err := k.client.GetContext(ctx, searchURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Referer", MobileReferer),
 		utils.WithHeader("Cookie", k.cookie),
@@ -489,17 +687,17 @@ func (k *Kugou) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return "", fmt.Errorf("search lyrics json parse error: %w", err)
+		return "", model.Errorf("kugou", model.ErrSchemaChanged, "search lyrics json parse error: %w", err)
 	}
 
 	if len(searchResp.Candidates) == 0 {
-		return "", errors.New("lyrics not found")
+		return "", model.Errorf("kugou", model.ErrNotFound, "lyrics not found")
 	}
 
 	candidate := searchResp.Candidates[0]
-	downloadURL := fmt.Sprintf("http://lyrics.kugou.com/download?ver=1&client=pc&id=%v&accesskey=%s&fmt=lrc&charset=utf8", candidate.ID, candidate.AccessKey)
+	downloadURL := fmt.Sprintf("http://lyrics.kugou.com/download?ver=1&client=pc&id=%v&accesskey=%s&fmt=%s&charset=utf8", candidate.ID, candidate.AccessKey, format)
 
-	lrcBody, err := utils.Get(downloadURL,
+	lrcBody, err := k.client.GetContext(ctx, downloadURL,
 		utils.WithHeader("User-Agent", MobileUserAgent),
 		utils.WithHeader("Referer", MobileReferer),
 		utils.WithHeader("Cookie", k.cookie),
@@ -514,18 +712,12 @@ func (k *Kugou) GetLyrics(s *model.Song) (string, error) {
 		Fmt     string `json:"fmt"`
 	}
 	if err := json.Unmarshal(lrcBody, &downloadResp); err != nil {
-		return "", fmt.Errorf("download lyrics json parse error: %w", err)
+		return "", model.Errorf("kugou", model.ErrSchemaChanged, "download lyrics json parse error: %w", err)
 	}
 	if downloadResp.Content == "" {
-		return "", errors.New("lyrics content is empty")
+		return "", model.Errorf("kugou", model.ErrNotFound, "lyrics content is empty")
 	}
-
-	decodedBytes, err := base64.StdEncoding.DecodeString(downloadResp.Content)
-	if err != nil {
-		return "", fmt.Errorf("base64 decode error: %w", err)
-	}
-
-	return string(decodedBytes), nil
+	return downloadResp.Content, nil
 }
 
 func isValidHash(h string) bool {
@@ -0,0 +1,24 @@
+package kugou
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	song := &model.Song{Source: "kugou", ID: "8C6F2B1A9E0D4C7B6A5F4E3D2C1B0A99"}
+	replay.Run(t, newReplay, []replay.Case[*Kugou]{
+		{Name: "search", Call: func(_ *testing.T, k *Kugou) (any, error) { return k.Search("周杰伦") }},
+		{Name: "lyrics", Call: func(_ *testing.T, k *Kugou) (any, error) { return k.GetLyrics(song) }},
+		{Name: "word_lyrics", Call: func(_ *testing.T, k *Kugou) (any, error) { return k.GetWordLyrics(song) }},
+		{Name: "artist_search", Call: func(_ *testing.T, k *Kugou) (any, error) { return k.SearchArtist("周杰伦") }},
+		{Name: "artist_songs", Call: func(_ *testing.T, k *Kugou) (any, error) { return k.GetArtistTopSongs("3520") }},
+		// Two pages, the first of which says there is more.
+		{Name: "artist_albums", Len: 3, Call: func(_ *testing.T, k *Kugou) (any, error) { return k.GetArtistAlbums("3520") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Kugou { return New("", opts...) }
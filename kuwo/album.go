@@ -0,0 +1,194 @@
+package kuwo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// SearchAlbum 按关键词搜索专辑
+func SearchAlbum(keyword string) ([]model.Album, error) { return getDefault().SearchAlbum(keyword) }
+
+// GetAlbum 获取专辑及其曲目
+func GetAlbum(id string) (*model.Album, error) { return getDefault().GetAlbum(id) }
+
+// SearchAlbumContext 按关键词搜索专辑
+func SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	return getDefault().SearchAlbumContext(ctx, keyword)
+}
+
+// GetAlbumContext 获取专辑及其曲目
+func GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	return getDefault().GetAlbumContext(ctx, id)
+}
+
+// SearchAlbum 按关键词搜索专辑
+func (k *Kuwo) SearchAlbum(keyword string) ([]model.Album, error) {
+	return k.SearchAlbumContext(context.Background(), keyword)
+}
+
+// GetAlbum 获取专辑及其曲目
+func (k *Kuwo) GetAlbum(id string) (*model.Album, error) {
+	return k.GetAlbumContext(context.Background(), id)
+}
+
+// albumCover 把 r.s 搜索接口返回的相对图片路径转为 500px 的完整地址
+func albumCover(pic string) string {
+	if pic == "" {
+		return ""
+	}
+	if !strings.HasPrefix(pic, "http") {
+		pic = "http://img1.kwcdn.kuwo.cn/star/albumcover/" + strings.TrimPrefix(pic, "/")
+	}
+	for _, size := range []string{"/120/", "/150/", "/240/", "/300/"} {
+		if strings.Contains(pic, size) {
+			return strings.Replace(pic, size, "/500/", 1)
+		}
+	}
+	return pic
+}
+
+func splitArtists(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "&")
+}
+
+// SearchAlbumContext 按关键词搜索专辑
+func (k *Kuwo) SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	params := url.Values{}
+	params.Set("all", keyword)
+	params.Set("ft", "album")
+	params.Set("itemset", "web_2013")
+	params.Set("client", "kt")
+	params.Set("pn", "0")
+	params.Set("rn", "10")
+	params.Set("rformat", "json")
+	params.Set("encoding", "utf8")
+	apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		AlbumList []struct {
+			AlbumID  string `json:"albumid"`
+			Name     string `json:"name"`
+			Artist   string `json:"artist"`
+			Pub      string `json:"pub"`
+			MusicCnt string `json:"musiccnt"`
+			Pic      string `json:"pic"`
+			Info     string `json:"info"`
+		} `json:"albumlist"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "album search json error: %w", err)
+	}
+
+	albums := make([]model.Album, 0, len(resp.AlbumList))
+	for _, item := range resp.AlbumList {
+		albums = append(albums, model.Album{
+			ID:          item.AlbumID,
+			Name:        item.Name,
+			Artists:     splitArtists(item.Artist),
+			ReleaseDate: item.Pub,
+			Cover:       albumCover(item.Pic),
+			TrackCount:  utils.ParseAnyInt(item.MusicCnt),
+			Description: item.Info,
+			Source:      "kuwo",
+			Link:        fmt.Sprintf("http://www.kuwo.cn/album_detail/%s", item.AlbumID),
+		})
+	}
+	return albums, nil
+}
+
+// GetAlbumContext 一次请求取回专辑信息和歌曲。musiclist 按碟序返回但没有编号，
+// 曲目号按位置推算
+func (k *Kuwo) GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	params := url.Values{}
+	params.Set("stype", "albuminfo")
+	params.Set("albumid", id)
+	params.Set("pn", "0")
+	params.Set("rn", "1000")
+	params.Set("show_copyright_off", "1")
+	params.Set("encoding", "utf8")
+	params.Set("vipver", "MUSIC_9.1.0")
+	apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		AlbumID   string `json:"albumid"`
+		Name      string `json:"name"`
+		Artist    string `json:"artist"`
+		Pub       string `json:"pub"`
+		Pic       string `json:"pic"`
+		Info      string `json:"info"`
+		Songnum   string `json:"songnum"`
+		MusicList []struct {
+			ID       string      `json:"id"`
+			Name     string      `json:"name"`
+			Artist   string      `json:"artist"`
+			Duration interface{} `json:"duration"`
+		} `json:"musiclist"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "album json error: %w", err)
+	}
+	if resp.Name == "" && len(resp.MusicList) == 0 {
+		return nil, model.Errorf("kuwo", model.ErrNotFound, "album not found")
+	}
+
+	album := &model.Album{
+		ID:          id,
+		Name:        resp.Name,
+		Artists:     splitArtists(resp.Artist),
+		ReleaseDate: resp.Pub,
+		Cover:       albumCover(resp.Pic),
+		TrackCount:  utils.ParseAnyInt(resp.Songnum),
+		Description: resp.Info,
+		Source:      "kuwo",
+		Link:        fmt.Sprintf("http://www.kuwo.cn/album_detail/%s", id),
+	}
+	for i, item := range resp.MusicList {
+		album.Tracks = append(album.Tracks, model.AlbumTrack{
+			Song: model.Song{
+				Source:   "kuwo",
+				ID:       item.ID,
+				Name:     item.Name,
+				Artist:   item.Artist,
+				Album:    album.Name,
+				AlbumID:  id,
+				Duration: utils.ParseAnyInt(item.Duration),
+				Cover:    album.Cover,
+				Link:     fmt.Sprintf("http://www.kuwo.cn/play_detail/%s", item.ID),
+				Extra: map[string]string{
+					"rid": item.ID,
+				},
+			},
+			TrackNumber: i + 1,
+			DiscNumber:  1,
+		})
+	}
+	if album.TrackCount == 0 {
+		album.TrackCount = len(album.Tracks)
+	}
+	return album, nil
+}
@@ -0,0 +1,227 @@
+package kuwo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+const artistAlbumPageSize = 100
+
+// SearchArtist 按关键词搜索歌手
+func SearchArtist(keyword string) ([]model.Artist, error) { return getDefault().SearchArtist(keyword) }
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func GetArtistTopSongs(id string) ([]model.Song, error) { return getDefault().GetArtistTopSongs(id) }
+
+// GetArtistAlbums 获取歌手的全部专辑，从新到旧，不含曲目
+func GetArtistAlbums(id string) ([]model.Album, error) { return getDefault().GetArtistAlbums(id) }
+
+// SearchArtistContext 按关键词搜索歌手
+func SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	return getDefault().SearchArtistContext(ctx, keyword)
+}
+
+// GetArtistTopSongsContext 获取歌手最热门的歌曲
+func GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetArtistTopSongsContext(ctx, id)
+}
+
+// GetArtistAlbumsContext 获取歌手的全部专辑，不含曲目
+func GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	return getDefault().GetArtistAlbumsContext(ctx, id)
+}
+
+// SearchArtist 按关键词搜索歌手
+func (k *Kuwo) SearchArtist(keyword string) ([]model.Artist, error) {
+	return k.SearchArtistContext(context.Background(), keyword)
+}
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func (k *Kuwo) GetArtistTopSongs(id string) ([]model.Song, error) {
+	return k.GetArtistTopSongsContext(context.Background(), id)
+}
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func (k *Kuwo) GetArtistAlbums(id string) ([]model.Album, error) {
+	return k.GetArtistAlbumsContext(context.Background(), id)
+}
+
+// SearchArtistContext 按关键词搜索歌手
+func (k *Kuwo) SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	params := url.Values{}
+	params.Set("all", keyword)
+	params.Set("ft", "artist")
+	params.Set("itemset", "web_2013")
+	params.Set("client", "kt")
+	params.Set("pn", "0")
+	params.Set("rn", "10")
+	params.Set("rformat", "json")
+	params.Set("encoding", "utf8")
+	apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
+
+	body, err := k.getArtistAPI(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		AbsList []struct {
+			ArtistID string `json:"ARTISTID"`
+			Artist   string `json:"ARTIST"`
+			Aartist  string `json:"AARTIST"` // 外文名/别名
+			PicPath  string `json:"hts_PICPATH"`
+			SongNum  string `json:"SONGNUM"`
+			AlbumNum string `json:"ALBUMNUM"`
+		} `json:"abslist"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "artist search json error: %w", err)
+	}
+
+	artists := make([]model.Artist, 0, len(resp.AbsList))
+	for _, item := range resp.AbsList {
+		var aliases []string
+		if item.Aartist != "" {
+			aliases = []string{item.Aartist}
+		}
+		artists = append(artists, model.Artist{
+			ID:         item.ArtistID,
+			Name:       item.Artist,
+			Aliases:    aliases,
+			Avatar:     item.PicPath,
+			SongCount:  utils.ParseAnyInt(item.SongNum),
+			AlbumCount: utils.ParseAnyInt(item.AlbumNum),
+			Source:     "kuwo",
+			Link:       fmt.Sprintf("http://www.kuwo.cn/singer_detail/%s", item.ArtistID),
+		})
+	}
+	return artists, nil
+}
+
+// GetArtistTopSongsContext 返回按热度排序 (sortby=0) 的前 50 首
+func (k *Kuwo) GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	params := url.Values{}
+	params.Set("stype", "artist2music")
+	params.Set("artistid", id)
+	params.Set("sortby", "0")
+	params.Set("pn", "0")
+	params.Set("rn", "50")
+	params.Set("show_copyright_off", "1")
+	params.Set("encoding", "utf8")
+	params.Set("vipver", "MUSIC_9.1.0")
+	apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
+
+	body, err := k.getArtistAPI(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		MusicList []struct {
+			MusicRID string      `json:"musicrid"`
+			Name     string      `json:"name"`
+			Artist   string      `json:"artist"`
+			Album    string      `json:"album"`
+			AlbumID  string      `json:"albumid"`
+			Duration interface{} `json:"duration"`
+		} `json:"musiclist"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "artist songs json error: %w", err)
+	}
+
+	songs := make([]model.Song, 0, len(resp.MusicList))
+	for _, item := range resp.MusicList {
+		rid := strings.TrimPrefix(item.MusicRID, "MUSIC_")
+		if rid == "" {
+			continue
+		}
+		songs = append(songs, model.Song{
+			Source:   "kuwo",
+			ID:       rid,
+			Name:     item.Name,
+			Artist:   item.Artist,
+			Album:    item.Album,
+			AlbumID:  item.AlbumID,
+			Duration: utils.ParseAnyInt(item.Duration),
+			Link:     fmt.Sprintf("http://www.kuwo.cn/play_detail/%s", rid),
+			Extra: map[string]string{
+				"rid": rid,
+			},
+		})
+	}
+	return songs, nil
+}
+
+// GetArtistAlbumsContext 按发行时间排序 (sortby=1)，翻页直到达到接口报告的总数
+func (k *Kuwo) GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	return utils.FetchPages(func(page, offset int) ([]model.Album, bool, error) {
+		params := url.Values{}
+		params.Set("stype", "albumlist")
+		params.Set("artistid", id)
+		params.Set("sortby", "1")
+		params.Set("pn", strconv.Itoa(page))
+		params.Set("rn", strconv.Itoa(artistAlbumPageSize))
+		params.Set("show_copyright_off", "1")
+		params.Set("encoding", "utf8")
+		params.Set("vipver", "MUSIC_9.1.0")
+		apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
+
+		body, err := k.getArtistAPI(ctx, apiURL)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var resp struct {
+			Total     interface{} `json:"total"`
+			AlbumList []struct {
+				AlbumID  string `json:"albumid"`
+				Name     string `json:"name"`
+				Artist   string `json:"artist"`
+				Pub      string `json:"pub"`
+				Pic      string `json:"pic"`
+				MusicCnt string `json:"musiccnt"`
+			} `json:"albumlist"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false, model.Errorf("kuwo", model.ErrSchemaChanged, "artist albums json error: %w", err)
+		}
+
+		albums := make([]model.Album, 0, len(resp.AlbumList))
+		for _, item := range resp.AlbumList {
+			albums = append(albums, model.Album{
+				ID:          item.AlbumID,
+				Name:        item.Name,
+				Artists:     splitArtists(item.Artist),
+				ReleaseDate: item.Pub,
+				Cover:       albumCover(item.Pic),
+				TrackCount:  utils.ParseAnyInt(item.MusicCnt),
+				Source:      "kuwo",
+				Link:        fmt.Sprintf("http://www.kuwo.cn/album_detail/%s", item.AlbumID),
+			})
+		}
+		return albums, offset+len(albums) < utils.ParseAnyInt(resp.Total), nil
+	})
+}
+
+// getArtistAPI 请求 r.s 接口。部分接口返回单引号的伪 JSON，解码前先转换
+func (k *Kuwo) getArtistAPI(ctx context.Context, apiURL string) ([]byte, error) {
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > 0 && body[0] == '{' && !json.Valid(body) {
+		body = []byte(strings.ReplaceAll(string(body), "'", "\""))
+	}
+	return body, nil
+}
@@ -0,0 +1,129 @@
+package kuwo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Known Kuwo chart (bang) IDs.
+var kuwoCharts = []model.Chart{
+	{ID: "93", Name: "酷我飙升榜"},
+	{ID: "17", Name: "酷我新歌榜"},
+	{ID: "16", Name: "酷我热歌榜"},
+}
+
+// GetCharts returns the list of supported Kuwo charts.
+func GetCharts() ([]model.Chart, error) { return getDefault().GetCharts() }
+
+// GetChartSongs returns the top N songs from a Kuwo chart.
+func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongs(chartID, limit)
+}
+
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
+}
+
+func (k *Kuwo) GetCharts() ([]model.Chart, error) {
+	return k.GetChartsContext(context.Background())
+}
+
+func (k *Kuwo) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return k.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (k *Kuwo) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return kuwoCharts, nil
+}
+
+func (k *Kuwo) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	params := url.Values{}
+	params.Set("from", "pc")
+	params.Set("fmt", "json")
+	params.Set("type", "bang")
+	params.Set("data", "content")
+	params.Set("id", chartID)
+	params.Set("pn", "0")
+	params.Set("rn", strconv.Itoa(limit))
+	params.Set("isbang", "1")
+	params.Set("show_copyright_off", "1")
+	apiURL := "http://kbangserver.kuwo.cn/ksong.s?" + params.Encode()
+
+	body, err := k.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Cookie", k.cookie),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("kuwo chart request: %w", err)
+	}
+
+	var resp struct {
+		MusicList []struct {
+			ID               string      `json:"id"`
+			Name             string      `json:"name"`
+			Artist           string      `json:"artist"`
+			Album            string      `json:"album"`
+			AlbumID          string      `json:"albumid"`
+			Duration         interface{} `json:"duration"`
+			WebAlbumPicShort string      `json:"web_albumpic_short"`
+		} `json:"musiclist"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "chart json: %w", err)
+	}
+	if len(resp.MusicList) == 0 {
+		return nil, model.Errorf("kuwo", model.ErrNotFound, "chart %s is empty or unknown", chartID)
+	}
+
+	songs := make([]model.Song, 0, len(resp.MusicList))
+	for _, item := range resp.MusicList {
+		songs = append(songs, model.Song{
+			Source:   "kuwo",
+			ID:       item.ID,
+			Name:     item.Name,
+			Artist:   item.Artist,
+			Album:    item.Album,
+			AlbumID:  item.AlbumID,
+			Duration: utils.ParseAnyInt(item.Duration),
+			Cover:    chartCover(item.WebAlbumPicShort),
+			Link:     fmt.Sprintf("http://www.kuwo.cn/play_detail/%s", item.ID),
+			Extra: map[string]string{
+				"rid": item.ID,
+			},
+		})
+	}
+	if len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, nil
+}
+
+// chartCover turns a short album picture path such as
+// "120/s3s94/95/3527593617.jpg" into a 500px cover URL.
+func chartCover(short string) string {
+	if short == "" {
+		return ""
+	}
+	if strings.HasPrefix(short, "http") {
+		return short
+	}
+	if i := strings.IndexByte(short, '/'); i >= 0 {
+		short = short[i+1:]
+	}
+	return "https://img2.kuwo.cn/star/albumcover/500/" + short
+}
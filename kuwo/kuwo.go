@@ -1,14 +1,15 @@
 package kuwo
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
@@ -21,34 +22,173 @@ const (
 
 type Kuwo struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Kuwo { return &Kuwo{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *Kuwo {
+	return &Kuwo{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (k *Kuwo) Client() *utils.Client { return k.client }
+
+var (
+	defaultMu   sync.RWMutex
+	defaultKuwo = New("")
+)
 
-var defaultKuwo = New("")
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKuwo = New(defaultKuwo.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Kuwo {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKuwo
+}
 
-func Search(keyword string) ([]model.Song, error) { return defaultKuwo.Search(keyword) }
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultKuwo.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }
 func GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := defaultKuwo.fetchPlaylistDetail(id)
-	return songs, err
+	return getDefault().GetPlaylistSongs(id)
+}
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
-	return defaultKuwo.ParsePlaylist(link)
+	return getDefault().ParsePlaylist(link)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return defaultKuwo.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return defaultKuwo.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)       { return defaultKuwo.Parse(link) }
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
 
 // GetRecommendedPlaylists 获取推荐歌单 (新增)
 func GetRecommendedPlaylists() ([]model.Playlist, error) {
-	return defaultKuwo.GetRecommendedPlaylists()
+	return getDefault().GetRecommendedPlaylists()
+}
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
+	return getDefault().GetRecommendedPlaylistsContext(ctx)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
 }
 
-// Search 搜索歌曲
 func (k *Kuwo) Search(keyword string) ([]model.Song, error) {
+	return k.SearchContext(context.Background(), keyword)
+}
+
+func (k *Kuwo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return k.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (k *Kuwo) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return k.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (k *Kuwo) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return k.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (k *Kuwo) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return k.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (k *Kuwo) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return k.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (k *Kuwo) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return k.ParsePlaylistContext(context.Background(), link)
+}
+
+func (k *Kuwo) GetRecommendedPlaylists() ([]model.Playlist, error) {
+	return k.GetRecommendedPlaylistsContext(context.Background())
+}
+
+func (k *Kuwo) Parse(link string) (*model.Song, error) {
+	return k.ParseContext(context.Background(), link)
+}
+
+func (k *Kuwo) GetDownloadURL(s *model.Song) (string, error) {
+	return k.GetDownloadURLContext(context.Background(), s)
+}
+
+func (k *Kuwo) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return k.GetStreamsContext(context.Background(), s)
+}
+
+func (k *Kuwo) GetLyrics(s *model.Song) (string, error) {
+	return k.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (k *Kuwo) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := k.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，接口的 pn 从 0 开始
+func (k *Kuwo) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	params := url.Values{}
 	params.Set("vipver", "1")
 	params.Set("client", "kt")
@@ -60,13 +200,13 @@ func (k *Kuwo) Search(keyword string) ([]model.Song, error) {
 	params.Set("mobi", "1")
 	params.Set("issubtitle", "1")
 	params.Set("show_copyright_off", "1")
-	params.Set("pn", "0")
-	params.Set("rn", "10")
+	params.Set("pn", strconv.Itoa(page-1))
+	params.Set("rn", strconv.Itoa(limit))
 	params.Set("all", keyword)
 
 	apiURL := "http://www.kuwo.cn/search/searchMusicBykeyWord?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -86,10 +226,11 @@ func (k *Kuwo) Search(keyword string) ([]model.Song, error) {
 			PayInfo   string `json:"PAY"`
 			BitSwitch int    `json:"bitSwitch"`
 		} `json:"abslist"`
+		Total string `json:"TOTAL"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("kuwo json parse error: %w", err)
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -120,11 +261,22 @@ func (k *Kuwo) Search(keyword string) ([]model.Song, error) {
 		})
 	}
 
-	return songs, nil
+	total, _ := strconv.Atoi(resp.Total)
+	return model.NewPage(songs, page, limit, total, len(resp.AbsList)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (k *Kuwo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (k *Kuwo) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := k.SearchPlaylistPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (k *Kuwo) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	params := url.Values{}
 	params.Set("all", keyword)
 	params.Set("ft", "playlist")
@@ -133,14 +285,14 @@ func (k *Kuwo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	params.Set("pcmp4", "1")
 	params.Set("geo", "c")
 	params.Set("vipver", "1")
-	params.Set("pn", "0")
-	params.Set("rn", "10")
+	params.Set("pn", strconv.Itoa(page-1))
+	params.Set("rn", strconv.Itoa(limit))
 	params.Set("rformat", "json")
 	params.Set("encoding", "utf8")
 
 	apiURL := "http://search.kuwo.cn/r.s?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -160,10 +312,11 @@ func (k *Kuwo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Intro      string `json:"intro"`
 			NickName   string `json:"nickname"`
 		} `json:"abslist"`
+		Total string `json:"TOTAL"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &resp); err != nil {
-		return nil, fmt.Errorf("kuwo playlist json parse error: %w", err)
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -189,30 +342,38 @@ func (k *Kuwo) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Link: fmt.Sprintf("http://www.kuwo.cn/playlist_detail/%s", item.PlaylistID),
 		})
 	}
-	return playlists, nil
+	total, _ := strconv.Atoi(resp.Total)
+	return model.NewPage(playlists, page, limit, total, len(resp.AbsList)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情（解析歌曲列表）
-func (k *Kuwo) GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := k.fetchPlaylistDetail(id)
+// GetPlaylistSongsContext 获取歌单详情（解析歌曲列表）
+func (k *Kuwo) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	_, songs, err := k.fetchPlaylistDetail(ctx, id)
 	return songs, err
 }
 
-// ParsePlaylist 解析歌单链接
-func (k *Kuwo) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲
+func (k *Kuwo) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 100)
+	_, result, err := k.fetchPlaylistPage(ctx, id, page, limit)
+	return result, err
+}
+
+// ParsePlaylistContext 解析歌单链接
+func (k *Kuwo) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	// 链接格式: http://www.kuwo.cn/playlist_detail/1082685103
 	re := regexp.MustCompile(`playlist_detail/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, nil, errors.New("invalid kuwo playlist link")
+		return nil, nil, model.Errorf("kuwo", model.ErrInvalidInput, "invalid kuwo playlist link")
 	}
 	playlistID := matches[1]
 
-	return k.fetchPlaylistDetail(playlistID)
+	return k.fetchPlaylistDetail(ctx, playlistID)
 }
 
-// GetRecommendedPlaylists 获取推荐歌单 (酷我热门歌单)
-func (k *Kuwo) GetRecommendedPlaylists() ([]model.Playlist, error) {
+// GetRecommendedPlaylistsContext 获取推荐歌单 (酷我热门歌单)
+func (k *Kuwo) GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
 	// 使用 wapi 接口获取热门推荐歌单，不需要复杂 Token
 	params := url.Values{}
 	params.Set("pn", "0")
@@ -221,7 +382,7 @@ func (k *Kuwo) GetRecommendedPlaylists() ([]model.Playlist, error) {
 
 	apiURL := "http://wapi.kuwo.cn/api/pc/classify/playlist/getRcmPlayList?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -247,11 +408,11 @@ func (k *Kuwo) GetRecommendedPlaylists() ([]model.Playlist, error) {
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("kuwo recommend json parse error: %w", err)
+		return nil, model.Errorf("kuwo", model.ErrSchemaChanged, "recommend json parse error: %w", err)
 	}
 
 	if resp.Code != 200 {
-		return nil, fmt.Errorf("kuwo api error code: %d", resp.Code)
+		return nil, model.CodeError("kuwo", nil, resp.Code, "api error")
 	}
 
 	var playlists []model.Playlist
@@ -288,19 +449,29 @@ func (k *Kuwo) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	if len(playlists) == 0 {
-		return nil, errors.New("no recommended playlists found")
+		return nil, model.Errorf("kuwo", model.ErrNotFound, "no recommended playlists found")
 	}
 
 	return playlists, nil
 }
 
 // fetchPlaylistDetail [内部复用] 获取歌单详情 (Metadata + Songs)
-func (k *Kuwo) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, error) {
+func (k *Kuwo) fetchPlaylistDetail(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
+	playlist, result, err := k.fetchPlaylistPage(ctx, id, 1, 100)
+	if err != nil {
+		return nil, nil, err
+	}
+	playlist.TrackCount = len(result.Items)
+	return playlist, result.Items, nil
+}
+
+// fetchPlaylistPage 按页获取歌单歌曲，接口的 pn 从 0 开始
+func (k *Kuwo) fetchPlaylistPage(ctx context.Context, id string, page, pageSize int) (*model.Playlist, *model.Page[model.Song], error) {
 	params := url.Values{}
 	params.Set("op", "getlistinfo")
 	params.Set("pid", id)
-	params.Set("pn", "0")
-	params.Set("rn", "100")
+	params.Set("pn", strconv.Itoa(page-1))
+	params.Set("rn", strconv.Itoa(pageSize))
 	params.Set("encode", "utf8")
 	params.Set("keyset", "pl2012")
 	params.Set("identity", "kuwo")
@@ -310,7 +481,7 @@ func (k *Kuwo) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 
 	apiURL := "http://nplserver.kuwo.cn/pl.svc?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -329,21 +500,24 @@ func (k *Kuwo) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 			SongName   string      `json:"song_name"`
 			ArtistName string      `json:"artist_name"`
 		} `json:"musiclist"`
+		Total interface{} `json:"total"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("kuwo playlist detail json error: %w", err)
+		return nil, nil, model.Errorf("kuwo", model.ErrSchemaChanged, "playlist detail json error: %w", err)
 	}
 
-	if len(resp.MusicList) == 0 {
-		return nil, nil, errors.New("playlist is empty or id is invalid")
+	// 翻页越界时返回空列表是正常的，只有第一页为空才视为无效歌单
+	if len(resp.MusicList) == 0 && page == 1 {
+		return nil, nil, model.Errorf("kuwo", model.ErrNotFound, "playlist is empty or id is invalid")
 	}
 
+	total := utils.ParseAnyInt(resp.Total)
 	playlist := &model.Playlist{
 		Source:     "kuwo",
 		ID:         id,
 		Link:       fmt.Sprintf("http://www.kuwo.cn/playlist_detail/%s", id),
-		TrackCount: len(resp.MusicList),
+		TrackCount: total,
 	}
 
 	var songs []model.Song
@@ -387,25 +561,25 @@ func (k *Kuwo) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 			},
 		})
 	}
-	return playlist, songs, nil
+	return playlist, model.NewPage(songs, page, pageSize, total, len(resp.MusicList)), nil
 }
 
-// Parse 解析链接并获取完整信息
-func (k *Kuwo) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接并获取完整信息
+func (k *Kuwo) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`play_detail/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid kuwo link, rid not found")
+		return nil, model.Errorf("kuwo", model.ErrInvalidInput, "invalid kuwo link, rid not found")
 	}
 	rid := matches[1]
 
-	return k.fetchFullSongInfo(rid)
+	return k.fetchFullSongInfo(ctx, rid)
 }
 
-// GetDownloadURL 获取下载链接
-func (k *Kuwo) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (k *Kuwo) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "kuwo" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
@@ -416,23 +590,38 @@ func (k *Kuwo) GetDownloadURL(s *model.Song) (string, error) {
 		rid = s.Extra["rid"]
 	}
 
-	quality := ""
-	if s.Extra != nil {
-		quality = s.Extra["quality"]
+	streams, err := k.fetchStreams(ctx, rid)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("kuwo", model.ErrRestricted, "download url not found for song %s (copyright restricted)", rid)
 	}
+	return st.URL, nil
+}
 
-	return k.fetchAudioURLWithQuality(rid, quality)
+// GetStreamsContext 列出歌曲可用的音频流
+func (k *Kuwo) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "kuwo" {
+		return nil, model.ErrSourceMismatch
+	}
+	rid := s.ID
+	if s.Extra != nil && s.Extra["rid"] != "" {
+		rid = s.Extra["rid"]
+	}
+	return k.fetchStreams(ctx, rid)
 }
 
 // fetchFullSongInfo 内部聚合：同时获取元数据和下载链接
-func (k *Kuwo) fetchFullSongInfo(rid string) (*model.Song, error) {
+func (k *Kuwo) fetchFullSongInfo(ctx context.Context, rid string) (*model.Song, error) {
 	params := url.Values{}
 	params.Set("musicId", rid)
 	params.Set("httpsStatus", "1")
 	metaURL := "http://m.kuwo.cn/newh5/singles/songinfoandlrc?" + params.Encode()
 
 	var name, artist, cover string
-	metaBody, err := utils.Get(metaURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", k.cookie))
+	metaBody, err := k.client.GetContext(ctx, metaURL, utils.WithHeader("User-Agent", UserAgent), utils.WithHeader("Cookie", k.cookie))
 
 	if err == nil {
 		var metaResp struct {
@@ -455,7 +644,7 @@ func (k *Kuwo) fetchFullSongInfo(rid string) (*model.Song, error) {
 		name = fmt.Sprintf("Kuwo_Song_%s", rid)
 	}
 
-	audioURL, err := k.fetchAudioURL(rid)
+	audioURL, err := k.fetchAudioURL(ctx, rid)
 	if err != nil {
 		return nil, err
 	}
@@ -474,25 +663,24 @@ func (k *Kuwo) fetchFullSongInfo(rid string) (*model.Song, error) {
 	}, nil
 }
 
-// fetchAudioURL 内部核心：仅获取下载链接（默认无损优先）
-func (k *Kuwo) fetchAudioURL(rid string) (string, error) {
-	return k.fetchAudioURLWithQuality(rid, "lossless")
+// fetchAudioURL 内部核心：仅获取下载链接（无损优先）
+func (k *Kuwo) fetchAudioURL(ctx context.Context, rid string) (string, error) {
+	streams, err := k.fetchStreams(ctx, rid)
+	if err != nil {
+		return "", err
+	}
+	st, _ := model.SelectStream(streams, model.QualityLossless)
+	return st.URL, nil
 }
 
-// fetchAudioURLWithQuality 根据音质偏好获取下载链接
-func (k *Kuwo) fetchAudioURLWithQuality(rid string, quality string) (string, error) {
-	var qualities []string
-	switch quality {
-	case "standard":
-		qualities = []string{"128kmp3"}
-	case "high":
-		qualities = []string{"320kmp3", "128kmp3"}
-	default: // "lossless" or empty
-		qualities = []string{"2000kflac", "flac", "320kmp3", "128kmp3"}
-	}
+// fetchStreams 逐个音质请求 convert_url_with_sign。
+// 接口在没有对应音质时会降级返回较低的码率，按 URL 去重。
+func (k *Kuwo) fetchStreams(ctx context.Context, rid string) ([]model.Stream, error) {
 	randomID := fmt.Sprintf("C_APK_guanwang_%d%d", time.Now().UnixNano(), rand.Intn(1000000))
 
-	for _, br := range qualities {
+	var streams []model.Stream
+	seen := make(map[string]bool)
+	for _, br := range []string{"2000kflac", "flac", "320kmp3", "128kmp3"} {
 		params := url.Values{}
 		params.Set("f", "web")
 		params.Set("source", "kwplayercar_ar_6.0.0.9_B_jiakong_vh.apk")
@@ -504,11 +692,14 @@ func (k *Kuwo) fetchAudioURLWithQuality(rid string, quality string) (string, err
 
 		apiURL := "https://mobi.kuwo.cn/mobi.s?" + params.Encode()
 
-		body, err := utils.Get(apiURL,
+		body, err := k.client.GetContext(ctx, apiURL,
 			utils.WithHeader("User-Agent", UserAgent),
 			utils.WithHeader("Cookie", k.cookie),
 		)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			continue
 		}
 
@@ -522,18 +713,38 @@ func (k *Kuwo) fetchAudioURLWithQuality(rid string, quality string) (string, err
 		if err := json.Unmarshal(body, &resp); err != nil {
 			continue
 		}
-		if resp.Data.URL != "" {
-			return resp.Data.URL, nil
+		if resp.Data.URL == "" || seen[resp.Data.URL] {
+			continue
+		}
+		seen[resp.Data.URL] = true
+
+		st := model.Stream{
+			URL:   resp.Data.URL,
+			Ext:   strings.ToLower(resp.Data.Format),
+			Label: br,
+		}
+		if st.Ext == "" {
+			st.Ext = "mp3"
+			if strings.HasSuffix(br, "flac") {
+				st.Ext = "flac"
+			}
 		}
+		if st.Ext != "flac" {
+			st.Bitrate = resp.Data.Bitrate
+		}
+		streams = append(streams, st)
 	}
 
-	return "", fmt.Errorf("[kuwo] download url not found for song %s (copyright restricted)", rid)
+	if len(streams) == 0 {
+		return nil, model.Errorf("kuwo", model.ErrRestricted, "download url not found for song %s (copyright restricted)", rid)
+	}
+	return streams, nil
 }
 
-// GetLyrics 获取歌词
-func (k *Kuwo) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (k *Kuwo) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "kuwo" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	rid := s.ID
@@ -546,7 +757,7 @@ func (k *Kuwo) GetLyrics(s *model.Song) (string, error) {
 	params.Set("httpsStatus", "1")
 
 	apiURL := "http://m.kuwo.cn/newh5/singles/songinfoandlrc?" + params.Encode()
-	body, err := utils.Get(apiURL,
+	body, err := k.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", k.cookie),
 	)
@@ -563,7 +774,7 @@ func (k *Kuwo) GetLyrics(s *model.Song) (string, error) {
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("failed to parse kuwo lyric JSON: %w", err)
+		return "", model.Errorf("kuwo", model.ErrSchemaChanged, "failed to parse kuwo lyric JSON: %w", err)
 	}
 
 	if len(resp.Data.Lrclist) == 0 {
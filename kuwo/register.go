@@ -0,0 +1,13 @@
+package kuwo
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "kuwo",
+		DisplayName: "酷我音乐",
+		Qualities:   []string{registry.QualityLossless, registry.QualityHigh, registry.QualityStandard},
+		Canary:      registry.Canary{Keyword: "周杰伦", SongID: "228908"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
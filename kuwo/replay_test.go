@@ -0,0 +1,21 @@
+package kuwo
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Kuwo]{
+		{Name: "search", Call: func(_ *testing.T, k *Kuwo) (any, error) { return k.Search("周杰伦") }},
+		{Name: "charts", Call: func(_ *testing.T, k *Kuwo) (any, error) { return k.GetChartSongs("93", 2) }},
+		{Name: "artist_search", Call: func(_ *testing.T, k *Kuwo) (any, error) { return k.SearchArtist("周杰伦") }},
+		{Name: "artist_songs", Call: func(_ *testing.T, k *Kuwo) (any, error) { return k.GetArtistTopSongs("336") }},
+		// Two pages, the first of which says there is more.
+		{Name: "artist_albums", Len: 3, Call: func(_ *testing.T, k *Kuwo) (any, error) { return k.GetArtistAlbums("336") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Kuwo { return New("", opts...) }
@@ -0,0 +1,222 @@
+package migu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+const artistAlbumPageSize = 50
+
+// SearchArtist 按关键词搜索歌手
+func SearchArtist(keyword string) ([]model.Artist, error) { return getDefault().SearchArtist(keyword) }
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func GetArtistTopSongs(id string) ([]model.Song, error) { return getDefault().GetArtistTopSongs(id) }
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func GetArtistAlbums(id string) ([]model.Album, error) { return getDefault().GetArtistAlbums(id) }
+
+// SearchArtistContext 按关键词搜索歌手
+func SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	return getDefault().SearchArtistContext(ctx, keyword)
+}
+
+// GetArtistTopSongsContext 获取歌手最热门的歌曲
+func GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetArtistTopSongsContext(ctx, id)
+}
+
+// GetArtistAlbumsContext 获取歌手的全部专辑，不含曲目
+func GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	return getDefault().GetArtistAlbumsContext(ctx, id)
+}
+
+// SearchArtist 按关键词搜索歌手
+func (m *Migu) SearchArtist(keyword string) ([]model.Artist, error) {
+	return m.SearchArtistContext(context.Background(), keyword)
+}
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func (m *Migu) GetArtistTopSongs(id string) ([]model.Song, error) {
+	return m.GetArtistTopSongsContext(context.Background(), id)
+}
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func (m *Migu) GetArtistAlbums(id string) ([]model.Album, error) {
+	return m.GetArtistAlbumsContext(context.Background(), id)
+}
+
+// SearchArtistContext 复用 search_all.do，只打开歌手开关
+func (m *Migu) SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	params := url.Values{}
+	params.Set("ua", "Android_migu")
+	params.Set("version", "5.0.1")
+	params.Set("text", keyword)
+	params.Set("pageNo", "1")
+	params.Set("pageSize", "10")
+	params.Set("searchSwitch", `{"song":0,"album":0,"singer":1,"tagSong":0,"mvSong":0,"songlist":0,"bestShow":1}`)
+	apiURL := "http://pd.musicapp.migu.cn/MIGUM2.0/v1.0/content/search_all.do?" + params.Encode()
+
+	body, err := m.getJSON(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		SingerResultData struct {
+			Result []struct {
+				ID         string `json:"id"`
+				Name       string `json:"name"`
+				SongCount  string `json:"songCount"`
+				AlbumCount string `json:"albumCount"`
+				ImgItems   []struct {
+					Img string `json:"img"`
+				} `json:"imgItems"`
+			} `json:"result"`
+		} `json:"singerResultData"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "artist search json parse error: %w", err)
+	}
+
+	artists := make([]model.Artist, 0, len(resp.SingerResultData.Result))
+	for _, item := range resp.SingerResultData.Result {
+		var avatar string
+		if len(item.ImgItems) > 0 {
+			avatar = item.ImgItems[0].Img
+		}
+		artists = append(artists, model.Artist{
+			ID:         item.ID,
+			Name:       item.Name,
+			Avatar:     avatar,
+			SongCount:  utils.ParseAnyInt(item.SongCount),
+			AlbumCount: utils.ParseAnyInt(item.AlbumCount),
+			Source:     "migu",
+			Link:       fmt.Sprintf("https://music.migu.cn/v3/music/artist/%s", item.ID),
+		})
+	}
+	return artists, nil
+}
+
+// GetArtistTopSongsContext 返回歌手的前 50 首。条目与搜索结果结构相同，
+// 付费歌曲同样由 convertItemToSong 过滤
+func (m *Migu) GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	if id == "" {
+		return nil, model.Errorf("migu", model.ErrInvalidInput, "empty migu singer id")
+	}
+	params := url.Values{}
+	params.Set("singerId", id)
+	params.Set("pageNo", "1")
+	params.Set("pageSize", "50")
+	apiURL := "https://app.c.nf.migu.cn/MIGUM3.0/resource/singer/song/v2.0?" + params.Encode()
+
+	body, err := m.getJSON(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+		Data struct {
+			Items []MiguSongItem `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "artist songs json parse error: %w", err)
+	}
+	if resp.Code != "" && resp.Code != "000000" {
+		return nil, apiError(resp.Code, resp.Info)
+	}
+
+	songs := make([]model.Song, 0, len(resp.Data.Items))
+	for _, item := range resp.Data.Items {
+		if song := m.convertItemToSong(item); song != nil {
+			songs = append(songs, *song)
+		}
+	}
+	return songs, nil
+}
+
+// GetArtistAlbumsContext 翻页取回全部专辑，直到达到接口报告的总数
+func (m *Migu) GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	if id == "" {
+		return nil, model.Errorf("migu", model.ErrInvalidInput, "empty migu singer id")
+	}
+
+	return utils.FetchPages(func(page, offset int) ([]model.Album, bool, error) {
+		params := url.Values{}
+		params.Set("singerId", id)
+		params.Set("pageNo", strconv.Itoa(page+1))
+		params.Set("pageSize", strconv.Itoa(artistAlbumPageSize))
+		apiURL := "https://app.c.nf.migu.cn/MIGUM3.0/resource/singer/album/v2.0?" + params.Encode()
+
+		body, err := m.getJSON(ctx, apiURL)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var resp struct {
+			Code string `json:"code"`
+			Info string `json:"info"`
+			Data struct {
+				TotalCount interface{} `json:"totalCount"`
+				Items      []struct {
+					AlbumID     string `json:"albumId"`
+					AlbumName   string `json:"albumName"`
+					PublishTime string `json:"publishTime"`
+					TotalCount  string `json:"totalCount"`
+					Singers     []struct {
+						Name string `json:"name"`
+					} `json:"singers"`
+					ImgItems []struct {
+						Img string `json:"img"`
+					} `json:"imgItems"`
+				} `json:"items"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false, model.Errorf("migu", model.ErrSchemaChanged, "artist albums json parse error: %w", err)
+		}
+		if resp.Code != "" && resp.Code != "000000" {
+			return nil, false, apiError(resp.Code, resp.Info)
+		}
+
+		albums := make([]model.Album, 0, len(resp.Data.Items))
+		for _, item := range resp.Data.Items {
+			var artists []string
+			for _, s := range item.Singers {
+				artists = append(artists, s.Name)
+			}
+			var cover string
+			if len(item.ImgItems) > 0 {
+				cover = item.ImgItems[0].Img
+			}
+			albums = append(albums, model.Album{
+				ID:          item.AlbumID,
+				Name:        item.AlbumName,
+				Artists:     artists,
+				ReleaseDate: item.PublishTime,
+				Cover:       cover,
+				TrackCount:  utils.ParseAnyInt(item.TotalCount),
+				Source:      "migu",
+				Link:        fmt.Sprintf("https://music.migu.cn/v3/music/album/%s", item.AlbumID),
+			})
+		}
+		return albums, offset+len(albums) < utils.ParseAnyInt(resp.Data.TotalCount), nil
+	})
+}
+
+func (m *Migu) getJSON(ctx context.Context, apiURL string) ([]byte, error) {
+	return m.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Cookie", m.cookie),
+	)
+}
@@ -0,0 +1,144 @@
+package migu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Known Migu chart column IDs.
+var miguCharts = []model.Chart{
+	{ID: "27553319", Name: "尖叫新歌榜"},
+	{ID: "27186466", Name: "尖叫热歌榜"},
+	{ID: "27553408", Name: "尖叫原创榜"},
+}
+
+// GetCharts returns the list of supported Migu charts.
+func GetCharts() ([]model.Chart, error) { return getDefault().GetCharts() }
+
+// GetChartSongs returns the top N songs from a Migu chart.
+func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongs(chartID, limit)
+}
+
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
+}
+
+func (m *Migu) GetCharts() ([]model.Chart, error) {
+	return m.GetChartsContext(context.Background())
+}
+
+func (m *Migu) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return m.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (m *Migu) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return miguCharts, nil
+}
+
+// GetChartSongsContext fetches a chart column. The column always holds the
+// whole chart, so limit is applied locally.
+func (m *Migu) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	params := url.Values{}
+	params.Set("columnId", chartID)
+	params.Set("needAll", "0")
+	apiURL := "https://app.c.nf.migu.cn/MIGUM2.0/v1.0/content/querycontentbyId.do?" + params.Encode()
+
+	body, err := m.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Cookie", m.cookie),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("migu chart request: %w", err)
+	}
+
+	var resp struct {
+		Code       string `json:"code"`
+		Info       string `json:"info"`
+		ColumnInfo struct {
+			Contents []struct {
+				ObjectInfo struct {
+					ContentID   string `json:"contentId"`
+					CopyrightID string `json:"copyrightId"`
+					SongName    string `json:"songName"`
+					Singer      string `json:"singer"`
+					Album       string `json:"album"`
+					AlbumID     string `json:"albumId"`
+					Length      string `json:"length"` // 00:04:03
+					AlbumImgs   []struct {
+						Img string `json:"img"`
+					} `json:"albumImgs"`
+				} `json:"objectInfo"`
+			} `json:"contents"`
+		} `json:"columnInfo"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "chart json: %w", err)
+	}
+	if resp.Code != "000000" {
+		return nil, apiError(resp.Code, resp.Info)
+	}
+
+	var songs []model.Song
+	for _, c := range resp.ColumnInfo.Contents {
+		item := c.ObjectInfo
+		if item.ContentID == "" {
+			continue
+		}
+		cover := ""
+		if len(item.AlbumImgs) > 0 {
+			cover = item.AlbumImgs[0].Img
+		}
+		songs = append(songs, model.Song{
+			Source:   "migu",
+			ID:       item.ContentID,
+			Name:     item.SongName,
+			Artist:   item.Singer,
+			Album:    item.Album,
+			AlbumID:  item.AlbumID,
+			Duration: parseLength(item.Length),
+			Cover:    cover,
+			Link:     fmt.Sprintf("https://music.migu.cn/v3/music/song/%s", item.CopyrightID),
+			Extra: map[string]string{
+				"content_id":   item.ContentID,
+				"copyright_id": item.CopyrightID,
+			},
+		})
+		if len(songs) == limit {
+			break
+		}
+	}
+	if len(songs) == 0 {
+		return nil, model.Errorf("migu", model.ErrNotFound, "chart %s is empty or unknown", chartID)
+	}
+	return songs, nil
+}
+
+// parseLength converts "hh:mm:ss" or "mm:ss" to seconds.
+func parseLength(s string) int {
+	secs := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		secs = secs*60 + n
+	}
+	return secs
+}
@@ -1,8 +1,8 @@
 package migu
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -23,34 +24,158 @@ const (
 
 type Migu struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Migu { return &Migu{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *Migu {
+	return &Migu{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (m *Migu) Client() *utils.Client { return m.client }
+
+var (
+	defaultMu   sync.RWMutex
+	defaultMigu = New("")
+)
 
-var defaultMigu = New("")
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultMigu = New(defaultMigu.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Migu {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultMigu
+}
 
-func Search(keyword string) ([]model.Song, error) { return defaultMigu.Search(keyword) }
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultMigu.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }                                                      // [新增]
-func GetPlaylistSongs(id string) ([]model.Song, error) { return defaultMigu.GetPlaylistSongs(id) } // [新增]
-func GetDownloadURL(s *model.Song) (string, error)     { return defaultMigu.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)          { return defaultMigu.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)           { return defaultMigu.Parse(link) }
+func GetPlaylistSongs(id string) ([]model.Song, error) { return getDefault().GetPlaylistSongs(id) } // [新增]
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
+}
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
 
-// Search 搜索歌曲
 func (m *Migu) Search(keyword string) ([]model.Song, error) {
+	return m.SearchContext(context.Background(), keyword)
+}
+
+func (m *Migu) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return m.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (m *Migu) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return m.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (m *Migu) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return m.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (m *Migu) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return m.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (m *Migu) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return m.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (m *Migu) Parse(link string) (*model.Song, error) {
+	return m.ParseContext(context.Background(), link)
+}
+
+func (m *Migu) GetDownloadURL(s *model.Song) (string, error) {
+	return m.GetDownloadURLContext(context.Background(), s)
+}
+
+func (m *Migu) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return m.GetStreamsContext(context.Background(), s)
+}
+
+func (m *Migu) GetLyrics(s *model.Song) (string, error) {
+	return m.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (m *Migu) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := m.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，page 从 1 开始
+func (m *Migu) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 50)
 	params := url.Values{}
 	params.Set("ua", "Android_migu")
 	params.Set("version", "5.0.1")
 	params.Set("text", keyword)
-	params.Set("pageNo", "1")
-	params.Set("pageSize", "10")
+	params.Set("pageNo", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(limit))
 	params.Set("searchSwitch", `{"song":1,"album":0,"singer":0,"tagSong":0,"mvSong":0,"songlist":0,"bestShow":1}`)
 
 	apiURL := "http://pd.musicapp.migu.cn/MIGUM2.0/v1.0/content/search_all.do?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := m.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", m.cookie),
@@ -61,12 +186,13 @@ func (m *Migu) Search(keyword string) ([]model.Song, error) {
 
 	var resp struct {
 		SongResultData struct {
-			Result []MiguSongItem `json:"result"`
+			Result     []MiguSongItem `json:"result"`
+			TotalCount interface{}    `json:"totalCount"`
 		} `json:"songResultData"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("migu json parse error: %w", err)
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -76,23 +202,34 @@ func (m *Migu) Search(keyword string) ([]model.Song, error) {
 			songs = append(songs, *song)
 		}
 	}
-	return songs, nil
+	total := utils.ParseAnyInt(resp.SongResultData.TotalCount)
+	return model.NewPage(songs, page, limit, total, len(resp.SongResultData.Result)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (m *Migu) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (m *Migu) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := m.SearchPlaylistPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (m *Migu) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 50)
 	params := url.Values{}
 	params.Set("ua", "Android_migu")
 	params.Set("version", "5.0.1")
 	params.Set("text", keyword)
-	params.Set("pageNo", "1")
-	params.Set("pageSize", "10")
+	params.Set("pageNo", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(limit))
 	// 切换开关：songlist:1
 	params.Set("searchSwitch", `{"song":0,"album":0,"singer":0,"tagSong":0,"mvSong":0,"songlist":1,"bestShow":1}`)
 
 	apiURL := "http://pd.musicapp.migu.cn/MIGUM2.0/v1.0/content/search_all.do?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := m.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", m.cookie),
@@ -112,11 +249,12 @@ func (m *Migu) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 					Img string `json:"img"`
 				} `json:"imgItems"`
 			} `json:"result"`
+			TotalCount interface{} `json:"totalCount"`
 		} `json:"songListResultData"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("migu playlist json parse error: %w", err)
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -136,23 +274,37 @@ func (m *Migu) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Creator:    item.UserName,
 		})
 	}
-	return playlists, nil
+	total := utils.ParseAnyInt(resp.SongListResultData.TotalCount)
+	return model.NewPage(playlists, page, limit, total, len(resp.SongListResultData.Result)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情（解析歌曲列表）
-func (m *Migu) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取歌单详情（解析歌曲列表）
+func (m *Migu) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	page, err := m.GetPlaylistSongsPageContext(ctx, id, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, model.Errorf("migu", model.ErrNotFound, "playlist is empty")
+	}
+	return page.Items, nil
+}
+
+// GetPlaylistSongsPageContext 分页获取歌单歌曲
+func (m *Migu) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 100)
 	// [修复] 使用 musicListContent.do 接口
 	// resourceinfo.do (类型2021) 只返回歌单简介，不返回歌曲列表
 	// musicListContent.do 才是获取列表内容的正确接口
 	params := url.Values{}
 	params.Set("musicListId", id) // 参数名是 musicListId
-	params.Set("pageNo", "1")
-	params.Set("pageSize", "100")
+	params.Set("pageNo", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(limit))
 
 	// 保持域名 c.musicapp.migu.cn 不变
 	apiURL := "http://c.musicapp.migu.cn/MIGUM2.0/v1.0/content/musicListContent.do?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := m.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", m.cookie),
@@ -179,19 +331,16 @@ func (m *Migu) GetPlaylistSongs(id string) ([]model.Song, error) {
 			PicL        string `json:"picL"`        // 大封面
 			CopyrightId string `json:"copyrightId"` // 版权ID
 		} `json:"contentList"`
+		TotalCount interface{} `json:"totalCount"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("migu playlist json parse error: %w", err)
+		return nil, model.Errorf("migu", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	// 检查返回码
 	if resp.Code != "000000" {
-		return nil, fmt.Errorf("migu api error: %s (code %s)", resp.Info, resp.Code)
-	}
-
-	if len(resp.ContentList) == 0 {
-		return nil, errors.New("playlist is empty")
+		return nil, apiError(resp.Code, resp.Info)
 	}
 
 	var songs []model.Song
@@ -226,29 +375,30 @@ func (m *Migu) GetPlaylistSongs(id string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	total := utils.ParseAnyInt(resp.TotalCount)
+	return model.NewPage(songs, page, limit, total, len(resp.ContentList)), nil
 }
 
-// Parse 解析链接并获取完整信息
-func (m *Migu) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接并获取完整信息
+func (m *Migu) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	// 1. 提取 ContentID
 	// 支持格式: https://music.migu.cn/v3/music/song/60054701934
 	re := regexp.MustCompile(`music\.migu\.cn/v3/music/song/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid migu link")
+		return nil, model.Errorf("migu", model.ErrInvalidInput, "invalid migu link")
 	}
 	contentID := matches[1]
 
 	// 2. 获取歌曲详情 (为了拿到 resourceType 和 formatType)
-	song, err := m.fetchSongDetail(contentID)
+	song, err := m.fetchSongDetail(ctx, contentID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 3. 获取下载链接
 	// 因为 convertItemToSong 已经填充了 Extra，所以可以直接调用 GetDownloadURL
-	downloadURL, err := m.GetDownloadURL(song)
+	downloadURL, err := m.GetDownloadURLContext(ctx, song)
 	if err == nil {
 		song.URL = downloadURL
 	}
@@ -256,26 +406,93 @@ func (m *Migu) Parse(link string) (*model.Song, error) {
 	return song, nil
 }
 
-// GetDownloadURL 获取下载链接
-func (m *Migu) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接，按 s.Extra["quality"] 从 GetStreamsContext 的结果中选择
+func (m *Migu) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "migu" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
 	}
 
+	streams, err := m.GetStreamsContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("migu", model.ErrVIPRequired, "no playable format for song %s", s.ID)
+	}
+	return m.resolveListenURL(ctx, st.URL)
+}
+
+// GetStreamsContext 列出歌曲可用的音频流。
+// 先用 queryById 取全部音质格式，失败时退回搜索结果中记录的那一种；
+// 返回的是 listenSong 地址，请求时会 302 到 CDN。
+func (m *Migu) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "migu" {
+		return nil, model.ErrSourceMismatch
+	}
+
 	var contentID, resourceType, formatType string
 	if s.Extra != nil {
 		contentID = s.Extra["content_id"]
 		resourceType = s.Extra["resource_type"]
 		formatType = s.Extra["format_type"]
 	}
+	if contentID == "" {
+		return nil, model.Errorf("migu", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
+	}
 
-	if contentID == "" || resourceType == "" || formatType == "" {
-		return "", fmt.Errorf("[migu] missing extra data for song %s", s.ID)
+	var formats []MiguRateFormat
+	if item, err := m.fetchSongItem(ctx, contentID); err == nil {
+		for _, f := range item.RateFormats {
+			if f.playable(*item) {
+				formats = append(formats, f)
+			}
+		}
+	} else if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if len(formats) == 0 {
+		if resourceType == "" || formatType == "" {
+			return nil, model.Errorf("migu", model.ErrInvalidInput, "missing extra data for song %s", s.ID)
+		}
+		formats = []MiguRateFormat{{FormatType: formatType, ResourceType: resourceType}}
+	}
+
+	headers := map[string]string{"User-Agent": UserAgent, "Referer": Referer}
+	streams := make([]model.Stream, 0, len(formats))
+	for _, f := range formats {
+		size, ext := f.sizeAndExt()
+		st := model.Stream{
+			URL:     listenURL(contentID, f.ResourceType, f.FormatType),
+			Ext:     ext,
+			Size:    size,
+			Label:   f.FormatType,
+			Headers: headers,
+		}
+		switch f.FormatType {
+		case "SQ", "ZQ":
+			if st.Ext == "" {
+				st.Ext = "flac"
+			}
+		case "HQ":
+			st.Bitrate = 320
+		case "PQ":
+			st.Bitrate = 128
+		case "LQ":
+			st.Bitrate = 64
+		}
+		if st.Ext == "" {
+			st.Ext = "mp3"
+		}
+		streams = append(streams, st)
 	}
+	return streams, nil
+}
 
+func listenURL(contentID, resourceType, formatType string) string {
 	params := url.Values{}
 	params.Set("toneFlag", formatType)
 	params.Set("netType", "00")
@@ -286,16 +503,17 @@ func (m *Migu) GetDownloadURL(s *model.Song) (string, error) {
 	params.Set("contentId", contentID)
 	params.Set("resourceType", resourceType)
 	params.Set("channel", "0")
+	return "http://app.pd.nf.migu.cn/MIGUM2.0/v1.0/content/sub/listenSong.do?" + params.Encode()
+}
 
-	apiURL := "http://app.pd.nf.migu.cn/MIGUM2.0/v1.0/content/sub/listenSong.do?" + params.Encode()
-
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+// resolveListenURL 读取 listenSong 的 302 跳转地址，拿不到时直接返回原地址
+func (m *Migu) resolveListenURL(ctx context.Context, apiURL string) (string, error) {
+	client := *m.client.HTTPClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -334,27 +552,55 @@ type MiguSongItem struct {
 	ImgItems        []struct {
 		Img string `json:"img"`
 	} `json:"imgItems"`
-	RateFormats []struct {
-		FormatType      string   `json:"formatType"`
-		ResourceType    string   `json:"resourceType"`
-		Size            string   `json:"size"`
-		AndroidSize     string   `json:"androidSize"`
-		FileType        string   `json:"fileType"`
-		AndroidFileType string   `json:"androidFileType"`
-		Price           string   `json:"price"`
-		ShowTag         []string `json:"showTag"`
-	} `json:"rateFormats"`
+	RateFormats []MiguRateFormat `json:"rateFormats"`
 }
 
-// fetchSongDetail 通过 contentId 获取歌曲详情
-func (m *Migu) fetchSongDetail(contentID string) (*model.Song, error) {
+// MiguRateFormat 是歌曲的一种音质格式
+type MiguRateFormat struct {
+	FormatType      string   `json:"formatType"` // LQ / PQ / HQ / SQ / ZQ
+	ResourceType    string   `json:"resourceType"`
+	Size            string   `json:"size"`
+	AndroidSize     string   `json:"androidSize"`
+	FileType        string   `json:"fileType"`
+	AndroidFileType string   `json:"androidFileType"`
+	Price           string   `json:"price"`
+	ShowTag         []string `json:"showTag"`
+}
+
+// sizeAndExt 优先使用 Android 端的大小和格式
+func (f MiguRateFormat) sizeAndExt() (int64, string) {
+	sizeStr := f.AndroidSize
+	if sizeStr == "" || sizeStr == "0" {
+		sizeStr = f.Size
+	}
+	size, _ := strconv.ParseInt(sizeStr, 10, 64)
+	ext := f.AndroidFileType
+	if ext == "" {
+		ext = f.FileType
+	}
+	return size, ext
+}
+
+// playable 过滤 VIP 专属格式和隐藏收费的高价格式
+func (f MiguRateFormat) playable(item MiguSongItem) bool {
+	for _, tag := range f.ShowTag {
+		if tag == "vip" {
+			return false
+		}
+	}
+	price, _ := strconv.Atoi(f.Price)
+	return !(item.ChargeAuditions == "1" && price >= 200)
+}
+
+// fetchSongItem 通过 contentId 获取原始歌曲条目（含全部音质格式）
+func (m *Migu) fetchSongItem(ctx context.Context, contentID string) (*MiguSongItem, error) {
 	params := url.Values{}
 	params.Set("resourceType", "2")
 	params.Set("contentId", contentID)
 
 	// 使用 queryById 接口获取详情，结构与 Search 结果类似
 	apiURL := "http://c.musicapp.migu.cn/MIGUM2.0/v1.0/content/queryById.do?" + params.Encode()
-	body, err := utils.Get(apiURL,
+	body, err := m.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", m.cookie),
@@ -376,18 +622,24 @@ func (m *Migu) fetchSongDetail(contentID string) (*model.Song, error) {
 		return nil, err
 	}
 
-	var item MiguSongItem
 	if len(resp.Resource) > 0 {
-		item = resp.Resource[0]
-	} else if resp.Data.Item.ContentID != "" {
-		item = resp.Data.Item
-	} else {
-		return nil, errors.New("song detail not found")
+		return &resp.Resource[0], nil
 	}
+	if resp.Data.Item.ContentID != "" {
+		return &resp.Data.Item, nil
+	}
+	return nil, model.Errorf("migu", model.ErrNotFound, "song detail not found")
+}
 
-	song := m.convertItemToSong(item)
+// fetchSongDetail 通过 contentId 获取歌曲详情
+func (m *Migu) fetchSongDetail(ctx context.Context, contentID string) (*model.Song, error) {
+	item, err := m.fetchSongItem(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	song := m.convertItemToSong(*item)
 	if song == nil {
-		return nil, errors.New("no valid format found for this song")
+		return nil, model.Errorf("migu", model.ErrVIPRequired, "no valid format found for this song")
 	}
 	return song, nil
 }
@@ -418,16 +670,7 @@ func (m *Migu) convertItemToSong(item MiguSongItem) *model.Song {
 	var pqSize int64 = 0
 
 	for i, fmtItem := range item.RateFormats {
-		sizeStr := fmtItem.AndroidSize
-		if sizeStr == "" || sizeStr == "0" {
-			sizeStr = fmtItem.Size
-		}
-		sizeVal, _ := strconv.ParseInt(sizeStr, 10, 64)
-
-		ext := fmtItem.AndroidFileType
-		if ext == "" {
-			ext = fmtItem.FileType
-		}
+		sizeVal, ext := fmtItem.sizeAndExt()
 
 		if fmtItem.FormatType == "PQ" {
 			pqSize = sizeVal
@@ -448,17 +691,7 @@ func (m *Migu) convertItemToSong(item MiguSongItem) *model.Song {
 			}
 		}
 
-		priceVal, _ := strconv.Atoi(fmtItem.Price)
-		isVipTag := false
-		for _, tag := range fmtItem.ShowTag {
-			if tag == "vip" {
-				isVipTag = true
-				break
-			}
-		}
-		isHiddenPaid := (item.ChargeAuditions == "1" && priceVal >= 200)
-
-		if !isVipTag && !isHiddenPaid {
+		if fmtItem.playable(item) {
 			candidates = append(candidates, validFormat{index: i, size: sizeVal, ext: ext})
 		}
 	}
@@ -506,10 +739,10 @@ func (m *Migu) convertItemToSong(item MiguSongItem) *model.Song {
 	}
 }
 
-// GetLyrics 获取歌词
-func (m *Migu) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (m *Migu) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "migu" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	contentID := ""
@@ -520,7 +753,7 @@ func (m *Migu) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if contentID == "" {
-		return "", fmt.Errorf("[migu] missing content_id for song %s", s.ID)
+		return "", model.Errorf("migu", model.ErrInvalidInput, "missing content_id for song %s", s.ID)
 	}
 
 	params := url.Values{}
@@ -529,7 +762,7 @@ func (m *Migu) GetLyrics(s *model.Song) (string, error) {
 
 	apiURL := "http://c.musicapp.migu.cn/MIGUM2.0/v1.0/content/resourceinfo.do?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := m.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", m.cookie),
@@ -546,11 +779,11 @@ func (m *Migu) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("migu resource info parse error: %w", err)
+		return "", model.Errorf("migu", model.ErrSchemaChanged, "resource info parse error: %w", err)
 	}
 
 	if len(resp.Resource) == 0 {
-		return "", errors.New("resource info not found")
+		return "", model.Errorf("migu", model.ErrNotFound, "resource info not found")
 	}
 
 	lyricUrl := resp.Resource[0].LrcUrl
@@ -559,12 +792,12 @@ func (m *Migu) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if lyricUrl == "" {
-		return "", errors.New("lyric url not found")
+		return "", model.Errorf("migu", model.ErrNotFound, "lyric url not found")
 	}
 
 	lyricUrl = strings.Replace(lyricUrl, "http://", "https://", 1)
 
-	lrcBody, err := utils.Get(lyricUrl,
+	lrcBody, err := m.client.GetContext(ctx, lyricUrl,
 		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"),
 		utils.WithHeader("Referer", "https://y.migu.cn/"),
 		utils.WithHeader("Cookie", m.cookie),
@@ -575,3 +808,21 @@ func (m *Migu) GetLyrics(s *model.Song) (string, error) {
 
 	return string(lrcBody), nil
 }
+
+// apiError 把咪咕接口的错误映射为统一的错误分类。咪咕的 code 没有公开含义，
+// 这里按提示文案归类
+func apiError(code, info string) error {
+	var kind error
+	switch {
+	case strings.Contains(info, "登录"):
+		kind = model.ErrLoginExpired
+	case strings.Contains(info, "频繁"):
+		kind = model.ErrRateLimited
+	case strings.Contains(info, "不存在"), strings.Contains(info, "下架"):
+		kind = model.ErrNotFound
+	case strings.Contains(info, "版权"):
+		kind = model.ErrRestricted
+	}
+	n, _ := strconv.Atoi(code)
+	return &model.ProviderError{Source: "migu", Kind: kind, Code: n, Err: fmt.Errorf("api error: %s (code %s)", info, code)}
+}
@@ -0,0 +1,12 @@
+package migu
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "migu",
+		DisplayName: "咪咕音乐",
+		Canary:      registry.Canary{Keyword: "周杰伦"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
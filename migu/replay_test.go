@@ -0,0 +1,21 @@
+package migu
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Migu]{
+		{Name: "search", Call: func(_ *testing.T, m *Migu) (any, error) { return m.Search("周深") }},
+		{Name: "charts", Call: func(_ *testing.T, m *Migu) (any, error) { return m.GetChartSongs("27553319", 2) }},
+		{Name: "artist_search", Call: func(_ *testing.T, m *Migu) (any, error) { return m.SearchArtist("周深") }},
+		{Name: "artist_songs", Call: func(_ *testing.T, m *Migu) (any, error) { return m.GetArtistTopSongs("1003567937") }},
+		// Two pages, the first of which says there is more.
+		{Name: "artist_albums", Len: 3, Call: func(_ *testing.T, m *Migu) (any, error) { return m.GetArtistAlbums("1003567937") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Migu { return New("", opts...) }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// Album 是所有音乐源通用的专辑结构
+type Album struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Artists     []string `json:"artists"`
+	ReleaseDate string   `json:"release_date"` // YYYY-MM-DD，平台只给到年份时为 YYYY
+	Cover       string   `json:"cover"`
+	TrackCount  int      `json:"track_count"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`
+	Link        string   `json:"link"`
+
+	// Tracks 仅在获取专辑详情时填充，搜索结果中为空
+	Tracks []AlbumTrack `json:"tracks,omitempty"`
+
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// AlbumTrack 是专辑中的一首歌，附带曲目号和碟号（从 1 开始，未知时为 0）
+type AlbumTrack struct {
+	Song
+	TrackNumber int `json:"track_number"`
+	DiscNumber  int `json:"disc_number"`
+}
+
+// ArtistString 返回以 "、" 连接的歌手名，与 Song.Artist 的格式一致
+func (a *Album) ArtistString() string {
+	return strings.Join(a.Artists, "、")
+}
+
+// Songs 返回专辑内的歌曲列表，便于直接交给下载队列。
+// 曲目号、碟号和发行年份写入 Extra 的 "track"、"disc"、"year"，供刮削时写入标签。
+func (a *Album) Songs() []Song {
+	var year string
+	if len(a.ReleaseDate) >= 4 {
+		year = a.ReleaseDate[:4]
+	}
+	songs := make([]Song, 0, len(a.Tracks))
+	for _, t := range a.Tracks {
+		song := t.Song
+		song.Extra = maps.Clone(song.Extra)
+		if song.Extra == nil {
+			song.Extra = map[string]string{}
+		}
+		if t.TrackNumber > 0 {
+			song.Extra["track"] = strconv.Itoa(t.TrackNumber)
+		}
+		if t.DiscNumber > 0 {
+			song.Extra["disc"] = strconv.Itoa(t.DiscNumber)
+		}
+		if year != "" && song.Extra["year"] == "" {
+			song.Extra["year"] = year
+		}
+		songs = append(songs, song)
+	}
+	return songs
+}
@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestAlbum_SongsKeepsTrackOrder(t *testing.T) {
+	a := &Album{
+		Name:        "范特西",
+		Artists:     []string{"周杰伦"},
+		ReleaseDate: "2001-09-14",
+		Tracks: []AlbumTrack{
+			{Song: Song{ID: "1", Name: "爱在西元前"}, TrackNumber: 1, DiscNumber: 1},
+			{Song: Song{ID: "2", Name: "爸我回来了"}, TrackNumber: 2, DiscNumber: 1},
+		},
+	}
+	songs := a.Songs()
+	if len(songs) != 2 || songs[0].ID != "1" || songs[1].ID != "2" {
+		t.Fatalf("unexpected songs: %+v", songs)
+	}
+	if songs[1].Extra["track"] != "2" || songs[1].Extra["disc"] != "1" {
+		t.Fatalf("track/disc not carried over: %v", songs[1].Extra)
+	}
+	if songs[0].Extra["year"] != "2001" {
+		t.Fatalf("year = %q, want 2001", songs[0].Extra["year"])
+	}
+	if a.Tracks[0].Extra != nil {
+		t.Fatal("Songs must not modify the album tracks")
+	}
+	if len((&Album{}).Songs()) != 0 {
+		t.Fatal("album without tracks should return no songs")
+	}
+}
+
+func TestAlbum_ArtistString(t *testing.T) {
+	a := &Album{Artists: []string{"周杰伦", "费玉清"}}
+	if got := a.ArtistString(); got != "周杰伦、费玉清" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+// Artist 是所有音乐源通用的歌手结构
+type Artist struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Avatar      string   `json:"avatar"`
+	SongCount   int      `json:"song_count"`  // 平台未返回时为 0
+	AlbumCount  int      `json:"album_count"` // 平台未返回时为 0
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`
+	Link        string   `json:"link"`
+
+	Extra map[string]string `json:"extra,omitempty"`
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// 平台错误分类。各平台把自己的响应码映射到这些哨兵错误上，
+// 调用方用 errors.Is 或 KindOf 判断错误类型，而不是匹配错误信息
+var (
+	ErrNotFound       = errors.New("not found")                      // 歌曲、歌单、专辑等不存在
+	ErrVIPRequired    = errors.New("vip required")                   // 需要会员或付费，通常只有试听片段
+	ErrRestricted     = errors.New("copyright or region restricted") // 无版权或地区限制
+	ErrRateLimited    = errors.New("rate limited")                   // 请求过于频繁，可配合 RetryAfter 稍后重试
+	ErrLoginExpired   = errors.New("login expired")                  // Cookie 失效或需要登录
+	ErrSchemaChanged  = errors.New("upstream schema changed")        // 响应结构与预期不符
+	ErrInvalidInput   = errors.New("invalid input")                  // 链接、ID 等参数不合法
+	ErrSourceMismatch = errors.New("source mismatch")                // 歌曲不属于该平台
+)
+
+var kinds = []error{
+	ErrNotFound, ErrVIPRequired, ErrRestricted, ErrRateLimited,
+	ErrLoginExpired, ErrSchemaChanged, ErrInvalidInput, ErrSourceMismatch,
+}
+
+// ProviderError 是平台返回的错误，携带错误分类、平台原始错误码和可选的重试等待时间。
+// errors.Is(err, Kind) 和 errors.As 到 Err 链上的错误都成立
+type ProviderError struct {
+	Source     string        // 平台名，如 "netease"
+	Kind       error         // 上面的哨兵错误之一，未知时为 nil
+	Code       int           // 平台原始错误码，没有时为 0
+	RetryAfter time.Duration // 限流时平台建议的等待时间，未知时为 0
+	Err        error         // 具体错误
+}
+
+func (e *ProviderError) Error() string {
+	msg := ""
+	if e.Err != nil {
+		msg = e.Err.Error()
+	} else if e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if e.Source != "" {
+		msg = "[" + e.Source + "] " + msg
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// Errorf 构造一个带分类的平台错误，format 支持 %w
+func Errorf(source string, kind error, format string, args ...any) error {
+	return &ProviderError{Source: source, Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// CodeError 构造一个带平台原始错误码的错误
+func CodeError(source string, kind error, code int, msg string) error {
+	if msg == "" {
+		msg = "api error"
+	}
+	return &ProviderError{Source: source, Kind: kind, Code: code, Err: fmt.Errorf("%s (code %d)", msg, code)}
+}
+
+// KindOf 返回 err 所属的错误分类，无法归类时返回 nil。
+// 除了 ProviderError，也会识别 utils 返回的 HTTP 状态码错误：
+// 404 视为 ErrNotFound，429 视为 ErrRateLimited，401 视为 ErrLoginExpired
+func KindOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, k := range kinds {
+		if errors.Is(err, k) {
+			return k
+		}
+	}
+	var se *utils.StatusError
+	if errors.As(err, &se) {
+		switch se.StatusCode {
+		case http.StatusNotFound:
+			return ErrNotFound
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		case http.StatusUnauthorized:
+			return ErrLoginExpired
+		}
+	}
+	return nil
+}
+
+// RetryAfter 返回平台建议的重试等待时间，未给出时返回 0
+func RetryAfter(err error) time.Duration {
+	var pe *ProviderError
+	if errors.As(err, &pe) && pe.RetryAfter > 0 {
+		return pe.RetryAfter
+	}
+	var se *utils.StatusError
+	if errors.As(err, &se) {
+		return se.RetryAfter
+	}
+	return 0
+}
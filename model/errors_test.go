@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestErrorf_IsAndAs(t *testing.T) {
+	inner := errors.New("boom")
+	err := Errorf("netease", ErrNotFound, "song 1: %w", inner)
+	wrapped := fmt.Errorf("detail: %w", err)
+
+	if !errors.Is(wrapped, ErrNotFound) {
+		t.Error("errors.Is should match the kind")
+	}
+	if !errors.Is(wrapped, inner) {
+		t.Error("errors.Is should match the wrapped cause")
+	}
+	var pe *ProviderError
+	if !errors.As(wrapped, &pe) || pe.Source != "netease" {
+		t.Fatalf("errors.As failed: %+v", pe)
+	}
+	if got, want := err.Error(), "[netease] song 1: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCodeError(t *testing.T) {
+	err := CodeError("kuwo", nil, -1, "")
+	if got, want := err.Error(), "[kuwo] api error (code -1)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if KindOf(err) != nil {
+		t.Error("nil kind should not be classified")
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		want error
+	}{
+		{nil, nil},
+		{errors.New("plain"), nil},
+		{Errorf("qq", ErrVIPRequired, "vkey"), ErrVIPRequired},
+		{fmt.Errorf("wrap: %w", ErrSourceMismatch), ErrSourceMismatch},
+		{&utils.StatusError{StatusCode: 404}, ErrNotFound},
+		{&utils.StatusError{StatusCode: 429}, ErrRateLimited},
+		{&utils.StatusError{StatusCode: 401}, ErrLoginExpired},
+		{&utils.StatusError{StatusCode: 503}, nil},
+	}
+	for _, c := range cases {
+		if got := KindOf(c.err); got != c.want {
+			t.Errorf("KindOf(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	pe := &ProviderError{Source: "bilibili", Kind: ErrRateLimited, RetryAfter: 3 * time.Second}
+	if got := RetryAfter(fmt.Errorf("wrap: %w", pe)); got != 3*time.Second {
+		t.Errorf("ProviderError: got %v", got)
+	}
+	se := &utils.StatusError{StatusCode: 429, RetryAfter: 5 * time.Second}
+	if got := RetryAfter(se); got != 5*time.Second {
+		t.Errorf("StatusError: got %v", got)
+	}
+	if got := RetryAfter(errors.New("plain")); got != 0 {
+		t.Errorf("plain error: got %v", got)
+	}
+}
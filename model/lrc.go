@@ -0,0 +1,360 @@
+package model
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Lyrics 是结构化的歌词：ID 标签加按时间排序的歌词行，行可以带逐字时间和翻译。
+// 由 ParseLyrics 从 LRC 解析，可导出为 LRC、增强 LRC、SRT、WebVTT 和 TTML
+type Lyrics struct {
+	Tags   map[string]string `json:"tags,omitempty"`   // [ti:]、[ar:] 等 ID 标签，不含 offset
+	Offset int               `json:"offset,omitempty"` // [offset:] 毫秒，正数表示歌词提前显示
+	Lines  []LyricLine       `json:"lines"`
+}
+
+// lrcTagOrder 是导出 LRC 时输出的 ID 标签及其顺序，
+// 平台私有的标签 (如酷狗的 [hash:]、[language:]) 不输出
+var lrcTagOrder = []string{"ti", "ar", "al", "au", "lr", "by", "re", "ve", "length"}
+
+// lastCueDuration 是导出字幕时最后一行没有时长时的显示时间 (毫秒)
+const lastCueDuration = 5000
+
+var (
+	// reLRCTime 匹配行首的时间标签，如 [01:02.34]、[01:02.345]、[01:02]
+	reLRCTime = regexp.MustCompile(`^\[(\d+):(\d+)(?:[.:](\d+))?\]`)
+	// reLRCTag 匹配 [ti:xxx] 这类 ID 标签行，LRC 和逐字歌词共用
+	reLRCTag = regexp.MustCompile(`^\[([a-zA-Z#]+):(.*)\]$`)
+	// reWordTime 匹配增强 LRC 行内的逐字时间 <mm:ss.xx>
+	reWordTime = regexp.MustCompile(`<(\d+):(\d+)(?:[.:](\d+))?>`)
+)
+
+// ParseLyrics 解析 LRC 或增强 LRC 文本：
+//   - 统一换行符，去掉 BOM，非 UTF-8 的文本按 GBK (GB18030) 解码
+//   - 一行多个时间标签的展开为多行，按时间排序
+//   - 时间标签的精度统一到毫秒，[mm:ss]、[mm:ss.x]、[mm:ss:xx] 都能识别
+//   - 与上一行时间相同的行视为翻译，挂到上一行的 Translation
+//   - 没有时间标签的正文行被丢弃
+func ParseLyrics(text string) *Lyrics {
+	l := &Lyrics{}
+	type entry struct {
+		start int
+		text  string
+		words []LyricWord
+	}
+	var entries []entry
+	for _, line := range splitLines(normalizeLyricsText(text)) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		ms, rest := parseLRCLine(line)
+		if len(ms) == 0 {
+			if m := reLRCTag.FindStringSubmatch(line); m != nil {
+				l.setTag(m[1], m[2])
+			}
+			continue
+		}
+		body, words := parseWordTimes(rest)
+		for _, t := range ms {
+			e := entry{start: t, text: body}
+			// 逐字时间是绝对时间，只属于第一个时间标签那一行
+			if len(ms) == 1 {
+				e.words = words
+			}
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].start < entries[j].start })
+
+	for _, e := range entries {
+		if n := len(l.Lines); n > 0 && l.Lines[n-1].Start == e.start && l.Lines[n-1].Text != "" && e.text != "" {
+			last := &l.Lines[n-1]
+			if last.Translation != "" {
+				last.Translation += "\n"
+			}
+			last.Translation += e.text
+			continue
+		}
+		l.Lines = append(l.Lines, LyricLine{Start: e.start, Text: e.text, Words: e.words})
+	}
+	return l
+}
+
+// NormalizeLRC 把平台返回的歌词整理成规范的 LRC：经 ParseLyrics 解析后重新输出，
+// 有逐字时间时输出增强 LRC。没有任何时间标签的纯文本歌词只统一换行和编码
+func NormalizeLRC(text string) string {
+	l := ParseLyrics(text)
+	if len(l.Lines) == 0 {
+		return strings.TrimSpace(normalizeLyricsText(text))
+	}
+	if l.HasWords() {
+		return l.EnhancedLRC()
+	}
+	return l.LRC()
+}
+
+// normalizeLyricsText 把 GBK 文本转成 UTF-8，去掉 BOM，统一换行为 \n
+func normalizeLyricsText(s string) string {
+	if !utf8.ValidString(s) {
+		if decoded, err := simplifiedchinese.GB18030.NewDecoder().String(s); err == nil {
+			s = decoded
+		}
+	}
+	s = strings.TrimPrefix(s, "\ufeff")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
+// parseLRCLine 拆出行首所有时间标签 (一行可以有多个) 的毫秒值和歌词正文。
+// [ar:] 这类标签行不算时间标签，返回的 ms 为空
+func parseLRCLine(line string) (ms []int, text string) {
+	rest := line
+	for {
+		loc := reLRCTime.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			return ms, rest
+		}
+		ms = append(ms, lrcTimeMs(rest, loc[2:8]))
+		rest = rest[loc[1]:]
+	}
+}
+
+// parseWordTimes 拆出增强 LRC 的逐字时间，返回去掉标签后的正文。
+// 每个字的时长到下一个标签为止，最后一个标签只表示结束时间
+func parseWordTimes(s string) (string, []LyricWord) {
+	locs := reWordTime.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return strings.TrimSpace(s), nil
+	}
+	var words []LyricWord
+	var text strings.Builder
+	text.WriteString(s[:locs[0][0]])
+	for i, loc := range locs {
+		start := lrcTimeMs(s, loc[2:8])
+		if n := len(words); n > 0 {
+			words[n-1].Duration = start - words[n-1].Start
+		}
+		end := len(s)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		if seg := s[loc[1]:end]; seg != "" {
+			words = append(words, LyricWord{Start: start, Text: seg})
+			text.WriteString(seg)
+		}
+	}
+	return strings.TrimSpace(text.String()), words
+}
+
+// lrcTimeMs 把 reLRCTime 或 reWordTime 的分、秒、小数三个子匹配换算为毫秒，
+// 小数部分按位数换算：.3 = 300ms，.34 = 340ms，.345 = 345ms
+func lrcTimeMs(s string, idx []int) int {
+	mm, _ := strconv.Atoi(s[idx[0]:idx[1]])
+	ss, _ := strconv.Atoi(s[idx[2]:idx[3]])
+	frac := 0
+	if idx[4] >= 0 {
+		frac, _ = strconv.Atoi((s[idx[4]:idx[5]] + "00")[:3])
+	}
+	return (mm*60+ss)*1000 + frac
+}
+
+func (l *Lyrics) setTag(key, value string) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+	if key == "offset" {
+		l.Offset, _ = strconv.Atoi(strings.TrimPrefix(value, "+"))
+		return
+	}
+	if l.Tags == nil {
+		l.Tags = make(map[string]string)
+	}
+	l.Tags[key] = value
+}
+
+// HasWords 报告是否至少有一行带逐字时间
+func (l *Lyrics) HasWords() bool {
+	for _, line := range l.Lines {
+		if len(line.Words) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LRC 导出为只有行时间的 LRC，时间统一为 [mm:ss.xx]，翻译作为同一时间的下一行
+func (l *Lyrics) LRC() string {
+	return l.renderLRC(false)
+}
+
+// EnhancedLRC 导出为增强 LRC (A2 扩展)：每个字前面加 <mm:ss.xx> 时间标签，
+// 行尾再加一个结束时间，卡拉 OK 播放器据此逐字高亮。没有逐字时间的行按普通 LRC 输出
+func (l *Lyrics) EnhancedLRC() string {
+	return l.renderLRC(true)
+}
+
+func (l *Lyrics) renderLRC(enhanced bool) string {
+	var b strings.Builder
+	for _, k := range lrcTagOrder {
+		if v, ok := l.Tags[k]; ok {
+			fmt.Fprintf(&b, "[%s:%s]\n", k, v)
+		}
+	}
+	if l.Offset != 0 {
+		fmt.Fprintf(&b, "[offset:%d]\n", l.Offset)
+	}
+	for _, line := range l.Lines {
+		tag := "[" + FormatLRCTime(line.Start) + "]"
+		b.WriteString(tag)
+		if !enhanced || len(line.Words) == 0 {
+			b.WriteString(line.Text)
+		} else {
+			end := 0
+			for _, word := range line.Words {
+				b.WriteString("<" + FormatLRCTime(word.Start) + ">")
+				b.WriteString(word.Text)
+				end = word.Start + word.Duration
+			}
+			b.WriteString("<" + FormatLRCTime(end) + ">")
+		}
+		b.WriteByte('\n')
+		for _, t := range splitLines(line.Translation) {
+			b.WriteString(tag + t + "\n")
+		}
+	}
+	return b.String()
+}
+
+// FormatLRCTime 把毫秒格式化为 LRC 时间 mm:ss.xx
+func FormatLRCTime(ms int) string {
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
+
+// LyricsExportFormats 是 Export 支持的格式名
+var LyricsExportFormats = []string{"lrc", "elrc", "srt", "vtt", "ttml"}
+
+// Export 按格式名导出：lrc、elrc (增强 LRC)、srt、vtt (WebVTT) 或 ttml
+func (l *Lyrics) Export(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "lrc":
+		return l.LRC(), nil
+	case "elrc":
+		return l.EnhancedLRC(), nil
+	case "srt":
+		return l.SRT(), nil
+	case "vtt":
+		return l.WebVTT(), nil
+	case "ttml":
+		return l.TTML(), nil
+	}
+	return "", fmt.Errorf("unsupported lyrics format: %q", format)
+}
+
+// cue 是导出字幕用的一条：已应用 offset 的起止时间和文本
+type cue struct {
+	start, end int
+	line       LyricLine
+}
+
+// cues 计算每行的显示区间：有时长的用时长，否则显示到下一行开始。
+// 空行只作为上一行的结束时间，不单独输出
+func (l *Lyrics) cues() []cue {
+	var out []cue
+	for i, line := range l.Lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		end := line.Start + line.Duration
+		if line.Duration <= 0 {
+			end = line.Start + lastCueDuration
+			for _, next := range l.Lines[i+1:] {
+				if next.Start > line.Start {
+					end = next.Start
+					break
+				}
+			}
+		}
+		out = append(out, cue{start: line.Start - l.Offset, end: end - l.Offset, line: line})
+	}
+	return out
+}
+
+// text 是字幕里一条的文本：原文加翻译
+func (c cue) text() string {
+	if c.line.Translation == "" {
+		return c.line.Text
+	}
+	return c.line.Text + "\n" + c.line.Translation
+}
+
+// formatClock 把毫秒格式化为 hh:mm:ss<sep>mmm
+func formatClock(ms int, sep string) string {
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// SRT 导出为 SRT 字幕
+func (l *Lyrics) SRT() string {
+	var b strings.Builder
+	for i, c := range l.cues() {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatClock(c.start, ","), formatClock(c.end, ","), c.text())
+	}
+	return b.String()
+}
+
+// WebVTT 导出为 WebVTT 字幕
+func (l *Lyrics) WebVTT() string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range l.cues() {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatClock(c.start, "."), formatClock(c.end, "."), c.text())
+	}
+	return b.String()
+}
+
+// TTML 导出为 TTML。逐字时间输出为带 begin/end 的 span，
+// 翻译输出为 ttm:role="x-translation" 的 span
+func (l *Lyrics) TTML() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttm="http://www.w3.org/ns/ttml#metadata">` + "\n")
+	if ti, ar := l.Tags["ti"], l.Tags["ar"]; ti != "" || ar != "" {
+		b.WriteString("  <head>\n    <metadata>\n")
+		if ti != "" {
+			fmt.Fprintf(&b, "      <ttm:title>%s</ttm:title>\n", html.EscapeString(ti))
+		}
+		if ar != "" {
+			fmt.Fprintf(&b, "      <ttm:agent type=\"person\"><ttm:name type=\"full\">%s</ttm:name></ttm:agent>\n", html.EscapeString(ar))
+		}
+		b.WriteString("    </metadata>\n  </head>\n")
+	}
+	b.WriteString("  <body>\n    <div>\n")
+	for _, c := range l.cues() {
+		fmt.Fprintf(&b, `      <p begin="%s" end="%s">`, formatClock(c.start, "."), formatClock(c.end, "."))
+		if len(c.line.Words) == 0 {
+			b.WriteString(html.EscapeString(c.line.Text))
+		}
+		for _, w := range c.line.Words {
+			fmt.Fprintf(&b, `<span begin="%s" end="%s">%s</span>`,
+				formatClock(w.Start-l.Offset, "."), formatClock(w.Start+w.Duration-l.Offset, "."), html.EscapeString(w.Text))
+		}
+		for _, t := range splitLines(c.line.Translation) {
+			fmt.Fprintf(&b, `<span ttm:role="x-translation">%s</span>`, html.EscapeString(t))
+		}
+		b.WriteString("</p>\n")
+	}
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return b.String()
+}
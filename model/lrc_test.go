@@ -0,0 +1,97 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestParseLyrics_TagsAndMultiTimestamps(t *testing.T) {
+	l := ParseLyrics("[ti:晴天]\r\n[ar:周杰伦]\r\n[offset:+200]\r\n[00:29.35][01:10.5]故事的小黄花\r\n[00:40]从出生那年就飘着\r\n")
+	if l.Tags["ti"] != "晴天" || l.Tags["ar"] != "周杰伦" || l.Offset != 200 {
+		t.Fatalf("unexpected tags: %+v, offset %d", l.Tags, l.Offset)
+	}
+	if len(l.Lines) != 3 {
+		t.Fatalf("expected 3 lines, got %+v", l.Lines)
+	}
+	starts := []int{29350, 40000, 70500}
+	for i, line := range l.Lines {
+		if line.Start != starts[i] {
+			t.Errorf("line %d: start %d, want %d", i, line.Start, starts[i])
+		}
+	}
+	if l.Lines[2].Text != "故事的小黄花" {
+		t.Errorf("multi-timestamp line not expanded: %+v", l.Lines[2])
+	}
+}
+
+func TestParseLyrics_Translation(t *testing.T) {
+	l := ParseLyrics("[00:01.00]Hello\n[00:01.000]你好\n[00:02.00]World\n")
+	if len(l.Lines) != 2 || l.Lines[0].Translation != "你好" {
+		t.Fatalf("unexpected lines: %+v", l.Lines)
+	}
+	if got := l.LRC(); got != "[00:01.00]Hello\n[00:01.00]你好\n[00:02.00]World\n" {
+		t.Errorf("LRC: got %q", got)
+	}
+}
+
+func TestParseLyrics_GBK(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("[00:01.00]晴天\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ParseLyrics(gbk)
+	if len(l.Lines) != 1 || l.Lines[0].Text != "晴天" {
+		t.Fatalf("GBK not decoded: %+v", l.Lines)
+	}
+}
+
+func TestParseLyrics_EnhancedRoundTrip(t *testing.T) {
+	in := "[00:29.35]<00:29.35>故<00:29.75>事<00:30.35>\n"
+	l := ParseLyrics(in)
+	if len(l.Lines) != 1 || l.Lines[0].Text != "故事" || len(l.Lines[0].Words) != 2 {
+		t.Fatalf("unexpected lines: %+v", l.Lines)
+	}
+	if w := l.Lines[0].Words[1]; w.Start != 29750 || w.Duration != 600 {
+		t.Errorf("unexpected word: %+v", w)
+	}
+	if got := l.EnhancedLRC(); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+}
+
+func TestNormalizeLRC(t *testing.T) {
+	in := "\ufeff[ti:晴天]\r\n[hash:abc]\r\n[00:02.000]second\r\n[00:01.5]first\r\nno timestamp\r\n"
+	want := "[ti:晴天]\n[00:01.50]first\n[00:02.00]second\n"
+	if got := NormalizeLRC(in); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := NormalizeLRC("纯音乐，请欣赏\r\n"); got != "纯音乐，请欣赏" {
+		t.Errorf("plain text: got %q", got)
+	}
+}
+
+func TestLyrics_Subtitles(t *testing.T) {
+	l := ParseLyrics("[ti:A & B]\n[00:01.00]Hello\n[00:01.00]你好\n[00:03.50]World\n[00:05.00]\n")
+
+	srt := "1\n00:00:01,000 --> 00:00:03,500\nHello\n你好\n\n2\n00:00:03,500 --> 00:00:05,000\nWorld\n\n"
+	if got := l.SRT(); got != srt {
+		t.Errorf("SRT: got %q", got)
+	}
+	if got := l.WebVTT(); !strings.HasPrefix(got, "WEBVTT\n\n00:00:01.000 --> 00:00:03.500\nHello\n你好\n\n") {
+		t.Errorf("WebVTT: got %q", got)
+	}
+	ttml := l.TTML()
+	for _, want := range []string{
+		"<ttm:title>A &amp; B</ttm:title>",
+		`<p begin="00:00:01.000" end="00:00:03.500">Hello<span ttm:role="x-translation">你好</span></p>`,
+	} {
+		if !strings.Contains(ttml, want) {
+			t.Errorf("TTML missing %q:\n%s", want, ttml)
+		}
+	}
+	if _, err := l.Export("docx"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,102 @@
+package model
+
+import "strings"
+
+// LyricsSet 是一首歌各个版本的歌词，都是 LRC 文本，平台没有的版本为空
+type LyricsSet struct {
+	Original     string `json:"original"`
+	Translation  string `json:"translation,omitempty"`  // 中文翻译
+	Romanization string `json:"romanization,omitempty"` // 罗马音，日语、韩语歌曲常见
+}
+
+// LyricsOptions 选择原文之外还要哪些版本，以及原文是否用逐字歌词
+type LyricsOptions struct {
+	Translation  bool
+	Romanization bool
+	WordLevel    bool // 平台有逐字歌词时，原文用增强 LRC 代替普通 LRC
+}
+
+// Any 报告是否要求了原文之外的版本
+func (o LyricsOptions) Any() bool { return o.Translation || o.Romanization }
+
+// mergeTolerance 是合并时允许的时间差 (毫秒)，各平台翻译的时间标签精度不完全一致
+const mergeTolerance = 100
+
+// Merge 按时间戳把 opts 选中的翻译、罗马音插到对应原文行之后，生成双语 LRC：
+// 原文经 ParseLyrics 解析后重新输出，每行原文后面跟时间标签相同的罗马音和翻译。
+// 对不上原文的翻译行会被丢弃；原文为空时返回空字符串
+func (l LyricsSet) Merge(opts LyricsOptions) string {
+	if l.Original == "" {
+		return ""
+	}
+	var extras []map[int]string
+	if opts.Romanization && l.Romanization != "" {
+		extras = append(extras, lrcLineMap(l.Romanization))
+	}
+	if opts.Translation && l.Translation != "" {
+		extras = append(extras, lrcLineMap(l.Translation))
+	}
+	lyrics := ParseLyrics(l.Original)
+	if len(extras) == 0 || len(lyrics.Lines) == 0 {
+		return l.Original
+	}
+
+	for i := range lyrics.Lines {
+		line := &lyrics.Lines[i]
+		if line.Text == "" {
+			continue
+		}
+		for _, m := range extras {
+			if t, ok := nearestLine(m, line.Start); ok {
+				if line.Translation != "" {
+					line.Translation += "\n"
+				}
+				line.Translation += t
+			}
+		}
+	}
+	if lyrics.HasWords() {
+		return lyrics.EnhancedLRC()
+	}
+	return lyrics.LRC()
+}
+
+// lrcLineMap 把 LRC 解析成 时间(毫秒) -> 歌词，跳过空行和占位行
+func lrcLineMap(lrc string) map[int]string {
+	m := make(map[int]string)
+	for _, line := range ParseLyrics(lrc).Lines {
+		// 网易云的翻译用 "//" 表示该行没有翻译
+		if line.Text == "" || line.Text == "//" {
+			continue
+		}
+		m[line.Start] = line.Text
+	}
+	return m
+}
+
+// nearestLine 在 mergeTolerance 内找时间最接近 ms 的一行
+func nearestLine(m map[int]string, ms int) (string, bool) {
+	if t, ok := m[ms]; ok {
+		return t, true
+	}
+	best, bestDiff := "", mergeTolerance+1
+	for t, text := range m {
+		diff := t - ms
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < bestDiff {
+			best, bestDiff = text, diff
+		}
+	}
+	return best, bestDiff <= mergeTolerance
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,65 @@
+package model
+
+import "testing"
+
+func TestLyricsSet_Merge(t *testing.T) {
+	set := LyricsSet{
+		Original: "[ti:Lemon]\n[00:01.00]夢ならばどれほどよかったでしょう\n[00:05.50]未だにあなたのことを夢にみる\n[00:09.00]\n",
+		// 翻译的时间标签精度和原文不同，第二行差 5ms
+		Translation:  "[00:01.000]如果这一切都是梦境该有多好\n[00:05.505]至今仍能梦到你\n[00:09.000]//\n",
+		Romanization: "[00:01.00]yume naraba dore hodo yokatta deshou\n",
+	}
+
+	got := set.Merge(LyricsOptions{Translation: true, Romanization: true})
+	want := "[ti:Lemon]\n" +
+		"[00:01.00]夢ならばどれほどよかったでしょう\n" +
+		"[00:01.00]yume naraba dore hodo yokatta deshou\n" +
+		"[00:01.00]如果这一切都是梦境该有多好\n" +
+		"[00:05.50]未だにあなたのことを夢にみる\n" +
+		"[00:05.50]至今仍能梦到你\n" +
+		"[00:09.00]\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := set.Merge(LyricsOptions{}); got != set.Original {
+		t.Errorf("without options the original should be returned unchanged, got %q", got)
+	}
+	if got := (LyricsSet{Translation: "[00:01.00]x"}).Merge(LyricsOptions{Translation: true}); got != "" {
+		t.Errorf("empty original: got %q", got)
+	}
+}
+
+func TestLyricsSet_MergeSkipsDistantLines(t *testing.T) {
+	set := LyricsSet{
+		Original:    "[00:01.00]a\r\n[00:02.00]b\r\n",
+		Translation: "[00:01.50]甲\n",
+	}
+	want := "[00:01.00]a\n[00:02.00]b\n"
+	if got := set.Merge(LyricsOptions{Translation: true}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLyricsSet_MergeKeepsWordTimes(t *testing.T) {
+	set := LyricsSet{
+		Original:    "[00:01.00]<00:01.00>a<00:01.50>b<00:02.00>\n",
+		Translation: "[00:01.00]甲乙\n",
+	}
+	want := "[00:01.00]<00:01.00>a<00:01.50>b<00:02.00>\n[00:01.00]甲乙\n"
+	if got := set.Merge(LyricsOptions{Translation: true}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLyricsSet_MergeMultipleTimestamps(t *testing.T) {
+	set := LyricsSet{
+		Original:    "[00:01.00][00:10.00]副歌\n",
+		Translation: "[00:01.00][00:10.00]chorus\n",
+	}
+	// 多个时间标签的行展开为一行一个时间
+	want := "[00:01.00]副歌\n[00:01.00]chorus\n[00:10.00]副歌\n[00:10.00]chorus\n"
+	if got := set.Merge(LyricsOptions{Translation: true}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
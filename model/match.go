@@ -0,0 +1,62 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// reParens matches parenthesized or bracketed content (ASCII and CJK).
+var reParens = regexp.MustCompile(`[\(（\[【][^)）\]】]*[\)）\]】]`)
+
+// rePunctuation matches common punctuation and symbols.
+var rePunctuation = regexp.MustCompile(`[.,!?;:'"、。！？；：""''·\-_]+`)
+
+// reSpaces collapses multiple spaces into one.
+var reSpaces = regexp.MustCompile(`\s+`)
+
+// NormalizeName normalises a song/artist name for fuzzy comparison:
+//   - lower-case
+//   - strip parenthesized content (e.g. "(Live)", "（翻唱）")
+//   - strip punctuation
+//   - collapse whitespace and trim
+func NormalizeName(s string) string {
+	s = strings.ToLower(s)
+	s = reParens.ReplaceAllString(s, "")
+	s = rePunctuation.ReplaceAllString(s, " ")
+	s = reSpaces.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+// IsSameSong reports whether candidate is considered the same song as target.
+// It is shared by the download fallback and the aggregated search so both
+// agree on what counts as a duplicate.
+//
+// Rules:
+//  1. Normalised names are equal AND artist contains the other (or vice versa).
+//  2. One normalised name contains the other AND normalised artists are equal.
+func IsSameSong(target, candidate Song) bool {
+	tName := NormalizeName(target.Name)
+	cName := NormalizeName(candidate.Name)
+	tArtist := NormalizeName(target.Artist)
+	cArtist := NormalizeName(candidate.Artist)
+
+	if tName == "" || cName == "" {
+		return false
+	}
+
+	artistMatch := tArtist == cArtist ||
+		(tArtist != "" && cArtist != "" && (strings.Contains(tArtist, cArtist) || strings.Contains(cArtist, tArtist)))
+
+	// Rule 1: exact name match + artist contains
+	if tName == cName && artistMatch {
+		return true
+	}
+
+	// Rule 2: name containment + exact artist match
+	nameContains := strings.Contains(tName, cName) || strings.Contains(cName, tName)
+	if nameContains && tArtist == cArtist {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"晴天 (Live)":          "晴天",
+		"Hello,  World!":     "hello world",
+		"七里香（翻唱）":            "七里香",
+		"  Mixed_Case-Name ": "mixed case name",
+	}
+	for in, want := range cases {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIsSameSong(t *testing.T) {
+	target := Song{Name: "晴天", Artist: "周杰伦"}
+	if !IsSameSong(target, Song{Name: "晴天 (Live)", Artist: "周杰伦、五月天"}) {
+		t.Error("same name with containing artist should match")
+	}
+	if IsSameSong(target, Song{Name: "晴天", Artist: "孙燕姿"}) {
+		t.Error("different artist should not match")
+	}
+	if IsSameSong(Song{Artist: "周杰伦"}, Song{Artist: "周杰伦"}) {
+		t.Error("empty names should never match")
+	}
+}
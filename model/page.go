@@ -0,0 +1,57 @@
+package model
+
+// Page 是分页查询返回的一页结果
+type Page[T any] struct {
+	Items   []T  `json:"items"`
+	Page    int  `json:"page"`     // 页码，从 1 开始
+	Limit   int  `json:"limit"`    // 每页条数
+	Total   int  `json:"total"`    // 平台报告的总条数，未知时为 0
+	HasMore bool `json:"has_more"` // 是否还有下一页
+}
+
+// NormalizePage 修正调用方传入的页码与每页条数：page < 1 视为第 1 页，
+// limit <= 0 取 defaultLimit，超过 maxLimit 时截断
+func NormalizePage(page, limit, defaultLimit, maxLimit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	return page, limit
+}
+
+// NewPage 构造一页结果。total > 0 时按总数判断是否还有下一页；
+// 平台不返回总数时，按过滤前的原始条数 raw 是否填满一页来推断
+func NewPage[T any](items []T, page, limit, total, raw int) *Page[T] {
+	hasMore := raw >= limit
+	if total > 0 {
+		hasMore = page*limit < total
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return &Page[T]{Items: items, Page: page, Limit: limit, Total: total, HasMore: hasMore}
+}
+
+// SlicePage 对一次性拿到的完整列表做内存分页，用于平台接口本身不支持分页的情况
+func SlicePage[T any](all []T, page, limit int) *Page[T] {
+	start := (page - 1) * limit
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return &Page[T]{
+		Items:   append([]T{}, all[start:end]...),
+		Page:    page,
+		Limit:   limit,
+		Total:   len(all),
+		HasMore: end < len(all),
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import "testing"
+
+func TestNormalizePage(t *testing.T) {
+	page, limit := NormalizePage(0, 0, 10, 50)
+	if page != 1 || limit != 10 {
+		t.Errorf("expected 1/10, got %d/%d", page, limit)
+	}
+	page, limit = NormalizePage(3, 500, 10, 50)
+	if page != 3 || limit != 50 {
+		t.Errorf("expected 3/50, got %d/%d", page, limit)
+	}
+}
+
+func TestNewPage_UsesTotalWhenKnown(t *testing.T) {
+	p := NewPage([]int{1, 2}, 2, 10, 20, 10)
+	if p.HasMore {
+		t.Error("page 2 of 20 items with limit 10 should be the last page")
+	}
+	p = NewPage([]int{1, 2}, 1, 10, 20, 10)
+	if !p.HasMore {
+		t.Error("page 1 of 20 items with limit 10 should have more")
+	}
+}
+
+func TestNewPage_InfersFromRawCount(t *testing.T) {
+	// 过滤后只剩 2 条，但原始返回满 10 条，仍应认为有下一页
+	p := NewPage([]int{1, 2}, 1, 10, 0, 10)
+	if !p.HasMore {
+		t.Error("full raw page should imply more results")
+	}
+	p = NewPage[int](nil, 1, 10, 0, 3)
+	if p.HasMore || p.Items == nil {
+		t.Errorf("short page should be last and items non-nil, got %+v", p)
+	}
+}
+
+func TestSlicePage(t *testing.T) {
+	all := []int{1, 2, 3, 4, 5}
+	p := SlicePage(all, 2, 2)
+	if len(p.Items) != 2 || p.Items[0] != 3 || !p.HasMore || p.Total != 5 {
+		t.Errorf("unexpected page: %+v", p)
+	}
+	p = SlicePage(all, 3, 2)
+	if len(p.Items) != 1 || p.HasMore {
+		t.Errorf("unexpected last page: %+v", p)
+	}
+	p = SlicePage(all, 9, 2)
+	if len(p.Items) != 0 || p.HasMore {
+		t.Errorf("out of range page should be empty: %+v", p)
+	}
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// 音质档位，对应 Song.Extra["quality"] 的取值
+const (
+	QualityLossless = "lossless"
+	QualityHigh     = "high"
+	QualityStandard = "standard"
+)
+
+// Stream 是平台返回的一路可下载音频，一首歌通常有多路不同音质的 Stream
+type Stream struct {
+	URL        string `json:"url"`
+	Ext        string `json:"ext"`                   // flac / mp3 / m4a ...
+	Bitrate    int    `json:"bitrate"`               // kbps，无损或未知时为 0
+	Size       int64  `json:"size"`                  // 字节，未知时为 0
+	SampleRate int    `json:"sample_rate,omitempty"` // Hz，未知时为 0
+	Label      string `json:"label,omitempty"`       // 平台自己的音质标识，如 QQ 的 "F000"、咪咕的 "SQ"
+
+	// Headers 是请求音频时必须携带的请求头（如 Bilibili 的 Referer）
+	Headers map[string]string `json:"headers,omitempty"`
+	// Encryption 非空表示音频是加密的，Key 为解密所需的数据（如汽水音乐的 PlayAuth）
+	Encryption string `json:"encryption,omitempty"`
+	Key        string `json:"key,omitempty"`
+	// ExpiresAt 是链接失效时间（Unix 秒），0 表示平台未给出
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+// AudioStream 是打开后可以直接读取的音频：加密的流已经解密，
+// 读到的就是可以播放、可以写入文件的数据。用完必须 Close
+type AudioStream struct {
+	io.ReadCloser
+	Ext         string // 实际格式，如 m4a
+	ContentType string // 为空时按 application/octet-stream 处理
+	Size        int64  // 字节，未知时为 -1
+}
+
+// Tier 根据格式和码率推断音质档位
+func (st *Stream) Tier() string {
+	switch strings.ToLower(st.Ext) {
+	case "flac", "wav", "ape", "alac":
+		return QualityLossless
+	}
+	switch {
+	case st.Bitrate >= 700:
+		return QualityLossless
+	case st.Bitrate >= 192:
+		return QualityHigh
+	default:
+		return QualityStandard
+	}
+}
+
+// ApplyTo 把实际选中的格式、码率和大小回写到歌曲上
+func (st *Stream) ApplyTo(s *Song) {
+	if st.Ext != "" {
+		s.Ext = st.Ext
+		s.Bitrate = st.Bitrate
+	}
+	if st.Size > 0 {
+		s.Size = st.Size
+	}
+}
+
+var tierRank = map[string]int{QualityStandard: 0, QualityHigh: 1, QualityLossless: 2}
+
+// SelectStream 按音质档位从候选列表中挑选一路：优先请求的档位，没有时依次降档，
+// 再没有时取高于请求档位中最低的一路。quality 为空时按无损处理。URL 为空的候选会被忽略。
+func SelectStream(streams []Stream, quality string) (Stream, bool) {
+	want, ok := tierRank[quality]
+	if !ok {
+		want = tierRank[QualityLossless]
+	}
+
+	var candidates []Stream
+	for _, st := range streams {
+		if st.URL != "" {
+			candidates = append(candidates, st)
+		}
+	}
+	if len(candidates) == 0 {
+		return Stream{}, false
+	}
+
+	// 先按与请求档位的距离排序（不高于请求档位的优先），同档内取码率和体积更大的
+	distance := func(st *Stream) int {
+		d := want - tierRank[st.Tier()]
+		if d < 0 {
+			return 10 - d // 高于请求档位的排在所有降档之后，且越接近越优先
+		}
+		return d
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := &candidates[i], &candidates[j]
+		if da, db := distance(a), distance(b); da != db {
+			return da < db
+		}
+		if a.Bitrate != b.Bitrate {
+			return a.Bitrate > b.Bitrate
+		}
+		return a.Size > b.Size
+	})
+	return candidates[0], true
+}
+
+// ChooseStream 按 s.Extra["quality"] 从 streams 中选择一路，并把格式回写到 s
+func (s *Song) ChooseStream(streams []Stream) (Stream, bool) {
+	var quality string
+	if s.Extra != nil {
+		quality = s.Extra["quality"]
+	}
+	st, ok := SelectStream(streams, quality)
+	if ok {
+		st.ApplyTo(s)
+	}
+	return st, ok
+}
@@ -0,0 +1,80 @@
+package model
+
+import "testing"
+
+func testStreams() []Stream {
+	return []Stream{
+		{URL: "u128", Ext: "mp3", Bitrate: 128},
+		{URL: "uflac", Ext: "flac", Size: 30 << 20},
+		{URL: "u320", Ext: "mp3", Bitrate: 320},
+	}
+}
+
+func TestStream_Tier(t *testing.T) {
+	cases := []struct {
+		st   Stream
+		want string
+	}{
+		{Stream{Ext: "flac"}, QualityLossless},
+		{Stream{Ext: "m4a", Bitrate: 900}, QualityLossless},
+		{Stream{Ext: "mp3", Bitrate: 320}, QualityHigh},
+		{Stream{Ext: "mp3", Bitrate: 128}, QualityStandard},
+		{Stream{Ext: "mp3"}, QualityStandard},
+	}
+	for _, c := range cases {
+		if got := c.st.Tier(); got != c.want {
+			t.Errorf("%+v: got %s, want %s", c.st, got, c.want)
+		}
+	}
+}
+
+func TestSelectStream_ExactTier(t *testing.T) {
+	for quality, want := range map[string]string{
+		"":              "uflac",
+		QualityLossless: "uflac",
+		QualityHigh:     "u320",
+		QualityStandard: "u128",
+	} {
+		got, ok := SelectStream(testStreams(), quality)
+		if !ok || got.URL != want {
+			t.Errorf("quality %q: got %q, want %q", quality, got.URL, want)
+		}
+	}
+}
+
+func TestSelectStream_StepsDownThenUp(t *testing.T) {
+	streams := []Stream{
+		{URL: "u128", Ext: "mp3", Bitrate: 128},
+		{URL: "u96", Ext: "m4a", Bitrate: 96},
+	}
+	if got, _ := SelectStream(streams, QualityLossless); got.URL != "u128" {
+		t.Fatalf("lossless should fall back to best lower stream, got %q", got.URL)
+	}
+
+	streams = []Stream{
+		{URL: "uflac", Ext: "flac"},
+		{URL: "u320", Ext: "mp3", Bitrate: 320},
+	}
+	if got, _ := SelectStream(streams, QualityStandard); got.URL != "u320" {
+		t.Fatalf("standard should step up to the closest higher tier, got %q", got.URL)
+	}
+}
+
+func TestSelectStream_SkipsEmptyURL(t *testing.T) {
+	streams := []Stream{{Ext: "flac"}, {URL: "u128", Ext: "mp3", Bitrate: 128}}
+	if got, ok := SelectStream(streams, ""); !ok || got.URL != "u128" {
+		t.Fatalf("got %+v, %v", got, ok)
+	}
+	if _, ok := SelectStream([]Stream{{Ext: "flac"}}, ""); ok {
+		t.Fatal("expected no selectable stream")
+	}
+}
+
+func TestStream_ApplyTo(t *testing.T) {
+	s := Song{Ext: "mp3", Bitrate: 320, Size: 1}
+	st := Stream{Ext: "flac", Size: 100}
+	st.ApplyTo(&s)
+	if s.Ext != "flac" || s.Bitrate != 0 || s.Size != 100 {
+		t.Fatalf("unexpected song after apply: %+v", s)
+	}
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 逐字歌词格式
+const (
+	WordFormatQRC = "qrc" // QQ 音乐
+	WordFormatKRC = "krc" // 酷狗
+	WordFormatYRC = "yrc" // 网易云
+)
+
+// LyricWord 是逐字歌词里的一个字或词，时间单位为毫秒
+type LyricWord struct {
+	Start    int    `json:"start"`
+	Duration int    `json:"duration"`
+	Text     string `json:"text"`
+}
+
+// LyricLine 是歌词的一行，Text 为整行文本。
+// 作词、作曲这类信息行和普通 LRC 的行没有 Words
+type LyricLine struct {
+	Start       int         `json:"start"`
+	Duration    int         `json:"duration"` // 平台给出的行时长，普通 LRC 为 0
+	Text        string      `json:"text"`
+	Words       []LyricWord `json:"words,omitempty"`
+	Translation string      `json:"translation,omitempty"` // 翻译，多行时以换行分隔
+}
+
+// WordLyrics 是解码后的逐字歌词，Format 为来源格式
+type WordLyrics struct {
+	Format string `json:"format"`
+	Lyrics
+}
+
+var (
+	// reWordLine 匹配逐字歌词的行头 [开始,时长]
+	reWordLine = regexp.MustCompile(`^\[(\d+),(\d+)\](.*)$`)
+	// reQRCWord 匹配 QRC 的 "字(开始,时长)"，时间是绝对时间。
+	// 以结尾的 (数字,数字) 为界，字本身可以带括号，如 "(Live)"
+	reQRCWord = regexp.MustCompile(`(.*?)\((\d+),(\d+)\)`)
+	// reKRCWord 匹配 KRC 的 "<偏移,时长,0>字"，偏移相对于行开始
+	reKRCWord = regexp.MustCompile(`<(\d+),(\d+),\d+>([^<]*)`)
+	// reYRCWord 匹配 YRC 的 "(开始,时长,0)字"，时间是绝对时间
+	reYRCWord = regexp.MustCompile(`\((\d+),(\d+),\d+\)([^(]*)`)
+)
+
+// ParseQRC 解析已解密的 QRC 歌词正文 (XML 里 LyricContent 的内容)
+func ParseQRC(content string) *WordLyrics {
+	return parseWordLyrics(WordFormatQRC, content, func(start int, body string) []LyricWord {
+		var words []LyricWord
+		for _, m := range reQRCWord.FindAllStringSubmatch(body, -1) {
+			s, _ := strconv.Atoi(m[2])
+			d, _ := strconv.Atoi(m[3])
+			words = append(words, LyricWord{Start: s, Duration: d, Text: m[1]})
+		}
+		return words
+	})
+}
+
+// ParseKRC 解析已解密的 KRC 歌词
+func ParseKRC(content string) *WordLyrics {
+	return parseWordLyrics(WordFormatKRC, content, func(start int, body string) []LyricWord {
+		var words []LyricWord
+		for _, m := range reKRCWord.FindAllStringSubmatch(body, -1) {
+			off, _ := strconv.Atoi(m[1])
+			d, _ := strconv.Atoi(m[2])
+			words = append(words, LyricWord{Start: start + off, Duration: d, Text: m[3]})
+		}
+		return words
+	})
+}
+
+// ParseYRC 解析网易云的 YRC 歌词。开头的作词、作曲等信息是 JSON 行，
+// 转成没有逐字时间的普通行
+func ParseYRC(content string) *WordLyrics {
+	w := parseWordLyrics(WordFormatYRC, content, func(start int, body string) []LyricWord {
+		var words []LyricWord
+		for _, m := range reYRCWord.FindAllStringSubmatch(body, -1) {
+			s, _ := strconv.Atoi(m[1])
+			d, _ := strconv.Atoi(m[2])
+			words = append(words, LyricWord{Start: s, Duration: d, Text: m[3]})
+		}
+		return words
+	})
+
+	var info []LyricLine
+	for _, line := range splitLines(content) {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var meta struct {
+			T int `json:"t"`
+			C []struct {
+				Tx string `json:"tx"`
+			} `json:"c"`
+		}
+		if json.Unmarshal([]byte(line), &meta) != nil {
+			continue
+		}
+		var text strings.Builder
+		for _, c := range meta.C {
+			text.WriteString(c.Tx)
+		}
+		info = append(info, LyricLine{Start: meta.T, Text: text.String()})
+	}
+	if len(info) > 0 {
+		w.Lines = mergeLines(info, w.Lines)
+	}
+	return w
+}
+
+// parseWordLyrics 逐行解析 [开始,时长] 开头的歌词，行内的字由 words 按格式解析
+func parseWordLyrics(format, content string, words func(start int, body string) []LyricWord) *WordLyrics {
+	w := &WordLyrics{Format: format}
+	content = strings.TrimPrefix(content, "\ufeff")
+	for _, line := range splitLines(content) {
+		line = strings.TrimSpace(line)
+		if m := reWordLine.FindStringSubmatch(line); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			dur, _ := strconv.Atoi(m[2])
+			l := LyricLine{Start: start, Duration: dur, Words: words(start, m[3])}
+			for _, word := range l.Words {
+				l.Text += word.Text
+			}
+			if len(l.Words) == 0 {
+				l.Text = m[3]
+			}
+			w.Lines = append(w.Lines, l)
+			continue
+		}
+		if m := reLRCTag.FindStringSubmatch(line); m != nil {
+			w.setTag(m[1], m[2])
+		}
+	}
+	return w
+}
+
+// mergeLines 按开始时间合并两组已排序的行
+func mergeLines(a, b []LyricLine) []LyricLine {
+	out := make([]LyricLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i].Start <= b[j].Start {
+			out = append(out, a[i])
+			i++
+		} else {
+			out = append(out, b[j])
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
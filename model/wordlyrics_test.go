@@ -0,0 +1,84 @@
+package model
+
+import "testing"
+
+func TestParseQRC(t *testing.T) {
+	w := ParseQRC("[ti:晴天]\n[ar:周杰伦]\n[29350,1000]故(29350,400)事(29750,600)\n[40000,500]\n")
+	if w.Format != WordFormatQRC || w.Tags["ti"] != "晴天" || w.Tags["ar"] != "周杰伦" {
+		t.Fatalf("unexpected header: %+v", w)
+	}
+	if len(w.Lines) != 2 {
+		t.Fatalf("expected 2 lines, got %+v", w.Lines)
+	}
+	l := w.Lines[0]
+	if l.Start != 29350 || l.Duration != 1000 || l.Text != "故事" || len(l.Words) != 2 {
+		t.Fatalf("unexpected line: %+v", l)
+	}
+	if l.Words[1] != (LyricWord{Start: 29750, Duration: 600, Text: "事"}) {
+		t.Errorf("unexpected word: %+v", l.Words[1])
+	}
+}
+
+func TestParseQRC_WordWithParentheses(t *testing.T) {
+	w := ParseQRC("[1000,2000]晴天(1000,500) (Live)(1500,800)(1)(2300,100)\n")
+	if len(w.Lines) != 1 {
+		t.Fatalf("unexpected lines: %+v", w.Lines)
+	}
+	want := []LyricWord{
+		{Start: 1000, Duration: 500, Text: "晴天"},
+		{Start: 1500, Duration: 800, Text: " (Live)"},
+		{Start: 2300, Duration: 100, Text: "(1)"},
+	}
+	got := w.Lines[0].Words
+	if len(got) != len(want) {
+		t.Fatalf("got words %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("word %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if w.Lines[0].Text != "晴天 (Live)(1)" {
+		t.Errorf("line text = %q", w.Lines[0].Text)
+	}
+}
+
+func TestParseKRC_RelativeOffsets(t *testing.T) {
+	w := ParseKRC("\ufeff[id:$00000000]\n[29350,1000]<0,400,0>故<400,600,0>事\n")
+	if len(w.Lines) != 1 || len(w.Lines[0].Words) != 2 {
+		t.Fatalf("unexpected lines: %+v", w.Lines)
+	}
+	if got := w.Lines[0].Words[1]; got.Start != 29750 || got.Text != "事" {
+		t.Errorf("word offsets should be relative to the line: %+v", got)
+	}
+}
+
+func TestParseYRC_InfoLines(t *testing.T) {
+	w := ParseYRC(`{"t":0,"c":[{"tx":"作词: "},{"tx":"周杰伦"}]}` + "\n[29350,1000](29350,400,0)故(29750,600,0)事\n")
+	if len(w.Lines) != 2 || w.Lines[0].Text != "作词: 周杰伦" || len(w.Lines[0].Words) != 0 {
+		t.Fatalf("unexpected lines: %+v", w.Lines)
+	}
+	if !w.HasWords() {
+		t.Error("expected word timings")
+	}
+}
+
+func TestWordLyrics_EnhancedLRC(t *testing.T) {
+	w := &WordLyrics{Lyrics: Lyrics{
+		Tags: map[string]string{"ti": "晴天"},
+		Lines: []LyricLine{
+			{Start: 0, Text: "作词: 周杰伦"},
+			{Start: 29350, Duration: 1000, Text: "故事", Words: []LyricWord{
+				{Start: 29350, Duration: 400, Text: "故"},
+				{Start: 29750, Duration: 600, Text: "事"},
+			}},
+		},
+	}}
+	want := "[ti:晴天]\n[00:00.00]作词: 周杰伦\n[00:29.35]<00:29.35>故<00:29.75>事<00:30.35>\n"
+	if got := w.EnhancedLRC(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := w.LRC(); got != "[ti:晴天]\n[00:00.00]作词: 周杰伦\n[00:29.35]故事\n" {
+		t.Errorf("LRC: got %q", got)
+	}
+}
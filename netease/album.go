@@ -0,0 +1,187 @@
+package netease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// AlbumAPI 返回专辑信息和完整的曲目列表
+const AlbumAPI = "https://music.163.com/weapi/v1/album/"
+
+// SearchAlbum 按关键词搜索专辑
+func SearchAlbum(keyword string) ([]model.Album, error) { return getDefault().SearchAlbum(keyword) }
+
+// GetAlbum 获取专辑及其曲目
+func GetAlbum(id string) (*model.Album, error) { return getDefault().GetAlbum(id) }
+
+// SearchAlbumContext 按关键词搜索专辑
+func SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	return getDefault().SearchAlbumContext(ctx, keyword)
+}
+
+// GetAlbumContext 获取专辑及其曲目
+func GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	return getDefault().GetAlbumContext(ctx, id)
+}
+
+// SearchAlbum 按关键词搜索专辑
+func (n *Netease) SearchAlbum(keyword string) ([]model.Album, error) {
+	return n.SearchAlbumContext(context.Background(), keyword)
+}
+
+// GetAlbum 获取专辑及其曲目
+func (n *Netease) GetAlbum(id string) (*model.Album, error) {
+	return n.GetAlbumContext(context.Background(), id)
+}
+
+// SearchAlbumContext 与歌曲搜索共用 cloudsearch 接口 (type 10)
+func (n *Netease) SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	eparams := map[string]interface{}{
+		"method": "POST",
+		"url":    "http://music.163.com/api/cloudsearch/pc",
+		"params": map[string]interface{}{"s": keyword, "type": 10, "offset": 0, "limit": 10},
+	}
+	eparamsJSON, _ := json.Marshal(eparams)
+	form := url.Values{}
+	form.Set("eparams", EncryptLinux(string(eparamsJSON)))
+
+	body, err := n.client.PostContext(ctx, SearchAPI, strings.NewReader(form.Encode()),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		utils.WithHeader("Cookie", n.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			Albums []neteaseAlbum `json:"albums"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "album search json parse error: %w", err)
+	}
+
+	albums := make([]model.Album, 0, len(resp.Result.Albums))
+	for _, item := range resp.Result.Albums {
+		albums = append(albums, item.toModel())
+	}
+	return albums, nil
+}
+
+// GetAlbumContext 一次 weapi 请求取回专辑信息和曲目
+func (n *Netease) GetAlbumContext(ctx context.Context, id string) (*model.Album, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("netease", model.ErrInvalidInput, "invalid netease album id: %q", id)
+	}
+	params, encSecKey := EncryptWeApi(`{"csrf_token":""}`)
+	form := url.Values{}
+	form.Set("params", params)
+	form.Set("encSecKey", encSecKey)
+
+	body, err := n.client.PostContext(ctx, AlbumAPI+id, strings.NewReader(form.Encode()),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		utils.WithHeader("Cookie", n.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code  int          `json:"code"`
+		Album neteaseAlbum `json:"album"`
+		Songs []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Ar   []struct {
+				Name string `json:"name"`
+			} `json:"ar"`
+			Dt int    `json:"dt"`
+			No int    `json:"no"`
+			Cd string `json:"cd"`
+		} `json:"songs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "album json parse error: %w", err)
+	}
+	if resp.Code != 200 {
+		return nil, apiError(resp.Code)
+	}
+
+	album := resp.Album.toModel()
+	for _, item := range resp.Songs {
+		var artistNames []string
+		for _, ar := range item.Ar {
+			artistNames = append(artistNames, ar.Name)
+		}
+		disc, _ := strconv.Atoi(strings.TrimSpace(item.Cd))
+		album.Tracks = append(album.Tracks, model.AlbumTrack{
+			Song: model.Song{
+				Source:   "netease",
+				ID:       strconv.Itoa(item.ID),
+				Name:     item.Name,
+				Artist:   strings.Join(artistNames, "、"),
+				Album:    album.Name,
+				AlbumID:  album.ID,
+				Duration: item.Dt / 1000,
+				Cover:    album.Cover,
+				Link:     fmt.Sprintf("https://music.163.com/#/song?id=%d", item.ID),
+				Extra: map[string]string{
+					"song_id": strconv.Itoa(item.ID),
+				},
+			},
+			TrackNumber: item.No,
+			DiscNumber:  disc,
+		})
+	}
+	if album.TrackCount == 0 {
+		album.TrackCount = len(album.Tracks)
+	}
+	return &album, nil
+}
+
+// neteaseAlbum 是 cloudsearch 和专辑接口共用的专辑结构
+type neteaseAlbum struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	PicURL      string `json:"picUrl"`
+	PublishTime int64  `json:"publishTime"` // 毫秒
+	Size        int    `json:"size"`
+	Description string `json:"description"`
+	Artists     []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+}
+
+func (a neteaseAlbum) toModel() model.Album {
+	var artists []string
+	for _, ar := range a.Artists {
+		artists = append(artists, ar.Name)
+	}
+	var release string
+	if a.PublishTime > 0 {
+		// publishTime 是北京时间零点，按该时区格式化，避免在 UTC 主机上差一天
+		release = time.UnixMilli(a.PublishTime).In(time.FixedZone("CST", 8*3600)).Format("2006-01-02")
+	}
+	return model.Album{
+		ID:          strconv.Itoa(a.ID),
+		Name:        a.Name,
+		Artists:     artists,
+		ReleaseDate: release,
+		Cover:       a.PicURL,
+		TrackCount:  a.Size,
+		Description: a.Description,
+		Source:      "netease",
+		Link:        fmt.Sprintf("https://music.163.com/#/album?id=%d", a.ID),
+	}
+}
@@ -0,0 +1,197 @@
+package netease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+const (
+	ArtistTopSongAPI = "https://music.163.com/weapi/artist/top/song"
+	ArtistAlbumsAPI  = "https://music.163.com/weapi/artist/albums/"
+)
+
+const artistAlbumPageSize = 100
+
+// SearchArtist 按关键词搜索歌手
+func SearchArtist(keyword string) ([]model.Artist, error) { return getDefault().SearchArtist(keyword) }
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func GetArtistTopSongs(id string) ([]model.Song, error) { return getDefault().GetArtistTopSongs(id) }
+
+// GetArtistAlbums 获取歌手的全部专辑，从新到旧，不含曲目
+func GetArtistAlbums(id string) ([]model.Album, error) { return getDefault().GetArtistAlbums(id) }
+
+// SearchArtistContext 按关键词搜索歌手
+func SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	return getDefault().SearchArtistContext(ctx, keyword)
+}
+
+// GetArtistTopSongsContext 获取歌手最热门的歌曲
+func GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetArtistTopSongsContext(ctx, id)
+}
+
+// GetArtistAlbumsContext 获取歌手的全部专辑，不含曲目
+func GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	return getDefault().GetArtistAlbumsContext(ctx, id)
+}
+
+// SearchArtist 按关键词搜索歌手
+func (n *Netease) SearchArtist(keyword string) ([]model.Artist, error) {
+	return n.SearchArtistContext(context.Background(), keyword)
+}
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func (n *Netease) GetArtistTopSongs(id string) ([]model.Song, error) {
+	return n.GetArtistTopSongsContext(context.Background(), id)
+}
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func (n *Netease) GetArtistAlbums(id string) ([]model.Album, error) {
+	return n.GetArtistAlbumsContext(context.Background(), id)
+}
+
+// SearchArtistContext 使用 cloudsearch 的 type 100 (歌手) 搜索
+func (n *Netease) SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	eparams := map[string]interface{}{
+		"method": "POST",
+		"url":    "http://music.163.com/api/cloudsearch/pc",
+		"params": map[string]interface{}{"s": keyword, "type": 100, "offset": 0, "limit": 10},
+	}
+	eparamsJSON, _ := json.Marshal(eparams)
+	form := url.Values{}
+	form.Set("eparams", EncryptLinux(string(eparamsJSON)))
+
+	body, err := n.client.PostContext(ctx, SearchAPI, strings.NewReader(form.Encode()),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		utils.WithHeader("Cookie", n.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			Artists []struct {
+				ID        int      `json:"id"`
+				Name      string   `json:"name"`
+				PicURL    string   `json:"picUrl"`
+				Alias     []string `json:"alias"`
+				AlbumSize int      `json:"albumSize"`
+				MusicSize int      `json:"musicSize"`
+			} `json:"artists"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "artist search json parse error: %w", err)
+	}
+
+	artists := make([]model.Artist, 0, len(resp.Result.Artists))
+	for _, item := range resp.Result.Artists {
+		artists = append(artists, model.Artist{
+			ID:         strconv.Itoa(item.ID),
+			Name:       item.Name,
+			Aliases:    item.Alias,
+			Avatar:     item.PicURL,
+			SongCount:  item.MusicSize,
+			AlbumCount: item.AlbumSize,
+			Source:     "netease",
+			Link:       fmt.Sprintf("https://music.163.com/#/artist?id=%d", item.ID),
+		})
+	}
+	return artists, nil
+}
+
+// GetArtistTopSongsContext 先取热门前 50 首的 ID，再通过歌曲详情接口加载，
+// 使大小和封面与普通搜索结果一致
+func (n *Netease) GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("netease", model.ErrInvalidInput, "invalid netease artist id: %q", id)
+	}
+	reqJSON, _ := json.Marshal(map[string]interface{}{"id": id, "csrf_token": ""})
+	params, encSecKey := EncryptWeApi(string(reqJSON))
+	form := url.Values{}
+	form.Set("params", params)
+	form.Set("encSecKey", encSecKey)
+
+	body, err := n.client.PostContext(ctx, ArtistTopSongAPI, strings.NewReader(form.Encode()),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		utils.WithHeader("Cookie", n.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code  int `json:"code"`
+		Songs []struct {
+			ID int `json:"id"`
+		} `json:"songs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "artist top songs json parse error: %w", err)
+	}
+	if resp.Code != 200 {
+		return nil, apiError(resp.Code)
+	}
+
+	ids := make([]string, 0, len(resp.Songs))
+	for _, s := range resp.Songs {
+		ids = append(ids, strconv.Itoa(s.ID))
+	}
+	if len(ids) == 0 {
+		return []model.Song{}, nil
+	}
+	return n.fetchSongsByIDs(ctx, ids)
+}
+
+// GetArtistAlbumsContext 翻页取回全部专辑，直到接口不再报告还有更多
+func (n *Netease) GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, model.Errorf("netease", model.ErrInvalidInput, "invalid netease artist id: %q", id)
+	}
+
+	return utils.FetchPages(func(page, offset int) ([]model.Album, bool, error) {
+		reqData := fmt.Sprintf(`{"offset":%d,"limit":%d,"total":true,"csrf_token":""}`, offset, artistAlbumPageSize)
+		params, encSecKey := EncryptWeApi(reqData)
+		form := url.Values{}
+		form.Set("params", params)
+		form.Set("encSecKey", encSecKey)
+
+		body, err := n.client.PostContext(ctx, ArtistAlbumsAPI+id, strings.NewReader(form.Encode()),
+			utils.WithHeader("Referer", Referer),
+			utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+			utils.WithHeader("Cookie", n.cookie),
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var resp struct {
+			Code      int            `json:"code"`
+			More      bool           `json:"more"`
+			HotAlbums []neteaseAlbum `json:"hotAlbums"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false, model.Errorf("netease", model.ErrSchemaChanged, "artist albums json parse error: %w", err)
+		}
+		if resp.Code != 200 {
+			return nil, false, apiError(resp.Code)
+		}
+
+		albums := make([]model.Album, 0, len(resp.HotAlbums))
+		for _, item := range resp.HotAlbums {
+			albums = append(albums, item.toModel())
+		}
+		return albums, resp.More, nil
+	})
+}
@@ -1,6 +1,10 @@
 package netease
 
-import "github.com/guohuiyuan/music-lib/model"
+import (
+	"context"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
 
 // Known chart playlist IDs in Netease's system.
 var neteaseCharts = []model.Chart{
@@ -17,13 +21,29 @@ func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 	return getDefault().GetChartSongs(chartID, limit)
 }
 
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
+}
+
 func (n *Netease) GetCharts() ([]model.Chart, error) {
-	return neteaseCharts, nil
+	return n.GetChartsContext(context.Background())
 }
 
 func (n *Netease) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return n.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (n *Netease) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return neteaseCharts, nil
+}
+
+func (n *Netease) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
 	// Netease charts are just playlists with fixed IDs.
-	songs, err := n.GetPlaylistSongs(chartID)
+	songs, err := n.GetPlaylistSongsContext(ctx, chartID)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package netease
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"os"
@@ -28,10 +29,20 @@ func SetConfigDir(dir string) {
 }
 
 // SetCookie updates the global cookie and replaces the default Netease instance.
+// The new instance shares the old one's HTTP client, so the rate limiter and
+// circuit breaker state survive a login.
 func SetCookie(cookie string) {
 	loginMu.Lock()
 	defer loginMu.Unlock()
-	defaultNetease = New(cookie)
+	defaultNetease = &Netease{cookie: cookie, client: defaultNetease.client}
+}
+
+// Configure sets the HTTP client options (proxy, timeout, cookie jar...) of
+// the default instance. SetCookie keeps the client built here.
+func Configure(opts ...utils.ClientOption) {
+	loginMu.Lock()
+	defer loginMu.Unlock()
+	defaultNetease = New(defaultNetease.cookie, opts...)
 }
 
 // GetCookie returns the current cookie string.
@@ -93,7 +104,8 @@ func removeCookieFromDisk() {
 // GetLoginStatus checks whether the current cookie is valid.
 // Returns loggedIn status and nickname.
 func GetLoginStatus() (bool, string) {
-	cookie := GetCookie()
+	n := getDefault()
+	cookie := n.cookie
 	if cookie == "" {
 		return false, ""
 	}
@@ -111,7 +123,7 @@ func GetLoginStatus() (bool, string) {
 		utils.WithHeader("Cookie", cookie),
 	}
 
-	body, err := utils.Post(AccountAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(context.Background(), AccountAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return false, ""
 	}
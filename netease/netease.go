@@ -1,13 +1,14 @@
 package netease
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -24,9 +25,15 @@ const (
 
 type Netease struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Netease { return &Netease{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *Netease {
+	return &Netease{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (n *Netease) Client() *utils.Client { return n.client }
 
 var defaultNetease = New("")
 
@@ -37,23 +44,150 @@ func SearchPlaylist(keyword string) ([]model.Playlist, error) {
 func GetPlaylistSongs(playlistID string) ([]model.Song, error) {
 	return getDefault().GetPlaylistSongs(playlistID)
 }
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(playlistID string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(playlistID, page, limit)
+}
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
 	return getDefault().ParsePlaylist(link)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return getDefault().GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return getDefault().GetLyrics(s) }
-func Parse(link string) (*model.Song, error)       { return getDefault().Parse(link) }
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+func GetLyricsSet(s *model.Song) (*model.LyricsSet, error) {
+	return getDefault().GetLyricsSet(s)
+}
 
 func GetRecommendedPlaylists() ([]model.Playlist, error) {
 	return getDefault().GetRecommendedPlaylists()
 }
 
-// Search 搜索歌曲
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, playlistID string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, playlistID)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, playlistID string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, playlistID, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
+
+func GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error) {
+	return getDefault().GetLyricsSetContext(ctx, s)
+}
+
+func GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
+	return getDefault().GetRecommendedPlaylistsContext(ctx)
+}
+
 func (n *Netease) Search(keyword string) ([]model.Song, error) {
+	return n.SearchContext(context.Background(), keyword)
+}
+
+func (n *Netease) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return n.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (n *Netease) GetPlaylistSongs(playlistID string) ([]model.Song, error) {
+	return n.GetPlaylistSongsContext(context.Background(), playlistID)
+}
+
+func (n *Netease) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return n.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (n *Netease) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return n.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (n *Netease) GetPlaylistSongsPage(playlistID string, page, limit int) (*model.Page[model.Song], error) {
+	return n.GetPlaylistSongsPageContext(context.Background(), playlistID, page, limit)
+}
+
+func (n *Netease) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return n.ParsePlaylistContext(context.Background(), link)
+}
+
+func (n *Netease) Parse(link string) (*model.Song, error) {
+	return n.ParseContext(context.Background(), link)
+}
+
+func (n *Netease) GetDownloadURL(s *model.Song) (string, error) {
+	return n.GetDownloadURLContext(context.Background(), s)
+}
+
+func (n *Netease) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return n.GetStreamsContext(context.Background(), s)
+}
+
+func (n *Netease) GetLyrics(s *model.Song) (string, error) {
+	return n.GetLyricsContext(context.Background(), s)
+}
+
+func (n *Netease) GetLyricsSet(s *model.Song) (*model.LyricsSet, error) {
+	return n.GetLyricsSetContext(context.Background(), s)
+}
+
+func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
+	return n.GetRecommendedPlaylistsContext(context.Background())
+}
+
+// SearchContext 搜索歌曲（第一页）
+func (n *Netease) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := n.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，page 从 1 开始
+func (n *Netease) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	eparams := map[string]interface{}{
 		"method": "POST",
 		"url":    "http://music.163.com/api/cloudsearch/pc",
-		"params": map[string]interface{}{"s": keyword, "type": 1, "offset": 0, "limit": 10},
+		"params": map[string]interface{}{"s": keyword, "type": 1, "offset": (page - 1) * limit, "limit": limit},
 	}
 	eparamsJSON, _ := json.Marshal(eparams)
 	encryptedParam := EncryptLinux(string(eparamsJSON))
@@ -66,12 +200,13 @@ func (n *Netease) Search(keyword string) ([]model.Song, error) {
 		utils.WithHeader("Cookie", n.cookie),
 	}
 
-	body, err := utils.Post(SearchAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, SearchAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp struct {
+		Code   int `json:"code"`
 		Result struct {
 			Songs []struct {
 				ID   int    `json:"id"`
@@ -98,11 +233,15 @@ func (n *Netease) Search(keyword string) ([]model.Song, error) {
 					Size int64 `json:"size"`
 				} `json:"l"`
 			} `json:"songs"`
+			SongCount int `json:"songCount"`
 		} `json:"result"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("netease json parse error: %w", err)
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "json parse error: %w", err)
+	}
+	if resp.Code != 0 && resp.Code != 200 {
+		return nil, apiError(resp.Code)
 	}
 
 	var songs []model.Song
@@ -149,15 +288,25 @@ func (n *Netease) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	return model.NewPage(songs, page, limit, resp.Result.SongCount, len(resp.Result.Songs)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (n *Netease) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单（第一页）
+func (n *Netease) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := n.SearchPlaylistPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (n *Netease) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 100)
 	eparams := map[string]interface{}{
 		"method": "POST",
 		"url":    "http://music.163.com/api/cloudsearch/pc",
-		"params": map[string]interface{}{"s": keyword, "type": 1000, "offset": 0, "limit": 10},
+		"params": map[string]interface{}{"s": keyword, "type": 1000, "offset": (page - 1) * limit, "limit": limit},
 	}
 	eparamsJSON, _ := json.Marshal(eparams)
 	encryptedParam := EncryptLinux(string(eparamsJSON))
@@ -170,7 +319,7 @@ func (n *Netease) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 		utils.WithHeader("Cookie", n.cookie),
 	}
 
-	body, err := utils.Post(SearchAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, SearchAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return nil, err
 	}
@@ -188,11 +337,12 @@ func (n *Netease) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 				PlayCount   int    `json:"playCount"`
 				Description string `json:"description"`
 			} `json:"playlists"`
+			PlaylistCount int `json:"playlistCount"`
 		} `json:"result"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("netease playlist json parse error: %w", err)
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -209,28 +359,53 @@ func (n *Netease) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Link:        fmt.Sprintf("https://music.163.com/#/playlist?id=%d", item.ID),
 		})
 	}
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, resp.Result.PlaylistCount, len(resp.Result.Playlists)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情（仅返回歌曲列表）
-func (n *Netease) GetPlaylistSongs(playlistID string) ([]model.Song, error) {
-	_, songs, err := n.fetchPlaylistDetail(playlistID)
+// GetPlaylistSongsContext 获取歌单详情（仅返回歌曲列表）
+func (n *Netease) GetPlaylistSongsContext(ctx context.Context, playlistID string) ([]model.Song, error) {
+	_, songs, err := n.fetchPlaylistDetail(ctx, playlistID)
 	return songs, err
 }
 
-// ParsePlaylist 解析歌单链接
-func (n *Netease) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲：先拿全量 trackIds，只对当前页的 ID 拉取详情
+func (n *Netease) GetPlaylistSongsPageContext(ctx context.Context, playlistID string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 500)
+	_, allIDs, err := n.fetchPlaylistTrackIDs(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	ids := model.SlicePage(allIDs, page, limit)
+	songs, err := n.fetchSongsByIDs(ctx, ids.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Page[model.Song]{Items: songs, Page: page, Limit: limit, Total: ids.Total, HasMore: ids.HasMore}, nil
+}
+
+// ParsePlaylistContext 解析歌单链接
+func (n *Netease) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	re := regexp.MustCompile(`playlist\?id=(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, nil, errors.New("invalid netease playlist link")
+		return nil, nil, model.Errorf("netease", model.ErrInvalidInput, "invalid netease playlist link")
 	}
 	playlistID := matches[1]
-	return n.fetchPlaylistDetail(playlistID)
+	return n.fetchPlaylistDetail(ctx, playlistID)
 }
 
 // fetchPlaylistDetail 获取歌单详情 (核心逻辑：使用 trackIds 全量获取)
-func (n *Netease) fetchPlaylistDetail(playlistID string) (*model.Playlist, []model.Song, error) {
+func (n *Netease) fetchPlaylistDetail(ctx context.Context, playlistID string) (*model.Playlist, []model.Song, error) {
+	playlist, allIDs, err := n.fetchPlaylistTrackIDs(ctx, playlistID)
+	if err != nil {
+		return nil, nil, err
+	}
+	allSongs, _ := n.fetchSongsByIDs(ctx, allIDs)
+	return playlist, allSongs, nil
+}
+
+// fetchPlaylistTrackIDs 获取歌单元数据与完整的歌曲 ID 列表
+func (n *Netease) fetchPlaylistTrackIDs(ctx context.Context, playlistID string) (*model.Playlist, []string, error) {
 	reqData := map[string]interface{}{
 		"id":         playlistID,
 		"n":          0, // 0表示不直接返回详情，我们只需要ID列表
@@ -248,7 +423,7 @@ func (n *Netease) fetchPlaylistDetail(playlistID string) (*model.Playlist, []mod
 		utils.WithHeader("Cookie", n.cookie),
 	}
 
-	body, err := utils.Post(PlaylistAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, PlaylistAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -273,10 +448,10 @@ func (n *Netease) fetchPlaylistDetail(playlistID string) (*model.Playlist, []mod
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("netease playlist detail json parse error: %w", err)
+		return nil, nil, model.Errorf("netease", model.ErrSchemaChanged, "playlist detail json parse error: %w", err)
 	}
 	if resp.Code != 200 {
-		return nil, nil, fmt.Errorf("netease api error code: %d", resp.Code)
+		return nil, nil, apiError(resp.Code)
 	}
 
 	// 构造 Playlist 元数据
@@ -298,8 +473,14 @@ func (n *Netease) fetchPlaylistDetail(playlistID string) (*model.Playlist, []mod
 		allIDs = append(allIDs, strconv.Itoa(tid.ID))
 	}
 
-	// 分批获取歌曲详情 (Detail API 支持批量，每次 500-1000 首没问题，这里保守用 500)
+	return playlist, allIDs, nil
+}
+
+// fetchSongsByIDs 分批获取歌曲详情 (Detail API 支持批量，每次 500-1000 首没问题，这里保守用 500)。
+// 单批失败时跳过该批；全部失败时返回最后一个错误
+func (n *Netease) fetchSongsByIDs(ctx context.Context, allIDs []string) ([]model.Song, error) {
 	var allSongs []model.Song
+	var lastErr error
 	batchSize := 500
 	for i := 0; i < len(allIDs); i += batchSize {
 		end := i + batchSize
@@ -308,17 +489,21 @@ func (n *Netease) fetchPlaylistDetail(playlistID string) (*model.Playlist, []mod
 		}
 
 		batchIDs := allIDs[i:end]
-		batchSongs, err := n.fetchSongsBatch(batchIDs)
-		if err == nil {
-			allSongs = append(allSongs, batchSongs...)
+		batchSongs, err := n.fetchSongsBatch(ctx, batchIDs)
+		if err != nil {
+			lastErr = err
+			continue
 		}
+		allSongs = append(allSongs, batchSongs...)
 	}
-
-	return playlist, allSongs, nil
+	if len(allSongs) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return allSongs, nil
 }
 
 // fetchSongsBatch 批量获取歌曲详情 (利用 Detail 接口的批量特性，速度极快)
-func (n *Netease) fetchSongsBatch(songIDs []string) ([]model.Song, error) {
+func (n *Netease) fetchSongsBatch(ctx context.Context, songIDs []string) ([]model.Song, error) {
 	if len(songIDs) == 0 {
 		return nil, nil
 	}
@@ -348,7 +533,7 @@ func (n *Netease) fetchSongsBatch(songIDs []string) ([]model.Song, error) {
 		utils.WithHeader("Cookie", n.cookie),
 	}
 
-	body, err := utils.Post(DetailAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, DetailAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return nil, err
 	}
@@ -396,22 +581,22 @@ func (n *Netease) fetchSongsBatch(songIDs []string) ([]model.Song, error) {
 	return songs, nil
 }
 
-// Parse 解析单曲链接
-func (n *Netease) Parse(link string) (*model.Song, error) {
+// ParseContext 解析单曲链接
+func (n *Netease) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`id=(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid netease link")
+		return nil, model.Errorf("netease", model.ErrInvalidInput, "invalid netease link")
 	}
 	songID := matches[1]
 
-	songs, err := n.fetchSongsBatch([]string{songID})
+	songs, err := n.fetchSongsBatch(ctx, []string{songID})
 	if err != nil || len(songs) == 0 {
-		return nil, fmt.Errorf("fetch song detail failed: %v", err)
+		return nil, fmt.Errorf("fetch song detail failed: %w", err)
 	}
 	song := &songs[0]
 
-	downloadURL, err := n.GetDownloadURL(song)
+	downloadURL, err := n.GetDownloadURLContext(ctx, song)
 	if err == nil {
 		song.URL = downloadURL
 	}
@@ -419,10 +604,25 @@ func (n *Netease) Parse(link string) (*model.Song, error) {
 	return song, nil
 }
 
-// GetDownloadURL 获取下载链接
-func (n *Netease) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接，按 s.Extra["quality"] 从 GetStreamsContext 的结果中选择
+func (n *Netease) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	streams, err := n.GetStreamsContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("netease", model.ErrRestricted, "download url not found for song %s", s.ID)
+	}
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流。
+// 接口按 br 返回不超过该码率的最高音质，所以从高到低请求，
+// 已经拿到的档位不再重复请求。
+func (n *Netease) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
 	if s.Source != "netease" {
-		return "", errors.New("source mismatch")
+		return nil, model.ErrSourceMismatch
 	}
 
 	songID := s.ID
@@ -430,29 +630,19 @@ func (n *Netease) GetDownloadURL(s *model.Song) (string, error) {
 		songID = s.Extra["song_id"]
 	}
 
-	quality := ""
-	if s.Extra != nil {
-		quality = s.Extra["quality"]
-	}
-
-	var brList []int
-	switch quality {
-	case "standard":
-		brList = []int{128000}
-	case "high":
-		brList = []int{320000, 128000}
-	default: // "lossless" or empty
-		brList = []int{999000, 320000, 128000}
-	}
-
 	headers := []utils.RequestOption{
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
 		utils.WithHeader("Cookie", n.cookie),
 	}
 
+	var streams []model.Stream
 	var lastErr error
-	for _, br := range brList {
+	floor := 1 << 30 // 已取得的最低码率，低于它的 br 才需要再请求
+	for _, br := range []int{999000, 320000, 128000} {
+		if br >= floor {
+			continue
+		}
 		reqData := map[string]interface{}{
 			"ids": []string{songID},
 			"br":  br,
@@ -463,7 +653,7 @@ func (n *Netease) GetDownloadURL(s *model.Song) (string, error) {
 		form.Set("params", params)
 		form.Set("encSecKey", encSecKey)
 
-		body, err := utils.Post(DownloadAPI, strings.NewReader(form.Encode()), headers...)
+		body, err := n.client.PostContext(ctx, DownloadAPI, strings.NewReader(form.Encode()), headers...)
 		if err != nil {
 			lastErr = err
 			continue
@@ -471,47 +661,92 @@ func (n *Netease) GetDownloadURL(s *model.Song) (string, error) {
 
 		var resp struct {
 			Data []struct {
-				URL  string `json:"url"`
-				Code int    `json:"code"`
-				Br   int    `json:"br"`
+				URL           string          `json:"url"`
+				Code          int             `json:"code"`
+				Fee           int             `json:"fee"`
+				Br            int             `json:"br"`
+				Size          int64           `json:"size"`
+				Type          string          `json:"type"`
+				Expi          int             `json:"expi"` // 有效期，秒
+				Sr            int             `json:"sr"`
+				Level         string          `json:"level"`
+				FreeTrialInfo json.RawMessage `json:"freeTrialInfo"`
 			} `json:"data"`
 		}
 		if err := json.Unmarshal(body, &resp); err != nil {
-			lastErr = fmt.Errorf("json parse error: %w", err)
+			lastErr = model.Errorf("netease", model.ErrSchemaChanged, "json parse error: %w", err)
 			continue
 		}
-		if len(resp.Data) > 0 && resp.Data[0].URL != "" {
-			// 回写实际音质：优先使用 API 返回的真实 Br 值
-			actualBr := resp.Data[0].Br
-			if actualBr == 0 {
-				actualBr = br
-			}
-			switch {
-			case actualBr >= 900000:
-				s.Ext = "flac"
-				s.Bitrate = 0
-			case actualBr >= 320000:
-				s.Ext = "mp3"
-				s.Bitrate = 320
-			default:
-				s.Ext = "mp3"
-				s.Bitrate = 128
+		if len(resp.Data) == 0 {
+			lastErr = model.Errorf("netease", model.ErrSchemaChanged, "empty data for br=%d", br)
+			continue
+		}
+		d := resp.Data[0]
+		if d.URL == "" {
+			// fee 1/4 为会员或付费专辑，-110 为需要会员，其余通常是无版权
+			kind := model.ErrRestricted
+			if d.Fee == 1 || d.Fee == 4 || d.Code == -110 {
+				kind = model.ErrVIPRequired
 			}
-			return resp.Data[0].URL, nil
+			lastErr = model.Errorf("netease", kind, "empty url for br=%d (code %d, fee %d)", br, d.Code, d.Fee)
+			continue
+		}
+		// 试听片段不算可用的音频流
+		if len(d.FreeTrialInfo) > 0 && string(d.FreeTrialInfo) != "null" {
+			lastErr = model.Errorf("netease", model.ErrVIPRequired, "only a trial clip is available for br=%d", br)
+			continue
+		}
+
+		actualBr := d.Br
+		if actualBr == 0 {
+			actualBr = br
 		}
-		lastErr = fmt.Errorf("empty url for br=%d", br)
+		st := model.Stream{
+			URL:        d.URL,
+			Size:       d.Size,
+			SampleRate: d.Sr,
+			Label:      d.Level,
+		}
+		switch {
+		case actualBr >= 900000:
+			st.Ext = "flac"
+		case actualBr >= 320000:
+			st.Ext, st.Bitrate = "mp3", 320
+		default:
+			st.Ext, st.Bitrate = "mp3", 128
+		}
+		if d.Type != "" {
+			st.Ext = strings.ToLower(d.Type)
+		}
+		if d.Expi > 0 {
+			st.ExpiresAt = time.Now().Unix() + int64(d.Expi)
+		}
+		streams = append(streams, st)
+		floor = actualBr
 	}
 
-	if lastErr != nil {
-		return "", fmt.Errorf("[netease] download url not found for song %s: %w", songID, lastErr)
+	if len(streams) == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("download url not found for song %s: %w", songID, lastErr)
+		}
+		return nil, model.Errorf("netease", model.ErrRestricted, "download url not found for song %s", songID)
 	}
-	return "", fmt.Errorf("[netease] download url not found for song %s (might be vip or copyright restricted)", songID)
+	return streams, nil
 }
 
-// GetLyrics 获取歌词
-func (n *Netease) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (n *Netease) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	set, err := n.GetLyricsSetContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	return set.Original, nil
+}
+
+// GetLyricsSetContext 获取原文歌词以及翻译 (tlyric) 和罗马音 (romalrc)，没有的版本为空
+func (n *Netease) GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error) {
 	if s.Source != "netease" {
-		return "", errors.New("source mismatch")
+		return nil, model.ErrSourceMismatch
 	}
 
 	songID := s.ID
@@ -524,6 +759,7 @@ func (n *Netease) GetLyrics(s *model.Song) (string, error) {
 		"id":         songID,
 		"lv":         -1,
 		"tv":         -1,
+		"rv":         -1,
 	}
 	reqJSON, _ := json.Marshal(reqData)
 	params, encSecKey := EncryptWeApi(string(reqJSON))
@@ -538,28 +774,35 @@ func (n *Netease) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	lyricAPI := "https://music.163.com/weapi/song/lyric"
-	body, err := utils.Post(lyricAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, lyricAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	type lyricField struct {
+		Lyric string `json:"lyric"`
+	}
 	var resp struct {
-		Code int `json:"code"`
-		Lrc  struct {
-			Lyric string `json:"lyric"`
-		} `json:"lrc"`
+		Code    int        `json:"code"`
+		Lrc     lyricField `json:"lrc"`
+		Tlyric  lyricField `json:"tlyric"`
+		Romalrc lyricField `json:"romalrc"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("json parse error: %w", err)
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 	if resp.Code != 200 {
-		return "", fmt.Errorf("netease api error code: %d", resp.Code)
+		return nil, apiError(resp.Code)
 	}
-	return resp.Lrc.Lyric, nil
+	return &model.LyricsSet{
+		Original:     resp.Lrc.Lyric,
+		Translation:  resp.Tlyric.Lyric,
+		Romanization: resp.Romalrc.Lyric,
+	}, nil
 }
 
-// GetRecommendedPlaylists 获取推荐歌单 (无需登录，即首页推荐歌单)
-func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
+// GetRecommendedPlaylistsContext 获取推荐歌单 (无需登录，即首页推荐歌单)
+func (n *Netease) GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
 	reqData := map[string]interface{}{
 		"limit": 30, // 默认返回30个
 		"total": true,
@@ -577,7 +820,7 @@ func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
 		// 此接口不需要 Cookie
 	}
 
-	body, err := utils.Post(RecommendedPlaylistAPI, strings.NewReader(form.Encode()), headers...)
+	body, err := n.client.PostContext(ctx, RecommendedPlaylistAPI, strings.NewReader(form.Encode()), headers...)
 	if err != nil {
 		return nil, err
 	}
@@ -595,10 +838,10 @@ func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
 		} `json:"result"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("netease recommended playlist json parse error: %w", err)
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "recommended playlist json parse error: %w", err)
 	}
 	if resp.Code != 200 {
-		return nil, fmt.Errorf("netease api error code: %d", resp.Code)
+		return nil, apiError(resp.Code)
 	}
 
 	var playlists []model.Playlist
@@ -616,7 +859,7 @@ func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
 			Cover:       item.PicURL,
 			PlayCount:   int(item.PlayCount),
 			TrackCount:  item.TrackCount,
-			Description: item.Copywriter, 
+			Description: item.Copywriter,
 			Creator:     creatorDisplay, // [修改] 使用推荐语代替作者名
 			Link:        fmt.Sprintf("https://music.163.com/#/playlist?id=%d", item.ID),
 			Extra:       map[string]string{},
@@ -630,4 +873,22 @@ func (n *Netease) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	return playlists, nil
-}
\ No newline at end of file
+}
+
+// apiError 把网易云接口返回的 code 映射为统一的错误分类
+func apiError(code int) error {
+	var kind error
+	switch code {
+	case 301, 302:
+		kind = model.ErrLoginExpired
+	case 404:
+		kind = model.ErrNotFound
+	case 405, -460, -462:
+		kind = model.ErrRateLimited
+	case 403, -200:
+		kind = model.ErrRestricted
+	case 400:
+		kind = model.ErrInvalidInput
+	}
+	return model.CodeError("netease", kind, code, "api error")
+}
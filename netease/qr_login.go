@@ -39,7 +39,7 @@ func (p *QRProvider) StartQR(ctx context.Context) (string, string, error) {
 	form.Set("params", params)
 	form.Set("encSecKey", encSecKey)
 
-	body, err := utils.Post(qrUnikeyAPI, strings.NewReader(form.Encode()),
+	body, err := getDefault().client.PostContext(ctx, qrUnikeyAPI, strings.NewReader(form.Encode()),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
 	)
@@ -81,7 +81,7 @@ func (p *QRProvider) PollQR(ctx context.Context, unikey string) (login.PollResul
 	form.Set("params", params)
 	form.Set("encSecKey", encSecKey)
 
-	resp, err := utils.PostRaw(qrPollAPI, strings.NewReader(form.Encode()),
+	resp, err := getDefault().client.PostRawContext(ctx, qrPollAPI, strings.NewReader(form.Encode()),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
 	)
@@ -110,7 +110,7 @@ func (p *QRProvider) PollQR(ctx context.Context, unikey string) (login.PollResul
 		cookies := extractCookies(resp.Cookies(), "163.com")
 		nickname := result.Nickname
 		if nickname == "" {
-			nickname = getNicknameFromCookie(cookies)
+			nickname = getNicknameFromCookie(ctx, cookies)
 		}
 		return login.PollResult{
 			State:    login.StateSuccess,
@@ -132,7 +132,7 @@ func extractCookies(cookies []*http.Cookie, domainSuffix string) string {
 	return strings.Join(parts, "; ")
 }
 
-func getNicknameFromCookie(cookie string) string {
+func getNicknameFromCookie(ctx context.Context, cookie string) string {
 	if cookie == "" {
 		return ""
 	}
@@ -142,7 +142,7 @@ func getNicknameFromCookie(cookie string) string {
 	form.Set("params", params)
 	form.Set("encSecKey", encSecKey)
 
-	body, err := utils.Post(AccountAPI, strings.NewReader(form.Encode()),
+	body, err := getDefault().client.PostContext(ctx, AccountAPI, strings.NewReader(form.Encode()),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
 		utils.WithHeader("Cookie", cookie),
@@ -0,0 +1,15 @@
+package netease
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	// 登录后 SetCookie 会替换默认实例，因此每次调用都取当前实例
+	registry.Register(registry.Info{
+		Name:           "netease",
+		DisplayName:    "网易云音乐",
+		Qualities:      []string{registry.QualityLossless, registry.QualityHigh, registry.QualityStandard},
+		LoginSupported: true,
+		Canary:         registry.Canary{Keyword: "周杰伦", SongID: "186016"},
+		Configure:      Configure,
+	}, func() any { return getDefault() })
+}
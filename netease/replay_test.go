@@ -0,0 +1,51 @@
+package netease
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	song := &model.Song{Source: "netease", ID: "186016"}
+	replay.Run(t, newReplay, []replay.Case[*Netease]{
+		{Name: "search", Call: func(_ *testing.T, n *Netease) (any, error) { return n.Search("周杰伦") }},
+		{Name: "lyrics", Call: func(_ *testing.T, n *Netease) (any, error) { return n.GetLyrics(song) }},
+		{Name: "lyrics_set", Call: func(t *testing.T, n *Netease) (any, error) {
+			set, err := n.GetLyricsSet(&model.Song{Source: "netease", ID: "536622304"})
+			if err == nil && (set.Translation == "" || set.Romanization == "") {
+				t.Fatalf("translation or romanization missing: %+v", set)
+			}
+			return set, err
+		}},
+		{Name: "word_lyrics", Call: func(_ *testing.T, n *Netease) (any, error) { return n.GetWordLyrics(song) }},
+		{Name: "streams", Call: func(t *testing.T, n *Netease) (any, error) {
+			streams, err := n.GetStreams(song)
+			// ExpiresAt is derived from the current time; check it, then drop it from the golden file.
+			for i := range streams {
+				if streams[i].ExpiresAt == 0 {
+					t.Errorf("stream %d: missing ExpiresAt", i)
+				}
+				streams[i].ExpiresAt = 0
+			}
+			return streams, err
+		}},
+		{Name: "artist_search", Call: func(_ *testing.T, n *Netease) (any, error) { return n.SearchArtist("周杰伦") }},
+		{Name: "artist_songs", Call: func(_ *testing.T, n *Netease) (any, error) { return n.GetArtistTopSongs("6452") }},
+		// Two pages, the first of which says there is more.
+		{Name: "artist_albums", Len: 3, Call: func(_ *testing.T, n *Netease) (any, error) { return n.GetArtistAlbums("6452") }},
+	})
+}
+
+func TestReplay_StreamsVIPOnly(t *testing.T) {
+	n := newReplay(replay.Open(t, "streams_vip")...)
+	_, err := n.GetStreams(&model.Song{Source: "netease", ID: "186016"})
+	if !errors.Is(err, model.ErrVIPRequired) {
+		t.Fatalf("expected ErrVIPRequired, got %v", err)
+	}
+}
+
+func newReplay(opts ...utils.ClientOption) *Netease { return New("", opts...) }
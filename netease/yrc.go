@@ -0,0 +1,79 @@
+package netease
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// GetWordLyrics returns the word-timed YRC lyrics of a Netease song.
+func GetWordLyrics(s *model.Song) (*model.WordLyrics, error) { return getDefault().GetWordLyrics(s) }
+
+// GetWordLyricsContext is GetWordLyrics with a context.
+func GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	return getDefault().GetWordLyricsContext(ctx, s)
+}
+
+func (n *Netease) GetWordLyrics(s *model.Song) (*model.WordLyrics, error) {
+	return n.GetWordLyricsContext(context.Background(), s)
+}
+
+// GetWordLyricsContext fetches the song's YRC ("yv") lyrics from the v1
+// lyric API and parses the per-word timings. Songs without YRC return
+// model.ErrNotFound so callers can fall back to GetLyrics.
+func (n *Netease) GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	if s.Source != "netease" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	songID := s.ID
+	if s.Extra != nil && s.Extra["song_id"] != "" {
+		songID = s.Extra["song_id"]
+	}
+
+	reqData := map[string]interface{}{
+		"csrf_token": "",
+		"id":         songID,
+		"cp":         false,
+		"lv":         -1,
+		"yv":         -1,
+	}
+	reqJSON, _ := json.Marshal(reqData)
+	params, encSecKey := EncryptWeApi(string(reqJSON))
+	form := url.Values{}
+	form.Set("params", params)
+	form.Set("encSecKey", encSecKey)
+
+	headers := []utils.RequestOption{
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		utils.WithHeader("Cookie", n.cookie),
+	}
+
+	body, err := n.client.PostContext(ctx, "https://music.163.com/weapi/song/lyric/v1", strings.NewReader(form.Encode()), headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code int `json:"code"`
+		Yrc  struct {
+			Lyric string `json:"lyric"`
+		} `json:"yrc"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("netease", model.ErrSchemaChanged, "json parse error: %w", err)
+	}
+	if resp.Code != 200 {
+		return nil, apiError(resp.Code)
+	}
+	w := model.ParseYRC(resp.Yrc.Lyric)
+	if !w.HasWords() {
+		return nil, model.Errorf("netease", model.ErrNotFound, "no word-timed lyrics for %s", songID)
+	}
+	return w, nil
+}
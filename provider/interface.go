@@ -1,18 +1,31 @@
 package provider
 
-import "github.com/guohuiyuan/music-lib/model"
+import (
+	"context"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
 
 // MusicProvider 定义了所有音乐源必须实现的方法
 type MusicProvider interface {
 	// Search 搜索歌曲
 	Search(keyword string) ([]model.Song, error)
-	
+
 	// Parse 解析分享链接，返回单首歌曲详情（包含下载链接）
 	Parse(link string) (*model.Song, error)
-	
+
 	// GetDownloadURL 获取下载链接（主要用于搜索结果后续获取，Parse 结果通常已包含 URL）
 	GetDownloadURL(s *model.Song) (string, error)
-	
+
 	// GetLyrics 获取歌词
 	GetLyrics(s *model.Song) (string, error)
-}
\ No newline at end of file
+
+	// 以下为带 context 的版本，ctx 取消或超时后会中断正在进行的上游请求
+	SearchContext(ctx context.Context, keyword string) ([]model.Song, error)
+	ParseContext(ctx context.Context, link string) (*model.Song, error)
+	GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error)
+	GetLyricsContext(ctx context.Context, s *model.Song) (string, error)
+
+	// SearchPageContext 分页搜索歌曲，page 从 1 开始，limit <= 0 时使用平台默认条数
+	SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error)
+}
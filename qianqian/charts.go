@@ -0,0 +1,123 @@
+package qianqian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Known Qianqian chart (bd) IDs.
+var qianqianCharts = []model.Chart{
+	{ID: "257851", Name: "新歌榜"},
+	{ID: "257856", Name: "热歌榜"},
+}
+
+// GetCharts returns the list of supported Qianqian charts.
+func GetCharts() ([]model.Chart, error) { return getDefault().GetCharts() }
+
+// GetChartSongs returns the top N songs from a Qianqian chart.
+func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongs(chartID, limit)
+}
+
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
+}
+
+func (q *Qianqian) GetCharts() ([]model.Chart, error) {
+	return q.GetChartsContext(context.Background())
+}
+
+func (q *Qianqian) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return q.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (q *Qianqian) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return qianqianCharts, nil
+}
+
+func (q *Qianqian) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	params := url.Values{}
+	params.Set("bdid", chartID)
+	params.Set("pageNo", "1")
+	params.Set("pageSize", strconv.Itoa(limit))
+	params.Set("appid", AppID)
+	signParams(params)
+	apiURL := "https://music.91q.com/v1/bd/list?" + params.Encode()
+
+	body, err := q.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Referer", Referer),
+		utils.WithHeader("Cookie", q.cookie),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("qianqian chart request: %w", err)
+	}
+
+	var resp struct {
+		Data struct {
+			Result []struct {
+				TSID           string `json:"TSID"`
+				Title          string `json:"title"`
+				AlbumTitle     string `json:"albumTitle"`
+				AlbumAssetCode string `json:"albumAssetCode"`
+				Pic            string `json:"pic"`
+				Duration       int    `json:"duration"`
+				Artist         []struct {
+					Name string `json:"name"`
+				} `json:"artist"`
+			} `json:"result"`
+		} `json:"data"`
+		Errno  int    `json:"errno"`
+		ErrMsg string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qianqian", model.ErrSchemaChanged, "chart json: %w", err)
+	}
+	if resp.Errno != 0 && resp.Errno != 22000 {
+		return nil, model.CodeError("qianqian", nil, resp.Errno, resp.ErrMsg)
+	}
+	if len(resp.Data.Result) == 0 {
+		return nil, model.Errorf("qianqian", model.ErrNotFound, "chart %s is empty or unknown", chartID)
+	}
+
+	songs := make([]model.Song, 0, len(resp.Data.Result))
+	for _, item := range resp.Data.Result {
+		var artistNames []string
+		for _, ar := range item.Artist {
+			artistNames = append(artistNames, ar.Name)
+		}
+		songs = append(songs, model.Song{
+			Source:   "qianqian",
+			ID:       item.TSID,
+			Name:     item.Title,
+			Artist:   strings.Join(artistNames, "、"),
+			Album:    item.AlbumTitle,
+			AlbumID:  item.AlbumAssetCode,
+			Duration: item.Duration,
+			Cover:    item.Pic,
+			Link:     fmt.Sprintf("https://music.91q.com/song/%s", item.TSID),
+			Extra: map[string]string{
+				"tsid": item.TSID,
+			},
+		})
+	}
+	if len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, nil
+}
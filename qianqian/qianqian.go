@@ -1,16 +1,17 @@
 package qianqian
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
@@ -26,33 +27,157 @@ const (
 
 type Qianqian struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *Qianqian { return &Qianqian{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *Qianqian {
+	return &Qianqian{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (q *Qianqian) Client() *utils.Client { return q.client }
+
+var (
+	defaultMu       sync.RWMutex
+	defaultQianqian = New("")
+)
 
-var defaultQianqian = New("")
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultQianqian = New(defaultQianqian.cookie, opts...)
+}
+
+// getDefault 返回当前的默认实例
+func getDefault() *Qianqian {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultQianqian
+}
 
-func Search(keyword string) ([]model.Song, error) { return defaultQianqian.Search(keyword) }
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultQianqian.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }                                                      // [新增]
-func GetPlaylistSongs(id string) ([]model.Song, error) { return defaultQianqian.GetPlaylistSongs(id) } // [新增]
-func GetDownloadURL(s *model.Song) (string, error)     { return defaultQianqian.GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)          { return defaultQianqian.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)           { return defaultQianqian.Parse(link) }
+func GetPlaylistSongs(id string) ([]model.Song, error) { return getDefault().GetPlaylistSongs(id) } // [新增]
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
+}
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
 
-// Search 搜索歌曲
 func (q *Qianqian) Search(keyword string) ([]model.Song, error) {
+	return q.SearchContext(context.Background(), keyword)
+}
+
+func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return q.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (q *Qianqian) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return q.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (q *Qianqian) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return q.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (q *Qianqian) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return q.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (q *Qianqian) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return q.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (q *Qianqian) Parse(link string) (*model.Song, error) {
+	return q.ParseContext(context.Background(), link)
+}
+
+func (q *Qianqian) GetDownloadURL(s *model.Song) (string, error) {
+	return q.GetDownloadURLContext(context.Background(), s)
+}
+
+func (q *Qianqian) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return q.GetStreamsContext(context.Background(), s)
+}
+
+func (q *Qianqian) GetLyrics(s *model.Song) (string, error) {
+	return q.GetLyricsContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (q *Qianqian) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := q.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，page 从 1 开始
+func (q *Qianqian) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 50)
 	params := url.Values{}
 	params.Set("word", keyword)
 	params.Set("type", "1")
-	params.Set("pageNo", "1")
-	params.Set("pageSize", "10")
+	params.Set("pageNo", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(limit))
 	params.Set("appid", AppID)
 	signParams(params)
 	apiURL := "https://music.91q.com/v1/search?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -79,11 +204,12 @@ func (q *Qianqian) Search(keyword string) ([]model.Song, error) {
 				} `json:"rateFileInfo"`
 				IsVip int `json:"isVip"`
 			} `json:"typeTrack"`
+			Total int `json:"total"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qianqian json parse error: %w", err)
+		return nil, model.Errorf("qianqian", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -132,17 +258,30 @@ func (q *Qianqian) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	return model.NewPage(songs, page, limit, resp.Data.Total, len(resp.Data.TypeTrack)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (q *Qianqian) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := q.SearchPlaylistPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单
+func (q *Qianqian) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 50)
 	// [参数修正] timestamp 是必须的，type=6 代表歌单 (之前可能用了 10000 导致报错)
 	params := url.Values{}
 	params.Set("word", keyword)
 	params.Set("type", "6") // 6 = 歌单
-	params.Set("pageNo", "1")
-	params.Set("pageSize", "10")
+	params.Set("pageNo", strconv.Itoa(page))
+	params.Set("pageSize", strconv.Itoa(limit))
 	params.Set("appid", AppID)
 	params.Set("timestamp", strconv.FormatInt(time.Now().Unix(), 10))
 
@@ -151,7 +290,7 @@ func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 
 	apiURL := "https://music.91q.com/v1/search?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -171,13 +310,13 @@ func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &rawResp); err != nil {
-		return nil, fmt.Errorf("qianqian playlist json parse error: %w", err)
+		return nil, model.Errorf("qianqian", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	if !rawResp.State {
 		// 如果 API 返回失败，通常 Data 是 []，直接返回空或错误
 		// 忽略 "没有结果" 的错误，返回空列表
-		return nil, nil // 或者 fmt.Errorf("api error: %s", rawResp.Msg)
+		return model.NewPage[model.Playlist](nil, page, limit, 0, 0), nil // 或者 fmt.Errorf("api error: %s", rawResp.Msg)
 	}
 
 	// 解析 Data 部分
@@ -189,12 +328,13 @@ func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			TrackCount int         `json:"trackCount"`
 			Tag        string      `json:"tag"`
 		} `json:"typeSonglist"`
+		Total int `json:"total"`
 	}
 
 	// 尝试将 RawMessage 解析为对象
 	if err := json.Unmarshal(rawResp.Data, &dataObj); err != nil {
 		// 如果解析失败，可能是因为 Data 是 [] (空结果)
-		return nil, nil
+		return model.NewPage[model.Playlist](nil, page, limit, 0, 0), nil
 	}
 
 	var playlists []model.Playlist
@@ -216,21 +356,21 @@ func (q *Qianqian) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 		})
 	}
 
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, dataObj.Total, len(dataObj.TypeSonglist)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情（解析歌曲列表）
-func (q *Qianqian) GetPlaylistSongs(id string) ([]model.Song, error) {
+// GetPlaylistSongsContext 获取歌单详情（解析歌曲列表）
+func (q *Qianqian) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
 	params := url.Values{}
 	params.Set("id", id) // 歌单 ID
 	params.Set("appid", AppID)
 	// [关键修正] 接口路径改为 v1/tracklist/info，原 songlist/info 已 404
 	params.Set("type", "0") // 0通常代表默认列表，有些情况可能需要此参数
 	signParams(params)
-	
+
 	apiURL := "https://music.91q.com/v1/tracklist/info?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -261,17 +401,17 @@ func (q *Qianqian) GetPlaylistSongs(id string) ([]model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qianqian playlist detail json error: %w", err)
+		return nil, model.Errorf("qianqian", model.ErrSchemaChanged, "playlist detail json error: %w", err)
 	}
 
 	if resp.Errno != 0 && resp.Errno != 22000 { // 22000 sometimes implies success with empty data
-		return nil, fmt.Errorf("api error: %s (code %d)", resp.ErrMsg, resp.Errno)
+		return nil, model.CodeError("qianqian", nil, resp.Errno, resp.ErrMsg)
 	}
 
 	var songs []model.Song
 	for _, item := range resp.Data.TrackList {
 		// 过滤掉需要 VIP 的歌曲，或者虽然列出但无法播放的
-		// if item.IsVip != 0 { continue } 
+		// if item.IsVip != 0 { continue }
 
 		var artistNames []string
 		for _, ar := range item.Artist {
@@ -287,7 +427,7 @@ func (q *Qianqian) GetPlaylistSongs(id string) ([]model.Song, error) {
 			Duration: item.Duration,
 			Cover:    item.Pic,
 			// 构造网页链接
-			Link:     fmt.Sprintf("https://music.91q.com/song/%s", item.TSID),
+			Link: fmt.Sprintf("https://music.91q.com/song/%s", item.TSID),
 			Extra: map[string]string{
 				"tsid": item.TSID,
 			},
@@ -295,66 +435,95 @@ func (q *Qianqian) GetPlaylistSongs(id string) ([]model.Song, error) {
 	}
 
 	if len(songs) == 0 {
-		return nil, errors.New("playlist is empty or invalid")
+		return nil, model.Errorf("qianqian", model.ErrNotFound, "playlist is empty or invalid")
 	}
 
 	return songs, nil
 }
 
-// Parse 解析链接并获取完整信息
-func (q *Qianqian) Parse(link string) (*model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲。歌单接口一次返回全部歌曲，这里在内存中切页
+func (q *Qianqian) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	songs, err := q.GetPlaylistSongsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParseContext 解析链接并获取完整信息
+func (q *Qianqian) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	// 1. 提取 TSID
 	re := regexp.MustCompile(`music\.91q\.com/song/(\w+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid qianqian link")
+		return nil, model.Errorf("qianqian", model.ErrInvalidInput, "invalid qianqian link")
 	}
 	tsid := matches[1]
 
 	// 2. 获取 Metadata (通过 song/info 接口)
-	song, err := q.fetchSongInfo(tsid)
+	song, err := q.fetchSongInfo(ctx, tsid)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. 获取下载链接 (直接调用 fetchDownloadURL)
-	url, err := q.fetchDownloadURL(tsid)
-	if err == nil {
-		song.URL = url
+	// 3. 获取音频流并按音质选择下载链接
+	if streams, err := q.fetchStreams(ctx, tsid); err == nil {
+		if st, ok := song.ChooseStream(streams); ok {
+			song.URL = st.URL
+		}
 	}
 
 	return song, nil
 }
 
-// GetDownloadURL 获取下载链接
-func (q *Qianqian) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接
+func (q *Qianqian) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "qianqian" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 	if s.URL != "" {
 		return s.URL, nil
 	}
 
+	streams, err := q.GetStreamsContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("qianqian", model.ErrRestricted, "download url not found for song %s", s.ID)
+	}
+	return st.URL, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流
+func (q *Qianqian) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	if s.Source != "qianqian" {
+		return nil, model.ErrSourceMismatch
+	}
+
 	tsid := s.ID
 	if s.Extra != nil && s.Extra["tsid"] != "" {
 		tsid = s.Extra["tsid"]
 	}
-
-	return q.fetchDownloadURL(tsid)
+	return q.fetchStreams(ctx, tsid)
 }
 
-// fetchDownloadURL 内部方法：获取下载链接
-func (q *Qianqian) fetchDownloadURL(tsid string) (string, error) {
-	qualities := []string{"3000", "320", "128", "64"}
-	for _, rate := range qualities {
+// fetchStreams 内部方法：按 rate 逐档请求 tracklink，同一链接只保留一次。
+// 只有试听片段时才退回试听链接，并以 Label "trial" 标记
+func (q *Qianqian) fetchStreams(ctx context.Context, tsid string) ([]model.Stream, error) {
+	var streams, trials []model.Stream
+	seen := make(map[string]bool)
+	for _, rate := range []int{3000, 320, 128, 64} {
 		params := url.Values{}
 		params.Set("TSID", tsid)
 		params.Set("appid", AppID)
-		params.Set("rate", rate)
+		params.Set("rate", strconv.Itoa(rate))
 		signParams(params)
 		apiURL := "https://music.91q.com/v1/song/tracklink?" + params.Encode()
 
-		body, err := utils.Get(apiURL,
+		body, err := q.client.GetContext(ctx, apiURL,
 			utils.WithHeader("User-Agent", UserAgent),
 			utils.WithHeader("Referer", Referer),
 			utils.WithHeader("Cookie", q.cookie),
@@ -369,6 +538,7 @@ func (q *Qianqian) fetchDownloadURL(tsid string) (string, error) {
 				Format         string `json:"format"`
 				Size           int64  `json:"size"`
 				Duration       int    `json:"duration"`
+				Rate           int    `json:"rate"`
 				TrailAudioInfo struct {
 					Path string `json:"path"`
 				} `json:"trail_audio_info"`
@@ -377,26 +547,55 @@ func (q *Qianqian) fetchDownloadURL(tsid string) (string, error) {
 		if err := json.Unmarshal(body, &resp); err != nil {
 			continue
 		}
-		downloadURL := resp.Data.Path
-		if downloadURL == "" {
-			downloadURL = resp.Data.TrailAudioInfo.Path
+		d := resp.Data
+
+		st := model.Stream{Ext: strings.ToLower(d.Format), Size: d.Size, Label: strconv.Itoa(rate)}
+		actual := d.Rate
+		if actual == 0 {
+			actual = rate
+		}
+		if actual >= 1000 {
+			if st.Ext == "" {
+				st.Ext = "flac"
+			}
+		} else {
+			st.Bitrate = actual
+			if st.Ext == "" {
+				st.Ext = "mp3"
+			}
 		}
-		if downloadURL != "" {
-			return downloadURL, nil
+
+		switch {
+		case d.Path != "" && !seen[d.Path]:
+			seen[d.Path] = true
+			st.URL = d.Path
+			streams = append(streams, st)
+		case d.Path == "" && d.TrailAudioInfo.Path != "" && !seen[d.TrailAudioInfo.Path]:
+			seen[d.TrailAudioInfo.Path] = true
+			st.URL = d.TrailAudioInfo.Path
+			st.Size = 0
+			st.Label = "trial"
+			trials = append(trials, st)
 		}
 	}
-	return "", fmt.Errorf("[qianqian] download url not found for song %s", tsid)
+	if len(streams) == 0 {
+		streams = trials
+	}
+	if len(streams) == 0 {
+		return nil, model.Errorf("qianqian", model.ErrRestricted, "download url not found for song %s", tsid)
+	}
+	return streams, nil
 }
 
 // fetchSongInfo 内部方法：获取元数据
-func (q *Qianqian) fetchSongInfo(tsid string) (*model.Song, error) {
+func (q *Qianqian) fetchSongInfo(ctx context.Context, tsid string) (*model.Song, error) {
 	params := url.Values{}
 	params.Set("TSID", tsid)
 	params.Set("appid", AppID)
 	signParams(params)
 	apiURL := "https://music.91q.com/v1/song/info?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -419,10 +618,10 @@ func (q *Qianqian) fetchSongInfo(tsid string) (*model.Song, error) {
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qianqian song info parse error: %w", err)
+		return nil, model.Errorf("qianqian", model.ErrSchemaChanged, "song info parse error: %w", err)
 	}
 	if len(resp.Data) == 0 {
-		return nil, errors.New("song info not found")
+		return nil, model.Errorf("qianqian", model.ErrNotFound, "song info not found")
 	}
 
 	item := resp.Data[0]
@@ -446,10 +645,10 @@ func (q *Qianqian) fetchSongInfo(tsid string) (*model.Song, error) {
 	}, nil
 }
 
-// GetLyrics 获取歌词
-func (q *Qianqian) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (q *Qianqian) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
 	if s.Source != "qianqian" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	tsid := s.ID
@@ -463,7 +662,7 @@ func (q *Qianqian) GetLyrics(s *model.Song) (string, error) {
 	signParams(params)
 	apiURL := "https://music.91q.com/v1/song/info?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", Referer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -478,14 +677,14 @@ func (q *Qianqian) GetLyrics(s *model.Song) (string, error) {
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("qianqian song info parse error: %w", err)
+		return "", model.Errorf("qianqian", model.ErrSchemaChanged, "song info parse error: %w", err)
 	}
 	if len(resp.Data) == 0 || resp.Data[0].Lyric == "" {
-		return "", errors.New("lyric url not found")
+		return "", model.Errorf("qianqian", model.ErrNotFound, "lyric url not found")
 	}
 
 	lyricURL := resp.Data[0].Lyric
-	lrcBody, err := utils.Get(lyricURL,
+	lrcBody, err := q.client.GetContext(ctx, lyricURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", q.cookie),
 	)
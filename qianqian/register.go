@@ -0,0 +1,12 @@
+package qianqian
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	registry.Register(registry.Info{
+		Name:        "qianqian",
+		DisplayName: "千千音乐",
+		Canary:      registry.Canary{Keyword: "周杰伦"},
+		Configure:   Configure,
+	}, func() any { return getDefault() })
+}
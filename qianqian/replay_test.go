@@ -0,0 +1,17 @@
+package qianqian
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Qianqian]{
+		{Name: "search", Call: func(_ *testing.T, q *Qianqian) (any, error) { return q.Search("林俊杰") }},
+		{Name: "charts", Call: func(_ *testing.T, q *Qianqian) (any, error) { return q.GetChartSongs("257851", 2) }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Qianqian { return New("", opts...) }
@@ -0,0 +1,273 @@
+package qq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// SearchAlbum 按关键词搜索专辑
+func SearchAlbum(keyword string) ([]model.Album, error) { return getDefault().SearchAlbum(keyword) }
+
+// GetAlbum 获取专辑 (album mid) 及其曲目
+func GetAlbum(mid string) (*model.Album, error) { return getDefault().GetAlbum(mid) }
+
+// SearchAlbumContext 按关键词搜索专辑
+func SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	return getDefault().SearchAlbumContext(ctx, keyword)
+}
+
+// GetAlbumContext 获取专辑及其曲目
+func GetAlbumContext(ctx context.Context, mid string) (*model.Album, error) {
+	return getDefault().GetAlbumContext(ctx, mid)
+}
+
+// SearchAlbum 按关键词搜索专辑
+func (q *QQ) SearchAlbum(keyword string) ([]model.Album, error) {
+	return q.SearchAlbumContext(context.Background(), keyword)
+}
+
+// GetAlbum 获取专辑及其曲目
+func (q *QQ) GetAlbum(mid string) (*model.Album, error) {
+	return q.GetAlbumContext(context.Background(), mid)
+}
+
+// SearchAlbumContext 使用旧版 search_for_qq_cp 接口，t=8 (专辑)
+func (q *QQ) SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error) {
+	params := url.Values{}
+	params.Set("w", keyword)
+	params.Set("format", "json")
+	params.Set("p", "1")
+	params.Set("n", "10")
+	params.Set("t", "8")
+	apiURL := "http://c.y.qq.com/soso/fcgi-bin/search_for_qq_cp?" + params.Encode()
+
+	body, err := q.client.GetContext(ctx, apiURL,
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Referer", SearchReferer),
+		utils.WithHeader("Cookie", q.cookie),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Album struct {
+				List []struct {
+					AlbumMID   string `json:"albumMID"`
+					AlbumName  string `json:"albumName"`
+					SingerName string `json:"singerName"`
+					PublicTime string `json:"publicTime"`
+					SongCount  int    `json:"song_count"`
+				} `json:"list"`
+			} `json:"album"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "album search json parse error: %w", err)
+	}
+
+	albums := make([]model.Album, 0, len(resp.Data.Album.List))
+	for _, item := range resp.Data.Album.List {
+		if item.AlbumMID == "" {
+			continue
+		}
+		var artists []string
+		if item.SingerName != "" {
+			artists = strings.Split(item.SingerName, "/")
+		}
+		albums = append(albums, model.Album{
+			ID:          item.AlbumMID,
+			Name:        item.AlbumName,
+			Artists:     artists,
+			ReleaseDate: item.PublicTime,
+			Cover:       albumCover(item.AlbumMID),
+			TrackCount:  item.SongCount,
+			Source:      "qq",
+			Link:        fmt.Sprintf("https://y.qq.com/n/ryqq/albumDetail/%s", item.AlbumMID),
+		})
+	}
+	return albums, nil
+}
+
+// GetAlbumContext 一次 musicu.fcg 请求取回专辑详情和曲目列表
+func (q *QQ) GetAlbumContext(ctx context.Context, mid string) (*model.Album, error) {
+	if mid == "" {
+		return nil, model.Errorf("qq", model.ErrInvalidInput, "empty qq album mid")
+	}
+	reqData := map[string]interface{}{
+		"comm": map[string]interface{}{"ct": 24, "cv": 10000},
+		"detail": map[string]interface{}{
+			"module": "music.musichallAlbum.AlbumInfoServer",
+			"method": "GetAlbumDetail",
+			"param":  map[string]interface{}{"albumMid": mid},
+		},
+		"songs": map[string]interface{}{
+			"module": "music.musichallAlbum.AlbumSongList",
+			"method": "GetAlbumSongList",
+			"param":  map[string]interface{}{"albumMid": mid, "begin": 0, "num": 500, "order": 2},
+		},
+	}
+	body, err := q.postMusicu(ctx, reqData)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code   int `json:"code"`
+		Detail struct {
+			Code int `json:"code"`
+			Data struct {
+				BasicInfo struct {
+					AlbumMid    string `json:"albumMid"`
+					AlbumName   string `json:"albumName"`
+					PublishDate string `json:"publishDate"`
+					Desc        string `json:"desc"`
+				} `json:"basicInfo"`
+				Singer struct {
+					SingerList []struct {
+						Name string `json:"name"`
+					} `json:"singerList"`
+				} `json:"singer"`
+			} `json:"data"`
+		} `json:"detail"`
+		Songs struct {
+			Code int `json:"code"`
+			Data struct {
+				SongList []struct {
+					SongInfo musicuSongInfo `json:"songInfo"`
+				} `json:"songList"`
+				TotalNum int `json:"totalNum"`
+			} `json:"data"`
+		} `json:"songs"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "album json parse error: %w", err)
+	}
+	if resp.Code != 0 || resp.Detail.Code != 0 || resp.Songs.Code != 0 {
+		return nil, apiError(firstNonZero(resp.Code, resp.Detail.Code, resp.Songs.Code))
+	}
+
+	info := resp.Detail.Data.BasicInfo
+	var artists []string
+	for _, s := range resp.Detail.Data.Singer.SingerList {
+		artists = append(artists, s.Name)
+	}
+	album := &model.Album{
+		ID:          mid,
+		Name:        info.AlbumName,
+		Artists:     artists,
+		ReleaseDate: info.PublishDate,
+		Cover:       albumCover(mid),
+		TrackCount:  resp.Songs.Data.TotalNum,
+		Description: info.Desc,
+		Source:      "qq",
+		Link:        fmt.Sprintf("https://y.qq.com/n/ryqq/albumDetail/%s", mid),
+	}
+
+	for _, item := range resp.Songs.Data.SongList {
+		si := item.SongInfo
+		if si.Mid == "" {
+			continue
+		}
+		song := si.toSong()
+		song.Album = album.Name
+		song.AlbumID = mid
+		song.Cover = album.Cover
+		// QQ 的 index_cd 从 0 开始
+		album.Tracks = append(album.Tracks, model.AlbumTrack{
+			Song:        song,
+			TrackNumber: si.IndexAlbum,
+			DiscNumber:  si.IndexCD + 1,
+		})
+	}
+	if album.TrackCount == 0 {
+		album.TrackCount = len(album.Tracks)
+	}
+	return album, nil
+}
+
+func albumCover(albumMID string) string {
+	if albumMID == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://y.gtimg.cn/music/photo_new/T002R300x300M000%s.jpg", albumMID)
+}
+
+// postMusicu 向 musicu.fcg 发送合并请求。reqData 中除 "comm" 外的每个顶层键
+// 都是一次模块调用，结果在响应的同名键下返回
+func (q *QQ) postMusicu(ctx context.Context, reqData map[string]interface{}) ([]byte, error) {
+	jsonData, _ := json.Marshal(reqData)
+	return q.client.PostContext(ctx, musicuURL, bytes.NewReader(jsonData),
+		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36"),
+		utils.WithHeader("Referer", "https://y.qq.com/"),
+		utils.WithHeader("Content-Type", "application/json"),
+		utils.WithHeader("Cookie", q.cookie),
+	)
+}
+
+// musicuSongInfo 是 musichall 模块返回的 songInfo 对象
+type musicuSongInfo struct {
+	Mid        string `json:"mid"`
+	Name       string `json:"name"`
+	Interval   int    `json:"interval"`
+	IndexAlbum int    `json:"index_album"`
+	IndexCD    int    `json:"index_cd"`
+	Singer     []struct {
+		Name string `json:"name"`
+	} `json:"singer"`
+	Album struct {
+		Mid  string `json:"mid"`
+		Name string `json:"name"`
+	} `json:"album"`
+	File struct {
+		Size128  int64 `json:"size_128mp3"`
+		Size320  int64 `json:"size_320mp3"`
+		SizeFlac int64 `json:"size_flac"`
+	} `json:"file"`
+}
+
+func (si musicuSongInfo) toSong() model.Song {
+	var artistNames []string
+	for _, s := range si.Singer {
+		artistNames = append(artistNames, s.Name)
+	}
+
+	fileSize := si.File.Size128
+	bitrate := 128
+	if si.File.SizeFlac > 0 {
+		fileSize = si.File.SizeFlac
+		if si.Interval > 0 {
+			bitrate = int(fileSize * 8 / 1000 / int64(si.Interval))
+		} else {
+			bitrate = 800
+		}
+	} else if si.File.Size320 > 0 {
+		fileSize = si.File.Size320
+		bitrate = 320
+	}
+
+	return model.Song{
+		Source:   "qq",
+		ID:       si.Mid,
+		Name:     si.Name,
+		Artist:   strings.Join(artistNames, "、"),
+		Album:    si.Album.Name,
+		AlbumID:  si.Album.Mid,
+		Duration: si.Interval,
+		Size:     fileSize,
+		Bitrate:  bitrate,
+		Cover:    albumCover(si.Album.Mid),
+		Link:     fmt.Sprintf("https://y.qq.com/n/ryqq/songDetail/%s", si.Mid),
+		Extra: map[string]string{
+			"songmid": si.Mid,
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package qq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+const artistAlbumPageSize = 100
+
+// SearchArtist 按关键词搜索歌手
+func SearchArtist(keyword string) ([]model.Artist, error) { return getDefault().SearchArtist(keyword) }
+
+// GetArtistTopSongs 获取歌手 (singer mid) 最热门的歌曲
+func GetArtistTopSongs(mid string) ([]model.Song, error) { return getDefault().GetArtistTopSongs(mid) }
+
+// GetArtistAlbums 获取歌手的全部专辑，从新到旧，不含曲目
+func GetArtistAlbums(mid string) ([]model.Album, error) { return getDefault().GetArtistAlbums(mid) }
+
+// SearchArtistContext 按关键词搜索歌手
+func SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	return getDefault().SearchArtistContext(ctx, keyword)
+}
+
+// GetArtistTopSongsContext 获取歌手最热门的歌曲
+func GetArtistTopSongsContext(ctx context.Context, mid string) ([]model.Song, error) {
+	return getDefault().GetArtistTopSongsContext(ctx, mid)
+}
+
+// GetArtistAlbumsContext 获取歌手的全部专辑，不含曲目
+func GetArtistAlbumsContext(ctx context.Context, mid string) ([]model.Album, error) {
+	return getDefault().GetArtistAlbumsContext(ctx, mid)
+}
+
+// SearchArtist 按关键词搜索歌手
+func (q *QQ) SearchArtist(keyword string) ([]model.Artist, error) {
+	return q.SearchArtistContext(context.Background(), keyword)
+}
+
+// GetArtistTopSongs 获取歌手最热门的歌曲
+func (q *QQ) GetArtistTopSongs(mid string) ([]model.Song, error) {
+	return q.GetArtistTopSongsContext(context.Background(), mid)
+}
+
+// GetArtistAlbums 获取歌手的全部专辑，不含曲目
+func (q *QQ) GetArtistAlbums(mid string) ([]model.Album, error) {
+	return q.GetArtistAlbumsContext(context.Background(), mid)
+}
+
+// SearchArtistContext 使用桌面版搜索模块，search_type 1 (歌手)
+func (q *QQ) SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error) {
+	body, err := q.postMusicu(ctx, map[string]interface{}{
+		"comm": map[string]interface{}{"ct": 24, "cv": 10000},
+		"req": map[string]interface{}{
+			"module": "music.search.SearchCgiService",
+			"method": "DoSearchForQQMusicDesktop",
+			"param":  map[string]interface{}{"query": keyword, "search_type": 1, "num_per_page": 10, "page_num": 1},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Req struct {
+			Code int `json:"code"`
+			Data struct {
+				Body struct {
+					Singer struct {
+						List []struct {
+							SingerMID  string `json:"singerMID"`
+							SingerName string `json:"singerName"`
+							SingerPic  string `json:"singerPic"`
+							SongNum    int    `json:"songNum"`
+							AlbumNum   int    `json:"albumNum"`
+						} `json:"list"`
+					} `json:"singer"`
+				} `json:"body"`
+			} `json:"data"`
+		} `json:"req"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "artist search json parse error: %w", err)
+	}
+	if resp.Req.Code != 0 {
+		return nil, apiError(resp.Req.Code)
+	}
+
+	list := resp.Req.Data.Body.Singer.List
+	artists := make([]model.Artist, 0, len(list))
+	for _, item := range list {
+		if item.SingerMID == "" {
+			continue
+		}
+		artists = append(artists, model.Artist{
+			ID:         item.SingerMID,
+			Name:       item.SingerName,
+			Avatar:     singerAvatar(item.SingerMID),
+			SongCount:  item.SongNum,
+			AlbumCount: item.AlbumNum,
+			Source:     "qq",
+			Link:       fmt.Sprintf("https://y.qq.com/n/ryqq/singer/%s", item.SingerMID),
+		})
+	}
+	return artists, nil
+}
+
+// GetArtistTopSongsContext 返回按热度排序的前 50 首
+func (q *QQ) GetArtistTopSongsContext(ctx context.Context, mid string) ([]model.Song, error) {
+	if mid == "" {
+		return nil, model.Errorf("qq", model.ErrInvalidInput, "empty qq singer mid")
+	}
+	body, err := q.postMusicu(ctx, map[string]interface{}{
+		"comm": map[string]interface{}{"ct": 24, "cv": 10000},
+		"req": map[string]interface{}{
+			"module": "musichall.song_list_server",
+			"method": "GetSingerSongList",
+			"param":  map[string]interface{}{"singerMid": mid, "begin": 0, "num": 50, "order": 1},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Req struct {
+			Code int `json:"code"`
+			Data struct {
+				SongList []struct {
+					SongInfo musicuSongInfo `json:"songInfo"`
+				} `json:"songList"`
+			} `json:"data"`
+		} `json:"req"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "artist songs json parse error: %w", err)
+	}
+	if resp.Req.Code != 0 {
+		return nil, apiError(resp.Req.Code)
+	}
+
+	songs := make([]model.Song, 0, len(resp.Req.Data.SongList))
+	for _, item := range resp.Req.Data.SongList {
+		if item.SongInfo.Mid == "" {
+			continue
+		}
+		songs = append(songs, item.SongInfo.toSong())
+	}
+	return songs, nil
+}
+
+// GetArtistAlbumsContext 翻页取回全部专辑，直到达到接口报告的总数
+func (q *QQ) GetArtistAlbumsContext(ctx context.Context, mid string) ([]model.Album, error) {
+	if mid == "" {
+		return nil, model.Errorf("qq", model.ErrInvalidInput, "empty qq singer mid")
+	}
+
+	return utils.FetchPages(func(page, offset int) ([]model.Album, bool, error) {
+		body, err := q.postMusicu(ctx, map[string]interface{}{
+			"comm": map[string]interface{}{"ct": 24, "cv": 10000},
+			"req": map[string]interface{}{
+				"module": "music.musichallAlbum.AlbumListServer",
+				"method": "GetAlbumList",
+				"param": map[string]interface{}{
+					"singerMid": mid, "order": 0,
+					"begin": offset, "num": artistAlbumPageSize,
+				},
+			},
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		var resp struct {
+			Req struct {
+				Code int `json:"code"`
+				Data struct {
+					Total     int `json:"total"`
+					AlbumList []struct {
+						AlbumMid    string `json:"albumMid"`
+						AlbumName   string `json:"albumName"`
+						PublishDate string `json:"publishDate"`
+						TotalNum    int    `json:"totalNum"`
+						SingerName  string `json:"singerName"`
+					} `json:"albumList"`
+				} `json:"data"`
+			} `json:"req"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false, model.Errorf("qq", model.ErrSchemaChanged, "artist albums json parse error: %w", err)
+		}
+		if resp.Req.Code != 0 {
+			return nil, false, apiError(resp.Req.Code)
+		}
+
+		albums := make([]model.Album, 0, len(resp.Req.Data.AlbumList))
+		for _, item := range resp.Req.Data.AlbumList {
+			var artists []string
+			if item.SingerName != "" {
+				artists = []string{item.SingerName}
+			}
+			albums = append(albums, model.Album{
+				ID:          item.AlbumMid,
+				Name:        item.AlbumName,
+				Artists:     artists,
+				ReleaseDate: item.PublishDate,
+				Cover:       albumCover(item.AlbumMid),
+				TrackCount:  item.TotalNum,
+				Source:      "qq",
+				Link:        fmt.Sprintf("https://y.qq.com/n/ryqq/albumDetail/%s", item.AlbumMid),
+			})
+		}
+		return albums, offset+len(albums) < resp.Req.Data.Total, nil
+	})
+}
+
+func singerAvatar(singerMID string) string {
+	return fmt.Sprintf("https://y.gtimg.cn/music/photo_new/T001R300x300M000%s.jpg", singerMID)
+}
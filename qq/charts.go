@@ -1,6 +1,7 @@
 package qq
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -25,11 +26,27 @@ func GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 	return getDefault().GetChartSongs(chartID, limit)
 }
 
+func GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return getDefault().GetChartsContext(ctx)
+}
+
+func GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return getDefault().GetChartSongsContext(ctx, chartID, limit)
+}
+
 func (q *QQ) GetCharts() ([]model.Chart, error) {
-	return qqCharts, nil
+	return q.GetChartsContext(context.Background())
 }
 
 func (q *QQ) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
+	return q.GetChartSongsContext(context.Background(), chartID, limit)
+}
+
+func (q *QQ) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return qqCharts, nil
+}
+
+func (q *QQ) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -53,7 +70,7 @@ func (q *QQ) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 
 	apiURL := "https://c.y.qq.com/v8/fcg-bin/fcg_v8_toplist_cp.fcg?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"),
 		utils.WithHeader("Referer", "https://y.qq.com/"),
 		utils.WithHeader("Cookie", q.cookie),
@@ -89,7 +106,7 @@ func (q *QQ) GetChartSongs(chartID string, limit int) ([]model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qq chart json: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "chart json: %w", err)
 	}
 
 	var songs []model.Song
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+
+	"github.com/guohuiyuan/music-lib/utils"
 )
 
 var (
@@ -21,10 +23,20 @@ func SetConfigDir(dir string) {
 }
 
 // SetCookie updates the global cookie and replaces the default QQ instance.
+// The new instance shares the old one's HTTP client, so the rate limiter and
+// circuit breaker state survive a login.
 func SetCookie(cookie string) {
 	loginMu.Lock()
 	defer loginMu.Unlock()
-	defaultQQ = New(cookie)
+	defaultQQ = &QQ{cookie: cookie, client: defaultQQ.client}
+}
+
+// Configure sets the HTTP client options (proxy, timeout, cookie jar...) of
+// the default instance. SetCookie keeps the client built here.
+func Configure(opts ...utils.ClientOption) {
+	loginMu.Lock()
+	defer loginMu.Unlock()
+	defaultQQ = New(defaultQQ.cookie, opts...)
 }
 
 // GetCookie returns the current cookie string.
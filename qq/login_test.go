@@ -62,3 +62,21 @@ func TestSetGetLoginType(t *testing.T) {
 		t.Errorf("expected empty, got %q", got)
 	}
 }
+
+func TestSetCookie_KeepsClient(t *testing.T) {
+	before := getDefault()
+	defer func() {
+		loginMu.Lock()
+		defaultQQ = before
+		loginMu.Unlock()
+	}()
+
+	SetCookie("uin=123; qqmusic_key=abc")
+	after := getDefault()
+	if after.cookie != "uin=123; qqmusic_key=abc" {
+		t.Errorf("cookie not set: %q", after.cookie)
+	}
+	if after.Client() != before.Client() {
+		t.Error("SetCookie should keep the client, with its limiter and breaker")
+	}
+}
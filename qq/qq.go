@@ -2,9 +2,9 @@ package qq
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -44,9 +44,15 @@ const hexGUIDChars = "abcdef1234567890"
 
 type QQ struct {
 	cookie string
+	client *utils.Client
 }
 
-func New(cookie string) *QQ { return &QQ{cookie: cookie} }
+func New(cookie string, opts ...utils.ClientOption) *QQ {
+	return &QQ{cookie: cookie, client: utils.NewClient(opts...)}
+}
+
+// Client 返回实例使用的 HTTP 客户端
+func (q *QQ) Client() *utils.Client { return q.client }
 
 var defaultQQ = New("")
 
@@ -55,31 +61,157 @@ func SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	return getDefault().SearchPlaylist(keyword)
 }
 func GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := getDefault().fetchPlaylistDetail(id)
-	return songs, err
+	return getDefault().GetPlaylistSongs(id)
+}
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
 	return getDefault().ParsePlaylist(link)
 }
-func GetDownloadURL(s *model.Song) (string, error) { return getDefault().GetDownloadURL(s) }
-func GetLyrics(s *model.Song) (string, error)      { return getDefault().GetLyrics(s) }
-func Parse(link string) (*model.Song, error)        { return getDefault().Parse(link) }
+func GetDownloadURL(s *model.Song) (string, error)     { return getDefault().GetDownloadURL(s) }
+func GetStreams(s *model.Song) ([]model.Stream, error) { return getDefault().GetStreams(s) }
+func GetLyrics(s *model.Song) (string, error)          { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)           { return getDefault().Parse(link) }
+func GetLyricsSet(s *model.Song) (*model.LyricsSet, error) {
+	return getDefault().GetLyricsSet(s)
+}
 
 // GetRecommendedPlaylists 获取推荐歌单
 func GetRecommendedPlaylists() ([]model.Playlist, error) {
 	return getDefault().GetRecommendedPlaylists()
 }
 
-// Search 搜索歌曲
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
+}
+
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
+	return getDefault().GetRecommendedPlaylistsContext(ctx)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, s)
+}
+
+func GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, s)
+}
+
+func GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, s)
+}
+
+func GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error) {
+	return getDefault().GetLyricsSetContext(ctx, s)
+}
+
 func (q *QQ) Search(keyword string) ([]model.Song, error) {
+	return q.SearchContext(context.Background(), keyword)
+}
+
+func (q *QQ) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return q.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (q *QQ) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return q.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (q *QQ) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return q.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (q *QQ) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return q.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (q *QQ) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return q.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (q *QQ) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return q.ParsePlaylistContext(context.Background(), link)
+}
+
+func (q *QQ) GetRecommendedPlaylists() ([]model.Playlist, error) {
+	return q.GetRecommendedPlaylistsContext(context.Background())
+}
+
+func (q *QQ) Parse(link string) (*model.Song, error) {
+	return q.ParseContext(context.Background(), link)
+}
+
+func (q *QQ) GetDownloadURL(s *model.Song) (string, error) {
+	return q.GetDownloadURLContext(context.Background(), s)
+}
+
+func (q *QQ) GetStreams(s *model.Song) ([]model.Stream, error) {
+	return q.GetStreamsContext(context.Background(), s)
+}
+
+func (q *QQ) GetLyrics(s *model.Song) (string, error) {
+	return q.GetLyricsContext(context.Background(), s)
+}
+
+func (q *QQ) GetLyricsSet(s *model.Song) (*model.LyricsSet, error) {
+	return q.GetLyricsSetContext(context.Background(), s)
+}
+
+// SearchContext 搜索歌曲
+func (q *QQ) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := q.SearchPageContext(ctx, keyword, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲，page 从 1 开始
+func (q *QQ) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 10, 60)
 	params := url.Values{}
 	params.Set("w", keyword)
 	params.Set("format", "json")
-	params.Set("p", "1")
-	params.Set("n", "10")
+	params.Set("p", strconv.Itoa(page))
+	params.Set("n", strconv.Itoa(limit))
 	apiURL := "http://c.y.qq.com/soso/fcgi-bin/search_for_qq_cp?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", SearchReferer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -110,12 +242,13 @@ func (q *QQ) Search(keyword string) ([]model.Song, error) {
 						PayTrackPrice int `json:"paytrackprice"`
 					} `json:"pay"`
 				} `json:"list"`
+				TotalNum int `json:"totalnum"`
 			} `json:"song"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qq json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	var songs []model.Song
@@ -165,22 +298,35 @@ func (q *QQ) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	return model.NewPage(songs, page, limit, resp.Data.Song.TotalNum, len(resp.Data.Song.List)), nil
 }
 
-// SearchPlaylist 搜索歌单
-func (q *QQ) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单
+func (q *QQ) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := q.SearchPlaylistPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, model.Errorf("qq", model.ErrNotFound, "no playlists found")
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单，接口的 page_no 从 0 开始
+func (q *QQ) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 50)
 	params := url.Values{}
 	params.Set("query", keyword)
-	params.Set("page_no", "0")
-	params.Set("num_per_page", "20")
+	params.Set("page_no", strconv.Itoa(page-1))
+	params.Set("num_per_page", strconv.Itoa(limit))
 	params.Set("format", "json")
 	params.Set("remoteplace", "txt.yqq.playlist")
 	params.Set("flag_qc", "0")
 
 	apiURL := "http://c.y.qq.com/soso/fcgi-bin/client_music_search_songlist?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36"),
 		utils.WithHeader("Referer", "https://y.qq.com/portal/search.html"),
 		utils.WithHeader("Cookie", q.cookie),
@@ -202,6 +348,7 @@ func (q *QQ) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 					Name string `json:"name"`
 				} `json:"creator"`
 			} `json:"list"`
+			Sum int `json:"sum"`
 		} `json:"data"`
 		Message string `json:"message"`
 	}
@@ -214,7 +361,7 @@ func (q *QQ) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qq playlist json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
@@ -239,34 +386,40 @@ func (q *QQ) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 		})
 	}
 
-	if len(playlists) == 0 {
-		return nil, errors.New("no playlists found")
-	}
-
-	return playlists, nil
+	return model.NewPage(playlists, page, limit, resp.Data.Sum, len(resp.Data.List)), nil
 }
 
-// GetPlaylistSongs 获取歌单详情（仅返回歌曲列表）
-func (q *QQ) GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := q.fetchPlaylistDetail(id)
+// GetPlaylistSongsContext 获取歌单详情（仅返回歌曲列表）
+func (q *QQ) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	_, songs, err := q.fetchPlaylistDetail(ctx, id)
 	return songs, err
 }
 
-// ParsePlaylist 解析歌单链接并返回详情
-func (q *QQ) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// GetPlaylistSongsPageContext 分页获取歌单歌曲。歌单接口一次返回全部歌曲，这里在内存中切页
+func (q *QQ) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	songs, err := q.GetPlaylistSongsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
+}
+
+// ParsePlaylistContext 解析歌单链接并返回详情
+func (q *QQ) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	// 链接格式如: https://y.qq.com/n/ryqq/playlist/8825279434
 	re := regexp.MustCompile(`playlist/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, nil, errors.New("invalid qq playlist link")
+		return nil, nil, model.Errorf("qq", model.ErrInvalidInput, "invalid qq playlist link")
 	}
 	dissid := matches[1]
 
-	return q.fetchPlaylistDetail(dissid)
+	return q.fetchPlaylistDetail(ctx, dissid)
 }
 
-// GetRecommendedPlaylists 获取推荐歌单 (QQ音乐每日推荐/热门歌单)
-func (q *QQ) GetRecommendedPlaylists() ([]model.Playlist, error) {
+// GetRecommendedPlaylistsContext 获取推荐歌单 (QQ音乐每日推荐/热门歌单)
+func (q *QQ) GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error) {
 	// 构造 musicu.fcg 的请求体
 	reqData := map[string]interface{}{
 		"comm": map[string]interface{}{
@@ -292,7 +445,7 @@ func (q *QQ) GetRecommendedPlaylists() ([]model.Playlist, error) {
 		utils.WithHeader("Cookie", q.cookie),
 	}
 
-	body, err := utils.Post("https://u.y.qq.com/cgi-bin/musicu.fcg", bytes.NewReader(jsonData), headers...)
+	body, err := q.client.PostContext(ctx, "https://u.y.qq.com/cgi-bin/musicu.fcg", bytes.NewReader(jsonData), headers...)
 	if err != nil {
 		return nil, err
 	}
@@ -316,11 +469,11 @@ func (q *QQ) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qq recommended playlist json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "recommended playlist json parse error: %w", err)
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("qq api error code: %d", resp.Code)
+		return nil, apiError(resp.Code)
 	}
 
 	var playlists []model.Playlist
@@ -351,14 +504,14 @@ func (q *QQ) GetRecommendedPlaylists() ([]model.Playlist, error) {
 	}
 
 	if len(playlists) == 0 {
-		return nil, errors.New("no recommended playlists found")
+		return nil, model.Errorf("qq", model.ErrNotFound, "no recommended playlists found")
 	}
 
 	return playlists, nil
 }
 
 // fetchPlaylistDetail [内部复用] 获取歌单详情（元数据+歌曲）
-func (q *QQ) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, error) {
+func (q *QQ) fetchPlaylistDetail(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
 	params := url.Values{}
 	params.Set("type", "1")
 	params.Set("json", "1")
@@ -377,7 +530,7 @@ func (q *QQ) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, erro
 
 	apiURL := "http://c.y.qq.com/qzone/fcg-bin/fcg_ucc_getcdinfo_byids_cp.fcg?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"),
 		utils.WithHeader("Referer", "https://y.qq.com/"),
 		utils.WithHeader("Cookie", q.cookie),
@@ -422,12 +575,12 @@ func (q *QQ) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, erro
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("qq playlist detail json error: %w", err)
+		return nil, nil, model.Errorf("qq", model.ErrSchemaChanged, "playlist detail json error: %w", err)
 	}
 
 	if len(resp.Cdlist) == 0 {
 		slog.Warn("qq playlist cdlist empty, trying v2", "id", id)
-		return q.fetchPlaylistDetailV2(id)
+		return q.fetchPlaylistDetailV2(ctx, id)
 	}
 
 	info := resp.Cdlist[0]
@@ -493,10 +646,10 @@ func (q *QQ) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, erro
 
 // fetchPlaylistDetailV2 通过 musicu.fcg 统一接口获取歌单详情（content_id 兼容）。
 // 当旧接口 fcg_ucc_getcdinfo_byids_cp.fcg 返回空 cdlist 时作为 fallback。
-func (q *QQ) fetchPlaylistDetailV2(id string) (*model.Playlist, []model.Song, error) {
+func (q *QQ) fetchPlaylistDetailV2(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
 	idNum, _ := strconv.ParseInt(id, 10, 64)
 	if idNum == 0 {
-		return nil, nil, fmt.Errorf("invalid playlist id: %s", id)
+		return nil, nil, model.Errorf("qq", model.ErrInvalidInput, "invalid playlist id: %s", id)
 	}
 
 	reqData := map[string]interface{}{
@@ -518,7 +671,7 @@ func (q *QQ) fetchPlaylistDetailV2(id string) (*model.Playlist, []model.Song, er
 
 	jsonData, _ := json.Marshal(reqData)
 
-	body, err := utils.Post("https://u.y.qq.com/cgi-bin/musicu.fcg",
+	body, err := q.client.PostContext(ctx, "https://u.y.qq.com/cgi-bin/musicu.fcg",
 		bytes.NewReader(jsonData),
 		utils.WithHeader("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36"),
 		utils.WithHeader("Referer", "https://y.qq.com/"),
@@ -562,12 +715,16 @@ func (q *QQ) fetchPlaylistDetailV2(id string) (*model.Playlist, []model.Song, er
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("qq playlist v2 json error: %w", err)
+		return nil, nil, model.Errorf("qq", model.ErrSchemaChanged, "playlist v2 json error: %w", err)
 	}
 
 	if resp.Code != 0 || resp.Req.Code != 0 {
 		slog.Error("qq playlist v2 also failed", "id", id, "code", resp.Code, "req_code", resp.Req.Code)
-		return nil, nil, fmt.Errorf("playlist not found (empty cdlist, fallback also failed: code=%d/%d)", resp.Code, resp.Req.Code)
+		kind := model.ErrNotFound
+		if k := model.KindOf(apiError(firstNonZero(resp.Code, resp.Req.Code))); k != nil {
+			kind = k
+		}
+		return nil, nil, model.Errorf("qq", kind, "playlist not found (empty cdlist, fallback also failed: code=%d/%d)", resp.Code, resp.Req.Code)
 	}
 
 	data := resp.Req.Data
@@ -626,27 +783,27 @@ func (q *QQ) fetchPlaylistDetailV2(id string) (*model.Playlist, []model.Song, er
 	}
 
 	if len(songs) == 0 {
-		return nil, nil, errors.New("playlist not found (empty cdlist, fallback returned no songs)")
+		return nil, nil, model.Errorf("qq", model.ErrNotFound, "playlist not found (empty cdlist, fallback returned no songs)")
 	}
 
 	return playlist, songs, nil
 }
 
-// Parse 解析链接并获取完整信息
-func (q *QQ) Parse(link string) (*model.Song, error) {
+// ParseContext 解析链接并获取完整信息
+func (q *QQ) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`songDetail/(\w+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid qq music link")
+		return nil, model.Errorf("qq", model.ErrInvalidInput, "invalid qq music link")
 	}
 	songMID := matches[1]
 
-	song, err := q.fetchSongDetail(songMID)
+	song, err := q.fetchSongDetail(ctx, songMID)
 	if err != nil {
 		return nil, err
 	}
 
-	downloadURL, err := q.GetDownloadURL(song)
+	downloadURL, err := q.GetDownloadURLContext(ctx, song)
 	if err == nil {
 		song.URL = downloadURL
 	}
@@ -654,10 +811,38 @@ func (q *QQ) Parse(link string) (*model.Song, error) {
 	return song, nil
 }
 
-// GetDownloadURL 获取下载链接
-func (q *QQ) GetDownloadURL(s *model.Song) (string, error) {
+// GetDownloadURLContext 获取下载链接，按 s.Extra["quality"] 从 GetStreamsContext 的结果中选择
+func (q *QQ) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	streams, err := q.GetStreamsContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	st, ok := s.ChooseStream(streams)
+	if !ok {
+		return "", model.Errorf("qq", model.ErrRestricted, "download url not found: no stream selected")
+	}
+	return st.URL, nil
+}
+
+// qqRate 是 vkey 文件名前缀与格式的对应关系
+type qqRate struct {
+	Prefix  string
+	Ext     string
+	Bitrate int
+}
+
+var qqRates = []qqRate{
+	{"F000", "flac", 0},
+	{"M800", "mp3", 320},
+	{"M500", "mp3", 128},
+	{"C400", "m4a", 96},
+}
+
+// GetStreamsContext 列出歌曲可用的音频流。
+// 一次 GetVkey 请求带上所有音质的文件名，返回的 midurlinfo 与 filename 一一对应。
+func (q *QQ) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
 	if s.Source != "qq" {
-		return "", errors.New("source mismatch")
+		return nil, model.ErrSourceMismatch
 	}
 
 	songMID := s.ID
@@ -670,142 +855,133 @@ func (q *QQ) GetDownloadURL(s *model.Song) (string, error) {
 
 	slog.Info("qq.auth_mode", "mode", auth.mode, "uin", auth.uin)
 
-	type Rate struct {
-		Prefix string
-		Ext    string
-	}
-
-	quality := ""
-	if s.Extra != nil {
-		quality = s.Extra["quality"]
-	}
-
-	var rates []Rate
-	switch quality {
-	case "standard":
-		rates = []Rate{{"M500", "mp3"}, {"C400", "m4a"}}
-	case "high":
-		rates = []Rate{{"M800", "mp3"}, {"M500", "mp3"}, {"C400", "m4a"}}
-	default: // "lossless" or empty
-		rates = []Rate{{"F000", "flac"}, {"M800", "mp3"}, {"M500", "mp3"}, {"C400", "m4a"}}
-	}
-
-	var lastErr string
-
-	for _, rate := range rates {
-		filename := fmt.Sprintf("%s%s%s.%s", rate.Prefix, songMID, songMID, rate.Ext)
-
-		// Build request based on auth mode.
-		var reqData map[string]interface{}
-		var respKey string
-		var ua string
-
-		if auth.mode == "app" {
-			respKey = "music.vkey.GetVkey.UrlGetVkey"
-			ua = AppUserAgent
-			reqData = map[string]interface{}{
-				"comm": map[string]interface{}{
-					"cv":            appCV,
-					"v":             appCV,
-					"ct":            appCT,
-					"tmeAppID":      "qqmusic",
-					"format":        "json",
-					"inCharset":     "utf-8",
-					"outCharset":    "utf-8",
-					"qq":            auth.uin,
-					"authst":        auth.authst,
-					"tmeLoginType":  auth.loginType,
-				},
-				respKey: map[string]interface{}{
-					"module": "music.vkey.GetVkey",
-					"method": "UrlGetVkey",
-					"param": map[string]interface{}{
-						"guid":     guid,
-						"songmid":  []string{songMID},
-						"songtype": []int{0},
-						"uin":      auth.uin,
-						"loginflag": 1,
-						"platform": "20",
-						"filename": []string{filename},
-					},
-				},
-			}
-		} else {
-			respKey = "req_1"
-			ua = UserAgent
-			reqData = map[string]interface{}{
-				"comm": map[string]interface{}{
-					"cv": 4747474, "ct": 24, "format": "json",
-					"inCharset": "utf-8", "outCharset": "utf-8",
-					"notice": 0, "platform": "yqq.json", "needNewCode": 1,
-					"uin": auth.uin, "g_tk_new_20200303": auth.gtk, "g_tk": auth.gtk,
+	filenames := make([]string, len(qqRates))
+	songmids := make([]string, len(qqRates))
+	songtypes := make([]int, len(qqRates))
+	for i, rate := range qqRates {
+		filenames[i] = fmt.Sprintf("%s%s%s.%s", rate.Prefix, songMID, songMID, rate.Ext)
+		songmids[i] = songMID
+	}
+
+	// Build request based on auth mode.
+	var reqData map[string]interface{}
+	var respKey string
+	var ua string
+
+	if auth.mode == "app" {
+		respKey = "music.vkey.GetVkey.UrlGetVkey"
+		ua = AppUserAgent
+		reqData = map[string]interface{}{
+			"comm": map[string]interface{}{
+				"cv":           appCV,
+				"v":            appCV,
+				"ct":           appCT,
+				"tmeAppID":     "qqmusic",
+				"format":       "json",
+				"inCharset":    "utf-8",
+				"outCharset":   "utf-8",
+				"qq":           auth.uin,
+				"authst":       auth.authst,
+				"tmeLoginType": auth.loginType,
+			},
+			respKey: map[string]interface{}{
+				"module": "music.vkey.GetVkey",
+				"method": "UrlGetVkey",
+				"param": map[string]interface{}{
+					"guid":      guid,
+					"songmid":   songmids,
+					"songtype":  songtypes,
+					"uin":       auth.uin,
+					"loginflag": 1,
+					"platform":  "20",
+					"filename":  filenames,
 				},
-				respKey: map[string]interface{}{
-					"module": "music.vkey.GetVkey",
-					"method": "UrlGetVkey",
-					"param": map[string]interface{}{
-						"guid": guid, "songmid": []string{songMID},
-						"songtype": []int{0}, "uin": auth.uin,
-						"loginflag": 1, "platform": "20",
-						"filename": []string{filename},
-					},
+			},
+		}
+	} else {
+		respKey = "req_1"
+		ua = UserAgent
+		reqData = map[string]interface{}{
+			"comm": map[string]interface{}{
+				"cv": 4747474, "ct": 24, "format": "json",
+				"inCharset": "utf-8", "outCharset": "utf-8",
+				"notice": 0, "platform": "yqq.json", "needNewCode": 1,
+				"uin": auth.uin, "g_tk_new_20200303": auth.gtk, "g_tk": auth.gtk,
+			},
+			respKey: map[string]interface{}{
+				"module": "music.vkey.GetVkey",
+				"method": "UrlGetVkey",
+				"param": map[string]interface{}{
+					"guid": guid, "songmid": songmids,
+					"songtype": songtypes, "uin": auth.uin,
+					"loginflag": 1, "platform": "20",
+					"filename": filenames,
 				},
-			}
+			},
 		}
+	}
 
-		jsonData, _ := json.Marshal(reqData)
+	jsonData, _ := json.Marshal(reqData)
 
-		// Compute zzb sign and append to URL.
-		apiURL := "https://u.y.qq.com/cgi-bin/musicu.fcg"
-		sign := zzbSign(string(jsonData))
-		if sign != "" {
-			apiURL += "?sign=" + sign + "&signature=" + sign
-		}
+	// Compute zzb sign and append to URL.
+	apiURL := "https://u.y.qq.com/cgi-bin/musicu.fcg"
+	sign := zzbSign(string(jsonData))
+	if sign != "" {
+		apiURL += "?sign=" + sign + "&signature=" + sign
+	}
 
-		headers := []utils.RequestOption{
-			utils.WithHeader("User-Agent", ua),
-			utils.WithHeader("Referer", DownloadReferer),
-			utils.WithHeader("Content-Type", "application/json"),
-			utils.WithHeader("Cookie", q.cookie),
-		}
+	headers := []utils.RequestOption{
+		utils.WithHeader("User-Agent", ua),
+		utils.WithHeader("Referer", DownloadReferer),
+		utils.WithHeader("Content-Type", "application/json"),
+		utils.WithHeader("Cookie", q.cookie),
+	}
 
-		body, err := utils.Post(apiURL, bytes.NewReader(jsonData), headers...)
-		if err != nil {
-			lastErr = err.Error()
-			continue
-		}
+	body, err := q.client.PostContext(ctx, apiURL, bytes.NewReader(jsonData), headers...)
+	if err != nil {
+		return nil, err
+	}
 
-		// Parse response using the correct key.
-		purl, resultCode, parseErr := parseVkeyResponse(body, respKey)
-		if parseErr != nil {
-			lastErr = parseErr.Error()
-			continue
+	infos, err := parseVkeyInfos(body, respKey)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]vkeyInfo, len(infos))
+	for _, info := range infos {
+		byName[info.Filename] = info
+	}
+
+	var streams []model.Stream
+	var lastCode int
+	for i, rate := range qqRates {
+		info, ok := byName[filenames[i]]
+		if !ok && i < len(infos) {
+			info = infos[i] // 旧版响应不回传 filename 时按顺序对应
 		}
-		if purl == "" {
-			slog.Debug("qq.vkey_attempt", "rate", rate.Prefix, "purl_empty", true, "result_code", resultCode, "auth_mode", auth.mode)
-			lastErr = fmt.Sprintf("empty purl (result code: %d, auth_mode: %s)", resultCode, auth.mode)
+		if info.Purl == "" {
+			if info.Result != 0 {
+				lastCode = info.Result
+			}
 			continue
 		}
-
-		// 回写实际音质到 Song
-		switch rate.Prefix {
-		case "F000":
-			s.Ext = "flac"
-			s.Bitrate = 0
-		case "M800":
-			s.Ext = "mp3"
-			s.Bitrate = 320
-		case "M500":
-			s.Ext = "mp3"
-			s.Bitrate = 128
-		case "C400":
-			s.Ext = "m4a"
-			s.Bitrate = 96
+		streams = append(streams, model.Stream{
+			URL:     "http://ws.stream.qqmusic.qq.com/" + info.Purl,
+			Ext:     rate.Ext,
+			Bitrate: rate.Bitrate,
+			Label:   rate.Prefix,
+		})
+	}
+	slog.Debug("qq.vkey", "streams", len(streams), "result_code", lastCode, "auth_mode", auth.mode)
+	if len(streams) == 0 {
+		return nil, &model.ProviderError{
+			Source: "qq",
+			Kind:   vkeyErrorKind(lastCode),
+			Code:   lastCode,
+			Err:    fmt.Errorf("download url not found: empty purl (result code: %d, auth_mode: %s)", lastCode, auth.mode),
 		}
-		slog.Debug("qq.vkey_attempt", "rate", rate.Prefix, "purl_empty", false, "auth_mode", auth.mode)
-		return "http://ws.stream.qqmusic.qq.com/" + purl, nil
 	}
-	return "", fmt.Errorf("download url not found: %s", lastErr)
+	return streams, nil
 }
 
 // detectAuthMode examines the cookie to determine app or web auth mode.
@@ -838,36 +1014,49 @@ func generateHexGUID() string {
 	return string(b)
 }
 
-// parseVkeyResponse extracts purl from a GetVkey response using the given top-level key.
-func parseVkeyResponse(body []byte, key string) (purl string, resultCode int, err error) {
+// vkeyInfo is one midurlinfo entry of a GetVkey response.
+type vkeyInfo struct {
+	Filename string `json:"filename"`
+	Purl     string `json:"purl"`
+	WifiUrl  string `json:"wifiurl"`
+	Result   int    `json:"result"`
+}
+
+// parseVkeyInfos extracts every midurlinfo entry from a GetVkey response
+// using the given top-level key.
+func parseVkeyInfos(body []byte, key string) ([]vkeyInfo, error) {
 	// Use a generic map to handle the dynamic response key.
 	var raw map[string]json.RawMessage
 	if err := json.Unmarshal(body, &raw); err != nil {
-		return "", 0, fmt.Errorf("json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "json parse error: %w", err)
 	}
 
 	vkeyData, ok := raw[key]
 	if !ok {
-		return "", 0, fmt.Errorf("response key %q not found", key)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "response key %q not found", key)
 	}
 
 	var result struct {
 		Data struct {
-			MidUrlInfo []struct {
-				Purl    string `json:"purl"`
-				WifiUrl string `json:"wifiurl"`
-				Result  int    `json:"result"`
-			} `json:"midurlinfo"`
+			MidUrlInfo []vkeyInfo `json:"midurlinfo"`
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(vkeyData, &result); err != nil {
-		return "", 0, fmt.Errorf("vkey data parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "vkey data parse error: %w", err)
 	}
-	if len(result.Data.MidUrlInfo) == 0 {
+	return result.Data.MidUrlInfo, nil
+}
+
+// parseVkeyResponse extracts purl from a GetVkey response using the given top-level key.
+func parseVkeyResponse(body []byte, key string) (purl string, resultCode int, err error) {
+	infos, err := parseVkeyInfos(body, key)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(infos) == 0 {
 		return "", 0, nil
 	}
-	info := result.Data.MidUrlInfo[0]
-	return info.Purl, info.Result, nil
+	return infos[0].Purl, infos[0].Result, nil
 }
 
 // extractUin extracts the QQ uin (number) from the cookie string.
@@ -931,13 +1120,13 @@ func (q *QQ) getGTK() int {
 }
 
 // fetchSongDetail 内部方法：通过 songmid 获取详情
-func (q *QQ) fetchSongDetail(songMID string) (*model.Song, error) {
+func (q *QQ) fetchSongDetail(ctx context.Context, songMID string) (*model.Song, error) {
 	params := url.Values{}
 	params.Set("songmid", songMID)
 	params.Set("format", "json")
 
 	apiURL := "https://c.y.qq.com/v8/fcg-bin/fcg_play_single_song.fcg?" + params.Encode()
-	body, err := utils.Get(apiURL,
+	body, err := q.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Referer", SearchReferer),
 		utils.WithHeader("Cookie", q.cookie),
@@ -963,11 +1152,11 @@ func (q *QQ) fetchSongDetail(songMID string) (*model.Song, error) {
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("qq detail json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "detail json parse error: %w", err)
 	}
 
 	if len(resp.Data) == 0 {
-		return nil, errors.New("song detail not found")
+		return nil, model.Errorf("qq", model.ErrNotFound, "song detail not found")
 	}
 
 	item := resp.Data[0]
@@ -996,10 +1185,19 @@ func (q *QQ) fetchSongDetail(songMID string) (*model.Song, error) {
 	}, nil
 }
 
-// GetLyrics 获取歌词
-func (q *QQ) GetLyrics(s *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (q *QQ) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	set, err := q.GetLyricsSetContext(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	return set.Original, nil
+}
+
+// GetLyricsSetContext 获取原文歌词和翻译 (trans 字段)，该接口不提供罗马音
+func (q *QQ) GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error) {
 	if s.Source != "qq" {
-		return "", errors.New("source mismatch")
+		return nil, model.ErrSourceMismatch
 	}
 
 	songMID := s.ID
@@ -1025,9 +1223,9 @@ func (q *QQ) GetLyrics(s *model.Song) (string, error) {
 		utils.WithHeader("Cookie", q.cookie),
 	}
 
-	body, err := utils.Get(apiURL, headers...)
+	body, err := q.client.GetContext(ctx, apiURL, headers...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var resp struct {
@@ -1044,16 +1242,60 @@ func (q *QQ) GetLyrics(s *model.Song) (string, error) {
 	}
 
 	if err := json.Unmarshal([]byte(sBody), &resp); err != nil {
-		return "", fmt.Errorf("qq lyric json parse error: %w", err)
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "lyric json parse error: %w", err)
 	}
 	if resp.Lyric == "" {
-		return "", errors.New("lyric is empty or not found")
+		return nil, model.Errorf("qq", model.ErrNotFound, "lyric is empty or not found")
 	}
 
 	decodedBytes, err := base64.StdEncoding.DecodeString(resp.Lyric)
 	if err != nil {
-		return "", fmt.Errorf("base64 decode error: %w", err)
+		return nil, fmt.Errorf("base64 decode error: %w", err)
+	}
+	set := &model.LyricsSet{Original: string(decodedBytes)}
+
+	// 翻译同样是 base64 编码的 LRC，解码失败时只返回原文
+	if resp.Trans != "" {
+		if trans, err := base64.StdEncoding.DecodeString(resp.Trans); err == nil {
+			set.Translation = string(trans)
+		}
+	}
+	return set, nil
+}
+
+// apiError 把 QQ 音乐接口返回的 code 映射为统一的错误分类
+func apiError(code int) error {
+	var kind error
+	switch code {
+	case 1000, 1001:
+		kind = model.ErrLoginExpired
+	case 2000, 2001:
+		kind = model.ErrRateLimited
+	case 104400, 500001:
+		kind = model.ErrInvalidInput
+	}
+	return model.CodeError("qq", kind, code, "api error")
+}
+
+// firstNonZero 返回第一个非 0 的 code，musicu 接口外层和各模块各有一个 code
+func firstNonZero(codes ...int) int {
+	for _, c := range codes {
+		if c != 0 {
+			return c
+		}
 	}
+	return 0
+}
 
-	return string(decodedBytes), nil
+// vkeyErrorKind 把 vkey 接口每个文件的 result 映射为错误分类：
+// 104003 为需要会员/付费，1000 为登录态失效，其余按无版权处理
+func vkeyErrorKind(result int) error {
+	switch result {
+	case 104003:
+		return model.ErrVIPRequired
+	case 1000:
+		return model.ErrLoginExpired
+	default:
+		return model.ErrRestricted
+	}
 }
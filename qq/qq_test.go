@@ -218,3 +218,22 @@ func TestParseVkeyResponse_MissingKey(t *testing.T) {
 		t.Error("expected error for missing key")
 	}
 }
+
+func TestParseVkeyInfos_MultipleFilenames(t *testing.T) {
+	resp := `{"req_1":{"code":0,"data":{"midurlinfo":[` +
+		`{"filename":"F000abcabc.flac","purl":"","result":104003},` +
+		`{"filename":"M800abcabc.mp3","purl":"M800abcabc.mp3?vkey=x","result":0}]}}}`
+	infos, err := parseVkeyInfos([]byte(resp), "req_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(infos))
+	}
+	if infos[0].Purl != "" || infos[0].Result != 104003 {
+		t.Errorf("unexpected flac entry: %+v", infos[0])
+	}
+	if infos[1].Filename != "M800abcabc.mp3" || infos[1].Purl == "" {
+		t.Errorf("unexpected mp3 entry: %+v", infos[1])
+	}
+}
@@ -0,0 +1,330 @@
+package qq
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// GetWordLyrics returns the word-timed QRC lyrics of a QQ Music song.
+func GetWordLyrics(s *model.Song) (*model.WordLyrics, error) { return getDefault().GetWordLyrics(s) }
+
+// GetWordLyricsContext is GetWordLyrics with a context.
+func GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	return getDefault().GetWordLyricsContext(ctx, s)
+}
+
+func (q *QQ) GetWordLyrics(s *model.Song) (*model.WordLyrics, error) {
+	return q.GetWordLyricsContext(context.Background(), s)
+}
+
+// reQRCContent extracts the lyric text from the decrypted QRC XML.
+var reQRCContent = regexp.MustCompile(`LyricContent="([\s\S]*?)"\s*/>`)
+
+// GetWordLyricsContext asks musicu.fcg for the song's QRC lyrics, decrypts
+// them and parses the per-word timings. Songs without QRC return
+// model.ErrNotFound so callers can fall back to GetLyrics.
+func (q *QQ) GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	if s.Source != "qq" {
+		return nil, model.ErrSourceMismatch
+	}
+
+	songMID := s.ID
+	if s.Extra != nil && s.Extra["songmid"] != "" {
+		songMID = s.Extra["songmid"]
+	}
+
+	reqData := map[string]interface{}{
+		"comm": map[string]interface{}{
+			"ct": 19,
+			"cv": 1859,
+		},
+		"req": map[string]interface{}{
+			"module": "music.musichallSong.PlayLyricInfo",
+			"method": "GetPlayLyricInfo",
+			"param": map[string]interface{}{
+				"songMID": songMID,
+				"songID":  0,
+				"qrc":     1,
+				"qrc_t":   0,
+				"trans":   0,
+				"roma":    0,
+				"crypt":   1,
+				"lrc_t":   0,
+				"type":    0,
+			},
+		},
+	}
+	jsonData, _ := json.Marshal(reqData)
+
+	headers := []utils.RequestOption{
+		utils.WithHeader("User-Agent", UserAgent),
+		utils.WithHeader("Referer", LyricReferer),
+		utils.WithHeader("Content-Type", "application/json"),
+		utils.WithHeader("Cookie", q.cookie),
+	}
+
+	body, err := q.client.PostContext(ctx, "https://u.y.qq.com/cgi-bin/musicu.fcg", bytes.NewReader(jsonData), headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code int `json:"code"`
+		Req  struct {
+			Code int `json:"code"`
+			Data struct {
+				Lyric string `json:"lyric"`
+			} `json:"data"`
+		} `json:"req"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "lyric json parse error: %w", err)
+	}
+	if code := firstNonZero(resp.Code, resp.Req.Code); code != 0 {
+		return nil, apiError(code)
+	}
+	if resp.Req.Data.Lyric == "" {
+		return nil, model.Errorf("qq", model.ErrNotFound, "no qrc lyrics for %s", songMID)
+	}
+
+	content, err := decryptQRC(resp.Req.Data.Lyric)
+	if err != nil {
+		return nil, model.Errorf("qq", model.ErrSchemaChanged, "qrc decrypt: %w", err)
+	}
+	// Songs without word timings come back as plain LRC instead of QRC XML.
+	m := reQRCContent.FindStringSubmatch(content)
+	if m == nil {
+		return nil, model.Errorf("qq", model.ErrNotFound, "no word-timed lyrics for %s", songMID)
+	}
+	w := model.ParseQRC(html.UnescapeString(m[1]))
+	if !w.HasWords() {
+		return nil, model.Errorf("qq", model.ErrNotFound, "no word-timed lyrics for %s", songMID)
+	}
+	return w, nil
+}
+
+// qrcKey is the Triple DES key of QRC lyrics.
+var qrcKey = []byte("!@#)(*$%123ZXC!@!@#)(NHL")
+
+// decryptQRC decodes the hex QRC payload, decrypts it with QQ Music's Triple
+// DES and inflates the result.
+func decryptQRC(hexData string) (string, error) {
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return "", err
+	}
+	if len(data)%8 != 0 {
+		return "", fmt.Errorf("length %d is not a multiple of the block size", len(data))
+	}
+	c := newQRCCipher(qrcKey)
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += 8 {
+		c.decryptBlock(out[i:i+8], data[i:i+8])
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(out))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// QQ Music encrypts QRC with a Triple DES built on a nonstandard DES: key
+// and data blocks are handled as little-endian 32-bit words, and one S-box
+// entry differs from the standard. crypto/des cannot be used as-is, so the
+// cipher is implemented here with the standard tables below (bit positions
+// are 1-based from the most significant bit, as in FIPS 46-3).
+
+var (
+	desIP = [64]byte{
+		58, 50, 42, 34, 26, 18, 10, 2, 60, 52, 44, 36, 28, 20, 12, 4,
+		62, 54, 46, 38, 30, 22, 14, 6, 64, 56, 48, 40, 32, 24, 16, 8,
+		57, 49, 41, 33, 25, 17, 9, 1, 59, 51, 43, 35, 27, 19, 11, 3,
+		61, 53, 45, 37, 29, 21, 13, 5, 63, 55, 47, 39, 31, 23, 15, 7,
+	}
+	desFP = [64]byte{
+		40, 8, 48, 16, 56, 24, 64, 32, 39, 7, 47, 15, 55, 23, 63, 31,
+		38, 6, 46, 14, 54, 22, 62, 30, 37, 5, 45, 13, 53, 21, 61, 29,
+		36, 4, 44, 12, 52, 20, 60, 28, 35, 3, 43, 11, 51, 19, 59, 27,
+		34, 2, 42, 10, 50, 18, 58, 26, 33, 1, 41, 9, 49, 17, 57, 25,
+	}
+	desE = [48]byte{
+		32, 1, 2, 3, 4, 5, 4, 5, 6, 7, 8, 9,
+		8, 9, 10, 11, 12, 13, 12, 13, 14, 15, 16, 17,
+		16, 17, 18, 19, 20, 21, 20, 21, 22, 23, 24, 25,
+		24, 25, 26, 27, 28, 29, 28, 29, 30, 31, 32, 1,
+	}
+	desP = [32]byte{
+		16, 7, 20, 21, 29, 12, 28, 17, 1, 15, 23, 26, 5, 18, 31, 10,
+		2, 8, 24, 14, 32, 27, 3, 9, 19, 13, 30, 6, 22, 11, 4, 25,
+	}
+	desPC1 = [56]byte{
+		57, 49, 41, 33, 25, 17, 9, 1, 58, 50, 42, 34, 26, 18,
+		10, 2, 59, 51, 43, 35, 27, 19, 11, 3, 60, 52, 44, 36,
+		63, 55, 47, 39, 31, 23, 15, 7, 62, 54, 46, 38, 30, 22,
+		14, 6, 61, 53, 45, 37, 29, 21, 13, 5, 28, 20, 12, 4,
+	}
+	desPC2 = [48]byte{
+		14, 17, 11, 24, 1, 5, 3, 28, 15, 6, 21, 10,
+		23, 19, 12, 4, 26, 8, 16, 7, 27, 20, 13, 2,
+		41, 52, 31, 37, 47, 55, 30, 40, 51, 45, 33, 48,
+		44, 49, 39, 56, 34, 53, 46, 42, 50, 36, 29, 32,
+	}
+	desShifts = [16]uint{1, 1, 2, 2, 2, 2, 2, 2, 1, 2, 2, 2, 2, 2, 2, 1}
+
+	// qrcSBox holds the eight S-boxes, each indexed by row*16+column.
+	qrcSBox = [8][64]byte{
+		{
+			14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7,
+			0, 15, 7, 4, 14, 2, 13, 1, 10, 6, 12, 11, 9, 5, 3, 8,
+			4, 1, 14, 8, 13, 6, 2, 11, 15, 12, 9, 7, 3, 10, 5, 0,
+			15, 12, 8, 2, 4, 9, 1, 7, 5, 11, 3, 14, 10, 0, 6, 13,
+		},
+		{
+			15, 1, 8, 14, 6, 11, 3, 4, 9, 7, 2, 13, 12, 0, 5, 10,
+			3, 13, 4, 7, 15, 2, 8, 15, 12, 0, 1, 10, 6, 9, 11, 5, // standard DES has 14 in column 7
+			0, 14, 7, 11, 10, 4, 13, 1, 5, 8, 12, 6, 9, 3, 2, 15,
+			13, 8, 10, 1, 3, 15, 4, 2, 11, 6, 7, 12, 0, 5, 14, 9,
+		},
+		{
+			10, 0, 9, 14, 6, 3, 15, 5, 1, 13, 12, 7, 11, 4, 2, 8,
+			13, 7, 0, 9, 3, 4, 6, 10, 2, 8, 5, 14, 12, 11, 15, 1,
+			13, 6, 4, 9, 8, 15, 3, 0, 11, 1, 2, 12, 5, 10, 14, 7,
+			1, 10, 13, 0, 6, 9, 8, 7, 4, 15, 14, 3, 11, 5, 2, 12,
+		},
+		{
+			7, 13, 14, 3, 0, 6, 9, 10, 1, 2, 8, 5, 11, 12, 4, 15,
+			13, 8, 11, 5, 6, 15, 0, 3, 4, 7, 2, 12, 1, 10, 14, 9,
+			10, 6, 9, 0, 12, 11, 7, 13, 15, 1, 3, 14, 5, 2, 8, 4,
+			3, 15, 0, 6, 10, 1, 13, 8, 9, 4, 5, 11, 12, 7, 2, 14,
+		},
+		{
+			2, 12, 4, 1, 7, 10, 11, 6, 8, 5, 3, 15, 13, 0, 14, 9,
+			14, 11, 2, 12, 4, 7, 13, 1, 5, 0, 15, 10, 3, 9, 8, 6,
+			4, 2, 1, 11, 10, 13, 7, 8, 15, 9, 12, 5, 6, 3, 0, 14,
+			11, 8, 12, 7, 1, 14, 2, 13, 6, 15, 0, 9, 10, 4, 5, 3,
+		},
+		{
+			12, 1, 10, 15, 9, 2, 6, 8, 0, 13, 3, 4, 14, 7, 5, 11,
+			10, 15, 4, 2, 7, 12, 9, 5, 6, 1, 13, 14, 0, 11, 3, 8,
+			9, 14, 15, 5, 2, 8, 12, 3, 7, 0, 4, 10, 1, 13, 11, 6,
+			4, 3, 2, 12, 9, 5, 15, 10, 11, 14, 1, 7, 6, 0, 8, 13,
+		},
+		{
+			4, 11, 2, 14, 15, 0, 8, 13, 3, 12, 9, 7, 5, 10, 6, 1,
+			13, 0, 11, 7, 4, 9, 1, 10, 14, 3, 5, 12, 2, 15, 8, 6,
+			1, 4, 11, 13, 12, 3, 7, 14, 10, 15, 6, 8, 0, 5, 9, 2,
+			6, 11, 13, 8, 1, 4, 10, 7, 9, 5, 0, 15, 14, 2, 3, 12,
+		},
+		{
+			13, 2, 8, 4, 6, 15, 11, 1, 10, 9, 3, 14, 5, 0, 12, 7,
+			1, 15, 13, 8, 10, 3, 7, 4, 12, 5, 6, 11, 0, 14, 9, 2,
+			7, 11, 4, 1, 9, 12, 14, 2, 0, 6, 10, 13, 15, 3, 5, 8,
+			2, 1, 14, 7, 4, 10, 8, 13, 15, 12, 9, 0, 3, 5, 6, 11,
+		},
+	}
+)
+
+// qrcCipher is the Triple DES (EDE) decryptor for QRC.
+type qrcCipher struct {
+	subkeys [3][16]uint64
+	sbox    *[8][64]byte
+}
+
+// newQRCCipher sets up decryption with a 24-byte key: decrypt with the
+// third key, encrypt with the second, decrypt with the first.
+func newQRCCipher(key []byte) *qrcCipher {
+	c := &qrcCipher{sbox: &qrcSBox}
+	c.subkeys[0] = desSubkeys(key[16:24], true)
+	c.subkeys[1] = desSubkeys(key[8:16], false)
+	c.subkeys[2] = desSubkeys(key[0:8], true)
+	return c
+}
+
+func (c *qrcCipher) decryptBlock(dst, src []byte) {
+	var buf [8]byte
+	copy(buf[:], src)
+	for i := range c.subkeys {
+		buf = desBlock(buf, &c.subkeys[i], c.sbox)
+	}
+	copy(dst, buf[:])
+}
+
+// qrcLoad reads 8 bytes the way QQ Music's DES does: as two little-endian
+// 32-bit words. Output blocks are written back the same way.
+func qrcLoad(b []byte) uint64 {
+	return uint64(b[3])<<56 | uint64(b[2])<<48 | uint64(b[1])<<40 | uint64(b[0])<<32 |
+		uint64(b[7])<<24 | uint64(b[6])<<16 | uint64(b[5])<<8 | uint64(b[4])
+}
+
+// qrcStore is the inverse of qrcLoad.
+func qrcStore(v uint64) [8]byte {
+	return [8]byte{
+		byte(v >> 32), byte(v >> 40), byte(v >> 48), byte(v >> 56),
+		byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24),
+	}
+}
+
+// permute picks the bits listed in table (1-based, most significant first)
+// out of the low width bits of in.
+func permute(in uint64, width uint, table []byte) uint64 {
+	var out uint64
+	for _, pos := range table {
+		out = out<<1 | (in>>(width-uint(pos)))&1
+	}
+	return out
+}
+
+// desSubkeys computes the 16 round keys, reversed for decryption.
+func desSubkeys(key []byte, decrypt bool) [16]uint64 {
+	cd := permute(qrcLoad(key), 64, desPC1[:])
+	c, d := cd>>28, cd&0x0fffffff
+	var keys [16]uint64
+	for i, s := range desShifts {
+		c = (c<<s | c>>(28-s)) & 0x0fffffff
+		d = (d<<s | d>>(28-s)) & 0x0fffffff
+		k := permute(c<<28|d, 56, desPC2[:])
+		if decrypt {
+			keys[15-i] = k
+		} else {
+			keys[i] = k
+		}
+	}
+	return keys
+}
+
+// desBlock runs the 16 DES rounds over one block with the given S-boxes.
+func desBlock(in [8]byte, keys *[16]uint64, sbox *[8][64]byte) [8]byte {
+	v := permute(qrcLoad(in[:]), 64, desIP[:])
+	l, r := v>>32, v&0xffffffff
+	for _, k := range keys {
+		l, r = r, l^desF(r, k, sbox)
+	}
+	return qrcStore(permute(r<<32|l, 64, desFP[:]))
+}
+
+func desF(r, k uint64, sbox *[8][64]byte) uint64 {
+	e := permute(r, 32, desE[:]) ^ k
+	var s uint64
+	for i := 0; i < 8; i++ {
+		six := (e >> (42 - 6*uint(i))) & 0x3f
+		row := (six>>4)&2 | six&1
+		col := (six >> 1) & 0xf
+		s = s<<4 | uint64(sbox[i][row*16+col])
+	}
+	return permute(s, 32, desP[:])
+}
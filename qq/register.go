@@ -0,0 +1,15 @@
+package qq
+
+import "github.com/guohuiyuan/music-lib/registry"
+
+func init() {
+	// 登录后 SetCookie 会替换默认实例，因此每次调用都取当前实例
+	registry.Register(registry.Info{
+		Name:           "qq",
+		DisplayName:    "QQ 音乐",
+		Qualities:      []string{registry.QualityLossless, registry.QualityHigh, registry.QualityStandard},
+		LoginSupported: true,
+		Canary:         registry.Canary{Keyword: "周杰伦", SongID: "0039MnYb0qxYhV"},
+		Configure:      Configure,
+	}, func() any { return getDefault() })
+}
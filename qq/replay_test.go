@@ -0,0 +1,31 @@
+package qq
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	song := &model.Song{Source: "qq", ID: "0039MnYb0qxYhV"}
+	replay.Run(t, newReplay, []replay.Case[*QQ]{
+		{Name: "search", Call: func(_ *testing.T, q *QQ) (any, error) { return q.Search("周杰伦") }},
+		{Name: "lyrics", Call: func(_ *testing.T, q *QQ) (any, error) { return q.GetLyrics(song) }},
+		{Name: "lyrics_set", Call: func(t *testing.T, q *QQ) (any, error) {
+			set, err := q.GetLyricsSet(&model.Song{Source: "qq", ID: "001bhwUC1gE6ep"})
+			if err == nil && set.Translation == "" {
+				t.Fatalf("translation missing: %+v", set)
+			}
+			return set, err
+		}},
+		{Name: "word_lyrics", Call: func(_ *testing.T, q *QQ) (any, error) { return q.GetWordLyrics(song) }},
+		{Name: "artist_search", Call: func(_ *testing.T, q *QQ) (any, error) { return q.SearchArtist("周杰伦") }},
+		{Name: "artist_songs", Call: func(_ *testing.T, q *QQ) (any, error) { return q.GetArtistTopSongs("0025NhlN2yWrP4") }},
+		// Two pages, the first of which says there is more.
+		{Name: "artist_albums", Len: 3, Call: func(_ *testing.T, q *QQ) (any, error) { return q.GetArtistAlbums("0025NhlN2yWrP4") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *QQ { return New("", opts...) }
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Capability interfaces. A provider instance supports a capability when it
+// implements the matching interface; the registry discovers them by type
+// assertion, so a provider never has to declare them twice.
+//
+// Capability methods take a ctx whose cancellation or deadline aborts the
+// upstream requests. Provider packages also export each method without the
+// Context suffix, which is the same call with context.Background().
+
+// Searcher searches songs by keyword.
+type Searcher interface {
+	SearchContext(ctx context.Context, keyword string) ([]model.Song, error)
+}
+
+// Downloader resolves a playable/downloadable URL for a song.
+type Downloader interface {
+	GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error)
+}
+
+// LyricsFetcher fetches LRC lyrics for a song.
+type LyricsFetcher interface {
+	GetLyricsContext(ctx context.Context, s *model.Song) (string, error)
+}
+
+// LyricsSetFetcher fetches the translated and romanized lyrics a platform
+// offers alongside the original.
+type LyricsSetFetcher interface {
+	GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error)
+}
+
+// WordLyricsFetcher fetches word-timed lyrics (QRC, KRC, YRC). Songs that
+// only have line-level lyrics return model.ErrNotFound.
+type WordLyricsFetcher interface {
+	GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error)
+}
+
+// SongParser resolves a song share link.
+type SongParser interface {
+	ParseContext(ctx context.Context, link string) (*model.Song, error)
+}
+
+// PlaylistSearcher searches playlists by keyword.
+type PlaylistSearcher interface {
+	SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error)
+}
+
+// PlaylistSource lists the songs of a playlist by ID.
+type PlaylistSource interface {
+	GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error)
+}
+
+// PlaylistParser resolves a playlist share link into metadata and songs.
+type PlaylistParser interface {
+	ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error)
+}
+
+// PlaylistRecommender returns the platform's recommended playlists.
+type PlaylistRecommender interface {
+	GetRecommendedPlaylistsContext(ctx context.Context) ([]model.Playlist, error)
+}
+
+// PagedSearcher searches songs one page at a time. page starts at 1; a
+// non-positive limit selects the provider's default page size.
+type PagedSearcher interface {
+	SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error)
+}
+
+// PagedPlaylistSearcher searches playlists one page at a time.
+type PagedPlaylistSearcher interface {
+	SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error)
+}
+
+// PagedPlaylistSource lists the songs of a playlist one page at a time.
+type PagedPlaylistSource interface {
+	GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error)
+}
+
+// ChartSource lists charts and their top songs.
+type ChartSource interface {
+	GetChartsContext(ctx context.Context) ([]model.Chart, error)
+	GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error)
+}
+
+// AlbumSearcher searches albums by keyword.
+type AlbumSearcher interface {
+	SearchAlbumContext(ctx context.Context, keyword string) ([]model.Album, error)
+}
+
+// AlbumSource fetches an album with its track listing by ID.
+type AlbumSource interface {
+	GetAlbumContext(ctx context.Context, id string) (*model.Album, error)
+}
+
+// ArtistSearcher searches artists by keyword.
+type ArtistSearcher interface {
+	SearchArtistContext(ctx context.Context, keyword string) ([]model.Artist, error)
+}
+
+// ArtistSource lists an artist's top songs and albums by artist ID.
+type ArtistSource interface {
+	GetArtistTopSongsContext(ctx context.Context, id string) ([]model.Song, error)
+	GetArtistAlbumsContext(ctx context.Context, id string) ([]model.Album, error)
+}
+
+// StreamSource lists every stream the platform offers for a song, so the
+// caller can pick one by quality instead of the provider deciding.
+type StreamSource interface {
+	GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error)
+}
+
+// StreamOpener opens a resolved stream for reading, for providers whose
+// audio needs more than a plain GET of the stream URL, such as soda's
+// encrypted MP4. Callers that find it use it instead of fetching the URL.
+type StreamOpener interface {
+	OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error)
+}
+
+// ClientOwner exposes the HTTP client a provider instance sends its requests
+// through, so audio downloads and proxies can reuse its proxy and transport.
+type ClientOwner interface {
+	Client() *utils.Client
+}
+
+// Capabilities is a JSON-friendly summary of which interfaces a provider implements.
+type Capabilities struct {
+	Search              bool `json:"search"`
+	Download            bool `json:"download"`
+	Lyrics              bool `json:"lyrics"`
+	LyricsTranslation   bool `json:"lyrics_translation"`
+	WordLyrics          bool `json:"word_lyrics"`
+	Parse               bool `json:"parse"`
+	PlaylistSearch      bool `json:"playlist_search"`
+	PlaylistSongs       bool `json:"playlist_songs"`
+	PlaylistParse       bool `json:"playlist_parse"`
+	PlaylistRecommended bool `json:"playlist_recommended"`
+	Charts              bool `json:"charts"`
+	Paging              bool `json:"paging"`
+	AlbumSearch         bool `json:"album_search"`
+	Album               bool `json:"album"`
+	ArtistSearch        bool `json:"artist_search"`
+	Artist              bool `json:"artist"`
+	Streams             bool `json:"streams"`
+	OpenStream          bool `json:"open_stream"` // audio is decrypted by the provider
+	Probe               bool `json:"probe"`       // songs can be checked with Provider.Probe
+}
@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+// LyricsFallbackOrder is the order FallbackLyrics tries other providers in when
+// a song's own source has no lyrics; platforms with the most complete lyrics
+// catalogues come first.
+var LyricsFallbackOrder = []string{"netease", "qq", "kugou", "kuwo", "migu", "qianqian", "soda"}
+
+// LyricsLookupTimeout bounds a whole FallbackLyrics call, whose providers
+// are searched in parallel.
+const LyricsLookupTimeout = 15 * time.Second
+
+// lyricsDurationTolerance is how far apart, in seconds, two songs with the
+// same name may be and still share lyrics when their artists differ.
+const lyricsDurationTolerance = 3
+
+// FetchLyrics returns song's lyrics with the extra versions opts asks for.
+// Providers implementing LyricsSetFetcher are asked for everything in one
+// call when opts wants a translation or romanization; otherwise only
+// Original is filled from the LyricsFetcher.
+//
+// With opts.WordLevel, Original is replaced by Enhanced LRC when the
+// provider has word-timed lyrics for the song; line-level lyrics remain the
+// fallback when it does not.
+//
+// Every version is passed through model.NormalizeLRC, so callers get
+// UTF-8 LRC with sorted, uniformly formatted timestamps whatever the
+// platform sent.
+func (p *Provider) FetchLyrics(ctx context.Context, song *model.Song, opts model.LyricsOptions) (*model.LyricsSet, error) {
+	set, err := p.fetchLyricsSet(ctx, song, opts)
+	if wf := p.WordLyricsFetcher(); opts.WordLevel && wf != nil {
+		if w, werr := wf.GetWordLyricsContext(ctx, song); werr == nil {
+			if set == nil {
+				set = &model.LyricsSet{}
+			}
+			set.Original, err = w.EnhancedLRC(), nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	set.Original = model.NormalizeLRC(set.Original)
+	set.Translation = model.NormalizeLRC(set.Translation)
+	set.Romanization = model.NormalizeLRC(set.Romanization)
+	return set, nil
+}
+
+func (p *Provider) fetchLyricsSet(ctx context.Context, song *model.Song, opts model.LyricsOptions) (*model.LyricsSet, error) {
+	setFetcher, f := p.LyricsSetFetcher(), p.LyricsFetcher()
+	if setFetcher != nil && (opts.Any() || f == nil) {
+		return setFetcher.GetLyricsSetContext(ctx, song)
+	}
+	if f == nil {
+		return nil, fmt.Errorf("provider %q does not support lyrics", p.Name)
+	}
+	lrc, err := f.GetLyricsContext(ctx, song)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LyricsSet{Original: lrc}, nil
+}
+
+// FallbackLyrics looks for song's lyrics on other providers, for when the
+// song's own source has none: jamendo has no lyrics at all and bilibili
+// videos often carry no subtitles. All providers in LyricsFallbackOrder are
+// searched in parallel, bounded together by LyricsLookupTimeout; the first
+// one in that order with a matching song that has lyrics wins, and its name
+// is returned with them. The error is of kind model.ErrNotFound when no
+// provider had any.
+func (r *Registry) FallbackLyrics(ctx context.Context, song *model.Song, opts model.LyricsOptions) (*model.LyricsSet, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, LyricsLookupTimeout)
+	defer cancel()
+
+	var (
+		names   []string
+		results []chan *model.LyricsSet
+	)
+	for _, name := range LyricsFallbackOrder {
+		if name == song.Source {
+			continue
+		}
+		p, ok := r.Get(name)
+		if !ok || p.Searcher() == nil || (p.LyricsFetcher() == nil && p.LyricsSetFetcher() == nil) {
+			continue
+		}
+		if c := p.Client(); c != nil && !c.Available() {
+			continue
+		}
+		ch := make(chan *model.LyricsSet, 1)
+		go func() { ch <- p.lookupLyrics(ctx, song, opts) }()
+		names = append(names, name)
+		results = append(results, ch)
+	}
+
+	// Take results in fallback order, so a faster provider further down
+	// the list never wins over one with better lyrics.
+	for i, ch := range results {
+		select {
+		case set := <-ch:
+			if set != nil {
+				slog.Debug("registry.lyrics_fallback", "song", song.Display(), "from", song.Source, "to", names[i])
+				return set, names[i], nil
+			}
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, "", model.Errorf(song.Source, model.ErrNotFound, "no provider found lyrics for %s in %s", song.Display(), LyricsLookupTimeout)
+			}
+			return nil, "", ctx.Err()
+		}
+	}
+	return nil, "", model.Errorf(song.Source, model.ErrNotFound, "no provider has lyrics for %s", song.Display())
+}
+
+// lookupLyrics searches the provider for song, first by artist and name and
+// then by name alone, and returns the lyrics of the first match that has
+// any. It returns nil when nothing was found.
+func (p *Provider) lookupLyrics(ctx context.Context, song *model.Song, opts model.LyricsOptions) *model.LyricsSet {
+	keywords := []string{strings.TrimSpace(song.Artist + " " + song.Name)}
+	if song.Artist != "" {
+		keywords = append(keywords, song.Name)
+	}
+	for _, keyword := range keywords {
+		results, err := p.Searcher().SearchContext(ctx, keyword)
+		if err != nil {
+			slog.Debug("registry.lyrics_fallback.search_error", "provider", p.Name, "error", err)
+			return nil
+		}
+		matched := false
+		for i := range results {
+			if !lyricsMatch(*song, results[i]) {
+				continue
+			}
+			matched = true
+			set, err := p.FetchLyrics(ctx, &results[i], opts)
+			if err != nil {
+				switch model.KindOf(err) {
+				case model.ErrLoginExpired, model.ErrRateLimited, model.ErrSchemaChanged:
+					return nil
+				}
+				continue
+			}
+			if strings.TrimSpace(set.Original) != "" {
+				return set
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	return nil
+}
+
+// lyricsMatch reports whether candidate's lyrics can stand in for target's.
+// Besides model.IsSameSong it accepts songs with the same normalized name
+// and nearly the same duration, since uploaders on video sites are rarely
+// the credited artist.
+func lyricsMatch(target, candidate model.Song) bool {
+	if model.IsSameSong(target, candidate) {
+		return true
+	}
+	if target.Duration <= 0 || candidate.Duration <= 0 {
+		return false
+	}
+	name := model.NormalizeName(target.Name)
+	if name == "" || name != model.NormalizeName(candidate.Name) {
+		return false
+	}
+	d := target.Duration - candidate.Duration
+	return d >= -lyricsDurationTolerance && d <= lyricsDurationTolerance
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+// lyricsFake has line-level lyrics, a translation and, for song "w",
+// word-timed lyrics.
+type lyricsFake struct{}
+
+func (lyricsFake) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	return "[00:01.00]故事", nil
+}
+
+func (lyricsFake) GetLyricsSetContext(ctx context.Context, s *model.Song) (*model.LyricsSet, error) {
+	return &model.LyricsSet{Original: "[00:01.00]故事", Translation: "[00:01.000]story\r\n"}, nil
+}
+
+func (lyricsFake) GetWordLyricsContext(ctx context.Context, s *model.Song) (*model.WordLyrics, error) {
+	if s.ID != "w" {
+		return nil, model.Errorf("fake", model.ErrNotFound, "no word lyrics")
+	}
+	return &model.WordLyrics{Lyrics: model.Lyrics{Lines: []model.LyricLine{{Start: 1000, Duration: 500, Text: "故事",
+		Words: []model.LyricWord{{Start: 1000, Duration: 200, Text: "故"}, {Start: 1200, Duration: 300, Text: "事"}}}}}}, nil
+}
+
+func TestProvider_FetchLyrics(t *testing.T) {
+	r := New()
+	r.Register(Info{Name: "fake"}, func() any { return lyricsFake{} })
+	p, _ := r.Get("fake")
+	if !p.Capabilities().WordLyrics || !p.Capabilities().LyricsTranslation {
+		t.Fatalf("unexpected capabilities: %+v", p.Capabilities())
+	}
+	ctx := context.Background()
+
+	set, err := p.FetchLyrics(ctx, &model.Song{ID: "1"}, model.LyricsOptions{})
+	if err != nil || set.Original != "[00:01.00]故事\n" || set.Translation != "" {
+		t.Fatalf("plain: %+v, %v", set, err)
+	}
+
+	set, err = p.FetchLyrics(ctx, &model.Song{ID: "w"}, model.LyricsOptions{Translation: true, WordLevel: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Original != "[00:01.00]<00:01.00>故<00:01.20>事<00:01.50>\n" || set.Translation != "[00:01.00]story\n" {
+		t.Fatalf("word level: %+v", set)
+	}
+
+	set, err = p.FetchLyrics(ctx, &model.Song{ID: "1"}, model.LyricsOptions{WordLevel: true})
+	if err != nil || set.Original != "[00:01.00]故事\n" {
+		t.Fatalf("songs without word lyrics should fall back to LRC: %+v, %v", set, err)
+	}
+}
+
+// searchLyricsFake finds one song and has lyrics only for it.
+type searchLyricsFake struct{ song model.Song }
+
+func (f searchLyricsFake) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return []model.Song{{Source: f.song.Source, ID: "other", Name: "别的歌", Artist: f.song.Artist}, f.song}, nil
+}
+
+func (f searchLyricsFake) GetLyricsContext(ctx context.Context, s *model.Song) (string, error) {
+	if s.ID != f.song.ID {
+		return "", nil
+	}
+	return "[00:16.32]这一路上走走停停", nil
+}
+
+func TestRegistry_FallbackLyrics(t *testing.T) {
+	r := New()
+	r.Register(Info{Name: "jamendo"}, func() any { return lyricsFake{} })
+	r.Register(Info{Name: "netease"}, func() any {
+		return searchLyricsFake{model.Song{Source: "netease", ID: "1", Name: "起风了", Artist: "买辣椒也用券", Duration: 325}}
+	})
+	ctx := context.Background()
+
+	// A bilibili upload: different artist, same name and duration.
+	song := &model.Song{Source: "bilibili", ID: "BV1", Name: "起风了（翻唱）", Artist: "某UP主", Duration: 327}
+	set, from, err := r.FallbackLyrics(ctx, song, model.LyricsOptions{})
+	if err != nil || from != "netease" || set.Original != "[00:16.32]这一路上走走停停\n" {
+		t.Fatalf("got %+v from %q, %v", set, from, err)
+	}
+
+	song.Duration = 200
+	if _, _, err := r.FallbackLyrics(ctx, song, model.LyricsOptions{}); model.KindOf(err) != model.ErrNotFound {
+		t.Fatalf("a different recording should not match, got %v", err)
+	}
+}
+
+// slowLyricsFake is searchLyricsFake answering after a delay.
+type slowLyricsFake struct {
+	searchLyricsFake
+	delay time.Duration
+}
+
+func (f slowLyricsFake) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.searchLyricsFake.SearchContext(ctx, keyword)
+}
+
+func TestRegistry_FallbackLyrics_ParallelInOrder(t *testing.T) {
+	song := model.Song{Name: "起风了", Artist: "买辣椒也用券", Duration: 325}
+	withLyrics := func(source string, delay time.Duration) func() any {
+		s := song
+		s.Source, s.ID = source, "1"
+		return func() any { return slowLyricsFake{searchLyricsFake{s}, delay} }
+	}
+	r := New()
+	r.Register(Info{Name: "netease"}, withLyrics("netease", 150*time.Millisecond))
+	r.Register(Info{Name: "qq"}, withLyrics("qq", 0))
+	r.Register(Info{Name: "kugou"}, withLyrics("kugou", 150*time.Millisecond))
+
+	start := time.Now()
+	_, from, err := r.FallbackLyrics(context.Background(), &model.Song{Source: "jamendo", Name: song.Name, Artist: song.Artist, Duration: song.Duration}, model.LyricsOptions{})
+	if err != nil || from != "netease" {
+		t.Fatalf("expected the first provider in order to win, got %q, %v", from, err)
+	}
+	// Each slow provider takes 150ms per search; run one after another they
+	// would take at least 300ms.
+	if elapsed := time.Since(start); elapsed > 280*time.Millisecond {
+		t.Errorf("providers were not searched in parallel: %s", elapsed)
+	}
+}
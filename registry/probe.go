@@ -0,0 +1,236 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Probe outcomes.
+const (
+	ProbeOK          = "ok"
+	ProbeDead        = "dead"        // no stream, or the stream URL does not serve audio
+	ProbeVIPOnly     = "vip_only"    // needs a membership, a purchase or is region restricted
+	ProbePreview     = "preview"     // only a short preview clip is available
+	ProbeUnavailable = "unavailable" // could not tell: timeout, rate limit, circuit open
+)
+
+// ProbeTimeout bounds resolving and checking one song.
+const ProbeTimeout = 20 * time.Second
+
+// previewMaxSeconds is the longest clip treated as a preview; platforms serve
+// 30 to 60 second previews in place of VIP-only songs.
+const previewMaxSeconds = 62
+
+// ProbeResult is what Probe learned about a song.
+type ProbeResult struct {
+	Source      string `json:"source"`
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	Size        int64  `json:"size,omitempty"`         // bytes reported by the server
+	Bitrate     int    `json:"bitrate,omitempty"`      // kbps, measured from size and duration when possible
+	Ext         string `json:"ext,omitempty"`          // format of the stream that would be downloaded
+	ContentType string `json:"content_type,omitempty"` // as sent by the server
+}
+
+// Unusable reports whether the outcome is definite and bad: the song is
+// dead, VIP-only or a preview.
+func (r ProbeResult) Unusable() bool {
+	return r.Status == ProbeDead || r.Status == ProbeVIPOnly || r.Status == ProbePreview
+}
+
+// Probe resolves the stream that would be downloaded for song and checks it
+// with checkStream. It fills song.IsInvalid, Size and Bitrate from the
+// outcome.
+//
+// Songs are marked invalid only when the outcome is definite (dead, VIP-only,
+// preview); a timeout or rate limit leaves IsInvalid untouched.
+func (p *Provider) Probe(ctx context.Context, song *model.Song) ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+	defer cancel()
+
+	st, err := p.ResolveStream(ctx, song)
+	if err != nil {
+		res := ProbeResult{Source: p.Name, ID: song.ID, Status: classifyProbeError(err), Reason: err.Error()}
+		song.IsInvalid = song.IsInvalid || res.Status != ProbeUnavailable
+		return res
+	}
+	return p.checkStream(ctx, song, st)
+}
+
+// checkStream asks the server for the real length and type of an already
+// resolved stream without fetching the audio: a HEAD request, falling back
+// to a one-byte Range request for servers that reject HEAD. It updates song
+// the same way Probe does.
+func (p *Provider) checkStream(ctx context.Context, song *model.Song, st model.Stream) ProbeResult {
+	res := ProbeResult{Source: p.Name, ID: song.ID, Ext: st.Ext}
+	size, ctype, err := headStream(ctx, p.Client().HTTPClientWithTimeout(ProbeTimeout), st)
+	if err != nil {
+		res.Status, res.Reason = classifyProbeError(err), err.Error()
+		song.IsInvalid = song.IsInvalid || res.Status != ProbeUnavailable
+		return res
+	}
+	res.ContentType = ctype
+	if !isAudioType(ctype) {
+		res.Status, res.Reason = ProbeDead, fmt.Sprintf("content type %q is not audio", ctype)
+		song.IsInvalid = true
+		return res
+	}
+
+	res.Size = size
+	res.Bitrate = st.Bitrate
+	if res.Bitrate == 0 {
+		res.Bitrate = song.Bitrate
+	}
+	if size > 0 && res.Bitrate > 0 && song.Duration > previewMaxSeconds {
+		// A preview is much shorter than the song at the advertised bitrate.
+		if secs := int(size * 8 / 1000 / int64(res.Bitrate)); secs <= previewMaxSeconds {
+			res.Status, res.Reason = ProbePreview, fmt.Sprintf("stream is about %ds of a %ds song", secs, song.Duration)
+			song.IsInvalid = true
+			return res
+		}
+	}
+	if size > 0 && song.Duration > 0 {
+		res.Bitrate = int(size * 8 / 1000 / int64(song.Duration))
+	}
+
+	res.Status = ProbeOK
+	song.IsInvalid = false
+	if size > 0 {
+		song.Size = size
+	}
+	if res.Bitrate > 0 {
+		song.Bitrate = res.Bitrate
+	}
+	return res
+}
+
+// errDeadLink is returned by headStream when the server answers with an error status.
+var errDeadLink = errors.New("dead link")
+
+// headStream returns the content length and type of st.URL.
+func headStream(ctx context.Context, hc *http.Client, st model.Stream) (int64, string, error) {
+	size, ctype, status, err := requestStream(ctx, hc, st, http.MethodHead)
+	if err == nil && (status >= 400 || size <= 0) {
+		// Many CDNs reject HEAD or omit the length; a one-byte range request
+		// reports the full size in Content-Range.
+		size, ctype, status, err = requestStream(ctx, hc, st, http.MethodGet)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if status >= 400 {
+		return 0, "", fmt.Errorf("%w: HTTP %d", errDeadLink, status)
+	}
+	return size, ctype, nil
+}
+
+func requestStream(ctx context.Context, hc *http.Client, st model.Stream, method string) (size int64, ctype string, status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, st.URL, nil)
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("%w: %v", errDeadLink, err)
+	}
+	req.Header.Set("User-Agent", utils.DefaultUserAgent)
+	for k, v := range st.Headers {
+		req.Header.Set(k, v)
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	resp.Body.Close()
+
+	size = resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		size = 0
+		// Content-Range: bytes 0-0/12345
+		if cr := resp.Header.Get("Content-Range"); cr != "" {
+			if i := strings.LastIndexByte(cr, '/'); i >= 0 {
+				size, _ = strconv.ParseInt(cr[i+1:], 10, 64)
+			}
+		}
+	}
+	return size, resp.Header.Get("Content-Type"), resp.StatusCode, nil
+}
+
+// isAudioType reports whether a Content-Type can be an audio file. Servers
+// often send application/octet-stream or nothing at all for audio; error
+// pages come back as HTML, JSON or plain text.
+func isAudioType(ctype string) bool {
+	if ctype == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		return true
+	}
+	switch {
+	case strings.HasPrefix(mt, "text/"), mt == "application/json", mt == "application/xml":
+		return false
+	}
+	return true
+}
+
+// classifyProbeError maps an error from resolving or checking a stream to a probe
+// outcome: ProbeDead, ProbeVIPOnly, or ProbeUnavailable when it is not
+// definite.
+func classifyProbeError(err error) string {
+	switch {
+	case errors.Is(err, errDeadLink), errors.Is(err, ErrNoStream):
+		return ProbeDead
+	}
+	switch model.KindOf(err) {
+	case model.ErrVIPRequired, model.ErrRestricted:
+		return ProbeVIPOnly
+	case model.ErrNotFound:
+		return ProbeDead
+	}
+	return ProbeUnavailable
+}
+
+// ProbeSongs probes songs concurrently, at most concurrency at a time, using
+// each song's Source to find its provider. Results are in input order and
+// songs are updated in place.
+func (r *Registry) ProbeSongs(ctx context.Context, songs []model.Song, concurrency int) []ProbeResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]ProbeResult, len(songs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range songs {
+		p, ok := r.Get(songs[i].Source)
+		if !ok {
+			results[i] = ProbeResult{Source: songs[i].Source, ID: songs[i].ID, Status: ProbeUnavailable,
+				Reason: fmt.Sprintf("unknown source %q", songs[i].Source)}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p *Provider) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = ProbeResult{Source: p.Name, ID: songs[i].ID, Status: ProbeUnavailable, Reason: ctx.Err().Error()}
+				return
+			}
+			defer func() { <-sem }()
+			results[i] = p.Probe(ctx, &songs[i])
+		}(i, p)
+	}
+	wg.Wait()
+	return results
+}
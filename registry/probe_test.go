@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+// fakeDownloader returns base+song.ID as the download URL, or err.
+type fakeDownloader struct {
+	base string
+	err  error
+}
+
+func (f fakeDownloader) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.base + s.ID, nil
+}
+
+func TestProvider_Probe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/full":
+			// 240s at 128kbps
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("Content-Length", "3840000")
+		case "/preview":
+			// 30s at 128kbps
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Header().Set("Content-Length", "480000")
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("fallback request without range: %q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/3840000")
+			w.WriteHeader(http.StatusPartialContent)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := New()
+	r.Register(Info{Name: "fake"}, func() any { return fakeDownloader{base: srv.URL + "/"} })
+	r.Register(Info{Name: "vip"}, func() any {
+		return fakeDownloader{err: model.Errorf("vip", model.ErrVIPRequired, "song requires VIP")}
+	})
+	r.Register(Info{Name: "flaky"}, func() any {
+		return fakeDownloader{err: model.Errorf("flaky", model.ErrRateLimited, "slow down")}
+	})
+
+	cases := []struct {
+		source, id string
+		want       string
+		invalid    bool
+	}{
+		{"fake", "full", ProbeOK, false},
+		{"fake", "nohead", ProbeOK, false},
+		{"fake", "preview", ProbePreview, true},
+		{"fake", "html", ProbeDead, true},
+		{"fake", "missing", ProbeDead, true},
+		{"vip", "1", ProbeVIPOnly, true},
+		{"flaky", "1", ProbeUnavailable, false},
+	}
+	songs := make([]model.Song, len(cases))
+	for i, tc := range cases {
+		songs[i] = model.Song{Source: tc.source, ID: tc.id, Duration: 240, Bitrate: 128}
+	}
+	results := r.ProbeSongs(context.Background(), songs, 3)
+	for i, tc := range cases {
+		if results[i].ID != tc.id || results[i].Status != tc.want {
+			t.Errorf("%s/%s: got %+v, want %s", tc.source, tc.id, results[i], tc.want)
+		}
+		if songs[i].IsInvalid != tc.invalid {
+			t.Errorf("%s/%s: IsInvalid = %v, want %v", tc.source, tc.id, songs[i].IsInvalid, tc.invalid)
+		}
+	}
+	if songs[0].Size != 3840000 || songs[0].Bitrate != 128 {
+		t.Errorf("size/bitrate not filled: %+v", songs[0])
+	}
+	if songs[1].Size != 3840000 {
+		t.Errorf("size from Content-Range not filled: %+v", songs[1])
+	}
+
+	unknown := r.ProbeSongs(context.Background(), []model.Song{{Source: "nope", ID: "1"}}, 1)
+	if unknown[0].Status != ProbeUnavailable {
+		t.Errorf("unknown source: %+v", unknown[0])
+	}
+}
@@ -0,0 +1,284 @@
+// Package registry is the single place music providers are registered.
+//
+// Each provider package calls Register from an init function with its
+// metadata and a function returning the current instance. Consumers (HTTP
+// API, download manager, chart monitor) look providers up by name and ask
+// for the capability they need, e.g. Get("qq").Searcher().
+package registry
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+// Quality tiers understood by providers that honour Song.Extra["quality"].
+const (
+	QualityLossless = model.QualityLossless
+	QualityHigh     = model.QualityHigh
+	QualityStandard = model.QualityStandard
+)
+
+// Info is the static metadata a provider registers with.
+type Info struct {
+	Name        string `json:"name"`         // source key, e.g. "netease"
+	DisplayName string `json:"display_name"` // human-readable name, e.g. "网易云音乐"
+	// Qualities lists the quality tiers the provider can select between.
+	// Empty means the provider always returns its best available stream.
+	Qualities      []string `json:"qualities"`
+	LoginSupported bool     `json:"login_supported"` // a login cookie unlocks more content
+	LoginRequired  bool     `json:"login_required"`  // nothing works without a login cookie
+	// Canary is what the health prober queries to notice upstream API changes.
+	Canary Canary `json:"-"`
+	// Configure rebuilds the provider's default instance with the given
+	// HTTP client options (proxy, timeout, rate limit...). Nil means the
+	// provider has no client to configure.
+	Configure func(opts ...utils.ClientOption) `json:"-"`
+}
+
+// Canary names stable queries a provider should always answer.
+type Canary struct {
+	Keyword string // a search keyword that always has results
+	// SongID is a long-lived song used for the download URL and lyrics
+	// checks. Empty means the first search result is used instead.
+	SongID string
+}
+
+// Provider is a registered provider. The instance function is called on
+// every capability lookup so providers whose default instance is replaced
+// at runtime (e.g. after login) are always resolved to the current one.
+type Provider struct {
+	Info
+	instance func() any
+}
+
+// Instance returns the provider's current instance.
+func (p *Provider) Instance() any { return p.instance() }
+
+// Searcher returns the provider's Searcher, or nil if unsupported.
+func (p *Provider) Searcher() Searcher {
+	v, _ := p.instance().(Searcher)
+	return v
+}
+
+// Downloader returns the provider's Downloader, or nil if unsupported.
+func (p *Provider) Downloader() Downloader {
+	v, _ := p.instance().(Downloader)
+	return v
+}
+
+// LyricsFetcher returns the provider's LyricsFetcher, or nil if unsupported.
+func (p *Provider) LyricsFetcher() LyricsFetcher {
+	v, _ := p.instance().(LyricsFetcher)
+	return v
+}
+
+// SongParser returns the provider's SongParser, or nil if unsupported.
+func (p *Provider) SongParser() SongParser {
+	v, _ := p.instance().(SongParser)
+	return v
+}
+
+// PlaylistSearcher returns the provider's PlaylistSearcher, or nil if unsupported.
+func (p *Provider) PlaylistSearcher() PlaylistSearcher {
+	v, _ := p.instance().(PlaylistSearcher)
+	return v
+}
+
+// PlaylistSource returns the provider's PlaylistSource, or nil if unsupported.
+func (p *Provider) PlaylistSource() PlaylistSource {
+	v, _ := p.instance().(PlaylistSource)
+	return v
+}
+
+// PlaylistParser returns the provider's PlaylistParser, or nil if unsupported.
+func (p *Provider) PlaylistParser() PlaylistParser {
+	v, _ := p.instance().(PlaylistParser)
+	return v
+}
+
+// PlaylistRecommender returns the provider's PlaylistRecommender, or nil if unsupported.
+func (p *Provider) PlaylistRecommender() PlaylistRecommender {
+	v, _ := p.instance().(PlaylistRecommender)
+	return v
+}
+
+// ChartSource returns the provider's ChartSource, or nil if unsupported.
+func (p *Provider) ChartSource() ChartSource {
+	v, _ := p.instance().(ChartSource)
+	return v
+}
+
+// PagedSearcher returns the provider's PagedSearcher, or nil if unsupported.
+func (p *Provider) PagedSearcher() PagedSearcher {
+	v, _ := p.instance().(PagedSearcher)
+	return v
+}
+
+// PagedPlaylistSearcher returns the provider's PagedPlaylistSearcher, or nil if unsupported.
+func (p *Provider) PagedPlaylistSearcher() PagedPlaylistSearcher {
+	v, _ := p.instance().(PagedPlaylistSearcher)
+	return v
+}
+
+// PagedPlaylistSource returns the provider's PagedPlaylistSource, or nil if unsupported.
+func (p *Provider) PagedPlaylistSource() PagedPlaylistSource {
+	v, _ := p.instance().(PagedPlaylistSource)
+	return v
+}
+
+// AlbumSearcher returns the provider's AlbumSearcher, or nil if unsupported.
+func (p *Provider) AlbumSearcher() AlbumSearcher {
+	v, _ := p.instance().(AlbumSearcher)
+	return v
+}
+
+// AlbumSource returns the provider's AlbumSource, or nil if unsupported.
+func (p *Provider) AlbumSource() AlbumSource {
+	v, _ := p.instance().(AlbumSource)
+	return v
+}
+
+// ArtistSearcher returns the provider's ArtistSearcher, or nil if unsupported.
+func (p *Provider) ArtistSearcher() ArtistSearcher {
+	v, _ := p.instance().(ArtistSearcher)
+	return v
+}
+
+// ArtistSource returns the provider's ArtistSource, or nil if unsupported.
+func (p *Provider) ArtistSource() ArtistSource {
+	v, _ := p.instance().(ArtistSource)
+	return v
+}
+
+// LyricsSetFetcher returns the provider's LyricsSetFetcher, or nil if unsupported.
+func (p *Provider) LyricsSetFetcher() LyricsSetFetcher {
+	v, _ := p.instance().(LyricsSetFetcher)
+	return v
+}
+
+// WordLyricsFetcher returns the provider's WordLyricsFetcher, or nil if unsupported.
+func (p *Provider) WordLyricsFetcher() WordLyricsFetcher {
+	v, _ := p.instance().(WordLyricsFetcher)
+	return v
+}
+
+// StreamSource returns the provider's StreamSource, or nil if unsupported.
+func (p *Provider) StreamSource() StreamSource {
+	v, _ := p.instance().(StreamSource)
+	return v
+}
+
+// StreamOpener returns the provider's StreamOpener, or nil if unsupported.
+func (p *Provider) StreamOpener() StreamOpener {
+	v, _ := p.instance().(StreamOpener)
+	return v
+}
+
+// Client returns the HTTP client the provider sends its requests through, or
+// nil if the provider does not expose one.
+func (p *Provider) Client() *utils.Client {
+	if v, ok := p.instance().(ClientOwner); ok {
+		return v.Client()
+	}
+	return nil
+}
+
+// Capabilities reports which capability interfaces the provider implements.
+func (p *Provider) Capabilities() Capabilities {
+	return Capabilities{
+		Search:              p.Searcher() != nil,
+		Download:            p.Downloader() != nil,
+		Lyrics:              p.LyricsFetcher() != nil,
+		LyricsTranslation:   p.LyricsSetFetcher() != nil,
+		WordLyrics:          p.WordLyricsFetcher() != nil,
+		Parse:               p.SongParser() != nil,
+		PlaylistSearch:      p.PlaylistSearcher() != nil,
+		PlaylistSongs:       p.PlaylistSource() != nil,
+		PlaylistParse:       p.PlaylistParser() != nil,
+		PlaylistRecommended: p.PlaylistRecommender() != nil,
+		Charts:              p.ChartSource() != nil,
+		Paging:              p.PagedSearcher() != nil,
+		AlbumSearch:         p.AlbumSearcher() != nil,
+		Album:               p.AlbumSource() != nil,
+		ArtistSearch:        p.ArtistSearcher() != nil,
+		Artist:              p.ArtistSource() != nil,
+		Streams:             p.StreamSource() != nil,
+		OpenStream:          p.StreamOpener() != nil,
+		Probe:               p.StreamSource() != nil || p.Downloader() != nil,
+	}
+}
+
+// Registry holds providers keyed by name, preserving registration order.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]*Provider
+	order     []string
+}
+
+// New creates an empty Registry.
+func New() *Registry {
+	return &Registry{providers: make(map[string]*Provider)}
+}
+
+// Register adds a provider. It panics on an empty or duplicate name, since
+// both are programming errors caught at startup.
+func (r *Registry) Register(info Info, instance func() any) {
+	if info.Name == "" {
+		panic("registry: provider name is empty")
+	}
+	if instance == nil {
+		panic(fmt.Sprintf("registry: provider %q has nil instance func", info.Name))
+	}
+	if info.DisplayName == "" {
+		info.DisplayName = info.Name
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.providers[info.Name]; dup {
+		panic(fmt.Sprintf("registry: provider %q registered twice", info.Name))
+	}
+	r.providers[info.Name] = &Provider{Info: info, instance: instance}
+	r.order = append(r.order, info.Name)
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (*Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// All returns every registered provider in registration order.
+func (r *Registry) All() []*Provider {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*Provider, 0, len(r.order))
+	for _, name := range r.order {
+		result = append(result, r.providers[name])
+	}
+	return result
+}
+
+var defaultRegistry = New()
+
+// Default returns the process-wide registry that provider packages register into.
+func Default() *Registry { return defaultRegistry }
+
+// Register adds a provider to the default registry.
+func Register(info Info, instance func() any) { defaultRegistry.Register(info, instance) }
+
+// Get looks a provider up in the default registry.
+func Get(name string) (*Provider, bool) { return defaultRegistry.Get(name) }
+
+// All returns every provider in the default registry.
+func All() []*Provider { return defaultRegistry.All() }
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+type searchOnly struct{}
+
+func (searchOnly) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return []model.Song{{Name: keyword}}, nil
+}
+
+type searchAndCharts struct{ searchOnly }
+
+func (searchAndCharts) GetChartsContext(ctx context.Context) ([]model.Chart, error) {
+	return []model.Chart{{ID: "1"}}, nil
+}
+
+func (searchAndCharts) GetChartSongsContext(ctx context.Context, chartID string, limit int) ([]model.Song, error) {
+	return nil, nil
+}
+
+func TestRegistry_CapabilitiesByTypeAssertion(t *testing.T) {
+	r := New()
+	r.Register(Info{Name: "a"}, func() any { return searchOnly{} })
+	r.Register(Info{Name: "b", DisplayName: "B"}, func() any { return searchAndCharts{} })
+
+	a, ok := r.Get("a")
+	if !ok {
+		t.Fatal("expected provider a")
+	}
+	if a.DisplayName != "a" {
+		t.Fatalf("display name should default to name, got %q", a.DisplayName)
+	}
+	caps := a.Capabilities()
+	if !caps.Search || caps.Charts || caps.Download {
+		t.Fatalf("unexpected capabilities for a: %+v", caps)
+	}
+	if a.ChartSource() != nil {
+		t.Fatal("a should not expose ChartSource")
+	}
+
+	b, _ := r.Get("b")
+	if !b.Capabilities().Charts {
+		t.Fatal("b should expose charts")
+	}
+}
+
+func TestRegistry_AllKeepsRegistrationOrder(t *testing.T) {
+	r := New()
+	for _, name := range []string{"z", "a", "m"} {
+		r.Register(Info{Name: name}, func() any { return searchOnly{} })
+	}
+	all := r.All()
+	if len(all) != 3 || all[0].Name != "z" || all[1].Name != "a" || all[2].Name != "m" {
+		t.Fatalf("unexpected order: %v", all)
+	}
+}
+
+func TestRegistry_InstanceResolvedPerCall(t *testing.T) {
+	r := New()
+	var current any = searchOnly{}
+	r.Register(Info{Name: "x"}, func() any { return current })
+	p, _ := r.Get("x")
+	if p.ChartSource() != nil {
+		t.Fatal("expected no chart source before swap")
+	}
+	current = searchAndCharts{}
+	if p.ChartSource() == nil {
+		t.Fatal("expected chart source after instance swap")
+	}
+}
+
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	r := New()
+	r.Register(Info{Name: "dup"}, func() any { return searchOnly{} })
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+	}()
+	r.Register(Info{Name: "dup"}, func() any { return searchOnly{} })
+}
+
+func TestRegistry_NilGet(t *testing.T) {
+	var r *Registry
+	if _, ok := r.Get("any"); ok {
+		t.Fatal("nil registry should find nothing")
+	}
+}
+
+type urlOnly struct{ url string }
+
+func (u urlOnly) GetDownloadURLContext(ctx context.Context, s *model.Song) (string, error) {
+	s.Ext = "mp3"
+	return u.url, nil
+}
+
+type multiStream struct{ urlOnly }
+
+func (multiStream) GetStreamsContext(ctx context.Context, s *model.Song) ([]model.Stream, error) {
+	return []model.Stream{
+		{URL: "flac", Ext: "flac"},
+		{URL: "320", Ext: "mp3", Bitrate: 320},
+		{URL: "128", Ext: "mp3", Bitrate: 128},
+	}, nil
+}
+
+func TestProvider_ResolveStream(t *testing.T) {
+	r := New()
+	r.Register(Info{Name: "multi"}, func() any { return multiStream{} })
+	r.Register(Info{Name: "plain"}, func() any { return urlOnly{url: "u"} })
+	r.Register(Info{Name: "empty"}, func() any { return urlOnly{} })
+
+	multi, _ := r.Get("multi")
+	song := model.Song{Extra: map[string]string{"quality": model.QualityHigh}}
+	st, err := multi.ResolveStream(context.Background(), &song)
+	if err != nil || st.URL != "320" {
+		t.Fatalf("expected the 320 stream, got %+v (%v)", st, err)
+	}
+	if song.Ext != "mp3" || song.Bitrate != 320 {
+		t.Fatalf("chosen format not applied to song: %+v", song)
+	}
+
+	plain, _ := r.Get("plain")
+	song = model.Song{}
+	st, err = plain.ResolveStream(context.Background(), &song)
+	if err != nil || st.URL != "u" || st.Ext != "mp3" {
+		t.Fatalf("expected downloader URL wrapped in a stream, got %+v (%v)", st, err)
+	}
+
+	empty, _ := r.Get("empty")
+	if _, err := empty.ResolveStream(context.Background(), &model.Song{}); !errors.Is(err, ErrNoStream) {
+		t.Fatalf("expected ErrNoStream, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/guohuiyuan/music-lib/model"
+)
+
+// ErrNoStream is returned by ResolveStream when the provider answered but
+// offered nothing playable (typically VIP-only or copyright restricted).
+var ErrNoStream = errors.New("no playable stream")
+
+// ResolveStream picks the stream to download for song according to
+// song.Extra["quality"] and writes the chosen format back onto song.
+//
+// Providers implementing StreamSource are asked for all their streams and
+// the choice is made by Song.ChooseStream. Others fall back to their
+// Downloader, whose URL is wrapped in a Stream carrying whatever format
+// the provider wrote back onto song.
+func (p *Provider) ResolveStream(ctx context.Context, song *model.Song) (model.Stream, error) {
+	if src := p.StreamSource(); src != nil {
+		streams, err := src.GetStreamsContext(ctx, song)
+		if err != nil {
+			return model.Stream{}, err
+		}
+		st, ok := song.ChooseStream(streams)
+		if !ok {
+			return model.Stream{}, fmt.Errorf("%s: %w", p.Name, ErrNoStream)
+		}
+		return st, nil
+	}
+
+	d := p.Downloader()
+	if d == nil {
+		return model.Stream{}, fmt.Errorf("provider %q does not support download", p.Name)
+	}
+	u, err := d.GetDownloadURLContext(ctx, song)
+	if err != nil {
+		return model.Stream{}, err
+	}
+	if u == "" {
+		return model.Stream{}, fmt.Errorf("%s: %w", p.Name, ErrNoStream)
+	}
+	return model.Stream{URL: u, Ext: song.Ext, Bitrate: song.Bitrate}, nil
+}
@@ -29,6 +29,12 @@ func writeFLACTags(filePath string, song *model.Song, coverData []byte, coverMIM
 	if genre := song.Extra["genre"]; genre != "" {
 		cmts.Add(flacvorbis.FIELD_GENRE, genre)
 	}
+	if track := song.Extra["track"]; track != "" {
+		cmts.Add(flacvorbis.FIELD_TRACKNUMBER, track)
+	}
+	if disc := song.Extra["disc"]; disc != "" {
+		cmts.Add("DISCNUMBER", disc)
+	}
 	if lyrics != "" {
 		cmts.Add("LYRICS", lyrics)
 	}
@@ -8,7 +8,11 @@ import (
 // lrcTimestamp matches LRC timing tags: [mm:ss.xx], [mm:ss.xxx], or [mm:ss].
 var lrcTimestamp = regexp.MustCompile(`\[\d{2}:\d{2}(?:\.\d{2,3})?\]`)
 
-// StripLRCTimestamps removes LRC timing tags from lyrics text,
+// lrcWordTimestamp matches the per-word <mm:ss.xx> tags of Enhanced LRC.
+var lrcWordTimestamp = regexp.MustCompile(`<\d{2}:\d{2}(?:\.\d{2,3})?>`)
+
+// StripLRCTimestamps removes LRC timing tags, including Enhanced LRC word
+// tags, from lyrics text,
 // returning plain text suitable for ID3v2 USLT or FLAC LYRICS embedding.
 // Empty lines (after stripping) are removed.
 func StripLRCTimestamps(lrc string) string {
@@ -16,6 +20,7 @@ func StripLRCTimestamps(lrc string) string {
 	var result []string
 	for _, line := range lines {
 		cleaned := lrcTimestamp.ReplaceAllString(line, "")
+		cleaned = lrcWordTimestamp.ReplaceAllString(cleaned, "")
 		cleaned = strings.TrimSpace(cleaned)
 		if cleaned != "" {
 			result = append(result, cleaned)
@@ -5,7 +5,7 @@ import (
 	"github.com/guohuiyuan/music-lib/model"
 )
 
-// writeMP3Tags writes ID3v2.4 tags (title, artist, album, cover, lyrics)
+// writeMP3Tags writes ID3v2.4 tags (title, artist, album, track, cover, lyrics)
 // into an MP3 file. Existing tags are overwritten.
 func writeMP3Tags(filePath string, song *model.Song, coverData []byte, coverMIME, lyrics string) error {
 	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: false})
@@ -26,6 +26,12 @@ func writeMP3Tags(filePath string, song *model.Song, coverData []byte, coverMIME
 	if genre := song.Extra["genre"]; genre != "" {
 		tag.SetGenre(genre)
 	}
+	if track := song.Extra["track"]; track != "" {
+		tag.AddTextFrame(tag.CommonID("Track number/Position in set"), tag.DefaultEncoding(), track)
+	}
+	if disc := song.Extra["disc"]; disc != "" {
+		tag.AddTextFrame(tag.CommonID("Part of a set"), tag.DefaultEncoding(), disc)
+	}
 
 	// APIC — front cover.
 	if len(coverData) > 0 {
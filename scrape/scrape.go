@@ -17,6 +17,9 @@ type Config struct {
 	Enabled bool
 	Cover   bool
 	Lyrics  bool
+	// Client fetches the cover image, e.g. the provider's client so its
+	// proxy applies. nil uses a default client.
+	Client *http.Client
 }
 
 // Result reports the outcome of a scrape operation.
@@ -53,7 +56,7 @@ func Scrape(cfg Config, song *model.Song, filePath, lyrics string) Result {
 	var coverMIME string
 	if cfg.Cover && song.Cover != "" {
 		var err error
-		coverData, coverMIME, err = downloadCoverImage(song.Cover)
+		coverData, coverMIME, err = downloadCoverImage(cfg.Client, song.Cover)
 		if err != nil {
 			slog.Warn("scrape.cover_download", "error", err, "url", song.Cover)
 		}
@@ -84,13 +87,17 @@ func Scrape(cfg Config, song *model.Song, filePath, lyrics string) Result {
 }
 
 // downloadCoverImage fetches the cover image and returns raw bytes + MIME type.
-func downloadCoverImage(url string) ([]byte, string, error) {
+// A nil hc uses coverClient.
+func downloadCoverImage(hc *http.Client, url string) ([]byte, string, error) {
+	if hc == nil {
+		hc = coverClient
+	}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, "", fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("User-Agent", coverUserAgent)
-	resp, err := coverClient.Do(req)
+	resp, err := hc.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("http get: %w", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bogem/id3v2/v2"
 	"github.com/guohuiyuan/music-lib/model"
 )
 
@@ -46,6 +47,14 @@ func TestStripLRCTimestamps_MultipleTagsPerLine(t *testing.T) {
 	}
 }
 
+func TestStripLRCTimestamps_WordTags(t *testing.T) {
+	input := "[00:29.35]<00:29.35>故<00:29.75>事<00:30.35>"
+	got := StripLRCTimestamps(input)
+	if got != "故事" {
+		t.Fatalf("got %q", got)
+	}
+}
+
 func TestStripLRCTimestamps_EmptyLinesRemoved(t *testing.T) {
 	input := "[00:01.00]\n[00:02.00]有内容\n[00:03.00]\n"
 	got := StripLRCTimestamps(input)
@@ -162,7 +171,7 @@ func TestDownloadCoverImage_Success(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	data, mime, err := downloadCoverImage(srv.URL)
+	data, mime, err := downloadCoverImage(nil, srv.URL)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -180,7 +189,7 @@ func TestDownloadCoverImage_HTTPError(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, _, err := downloadCoverImage(srv.URL)
+	_, _, err := downloadCoverImage(nil, srv.URL)
 	if err == nil {
 		t.Fatal("expected error for 404")
 	}
@@ -197,7 +206,7 @@ func TestDownloadCoverImage_ContentSniff(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, mime, err := downloadCoverImage(srv.URL)
+	_, mime, err := downloadCoverImage(nil, srv.URL)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -316,3 +325,30 @@ func TestScrape_FLAC_InvalidFile(t *testing.T) {
 	}
 	fmt.Println("FLAC error (expected):", result.Error)
 }
+
+func TestWriteMP3Tags_TrackAndDisc(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "song.mp3")
+	os.WriteFile(tmp, make([]byte, 512), 0644)
+
+	song := &model.Song{
+		Name:   "爸我回来了",
+		Artist: "周杰伦",
+		Album:  "范特西",
+		Extra:  map[string]string{"track": "2", "disc": "1"},
+	}
+	if err := writeMP3Tags(tmp, song, nil, "", ""); err != nil {
+		t.Fatalf("writeMP3Tags: %v", err)
+	}
+
+	tag, err := id3v2.Open(tmp, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tag.Close()
+	if got := tag.GetTextFrame(tag.CommonID("Track number/Position in set")).Text; got != "2" {
+		t.Errorf("TRCK = %q, want 2", got)
+	}
+	if got := tag.GetTextFrame(tag.CommonID("Part of a set")).Text; got != "1" {
+		t.Errorf("TPOS = %q, want 1", got)
+	}
+}
@@ -8,33 +8,201 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 )
 
-// DecryptAudio 核心解密函数
+// maxBoxSize 限制 mdat 以外的 box 大小，它们需要整体读入内存
+const maxBoxSize = 64 << 20
+
+// DecryptAudio 核心解密函数，解密整个内存中的加密 MP4
 func DecryptAudio(fileData []byte, playAuth string) ([]byte, error) {
-	hexKey, err := extractKey(playAuth)
+	key, err := playAuthKey(playAuth)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Grow(len(fileData))
+	if err := decryptStream(&out, bytes.NewReader(fileData), key); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// NewDecryptReader 边读边解密 r 中的加密 MP4。moov 在 mdat 前面时 (CDN 返回的文件都是这样)
+// 内存占用与文件大小无关；moov 在后面时只能把 mdat 整体读入内存。
+// 返回的 ReadCloser 关闭后解密停止，但不会关闭 r
+func NewDecryptReader(r io.Reader, playAuth string) (io.ReadCloser, error) {
+	key, err := playAuthKey(playAuth)
 	if err != nil {
 		return nil, err
 	}
-	keyBytes, err := hex.DecodeString(hexKey)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(decryptStream(pw, r, key))
+	}()
+	return pr, nil
+}
+
+// playAuthKey 从 PlayAuth 中取出 AES 密钥
+func playAuthKey(playAuth string) ([]byte, error) {
+	hexKey, err := extractKey(playAuth)
 	if err != nil {
 		return nil, err
 	}
+	return hex.DecodeString(hexKey)
+}
+
+// sampleTable 是解密 mdat 需要的每个 sample 的大小和 IV
+type sampleTable struct {
+	sizes []uint32
+	ivs   [][]byte
+}
+
+// decryptStream 按顶层 box 顺序把 src 复制到 dst，mdat 中的 sample 用 AES-CTR 逐个解密。
+// mdat 以外的 box 整体读入，moov 中的 "enca" 改为 "mp4a"
+func decryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	var table *sampleTable
+	sawMdat := false
+	for {
+		hdr, size, boxType, err := readBoxHeader(src)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if boxType != "mdat" {
+			box, err := readBox(src, hdr, size)
+			if err != nil {
+				return err
+			}
+			if boxType == "moov" && table == nil {
+				if table, err = parseMoov(box, len(hdr)); err != nil {
+					return err
+				}
+			}
+			if _, err := dst.Write(box); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sawMdat = true
+		if table == nil {
+			// moov 在 mdat 后面，需要先读完整个文件
+			return decryptTrailingMoov(dst, src, hdr, size, block)
+		}
+		if _, err := dst.Write(hdr); err != nil {
+			return err
+		}
+		bodyLen := int64(-1)
+		if size >= 0 {
+			bodyLen = size - int64(len(hdr))
+		}
+		if err := decryptSamples(dst, src, bodyLen, table, block); err != nil {
+			return err
+		}
+	}
+
+	if table == nil {
+		return errors.New("moov box not found")
+	}
+	if !sawMdat {
+		return errors.New("mdat box not found")
+	}
+	return nil
+}
+
+// decryptTrailingMoov 处理 moov 在 mdat 之后的文件：读入剩余的全部数据，
+// 从 mdat 之后找到 moov 再解密
+func decryptTrailingMoov(dst io.Writer, src io.Reader, hdr []byte, size int64, block cipher.Block) error {
+	rest, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	bodyLen := int64(len(rest))
+	if size >= 0 {
+		bodyLen = size - int64(len(hdr))
+	}
+	if bodyLen > int64(len(rest)) {
+		return io.ErrUnexpectedEOF
+	}
+	body, tail := rest[:bodyLen], rest[bodyLen:]
 
-	moov, err := findBox(fileData, "moov", 0, len(fileData))
+	moov, err := findBox(tail, "moov", 0, len(tail))
+	if err != nil {
+		return errors.New("moov box not found")
+	}
+	table, err := parseMoov(tail[moov.offset:moov.offset+moov.size], 8)
 	if err != nil {
-		return nil, errors.New("moov box not found")
+		return err
+	}
+	if _, err := dst.Write(hdr); err != nil {
+		return err
+	}
+	if err := decryptSamples(dst, bytes.NewReader(body), bodyLen, table, block); err != nil {
+		return err
+	}
+	_, err = dst.Write(tail)
+	return err
+}
+
+// decryptSamples 从 r 读出 mdat 的内容逐个 sample 解密后写入 dst。
+// 没有 IV 的 sample 原样写出。bodyLen 为 -1 表示 mdat 一直到文件结尾
+func decryptSamples(dst io.Writer, r io.Reader, bodyLen int64, table *sampleTable, block cipher.Block) error {
+	var total int64
+	for _, n := range table.sizes {
+		total += int64(n)
+	}
+	if bodyLen >= 0 && total != bodyLen {
+		return errors.New("decrypted size mismatch")
+	}
+
+	var buf []byte
+	iv := make([]byte, aes.BlockSize)
+	for i, n := range table.sizes {
+		if cap(buf) < int(n) {
+			buf = make([]byte, n)
+		}
+		chunk := buf[:n]
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return fmt.Errorf("read sample %d: %w", i, err)
+		}
+		if i < len(table.ivs) {
+			clear(iv)
+			copy(iv, table.ivs[i])
+			cipher.NewCTR(block, iv).XORKeyStream(chunk, chunk)
+		}
+		if _, err := dst.Write(chunk); err != nil {
+			return err
+		}
 	}
+	if bodyLen < 0 {
+		_, err := io.Copy(dst, r)
+		return err
+	}
+	return nil
+}
 
-	stbl, err := findBox(fileData, "stbl", moov.offset, moov.offset+moov.size)
+// parseMoov 从第一条音轨取出 sample 表，并原地把 stsd 中的 "enca" 改为 "mp4a"，
+// 解密后的文件才会被当作普通 AAC。hdrLen 是 moov 头部的长度
+func parseMoov(moov []byte, hdrLen int) (*sampleTable, error) {
+	stbl, err := findBox(moov, "stbl", hdrLen, len(moov))
 	if err != nil {
-		trak, _ := findBox(fileData, "trak", moov.offset+8, moov.offset+moov.size)
+		trak, _ := findBox(moov, "trak", hdrLen, len(moov))
 		if trak != nil {
-			mdia, _ := findBox(fileData, "mdia", trak.offset+8, trak.offset+trak.size)
+			mdia, _ := findBox(moov, "mdia", trak.offset+8, trak.offset+trak.size)
 			if mdia != nil {
-				minf, _ := findBox(fileData, "minf", mdia.offset+8, mdia.offset+mdia.size)
+				minf, _ := findBox(moov, "minf", mdia.offset+8, mdia.offset+mdia.size)
 				if minf != nil {
-					stbl, _ = findBox(fileData, "stbl", minf.offset+8, minf.offset+minf.size)
+					stbl, _ = findBox(moov, "stbl", minf.offset+8, minf.offset+minf.size)
 				}
 			}
 		}
@@ -43,78 +211,68 @@ func DecryptAudio(fileData []byte, playAuth string) ([]byte, error) {
 		return nil, errors.New("stbl box not found")
 	}
 
-	stsz, err := findBox(fileData, "stsz", stbl.offset+8, stbl.offset+stbl.size)
+	stsz, err := findBox(moov, "stsz", stbl.offset+8, stbl.offset+stbl.size)
 	if err != nil {
 		return nil, errors.New("stsz box not found")
 	}
-	sampleSizes := parseStsz(stsz.data)
 
-	senc, err := findBox(fileData, "senc", moov.offset+8, moov.offset+moov.size)
+	senc, err := findBox(moov, "senc", hdrLen, len(moov))
 	if err != nil {
-		senc, err = findBox(fileData, "senc", stbl.offset+8, stbl.offset+stbl.size)
+		senc, err = findBox(moov, "senc", stbl.offset+8, stbl.offset+stbl.size)
 	}
 	if err != nil {
 		return nil, errors.New("senc box not found")
 	}
-	ivs := parseSenc(senc.data)
 
-	mdat, err := findBox(fileData, "mdat", 0, len(fileData))
-	if err != nil {
-		return nil, errors.New("mdat box not found")
+	if stsd, err := findBox(moov, "stsd", stbl.offset+8, stbl.offset+stbl.size); err == nil {
+		if idx := bytes.Index(stsd.data, []byte("enca")); idx != -1 {
+			copy(stsd.data[idx:], "mp4a")
+		}
 	}
+	return &sampleTable{sizes: parseStsz(stsz.data), ivs: parseSenc(senc.data)}, nil
+}
 
-	block, err := aes.NewCipher(keyBytes)
-	if err != nil {
-		return nil, err
+// readBoxHeader 读取一个 box 的头部，返回原始头部字节、box 总大小和类型。
+// 大小为 -1 表示 box 一直到文件结尾。正好在 box 边界遇到结尾时返回 io.EOF
+func readBoxHeader(r io.Reader) (hdr []byte, size int64, boxType string, err error) {
+	hdr = make([]byte, 8)
+	if _, err = io.ReadFull(r, hdr); err != nil {
+		return nil, 0, "", err
 	}
-
-	decryptedData := make([]byte, len(fileData))
-	copy(decryptedData, fileData)
-
-	readPtr := mdat.offset + 8
-	decryptedMdat := make([]byte, 0, mdat.size-8)
-
-	for i := 0; i < len(sampleSizes); i++ {
-		size := int(sampleSizes[i])
-		if readPtr+size > len(decryptedData) {
-			break
+	size = int64(binary.BigEndian.Uint32(hdr[:4]))
+	boxType = string(hdr[4:8])
+	switch size {
+	case 0:
+		size = -1
+	case 1:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(r, ext); err != nil {
+			return nil, 0, "", io.ErrUnexpectedEOF
 		}
-		chunk := decryptedData[readPtr : readPtr+size]
-
-		if i < len(ivs) {
-			iv := ivs[i]
-			if len(iv) < 16 {
-				padded := make([]byte, 16)
-				copy(padded, iv)
-				iv = padded
-			}
-			stream := cipher.NewCTR(block, iv)
-			dst := make([]byte, size)
-			stream.XORKeyStream(dst, chunk)
-			decryptedMdat = append(decryptedMdat, dst...)
-		} else {
-			decryptedMdat = append(decryptedMdat, chunk...)
-		}
-		readPtr += size
+		hdr = append(hdr, ext...)
+		size = int64(binary.BigEndian.Uint64(ext))
 	}
-
-	if len(decryptedMdat) == int(mdat.size)-8 {
-		copy(decryptedData[mdat.offset+8:], decryptedMdat)
-	} else {
-		return nil, errors.New("decrypted size mismatch")
+	if size >= 0 && size < int64(len(hdr)) {
+		return nil, 0, "", fmt.Errorf("invalid %q box size %d", boxType, size)
 	}
+	return hdr, size, boxType, nil
+}
 
-	stsd, err := findBox(fileData, "stsd", stbl.offset+8, stbl.offset+stbl.size)
-	if err == nil {
-		stsdOffset := stsd.offset
-		stsdData := decryptedData[stsdOffset : stsdOffset+stsd.size]
-		if idx := bytes.Index(stsdData, []byte("enca")); idx != -1 {
-			copy(stsdData[idx:], []byte("mp4a"))
-			copy(decryptedData[stsdOffset:], stsdData)
-		}
+// readBox 读入头部已经读过的整个 box，返回包括头部在内的全部字节
+func readBox(r io.Reader, hdr []byte, size int64) ([]byte, error) {
+	if size < 0 {
+		body, err := io.ReadAll(io.LimitReader(r, maxBoxSize))
+		return append(hdr, body...), err
 	}
-
-	return decryptedData, nil
+	if size > maxBoxSize {
+		return nil, fmt.Errorf("box too large: %d bytes", size)
+	}
+	box := make([]byte, size)
+	copy(box, hdr)
+	if _, err := io.ReadFull(r, box[len(hdr):]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return box, nil
 }
 
 type mp4Box struct {
@@ -0,0 +1,85 @@
+package soda
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], boxType)
+	return append(b, body...)
+}
+
+// encryptedMP4 builds a minimal CENC-style file like the ones soda serves:
+// one track whose samples are AES-CTR encrypted with per-sample IVs stored
+// in a senc box, and an "enca" sample entry.
+func encryptedMP4(t *testing.T, key []byte, samples [][]byte, moovFirst bool) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stsz := make([]byte, 12)
+	binary.BigEndian.PutUint32(stsz[8:], uint32(len(samples)))
+	senc := make([]byte, 8)
+	binary.BigEndian.PutUint32(senc[4:], uint32(len(samples)))
+	var mdat []byte
+	for i, s := range samples {
+		stsz = binary.BigEndian.AppendUint32(stsz, uint32(len(s)))
+		iv := []byte{0, 0, 0, 0, 0, 0, 0, byte(i + 1)}
+		senc = append(senc, iv...)
+		enc := make([]byte, len(s))
+		cipher.NewCTR(block, append(iv, make([]byte, 8)...)).XORKeyStream(enc, s)
+		mdat = append(mdat, enc...)
+	}
+
+	stsd := box("stsd", make([]byte, 8), box("enca", make([]byte, 28)))
+	stbl := box("stbl", stsd, box("stsz", stsz))
+	moov := box("moov", box("trak", box("mdia", box("minf", stbl))), box("senc", senc))
+	ftyp := box("ftyp", []byte("isom"))
+	if moovFirst {
+		return bytes.Join([][]byte{ftyp, moov, box("mdat", mdat)}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, box("mdat", mdat), moov}, nil)
+}
+
+func TestDecryptStream(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	samples := [][]byte{[]byte("first sample, a bit longer than a block"), []byte("second"), []byte("third!")}
+	want := bytes.Join(samples, nil)
+
+	for _, moovFirst := range []bool{true, false} {
+		in := encryptedMP4(t, key, samples, moovFirst)
+		var out bytes.Buffer
+		if err := decryptStream(&out, iotest.OneByteReader(bytes.NewReader(in)), key); err != nil {
+			t.Fatalf("moovFirst=%v: %v", moovFirst, err)
+		}
+		got := out.Bytes()
+		if len(got) != len(in) {
+			t.Fatalf("moovFirst=%v: length changed from %d to %d", moovFirst, len(in), len(got))
+		}
+		if !bytes.Contains(got, want) {
+			t.Errorf("moovFirst=%v: samples not decrypted", moovFirst)
+		}
+		if bytes.Contains(got, []byte("enca")) || !bytes.Contains(got, []byte("mp4a")) {
+			t.Errorf("moovFirst=%v: sample entry not renamed", moovFirst)
+		}
+	}
+}
+
+func TestDecryptStream_Truncated(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	in := encryptedMP4(t, key, [][]byte{[]byte("sample")}, true)
+	if err := decryptStream(io.Discard, bytes.NewReader(in[:len(in)-3]), key); err == nil {
+		t.Fatal("expected an error for a truncated download")
+	}
+}
@@ -0,0 +1,16 @@
+package soda
+
+import (
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/internal/replay"
+	"github.com/guohuiyuan/music-lib/utils"
+)
+
+func TestReplay(t *testing.T) {
+	replay.Run(t, newReplay, []replay.Case[*Soda]{
+		{Name: "search", Call: func(_ *testing.T, s *Soda) (any, error) { return s.Search("晴天") }},
+	})
+}
+
+func newReplay(opts ...utils.ClientOption) *Soda { return New("", opts...) }
@@ -1,6 +1,7 @@
 package soda
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/guohuiyuan/music-lib/model"
 	"github.com/guohuiyuan/music-lib/utils"
@@ -21,49 +24,218 @@ import (
 const (
 	// PC端 UserAgent
 	UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/134.0.0.0 Safari/537.36"
+
+	// 音频流的超时，与下载器的长超时一致，避免 CDN 卡住时永久阻塞
+	streamTimeout = 10 * time.Minute
 )
 
 type Soda struct {
 	cookie string
+	client *utils.Client
+}
+
+func New(cookie string, opts ...utils.ClientOption) *Soda {
+	return &Soda{cookie: cookie, client: utils.NewClient(opts...)}
 }
 
-func New(cookie string) *Soda { return &Soda{cookie: cookie} }
+// Client 返回实例使用的 HTTP 客户端
+func (s *Soda) Client() *utils.Client { return s.client }
+
+var (
+	defaultMu   sync.RWMutex
+	defaultSoda = New("")
+)
+
+// Configure 按 opts 重建默认实例的 HTTP 客户端 (代理、超时、Cookie Jar 等)，Cookie 保持不变。
+// 可以和正在进行的请求并发调用，之后的请求使用新客户端
+func Configure(opts ...utils.ClientOption) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultSoda = New(defaultSoda.cookie, opts...)
+}
 
-var defaultSoda = New("")
+// getDefault 返回当前的默认实例
+func getDefault() *Soda {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultSoda
+}
 
-func Search(keyword string) ([]model.Song, error) { return defaultSoda.Search(keyword) }
+func Search(keyword string) ([]model.Song, error) { return getDefault().Search(keyword) }
 func SearchPlaylist(keyword string) ([]model.Playlist, error) {
-	return defaultSoda.SearchPlaylist(keyword)
+	return getDefault().SearchPlaylist(keyword)
 }
 func GetPlaylistSongs(id string) ([]model.Song, error) {
 	// 复用 fetchPlaylistDetail，只返回歌曲列表
-	_, songs, err := defaultSoda.fetchPlaylistDetail(id)
-	return songs, err
+	return getDefault().GetPlaylistSongs(id)
+}
+func SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPage(keyword, page, limit)
+}
+func SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPage(keyword, page, limit)
+}
+func GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPage(id, page, limit)
 }
 func ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
-	return defaultSoda.ParsePlaylist(link)
+	return getDefault().ParsePlaylist(link)
+}
+func GetDownloadInfo(s *model.Song) (*DownloadInfo, error) { return getDefault().GetDownloadInfo(s) }
+func GetDownloadURL(s *model.Song) (string, error)         { return getDefault().GetDownloadURL(s) }
+func GetStreams(song *model.Song) ([]model.Stream, error)  { return getDefault().GetStreams(song) }
+func Download(s *model.Song, outputPath string) error      { return getDefault().Download(s, outputPath) }
+func GetLyrics(s *model.Song) (string, error)              { return getDefault().GetLyrics(s) }
+func Parse(link string) (*model.Song, error)               { return getDefault().Parse(link) }
+func OpenStream(st model.Stream) (*model.AudioStream, error) {
+	return getDefault().OpenStream(st)
+}
+
+// GetRecommendedPlaylists 获取推荐歌单 (空实现)
+// 汽水音乐目前没有公开的每日推荐歌单 PC 接口；Soda 类型不实现该方法，
+// 这样注册表不会把它当作支持推荐歌单的平台。
+func GetRecommendedPlaylists() ([]model.Playlist, error) {
+	return nil, errors.New("soda daily recommendation not supported")
+}
+
+func SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	return getDefault().SearchContext(ctx, keyword)
+}
+
+func SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	return getDefault().SearchPlaylistContext(ctx, keyword)
 }
-func GetDownloadInfo(s *model.Song) (*DownloadInfo, error) { return defaultSoda.GetDownloadInfo(s) }
-func GetDownloadURL(s *model.Song) (string, error)         { return defaultSoda.GetDownloadURL(s) }
-func Download(s *model.Song, outputPath string) error      { return defaultSoda.Download(s, outputPath) }
-func GetLyrics(s *model.Song) (string, error)              { return defaultSoda.GetLyrics(s) }
-func Parse(link string) (*model.Song, error)               { return defaultSoda.Parse(link) }
 
-// GetRecommendedPlaylists [新增] 获取推荐歌单 (空实现)
-func GetRecommendedPlaylists() ([]model.Playlist, error) { return defaultSoda.GetRecommendedPlaylists() }
+func GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	return getDefault().GetPlaylistSongsContext(ctx, id)
+}
+
+func SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().SearchPageContext(ctx, keyword, page, limit)
+}
+
+func SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return getDefault().SearchPlaylistPageContext(ctx, keyword, page, limit)
+}
+
+func GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	return getDefault().GetPlaylistSongsPageContext(ctx, id, page, limit)
+}
+
+func ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
+	return getDefault().ParsePlaylistContext(ctx, link)
+}
+
+func GetDownloadInfoContext(ctx context.Context, song *model.Song) (*DownloadInfo, error) {
+	return getDefault().GetDownloadInfoContext(ctx, song)
+}
+
+func GetDownloadURLContext(ctx context.Context, song *model.Song) (string, error) {
+	return getDefault().GetDownloadURLContext(ctx, song)
+}
+
+func GetStreamsContext(ctx context.Context, song *model.Song) ([]model.Stream, error) {
+	return getDefault().GetStreamsContext(ctx, song)
+}
+
+func DownloadContext(ctx context.Context, song *model.Song, outputPath string) error {
+	return getDefault().DownloadContext(ctx, song, outputPath)
+}
+
+func ParseContext(ctx context.Context, link string) (*model.Song, error) {
+	return getDefault().ParseContext(ctx, link)
+}
+
+func GetLyricsContext(ctx context.Context, song *model.Song) (string, error) {
+	return getDefault().GetLyricsContext(ctx, song)
+}
+
+func OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error) {
+	return getDefault().OpenStreamContext(ctx, st)
+}
 
-// Search 搜索歌曲 (PC API)
 func (s *Soda) Search(keyword string) ([]model.Song, error) {
+	return s.SearchContext(context.Background(), keyword)
+}
+
+func (s *Soda) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+	return s.SearchPlaylistContext(context.Background(), keyword)
+}
+
+func (s *Soda) GetPlaylistSongs(id string) ([]model.Song, error) {
+	return s.GetPlaylistSongsContext(context.Background(), id)
+}
+
+func (s *Soda) SearchPage(keyword string, page, limit int) (*model.Page[model.Song], error) {
+	return s.SearchPageContext(context.Background(), keyword, page, limit)
+}
+
+func (s *Soda) SearchPlaylistPage(keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	return s.SearchPlaylistPageContext(context.Background(), keyword, page, limit)
+}
+
+func (s *Soda) GetPlaylistSongsPage(id string, page, limit int) (*model.Page[model.Song], error) {
+	return s.GetPlaylistSongsPageContext(context.Background(), id, page, limit)
+}
+
+func (s *Soda) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+	return s.ParsePlaylistContext(context.Background(), link)
+}
+
+func (s *Soda) GetDownloadInfo(song *model.Song) (*DownloadInfo, error) {
+	return s.GetDownloadInfoContext(context.Background(), song)
+}
+
+func (s *Soda) GetDownloadURL(song *model.Song) (string, error) {
+	return s.GetDownloadURLContext(context.Background(), song)
+}
+
+func (s *Soda) GetStreams(song *model.Song) ([]model.Stream, error) {
+	return s.GetStreamsContext(context.Background(), song)
+}
+
+func (s *Soda) Download(song *model.Song, outputPath string) error {
+	return s.DownloadContext(context.Background(), song, outputPath)
+}
+
+func (s *Soda) Parse(link string) (*model.Song, error) {
+	return s.ParseContext(context.Background(), link)
+}
+
+func (s *Soda) GetLyrics(song *model.Song) (string, error) {
+	return s.GetLyricsContext(context.Background(), song)
+}
+
+func (s *Soda) OpenStream(st model.Stream) (*model.AudioStream, error) {
+	return s.OpenStreamContext(context.Background(), st)
+}
+
+// SearchContext 搜索歌曲 (PC API)
+func (s *Soda) SearchContext(ctx context.Context, keyword string) ([]model.Song, error) {
+	page, err := s.SearchPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return page.Items, nil
+}
+
+// SearchPageContext 分页搜索歌曲。汽水的 cursor 是结果偏移量，由 page/limit 换算得到
+func (s *Soda) SearchPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 50)
 	params := url.Values{}
 	params.Set("q", keyword)
-	params.Set("cursor", "0")
+	params.Set("cursor", strconv.Itoa((page-1)*limit))
+	params.Set("count", strconv.Itoa(limit))
 	params.Set("search_method", "input")
 	params.Set("aid", "386088")
 	params.Set("device_platform", "web")
 	params.Set("channel", "pc_web")
 
 	apiURL := "https://api.qishui.com/luna/pc/search/track?" + params.Encode()
-	body, err := utils.Get(apiURL,
+	body, err := s.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -96,14 +268,15 @@ func (s *Soda) Search(keyword string) ([]model.Song, error) {
 					} `json:"track"`
 				} `json:"entity"`
 			} `json:"data"`
+			HasMore bool `json:"has_more"`
 		} `json:"result_groups"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("soda search json parse error: %w", err)
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "search json parse error: %w", err)
 	}
 	if len(resp.ResultGroups) == 0 {
-		return nil, nil
+		return model.NewPage[model.Song](nil, page, limit, 0, 0), nil
 	}
 
 	var songs []model.Song
@@ -157,14 +330,30 @@ func (s *Soda) Search(keyword string) ([]model.Song, error) {
 			},
 		})
 	}
-	return songs, nil
+	result := model.NewPage(songs, page, limit, 0, len(resp.ResultGroups[0].Data))
+	result.HasMore = result.HasMore || resp.ResultGroups[0].HasMore
+	return result, nil
 }
 
-// SearchPlaylist 搜索歌单 (PC API)
-func (s *Soda) SearchPlaylist(keyword string) ([]model.Playlist, error) {
+// SearchPlaylistContext 搜索歌单 (PC API)
+func (s *Soda) SearchPlaylistContext(ctx context.Context, keyword string) ([]model.Playlist, error) {
+	page, err := s.SearchPlaylistPageContext(ctx, keyword, 1, 20)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	return page.Items, nil
+}
+
+// SearchPlaylistPageContext 分页搜索歌单 (PC API)
+func (s *Soda) SearchPlaylistPageContext(ctx context.Context, keyword string, page, limit int) (*model.Page[model.Playlist], error) {
+	page, limit = model.NormalizePage(page, limit, 20, 50)
 	params := url.Values{}
 	params.Set("q", keyword)
-	params.Set("cursor", "0")
+	params.Set("cursor", strconv.Itoa((page-1)*limit))
+	params.Set("count", strconv.Itoa(limit))
 	params.Set("search_method", "input")
 	params.Set("aid", "386088")
 	params.Set("device_platform", "web")
@@ -172,7 +361,7 @@ func (s *Soda) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 
 	apiURL := "https://api.qishui.com/luna/pc/search/playlist?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := s.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -200,16 +389,17 @@ func (s *Soda) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 					} `json:"playlist"`
 				} `json:"entity"`
 			} `json:"data"`
+			HasMore bool `json:"has_more"`
 		} `json:"result_groups"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("soda playlist json parse error: %w", err)
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "playlist json parse error: %w", err)
 	}
 
 	var playlists []model.Playlist
 	if len(resp.ResultGroups) == 0 || len(resp.ResultGroups[0].Data) == 0 {
-		return nil, nil
+		return model.NewPage[model.Playlist](nil, page, limit, 0, 0), nil
 	}
 
 	for _, item := range resp.ResultGroups[0].Data {
@@ -247,34 +437,40 @@ func (s *Soda) SearchPlaylist(keyword string) ([]model.Playlist, error) {
 			Link:        fmt.Sprintf("https://www.qishui.com/playlist/%s", pl.ID),
 		})
 	}
-	return playlists, nil
+	result := model.NewPage(playlists, page, limit, 0, len(resp.ResultGroups[0].Data))
+	result.HasMore = result.HasMore || resp.ResultGroups[0].HasMore
+	return result, nil
 }
 
-// GetRecommendedPlaylists [新增] 获取推荐歌单 (空实现)
-func (s *Soda) GetRecommendedPlaylists() ([]model.Playlist, error) {
-	// 汽水音乐目前没有公开的每日推荐歌单 PC 接口
-	return nil, errors.New("soda daily recommendation not supported")
+// GetPlaylistSongsContext 获取歌单所有歌曲
+func (s *Soda) GetPlaylistSongsContext(ctx context.Context, id string) ([]model.Song, error) {
+	_, songs, err := s.fetchPlaylistDetail(ctx, id)
+	return songs, err
 }
 
-// GetPlaylistSongs 获取歌单所有歌曲
-func (s *Soda) GetPlaylistSongs(id string) ([]model.Song, error) {
-	_, songs, err := s.fetchPlaylistDetail(id)
-	return songs, err
+// GetPlaylistSongsPageContext 分页获取歌单歌曲，基于完整歌单做切片
+func (s *Soda) GetPlaylistSongsPageContext(ctx context.Context, id string, page, limit int) (*model.Page[model.Song], error) {
+	page, limit = model.NormalizePage(page, limit, 100, 1000)
+	_, songs, err := s.fetchPlaylistDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.SlicePage(songs, page, limit), nil
 }
 
-// ParsePlaylist 解析歌单链接
-func (s *Soda) ParsePlaylist(link string) (*model.Playlist, []model.Song, error) {
+// ParsePlaylistContext 解析歌单链接
+func (s *Soda) ParsePlaylistContext(ctx context.Context, link string) (*model.Playlist, []model.Song, error) {
 	re := regexp.MustCompile(`playlist/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, nil, errors.New("invalid soda playlist link")
+		return nil, nil, model.Errorf("soda", model.ErrInvalidInput, "invalid soda playlist link")
 	}
 	playlistID := matches[1]
-	return s.fetchPlaylistDetail(playlistID)
+	return s.fetchPlaylistDetail(ctx, playlistID)
 }
 
 // fetchPlaylistDetail [内部通用] 获取歌单详情
-func (s *Soda) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, error) {
+func (s *Soda) fetchPlaylistDetail(ctx context.Context, id string) (*model.Playlist, []model.Song, error) {
 	params := url.Values{}
 	params.Set("playlist_id", id)
 	params.Set("cursor", "0")
@@ -285,7 +481,7 @@ func (s *Soda) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 
 	apiURL := "https://api.qishui.com/luna/pc/playlist/detail?" + params.Encode()
 
-	body, err := utils.Get(apiURL,
+	body, err := s.client.GetContext(ctx, apiURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -346,7 +542,7 @@ func (s *Soda) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("soda playlist detail json error: %w", err)
+		return nil, nil, model.Errorf("soda", model.ErrSchemaChanged, "playlist detail json error: %w", err)
 	}
 
 	pl := &model.Playlist{
@@ -451,27 +647,35 @@ func (s *Soda) fetchPlaylistDetail(id string) (*model.Playlist, []model.Song, er
 	return pl, songs, nil
 }
 
-// GetDownloadInfo 获取下载信息
-func (s *Soda) GetDownloadInfo(song *model.Song) (*DownloadInfo, error) {
-	if strings.Contains(song.URL, "#auth=") {
-		parts := strings.Split(song.URL, "#auth=")
-		if len(parts) == 2 {
-			auth, unescErr := url.QueryUnescape(parts[1])
-			if unescErr != nil {
-				slog.Warn("[soda] url.QueryUnescape failed, using raw value", "error", unescErr)
-				auth = parts[1]
-			}
-			return &DownloadInfo{
-				URL:      parts[0],
-				PlayAuth: auth,
-				Format:   song.Ext,
-				Size:     song.Size,
-			}, nil
-		}
+// GetDownloadInfoContext 获取下载信息，按 song.Extra["quality"] 从可用音频流中选择
+func (s *Soda) GetDownloadInfoContext(ctx context.Context, song *model.Song) (*DownloadInfo, error) {
+	streams, err := s.GetStreamsContext(ctx, song)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := song.ChooseStream(streams)
+	if !ok {
+		return nil, model.Errorf("soda", model.ErrRestricted, "no valid download url found in player info")
+	}
+	return &DownloadInfo{URL: st.URL, PlayAuth: st.Key, Format: st.Ext, Size: st.Size}, nil
+}
+
+// GetStreamsContext 列出歌曲可用的音频流。汽水音乐的音频都是加密的，
+// 每路流的 Key 为解密用的 PlayAuth
+func (s *Soda) GetStreamsContext(ctx context.Context, song *model.Song) ([]model.Stream, error) {
+	if audioURL, auth, ok := splitAuthURL(song.URL); ok {
+		return []model.Stream{{
+			URL:        audioURL,
+			Ext:        song.Ext,
+			Bitrate:    song.Bitrate,
+			Size:       song.Size,
+			Encryption: "playauth",
+			Key:        auth,
+		}}, nil
 	}
 
 	if song.Source != "soda" {
-		return nil, errors.New("source mismatch")
+		return nil, model.ErrSourceMismatch
 	}
 
 	trackID := song.ID
@@ -487,7 +691,7 @@ func (s *Soda) GetDownloadInfo(song *model.Song) (*DownloadInfo, error) {
 	params.Set("channel", "pc_web")
 
 	v2URL := "https://api.qishui.com/luna/pc/track_v2?" + params.Encode()
-	v2Body, err := utils.Get(v2URL,
+	v2Body, err := s.client.GetContext(ctx, v2URL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -501,13 +705,27 @@ func (s *Soda) GetDownloadInfo(song *model.Song) (*DownloadInfo, error) {
 		} `json:"track_player"`
 	}
 	if err := json.Unmarshal(v2Body, &v2Resp); err != nil {
-		return nil, fmt.Errorf("parse track_v2 response error: %w", err)
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "parse track_v2 response error: %w", err)
 	}
 	if v2Resp.TrackPlayer.URLPlayerInfo == "" {
-		return nil, errors.New("player info url not found")
+		return nil, model.Errorf("soda", model.ErrNotFound, "player info url not found")
 	}
 
-	return s.fetchPlayerInfo(v2Resp.TrackPlayer.URLPlayerInfo)
+	return s.fetchPlayerStreams(ctx, v2Resp.TrackPlayer.URLPlayerInfo)
+}
+
+// splitAuthURL 拆开 GetDownloadURL 返回的 "url#auth=<PlayAuth>"
+func splitAuthURL(u string) (audioURL, auth string, ok bool) {
+	parts := strings.Split(u, "#auth=")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	auth, err := url.QueryUnescape(parts[1])
+	if err != nil {
+		slog.Warn("[soda] url.QueryUnescape failed, using raw value", "error", err)
+		auth = parts[1]
+	}
+	return parts[0], auth, true
 }
 
 type DownloadInfo struct {
@@ -517,8 +735,19 @@ type DownloadInfo struct {
 	Size     int64
 }
 
-func (s *Soda) fetchPlayerInfo(playerInfoURL string) (*DownloadInfo, error) {
-	infoBody, err := utils.Get(playerInfoURL,
+// fetchPlayerInfo 取体积最大的一路音频流
+func (s *Soda) fetchPlayerInfo(ctx context.Context, playerInfoURL string) (*DownloadInfo, error) {
+	streams, err := s.fetchPlayerStreams(ctx, playerInfoURL)
+	if err != nil {
+		return nil, err
+	}
+	best := streams[0]
+	return &DownloadInfo{URL: best.URL, PlayAuth: best.Key, Format: best.Ext, Size: best.Size}, nil
+}
+
+// fetchPlayerStreams 解析 player info，返回按体积从大到小排列的音频流
+func (s *Soda) fetchPlayerStreams(ctx context.Context, playerInfoURL string) ([]model.Stream, error) {
+	infoBody, err := s.client.GetContext(ctx, playerInfoURL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -541,12 +770,12 @@ func (s *Soda) fetchPlayerInfo(playerInfoURL string) (*DownloadInfo, error) {
 		} `json:"Result"`
 	}
 	if err := json.Unmarshal(infoBody, &infoResp); err != nil {
-		return nil, fmt.Errorf("parse play info response error: %w", err)
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "parse play info response error: %w", err)
 	}
 
 	list := infoResp.Result.Data.PlayInfoList
 	if len(list) == 0 {
-		return nil, errors.New("no audio stream found")
+		return nil, model.Errorf("soda", model.ErrNotFound, "no audio stream found")
 	}
 
 	sort.Slice(list, func(i, j int) bool {
@@ -556,79 +785,135 @@ func (s *Soda) fetchPlayerInfo(playerInfoURL string) (*DownloadInfo, error) {
 		return list[i].Bitrate > list[j].Bitrate
 	})
 
-	best := list[0]
-	downloadURL := best.MainPlayUrl
-	if downloadURL == "" {
-		downloadURL = best.BackupPlayUrl
+	var streams []model.Stream
+	for _, info := range list {
+		downloadURL := info.MainPlayUrl
+		if downloadURL == "" {
+			downloadURL = info.BackupPlayUrl
+		}
+		if downloadURL == "" {
+			continue
+		}
+		bitrate := info.Bitrate
+		if bitrate > 10000 { // 接口返回的是 bps
+			bitrate /= 1000
+		}
+		streams = append(streams, model.Stream{
+			URL:        downloadURL,
+			Ext:        strings.ToLower(info.Format),
+			Bitrate:    bitrate,
+			Size:       info.Size,
+			Encryption: "playauth",
+			Key:        info.PlayAuth,
+		})
 	}
-	if downloadURL == "" {
-		return nil, errors.New("[soda] no valid download url found in player info")
+	if len(streams) == 0 {
+		return nil, model.Errorf("soda", model.ErrRestricted, "no valid download url found in player info")
 	}
-
-	return &DownloadInfo{URL: downloadURL, PlayAuth: best.PlayAuth, Format: best.Format, Size: best.Size}, nil
+	return streams, nil
 }
 
-// GetDownloadURL 返回下载链接
-func (s *Soda) GetDownloadURL(song *model.Song) (string, error) {
-	info, err := s.GetDownloadInfo(song)
+// GetDownloadURLContext 返回下载链接
+func (s *Soda) GetDownloadURLContext(ctx context.Context, song *model.Song) (string, error) {
+	info, err := s.GetDownloadInfoContext(ctx, song)
 	if err != nil {
 		return "", err
 	}
 	return info.URL + "#auth=" + url.QueryEscape(info.PlayAuth), nil
 }
 
-// Download 下载并解密歌曲
-func (s *Soda) Download(song *model.Song, outputPath string) error {
-	info, err := s.GetDownloadInfo(song)
+// DownloadContext 下载并解密歌曲，边下载边解密写入 outputPath
+func (s *Soda) DownloadContext(ctx context.Context, song *model.Song, outputPath string) error {
+	info, err := s.GetDownloadInfoContext(ctx, song)
 	if err != nil {
 		return fmt.Errorf("get download info failed: %w", err)
 	}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", info.URL, nil)
+	audio, err := s.OpenStreamContext(ctx, model.Stream{URL: info.URL, Ext: info.Format, Encryption: "playauth", Key: info.PlayAuth})
 	if err != nil {
 		return err
 	}
-	req.Header.Set("User-Agent", UserAgent)
+	defer audio.Close()
 
-	resp, err := client.Do(req)
+	f, err := os.Create(outputPath)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download status: %d", resp.StatusCode)
+	if _, err := io.Copy(f, audio); err != nil {
+		f.Close()
+		os.Remove(outputPath)
+		return fmt.Errorf("decrypt failed: %w", err)
 	}
+	return f.Close()
+}
 
-	encryptedData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+// OpenStreamContext 打开一路音频流，边下载边解密，读到的是解密后的 MP4。
+// st 可以是 GetStreams 返回的流，也可以只有 GetDownloadURL 返回的 "url#auth=" 链接
+func (s *Soda) OpenStreamContext(ctx context.Context, st model.Stream) (*model.AudioStream, error) {
+	if audioURL, auth, ok := splitAuthURL(st.URL); ok {
+		st.URL, st.Key = audioURL, auth
+	}
+	if st.Key == "" {
+		return nil, model.Errorf("soda", model.ErrInvalidInput, "missing play auth for %s", st.URL)
 	}
 
-	decryptedData, err := DecryptAudio(encryptedData, info.PlayAuth)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, st.URL, nil)
 	if err != nil {
-		return fmt.Errorf("decrypt failed: %w", err)
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	for k, v := range st.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.HTTPClientWithTimeout(streamTimeout).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &utils.StatusError{Method: req.Method, StatusCode: resp.StatusCode}
 	}
 
-	err = os.WriteFile(outputPath, decryptedData, 0644)
+	dec, err := NewDecryptReader(resp.Body, st.Key)
 	if err != nil {
-		return err
+		resp.Body.Close()
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "invalid play auth: %w", err)
+	}
+	ext := st.Ext
+	if ext == "" || ext == "mp4" {
+		ext = "m4a"
 	}
-	return nil
+	return &model.AudioStream{
+		ReadCloser:  decryptedBody{dec, resp.Body},
+		Ext:         ext,
+		ContentType: "audio/mp4",
+		Size:        resp.ContentLength, // 解密不改变长度
+	}, nil
 }
 
-// Parse 解析链接并获取完整信息
-func (s *Soda) Parse(link string) (*model.Song, error) {
+// decryptedBody 关闭时同时停止解密并关闭 HTTP 响应
+type decryptedBody struct {
+	io.ReadCloser
+	body io.Closer
+}
+
+func (d decryptedBody) Close() error {
+	d.ReadCloser.Close()
+	return d.body.Close()
+}
+
+// ParseContext 解析链接并获取完整信息
+func (s *Soda) ParseContext(ctx context.Context, link string) (*model.Song, error) {
 	re := regexp.MustCompile(`track/(\d+)`)
 	matches := re.FindStringSubmatch(link)
 	if len(matches) < 2 {
-		return nil, errors.New("invalid soda link")
+		return nil, model.Errorf("soda", model.ErrInvalidInput, "invalid soda link")
 	}
 	trackID := matches[1]
-	return s.fetchSongDetail(trackID)
+	return s.fetchSongDetail(ctx, trackID)
 }
 
-func (s *Soda) fetchSongDetail(trackID string) (*model.Song, error) {
+func (s *Soda) fetchSongDetail(ctx context.Context, trackID string) (*model.Song, error) {
 	params := url.Values{}
 	params.Set("track_id", trackID)
 	params.Set("media_type", "track")
@@ -637,7 +922,7 @@ func (s *Soda) fetchSongDetail(trackID string) (*model.Song, error) {
 	params.Set("channel", "pc_web")
 
 	v2URL := "https://api.qishui.com/luna/pc/track_v2?" + params.Encode()
-	v2Body, err := utils.Get(v2URL,
+	v2Body, err := s.client.GetContext(ctx, v2URL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -666,11 +951,11 @@ func (s *Soda) fetchSongDetail(trackID string) (*model.Song, error) {
 		} `json:"track_player"`
 	}
 	if err := json.Unmarshal(v2Body, &v2Resp); err != nil {
-		return nil, fmt.Errorf("parse track_v2 response error: %w", err)
+		return nil, model.Errorf("soda", model.ErrSchemaChanged, "parse track_v2 response error: %w", err)
 	}
 
 	if v2Resp.TrackInfo.ID == "" {
-		return nil, errors.New("track info not found")
+		return nil, model.Errorf("soda", model.ErrNotFound, "track info not found")
 	}
 
 	info := v2Resp.TrackInfo
@@ -703,7 +988,7 @@ func (s *Soda) fetchSongDetail(trackID string) (*model.Song, error) {
 	}
 
 	if v2Resp.TrackPlayer.URLPlayerInfo != "" {
-		dInfo, err := s.fetchPlayerInfo(v2Resp.TrackPlayer.URLPlayerInfo)
+		dInfo, err := s.fetchPlayerInfo(ctx, v2Resp.TrackPlayer.URLPlayerInfo)
 		if err == nil {
 			song.URL = dInfo.URL + "#auth=" + url.QueryEscape(dInfo.PlayAuth)
 			song.Size = dInfo.Size
@@ -717,10 +1002,10 @@ func (s *Soda) fetchSongDetail(trackID string) (*model.Song, error) {
 	return song, nil
 }
 
-// GetLyrics 获取歌词
-func (s *Soda) GetLyrics(song *model.Song) (string, error) {
+// GetLyricsContext 获取歌词
+func (s *Soda) GetLyricsContext(ctx context.Context, song *model.Song) (string, error) {
 	if song.Source != "soda" {
-		return "", errors.New("source mismatch")
+		return "", model.ErrSourceMismatch
 	}
 
 	trackID := song.ID
@@ -736,7 +1021,7 @@ func (s *Soda) GetLyrics(song *model.Song) (string, error) {
 	params.Set("channel", "pc_web")
 
 	v2URL := "https://api.qishui.com/luna/pc/track_v2?" + params.Encode()
-	body, err := utils.Get(v2URL,
+	body, err := s.client.GetContext(ctx, v2URL,
 		utils.WithHeader("User-Agent", UserAgent),
 		utils.WithHeader("Cookie", s.cookie),
 	)
@@ -750,7 +1035,7 @@ func (s *Soda) GetLyrics(song *model.Song) (string, error) {
 		} `json:"lyric"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("failed to parse lyric JSON: %w", err)
+		return "", model.Errorf("soda", model.ErrSchemaChanged, "failed to parse lyric JSON: %w", err)
 	}
 	if resp.Lyric.Content == "" {
 		return "", nil
@@ -784,4 +1069,4 @@ func parseSodaLyric(raw string) string {
 		}
 	}
 	return sb.String()
-}
\ No newline at end of file
+}
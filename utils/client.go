@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent 是未指定 UA 时使用的默认值 (模拟 Chrome)
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// DefaultTimeout 是接口请求的默认超时时间
+const DefaultTimeout = 15 * time.Second
+
+// Client 是可配置的 HTTP 客户端。每个平台实例持有自己的 Client，
+// 代理、超时、Cookie Jar 和传输层可以按平台分别设置。
+// nil 的 *Client 等价于包级默认客户端
+type Client struct {
+	hc        *http.Client
+	userAgent string
+	limiter   *limiter // nil 表示不限流
+	breaker   *breaker // nil 表示不熔断
+}
+
+type clientConfig struct {
+	timeout   time.Duration
+	proxy     func(*http.Request) (*url.URL, error)
+	jar       http.CookieJar
+	transport http.RoundTripper
+	userAgent string
+	rate      float64
+	burst     int
+	threshold int
+	cooldown  time.Duration
+	baseURL   *url.URL
+}
+
+// ClientOption 配置 NewClient 创建的客户端
+type ClientOption func(*clientConfig)
+
+// WithTimeout 设置单次请求的超时时间，0 表示不限时
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *clientConfig) { c.timeout = d }
+}
+
+// WithProxy 指定代理地址，支持 http、https 和 socks5 (socks5h)。
+// proxy 为 nil 表示直连，忽略 HTTP_PROXY 等环境变量。
+// 不设置时沿用环境变量中的代理
+func WithProxy(proxy *url.URL) ClientOption {
+	return func(c *clientConfig) {
+		if proxy == nil {
+			c.proxy = nil
+			return
+		}
+		c.proxy = http.ProxyURL(proxy)
+	}
+}
+
+// WithCookieJar 设置 Cookie Jar，用于保存接口下发的 Set-Cookie
+func WithCookieJar(jar http.CookieJar) ClientOption {
+	return func(c *clientConfig) { c.jar = jar }
+}
+
+// WithTransport 替换底层传输层，例如测试用的 RoundTripper 或自定义连接池。
+// 设置后 WithProxy 不再生效，代理由传入的传输层自己决定
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *clientConfig) { c.transport = rt }
+}
+
+// WithUserAgent 替换默认 UA，请求选项里显式设置的 User-Agent 仍然优先
+func WithUserAgent(ua string) ClientOption {
+	return func(c *clientConfig) { c.userAgent = ua }
+}
+
+// OriginalHostHeader 记录 WithBaseURL 改写前的 Host，镜像或回放服务器据此区分原始接口
+const OriginalHostHeader = "X-Original-Host"
+
+// WithBaseURL 把所有请求改发到 base (保留原路径和参数)，原始 Host 写在 OriginalHostHeader 里。
+// 用于接入自建镜像或在测试中指向 httptest 服务器，base 为 nil 表示不改写
+func WithBaseURL(base *url.URL) ClientOption {
+	return func(c *clientConfig) { c.baseURL = base }
+}
+
+// WithRateLimit 限制接口请求频率为每秒 perSecond 次，允许突发 burst 次。
+// perSecond 不大于 0 表示不限流 (默认)
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(c *clientConfig) {
+		c.rate = perSecond
+		c.burst = burst
+	}
+}
+
+// WithBreaker 设置熔断器：连续失败 threshold 次后打开，cooldown 后放行一个探测请求。
+// threshold 不大于 0 表示关闭熔断。默认 5 次、30 秒
+func WithBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.threshold = threshold
+		c.cooldown = cooldown
+	}
+}
+
+// ParseProxy 解析代理地址，空字符串、"direct" 和 "none" 表示直连并返回 nil
+func ParseProxy(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "", "direct", "none":
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %q", raw, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// NewClient 创建一个 HTTP 客户端，默认超时 15 秒，代理取自环境变量，不限流，
+// 连续失败 5 次后熔断 30 秒。限流和熔断只作用于 GetContext、PostContext 等接口请求，
+// 不影响 HTTPClient 返回的客户端 (例如下载音频)
+func NewClient(opts ...ClientOption) *Client {
+	cfg := clientConfig{
+		timeout:   DefaultTimeout,
+		proxy:     http.ProxyFromEnvironment,
+		userAgent: DefaultUserAgent,
+		threshold: DefaultBreakerThreshold,
+		cooldown:  DefaultBreakerCooldown,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	rt := cfg.transport
+	if rt == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = cfg.proxy
+		rt = t
+	}
+	if cfg.baseURL != nil {
+		rt = &rewriteTransport{base: cfg.baseURL, next: rt}
+	}
+	c := &Client{
+		hc: &http.Client{
+			Timeout:   cfg.timeout,
+			Transport: rt,
+			Jar:       cfg.jar,
+		},
+		userAgent: cfg.userAgent,
+	}
+	if cfg.rate > 0 {
+		c.limiter = newLimiter(cfg.rate, cfg.burst)
+	}
+	if cfg.threshold > 0 {
+		c.breaker = newBreaker(cfg.threshold, cfg.cooldown)
+	}
+	return c
+}
+
+var defaultClient = NewClient()
+
+// DefaultClient 返回包级函数 Get、Post 等使用的客户端
+func DefaultClient() *Client { return defaultClient }
+
+func (c *Client) orDefault() *Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
+
+// HTTPClient 返回底层的 *http.Client，供需要自己构造请求的代码使用
+func (c *Client) HTTPClient() *http.Client {
+	return c.orDefault().hc
+}
+
+// HTTPClientWithTimeout 返回一个与 c 共用传输层、代理和 Cookie Jar 但超时不同的 *http.Client，
+// 用于下载音频这类耗时较长的请求。timeout 为 0 表示不限时
+func (c *Client) HTTPClientWithTimeout(timeout time.Duration) *http.Client {
+	hc := *c.orDefault().hc
+	hc.Timeout = timeout
+	return &hc
+}
+
+// UserAgent 返回客户端默认使用的 UA
+func (c *Client) UserAgent() string {
+	return c.orDefault().userAgent
+}
+
+// newRequest 构造请求并设置默认 UA，再应用额外选项
+func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader, opts []RequestOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.orDefault().userAgent)
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req, nil
+}
+
+// send 经过熔断和限流后发送请求，并把结果计入熔断器
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	c = c.orDefault()
+	if err := c.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := c.hc.Do(req)
+	c.report(req.Context(), resp, err)
+	return resp, err
+}
+
+// do 发送请求，非 200 时返回 *StatusError
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, newStatusError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// GetContext 发送 HTTP GET 请求，ctx 取消或超时后请求会被中断
+func (c *Client) GetContext(ctx context.Context, url string, opts ...RequestOption) ([]byte, error) {
+	req, err := c.newRequest(ctx, "GET", url, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// PostContext 发送 HTTP POST 请求，ctx 取消或超时后请求会被中断
+func (c *Client) PostContext(ctx context.Context, url string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	req, err := c.newRequest(ctx, "POST", url, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// GetRawContext 发送 HTTP GET 请求并返回原始 *http.Response，调用方负责关闭 resp.Body
+func (c *Client) GetRawContext(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	req, err := c.newRequest(ctx, "GET", url, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(req)
+}
+
+// PostRawContext 发送 HTTP POST 请求并返回原始 *http.Response，调用方负责关闭 resp.Body
+func (c *Client) PostRawContext(ctx context.Context, url string, body io.Reader, opts ...RequestOption) (*http.Response, error) {
+	req, err := c.newRequest(ctx, "POST", url, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(req)
+}
+
+// rewriteTransport 把请求改发到 base，见 WithBaseURL
+type rewriteTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set(OriginalHostHeader, req.URL.Host)
+	r.URL.Scheme = t.base.Scheme
+	r.URL.Host = t.base.Host
+	r.URL.Path = strings.TrimSuffix(t.base.Path, "/") + req.URL.Path
+	r.URL.RawPath = ""
+	r.Host = ""
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 表示熔断器处于打开状态，请求没有发出
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// 熔断器默认在连续失败 5 次后打开，30 秒后进入半开状态放行一个探测请求
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerState 是熔断器的状态快照
+type BreakerState struct {
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`            // 连续失败次数
+	OpenedAt *time.Time `json:"opened_at,omitempty"` // 最近一次打开的时间
+	RetryAt  *time.Time `json:"retry_at,omitempty"`  // 打开状态下，进入半开的时间
+}
+
+// breaker 是按连续失败次数计数的熔断器。
+// 打开后经过 cooldown 进入半开状态，只放行一个探测请求：成功则关闭，失败则重新打开
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time // 零值表示关闭
+	probing   bool      // 半开状态下探测请求是否已发出
+	now       func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow 判断请求能否发出，半开状态下只放行一个
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.now().Sub(b.openedAt) < b.cooldown || b.probing {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// success 记录一次成功，关闭熔断器
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// failure 记录一次失败，达到阈值或半开探测失败时打开熔断器
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.probing || !b.openedAt.IsZero() || b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+	b.probing = false
+}
+
+// cancel 用于请求被调用方取消的情况，既不算成功也不算失败
+func (b *breaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) state() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := BreakerState{State: BreakerClosed, Failures: b.failures}
+	if b.openedAt.IsZero() {
+		return st
+	}
+	opened := b.openedAt
+	retry := opened.Add(b.cooldown)
+	st.OpenedAt = &opened
+	if b.now().Before(retry) {
+		st.State = BreakerOpen
+		st.RetryAt = &retry
+	} else {
+		st.State = BreakerHalfOpen
+	}
+	return st
+}
+
+// limiter 是令牌桶限流器，每秒补充 rate 个令牌，最多攒 burst 个
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait 取一个令牌，没有时等待补充；ctx 结束时归还预占的令牌并返回 ctx.Err()
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// 先预占令牌，令牌可以为负，等待时间按欠下的令牌数计算，保证先到先得
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(deficit / l.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// acquire 在发请求前检查熔断器并等待限流令牌
+func (c *Client) acquire(ctx context.Context) error {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			if c.breaker != nil {
+				c.breaker.cancel()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// report 把请求结果计入熔断器：网络错误、超时、5xx 和 429 算失败，调用方取消不计。
+// 上游一直不响应直到 ctx 超时也算失败，这正是限流时最常见的表现
+func (c *Client) report(ctx context.Context, resp *http.Response, err error) {
+	if c.breaker == nil {
+		return
+	}
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		c.breaker.cancel()
+	case err != nil:
+		c.breaker.failure()
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		c.breaker.failure()
+	default:
+		c.breaker.success()
+	}
+}
+
+// Breaker 返回熔断器的状态，未启用熔断时总是 closed
+func (c *Client) Breaker() BreakerState {
+	c = c.orDefault()
+	if c.breaker == nil {
+		return BreakerState{State: BreakerClosed}
+	}
+	return c.breaker.state()
+}
+
+// Available 报告熔断器是否允许发请求，半开状态也算可用
+func (c *Client) Available() bool {
+	return c.Breaker().State != BreakerOpen
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreaker_OpensAndHalfOpens(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newBreaker(3, 10*time.Second)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		b.failure()
+	}
+	if err := b.allow(); err != nil || b.state().State != BreakerClosed {
+		t.Fatalf("below threshold should stay closed, got %v %s", err, b.state().State)
+	}
+	b.failure()
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if st := b.state(); st.State != BreakerOpen || st.RetryAt == nil || !st.RetryAt.Equal(now.Add(10*time.Second)) {
+		t.Fatalf("unexpected state %+v", st)
+	}
+
+	now = now.Add(10 * time.Second)
+	if st := b.state().State; st != BreakerHalfOpen {
+		t.Fatalf("expected half-open, got %s", st)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("half-open should let one probe through, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe should be rejected, got %v", err)
+	}
+
+	// A failed probe reopens the breaker for another cooldown.
+	b.failure()
+	if st := b.state().State; st != BreakerOpen {
+		t.Fatalf("failed probe should reopen, got %s", st)
+	}
+
+	now = now.Add(10 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.success()
+	if st := b.state(); st.State != BreakerClosed || st.Failures != 0 {
+		t.Fatalf("successful probe should close, got %+v", st)
+	}
+}
+
+func TestBreaker_CancelReleasesProbe(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newBreaker(1, time.Second)
+	b.now = func() time.Time { return now }
+	b.failure()
+	now = now.Add(time.Second)
+
+	if err := b.allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.cancel()
+	if err := b.allow(); err != nil {
+		t.Fatalf("cancelled probe should free the slot, got %v", err)
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := newLimiter(20, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two tokens are available up front; the other two take 50ms each.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("unexpected elapsed %v", elapsed)
+	}
+}
+
+func TestLimiter_WaitCancelled(t *testing.T) {
+	l := newLimiter(0.1, 1)
+	_ = l.wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClient_BreakerTripsOnServerErrors(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBreaker(2, time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetContext(context.Background(), srv.URL); err == nil {
+			t.Fatal("expected status error")
+		}
+	}
+	if c.Available() {
+		t.Fatal("breaker should be open after 2 failures")
+	}
+	if _, err := c.GetContext(context.Background(), srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("open breaker should not reach the server, calls = %d", calls)
+	}
+}
+
+func TestClient_NotFoundDoesNotTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBreaker(1, time.Minute))
+	for i := 0; i < 3; i++ {
+		_, _ = c.GetContext(context.Background(), srv.URL)
+	}
+	if st := c.Breaker(); st.State != BreakerClosed {
+		t.Fatalf("4xx answers should not open the breaker, got %+v", st)
+	}
+}
+
+func TestClient_DeadlineTripsCancelDoesNot(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient(WithBreaker(2, time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { time.Sleep(20 * time.Millisecond); cancel() }()
+	if _, err := c.GetContext(ctx, srv.URL); err == nil {
+		t.Fatal("expected an error from a cancelled call")
+	}
+	if st := c.Breaker(); st.Failures != 0 {
+		t.Fatalf("a cancelled call should not count, got %+v", st)
+	}
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err := c.GetContext(ctx, srv.URL)
+		cancel()
+		if err == nil {
+			t.Fatal("expected a timeout")
+		}
+	}
+	if c.Available() {
+		t.Fatalf("calls that hang until their deadline should open the breaker, got %+v", c.Breaker())
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+// MaxPagedItems 是 FetchPages 最多取回的条数，接口报告的总数或“还有更多”有误时据此停止翻页
+const MaxPagedItems = 2000
+
+// FetchPages 逐页调用 fetch 取回整个列表。page 从 0 开始，offset 是已经取到的条数，
+// fetch 返回本页的条目以及是否还有下一页。遇到空页、没有下一页或取满 MaxPagedItems 条时停止；
+// 任何一页出错都返回该错误
+func FetchPages[T any](fetch func(page, offset int) (items []T, more bool, err error)) ([]T, error) {
+	all := []T{}
+	for page := 0; len(all) < MaxPagedItems; page++ {
+		items, more, err := fetch(page, len(all))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) == 0 || !more {
+			break
+		}
+	}
+	return all, nil
+}
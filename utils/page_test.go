@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFetchPages(t *testing.T) {
+	var offsets []int
+	got, err := FetchPages(func(page, offset int) ([]int, bool, error) {
+		offsets = append(offsets, offset)
+		items := []int{page * 2, page*2 + 1}
+		return items, offset+len(items) < 5, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 6 || len(offsets) != 3 || offsets[2] != 4 {
+		t.Errorf("got %v with offsets %v", got, offsets)
+	}
+
+	// 一直报告还有更多时，取满 MaxPagedItems 条就停
+	pages := 0
+	got, _ = FetchPages(func(page, offset int) ([]int, bool, error) {
+		pages++
+		return make([]int, 100), true, nil
+	})
+	if len(got) != MaxPagedItems || pages != MaxPagedItems/100 {
+		t.Errorf("got %d items in %d pages", len(got), pages)
+	}
+
+	boom := errors.New("boom")
+	if _, err := FetchPages(func(page, offset int) ([]int, bool, error) {
+		if page == 1 {
+			return nil, false, boom
+		}
+		return []int{1}, true, nil
+	}); !errors.Is(err, boom) {
+		t.Errorf("err = %v", err)
+	}
+}
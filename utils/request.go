@@ -1,16 +1,43 @@
 package utils
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-var defaultClient = &http.Client{
-	Timeout: 15 * time.Second,
+// StatusError 表示上游返回了非 200 的 HTTP 状态码
+type StatusError struct {
+	Method     string
+	StatusCode int
+	RetryAfter time.Duration // 来自 Retry-After 响应头，没有时为 0
+}
+
+func (e *StatusError) Error() string {
+	if e.Method == http.MethodPost {
+		return fmt.Sprintf("http post failed: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("http request failed: status %d", e.StatusCode)
+}
+
+// newStatusError 从响应构造 StatusError，Retry-After 支持秒数和 HTTP 日期两种格式
+func newStatusError(resp *http.Response) *StatusError {
+	e := &StatusError{Method: resp.Request.Method, StatusCode: resp.StatusCode}
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil && secs > 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(ra); err == nil {
+			if d := time.Until(t); d > 0 {
+				e.RetryAfter = d
+			}
+		}
+	}
+	return e
 }
 
 // RequestOption 定义请求选项函数
@@ -25,94 +52,45 @@ func WithHeader(key, value string) RequestOption {
 
 // Get 发送 HTTP GET 请求
 func Get(url string, opts ...RequestOption) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// 默认 UA (模拟 Chrome)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-	// 应用额外选项
-	for _, opt := range opts {
-		opt(req)
-	}
-
-	resp, err := defaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("http request failed: status %d", resp.StatusCode)
-	}
+	return GetContext(context.Background(), url, opts...)
+}
 
-	return io.ReadAll(resp.Body)
+// GetContext 发送 HTTP GET 请求，ctx 取消或超时后请求会被中断
+func GetContext(ctx context.Context, url string, opts ...RequestOption) ([]byte, error) {
+	return defaultClient.GetContext(ctx, url, opts...)
 }
 
 // Post 发送 HTTP POST 请求
 // body 这里的类型是 io.Reader，可以传 strings.NewReader(form.Encode())
 func Post(url string, body io.Reader, opts ...RequestOption) ([]byte, error) {
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return nil, err
-	}
-
-	// 默认 UA (模拟 Chrome)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	
-	// 默认 content type 如果用户没传，通常默认 json 或 form，这里留给 opts 控制
-	
-	for _, opt := range opts {
-		opt(req)
-	}
-
-	resp, err := defaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("http post failed: status %d", resp.StatusCode)
-	}
+	return PostContext(context.Background(), url, body, opts...)
+}
 
-	return io.ReadAll(resp.Body)
+// PostContext 发送 HTTP POST 请求，ctx 取消或超时后请求会被中断
+func PostContext(ctx context.Context, url string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	return defaultClient.PostContext(ctx, url, body, opts...)
 }
 
 // PostRaw 发送 HTTP POST 请求并返回原始 *http.Response
 // 调用方负责关闭 resp.Body 和提取 Cookie
 func PostRaw(url string, body io.Reader, opts ...RequestOption) (*http.Response, error) {
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-	for _, opt := range opts {
-		opt(req)
-	}
+	return PostRawContext(context.Background(), url, body, opts...)
+}
 
-	return defaultClient.Do(req)
+// PostRawContext 是 PostRaw 的 context 版本
+func PostRawContext(ctx context.Context, url string, body io.Reader, opts ...RequestOption) (*http.Response, error) {
+	return defaultClient.PostRawContext(ctx, url, body, opts...)
 }
 
 // GetRaw 发送 HTTP GET 请求并返回原始 *http.Response
 // 调用方负责关闭 resp.Body
 func GetRaw(url string, opts ...RequestOption) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-	for _, opt := range opts {
-		opt(req)
-	}
+	return GetRawContext(context.Background(), url, opts...)
+}
 
-	return defaultClient.Do(req)
+// GetRawContext 是 GetRaw 的 context 版本
+func GetRawContext(ctx context.Context, url string, opts ...RequestOption) (*http.Response, error) {
+	return defaultClient.GetRawContext(ctx, url, opts...)
 }
 
 // MD5 计算字符串哈希